@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/yanakipre/bot/app/cyprusapis/cmd/cyprusapis/internal"
+)
+
+func main() {
+	internal.Execute()
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/yanakipre/bot/app/cyprusapis/internal/pkg/staticconfig"
+)
+
+const configgenCmdName = "configgen"
+
+// configgenCmd represents the init command
+var configgenCmd = &cobra.Command{
+	Use:   configgenCmdName,
+	Short: "Generate example config to STDOUT.",
+	Example: `
+Generate config and put it into default place:
+
+	cyprusapis configgen > ~/.cyprusapis/config.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		marshalled, err := yaml.Marshal(staticconfig.DefaultConfig())
+		if err != nil {
+			return fmt.Errorf("could not marshal config: %w", err)
+		}
+		fmt.Print(string(marshalled))
+		return nil
+	},
+}
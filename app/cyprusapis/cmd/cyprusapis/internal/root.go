@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/cyprusapis/cmd/cyprusapis/internal/rootcmd"
+	"github.com/yanakipre/bot/app/cyprusapis/cmd/cyprusapis/internal/serve"
+	"github.com/yanakipre/bot/app/cyprusapis/internal/pkg/staticconfig"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+var rootCmd *cobra.Command
+
+func Execute() {
+	err := rootCmd.Execute()
+	if err != nil {
+		logger.SetNewGlobalLoggerQuietly(logger.DefaultConfig())
+		logger.Error(rootCmd.Context(), fmt.Errorf("error executing root command: %w", err))
+	}
+	cobra.CheckErr(err)
+}
+
+func init() {
+	rootCmd = rootcmd.NewRootCmd(func(cmd *cobra.Command, cfg *staticconfig.Config) {
+		cmd.AddCommand(serve.Command(cfg))
+		cmd.AddCommand(versionCmd)
+		cmd.AddCommand(configgenCmd)
+	})
+}
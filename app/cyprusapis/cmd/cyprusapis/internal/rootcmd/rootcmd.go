@@ -0,0 +1,85 @@
+package rootcmd
+
+import (
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/yanakipre/bot/app/cyprusapis/internal/pkg/staticconfig"
+	"github.com/yanakipre/bot/internal/config"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+const (
+	defaultConfigName = "config.yaml"
+	binaryName        = "cyprusapis"
+)
+
+const (
+	rootExampleMsg = `
+Show available commands:
+
+	cyprusapis --help
+
+	mkdir ~/.cyprusapis/
+	cyprusapis configgen > ~/.cyprusapis/config.yaml
+
+By default logs are printed to stdout, you can configure sink to some static place.
+`
+	rootLongDescription = `This binary serves APIs over open data of Cyprus,
+see app/cyprusapis/apispec.
+`
+)
+
+var (
+	// cfgName is Name of config to use
+	cfgName string
+	cfg     = lo.ToPtr(staticconfig.DefaultConfig())
+)
+
+// NewRootCmd represents the base command when called without any subcommands
+func NewRootCmd(visit func(*cobra.Command, *staticconfig.Config)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           binaryName,
+		Short:         "Open data APIs of Cyprus.",
+		Long:          rootLongDescription,
+		Example:       rootExampleMsg,
+		SilenceUsage:  true, // do not output usage on error
+		SilenceErrors: true, // do not print error twice
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// do not load config when generating config
+			if cmd.Name() == "configgen" {
+				return nil
+			}
+
+			if cfgName == "" {
+				cfgName = defaultConfigName
+			}
+
+			if err := config.Load(ctx, binaryName, cfg, cfgName); err != nil {
+				return err
+			}
+
+			logger.SetNewGlobalLoggerOnce(cfg.Logging)
+
+			marshal, err := yaml.Marshal(cfg)
+			if err != nil {
+				return err
+			}
+			logger.Debug(ctx, "running with config", zap.ByteString("config", marshal))
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVarP(
+		&cfgName,
+		"config",
+		"c",
+		defaultConfigName,
+		"Use a specific config located in application directory.",
+	)
+	visit(cmd, cfg)
+	return cmd
+}
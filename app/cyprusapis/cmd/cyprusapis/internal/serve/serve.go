@@ -0,0 +1,67 @@
+package serve
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/cyprusapis/internal/pkg/staticconfig"
+	"github.com/yanakipre/bot/app/cyprusapis/internal/pkg/transport/busestransport"
+	"github.com/yanakipre/bot/internal/application"
+	"github.com/yanakipre/bot/internal/buses/busregistry"
+)
+
+// Command represents serve command
+func Command(cfg *staticconfig.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the buses API.",
+		Long: `Serves apispec/busesv1/buses-v1.yaml on buses_api.app.addr,
+under buses_api.app.base_url. Health is reported on /healthz, metrics on /metrics.
+
+Every feed of buses.feeds serves its area. Positions of buses are polled in the background every timer of the feed.
+The timetable is downloaded from static_url of the feed on start and every static_refresh,
+the copy of Cyprus embedded at build time is used until the download succeeds, unless embedded_static is off.
+The embedded copy has routes only, lookups of stops and arrivals fail without static_url.
+`,
+		Example: `
+Start the API:
+
+	cyprusapis serve
+
+Find approaching buses:
+
+	curl -X POST localhost:8081/buses/v1/find-approaching-routes -d '{"position": {"lat": 34.684422, "lon": 33.037147}}'
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			client := busregistry.New(cfg.Buses)
+			api, err := busestransport.New(client, cfg.BusesAPI)
+			if err != nil {
+				return fmt.Errorf("new buses api: %w", err)
+			}
+
+			app := application.New()
+			// timetables of the feeds are loaded before the traffic.
+			app.ReadyCheck(client)
+			app.AddComponent(api)
+			app.IsReady(ctx)
+
+			go client.Run(ctx)
+			app.Start(ctx)
+
+			<-ctx.Done()
+			app.Shutdown(10 * time.Second)
+			return nil
+		},
+	}
+}
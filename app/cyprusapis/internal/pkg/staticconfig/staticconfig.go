@@ -0,0 +1,37 @@
+package staticconfig
+
+import (
+	"errors"
+
+	"github.com/yanakipre/bot/app/cyprusapis/internal/pkg/transport/busestransport"
+	"github.com/yanakipre/bot/internal/buses/busregistry"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+type Config struct {
+	Logging logger.Config `yaml:"logging"`
+	// BusesAPI is served by "cyprusapis serve".
+	BusesAPI busestransport.Config `yaml:"buses_api"`
+	// Buses are the feeds of bus operators, each with its timetable and realtime positions.
+	Buses busregistry.Config `yaml:"buses"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Logging:  logger.DefaultConfig(),
+		BusesAPI: busestransport.DefaultConfig(),
+		Buses:    busregistry.DefaultConfig(),
+	}
+}
+
+func (c *Config) DefaultConfig() {
+	*c = DefaultConfig()
+}
+
+// All sub validations should go here.
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.BusesAPI.App.Validate(),
+		c.Buses.Validate(),
+	)
+}
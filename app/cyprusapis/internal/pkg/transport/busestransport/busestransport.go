@@ -0,0 +1,226 @@
+// Package busestransport serves the buses API, apispec/busesv1/buses-v1.yaml.
+package busestransport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/samber/lo"
+
+	"github.com/yanakipre/bot/app/cyprusapis/internal/pkg/transport/busestransport/busesv1"
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/openapiapp"
+	"github.com/yanakipre/bot/internal/resttooling"
+	"github.com/yanakipre/bot/internal/resttooling/metricsapi"
+	"github.com/yanakipre/bot/internal/resttooling/requestid"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+var errAnonymous = errors.New("request is anonymous")
+
+// New creates the buses API application. Run it with App.StartServer.
+// Metrics are served on /metrics next to /healthz.
+func New(client buses.Client, cfg Config) (*openapiapp.App, error) {
+	h := &handler{client: client}
+	errorHandler := openapiapp.ErrorHandler(h.NewError)
+	srv, err := busesv1.NewServer(
+		h,
+		busesv1.WithErrorHandler(errorHandler),
+		busesv1.WithNotFound(openapiapp.NotFound(errorHandler)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	routeName := resttooling.UrlMethodGetter(srv, "unknown")
+	writeError := func(w http.ResponseWriter, r *http.Request, appErr error) {
+		errorHandler(r.Context(), w, r, appErr)
+	}
+	app := openapiapp.New(
+		cfg.App,
+		srv,
+		// first middleware in the list is executed last
+		openapiapp.Middlewares(
+			resttooling.LoggingMiddleware(
+				cfg.App.Name,
+				routeName,
+				// the API is public.
+				func(ctx context.Context) (string, error) { return "", errAnonymous },
+				resttooling.SubjectIdentityAsUserID,
+			),
+			resttooling.MetricsMiddleware(cfg.App.Name, routeName),
+			resttooling.SentryMiddleware(routeName),
+			resttooling.RecoveryMiddleware(writeError),
+		),
+		func(ctx context.Context) (any, error) {
+			return map[string]string{"status": "ok", "gtfs_static_version": client.StaticVersion()}, nil
+		},
+	)
+	app.Mux.Handle("/metrics", metricsapi.NewMetricsHandler(resttooling.SubjectIdentityAsUserID))
+	return app, nil
+}
+
+type handler struct {
+	client buses.Client
+}
+
+var _ busesv1.Handler = (*handler)(nil)
+
+func (h *handler) FindApproachingBuses(
+	ctx context.Context,
+	req busesv1.OptFindApproachingBusesReq,
+) (*busesv1.FindApproachingBusesOK, error) {
+	dot, err := fromDot(req.Value.Position)
+	if err != nil {
+		return nil, err
+	}
+	found, err := h.client.GetNearest(ctx, dot)
+	if err != nil {
+		return nil, err
+	}
+	return &busesv1.FindApproachingBusesOK{
+		Buses: lo.Map(found, func(item buses.Bus, _ int) busesv1.Bus {
+			bus := toBus(item)
+			bus.Distance = busesv1.NewOptFloat32(float32(geo.Distance(dot, item.Position)))
+			return bus
+		}),
+	}, nil
+}
+
+func (h *handler) FindNearestStops(ctx context.Context, req *busesv1.FindNearestStopsReq) (*busesv1.FindNearestStopsOK, error) {
+	dot, err := fromDot(busesv1.NewOptDot(req.Position))
+	if err != nil {
+		return nil, err
+	}
+	stops, err := h.client.NearestStops(ctx, dot, req.Limit.Or(5))
+	if err != nil {
+		return nil, err
+	}
+	return &busesv1.FindNearestStopsOK{
+		Stops: lo.Map(stops, func(item buses.Stop, _ int) busesv1.Stop {
+			stop := toStop(item)
+			stop.Distance = busesv1.NewOptFloat32(float32(geo.Distance(dot, item.Position)))
+			return stop
+		}),
+	}, nil
+}
+
+func (h *handler) ListRoutes(ctx context.Context) (*busesv1.ListRoutesOK, error) {
+	routes, err := h.client.Routes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &busesv1.ListRoutesOK{Routes: lo.Map(routes, func(item buses.Route, _ int) busesv1.BusRoute {
+		return toRoute(item)
+	})}, nil
+}
+
+func (h *handler) GetRoute(ctx context.Context, params busesv1.GetRouteParams) (busesv1.GetRouteRes, error) {
+	route, err := h.client.Route(ctx, params.RouteID)
+	if err != nil {
+		return nil, err
+	}
+	return lo.ToPtr(toRoute(route)), nil
+}
+
+func (h *handler) GetStop(ctx context.Context, params busesv1.GetStopParams) (busesv1.GetStopRes, error) {
+	stop, err := h.client.Stop(ctx, params.StopID)
+	if err != nil {
+		return nil, err
+	}
+	return lo.ToPtr(toStop(stop)), nil
+}
+
+func (h *handler) GetStopArrivals(
+	ctx context.Context,
+	params busesv1.GetStopArrivalsParams,
+) (busesv1.GetStopArrivalsRes, error) {
+	arrivals, err := h.client.Arrivals(ctx, params.StopID, params.Route.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &busesv1.GetStopArrivalsOK{
+		Arrivals: lo.Map(arrivals, func(item buses.Arrival, _ int) busesv1.Arrival {
+			return busesv1.Arrival{
+				Route:     toRoute(item.Route),
+				TripID:    item.TripID,
+				Headsign:  optString(item.Headsign),
+				BusID:     optString(item.BusID),
+				ArrivesAt: item.At,
+				Estimate:  busesv1.ArrivalEstimate(item.Estimate),
+			}
+		}),
+	}, nil
+}
+
+func (h *handler) GetVehicle(ctx context.Context, params busesv1.GetVehicleParams) (busesv1.GetVehicleRes, error) {
+	bus, err := h.client.Vehicle(ctx, params.BusID)
+	if err != nil {
+		return nil, err
+	}
+	return lo.ToPtr(toBus(bus)), nil
+}
+
+func (h *handler) NewError(ctx context.Context, err error) *busesv1.GeneralErrorStatusCode {
+	statusCode, _, message := openapiapp.PresentError(ctx, err)
+	resp := busesv1.GeneralError{Error: message}
+	if reqID, ok := requestid.FromContext(ctx); ok {
+		resp.RequestID = busesv1.NewOptString(reqID)
+	}
+	return &busesv1.GeneralErrorStatusCode{StatusCode: statusCode, Response: resp}
+}
+
+func fromDot(dot busesv1.OptDot) (geo.Point, error) {
+	if !dot.Value.Lat.Set || !dot.Value.Lon.Set {
+		return geo.Point{}, semerr.InvalidInput("position with lat and lon is required")
+	}
+	return geo.Point{Lat: float64(dot.Value.Lat.Value), Lon: float64(dot.Value.Lon.Value)}, nil
+}
+
+func toDot(dot geo.Point) busesv1.Dot {
+	return busesv1.Dot{
+		Lat: busesv1.NewOptFloat32(float32(dot.Lat)),
+		Lon: busesv1.NewOptFloat32(float32(dot.Lon)),
+	}
+}
+
+func toRoute(route buses.Route) busesv1.BusRoute {
+	return busesv1.BusRoute{
+		RouteID:   optString(route.ID),
+		ShortName: optString(route.ShortName),
+		LongName:  optString(route.LongName),
+		Operator:  optString(route.Operator),
+	}
+}
+
+func toStop(stop buses.Stop) busesv1.Stop {
+	return busesv1.Stop{
+		StopID:   busesv1.NewOptString(stop.ID),
+		Name:     optString(stop.Name),
+		Position: busesv1.NewOptDot(toDot(stop.Position)),
+		Operator: optString(stop.Operator),
+	}
+}
+
+func toBus(bus buses.Bus) busesv1.Bus {
+	r := busesv1.Bus{
+		BusID:    busesv1.NewOptString(bus.ID),
+		Route:    busesv1.NewOptBusRoute(toRoute(bus.Route)),
+		TripID:   optString(bus.TripID),
+		Position: busesv1.NewOptDot(toDot(bus.Position)),
+	}
+	if bus.Moving {
+		r.Heading = busesv1.NewOptFloat32(float32(bus.Heading))
+		r.Speed = busesv1.NewOptFloat32(float32(bus.Speed))
+	}
+	return r
+}
+
+// optString leaves empty values out of responses.
+func optString(s string) busesv1.OptString {
+	if s == "" {
+		return busesv1.OptString{}
+	}
+	return busesv1.NewOptString(s)
+}
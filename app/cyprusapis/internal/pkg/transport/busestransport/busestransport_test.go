@@ -0,0 +1,143 @@
+package busestransport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/cyprusapis/internal/pkg/transport/busestransport/busesv1"
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+	"github.com/yanakipre/bot/internal/testtooling"
+)
+
+var route30 = buses.Route{ID: "10300011", ShortName: "30", LongName: "Le Meridien - Old Port - Leontiou EMEL Station"}
+
+type fakeClient struct {
+	buses.Client
+}
+
+func (f fakeClient) GetNearest(ctx context.Context, dot geo.Point) ([]buses.Bus, error) {
+	return []buses.Bus{{
+		ID:       "123",
+		Route:    route30,
+		Position: geo.Point{Lat: dot.Lat + 0.001, Lon: dot.Lon},
+		Moving:   true,
+		Heading:  180,
+		Speed:    5,
+	}}, nil
+}
+
+func (f fakeClient) Routes(ctx context.Context) ([]buses.Route, error) {
+	return []buses.Route{route30}, nil
+}
+
+func (f fakeClient) Route(ctx context.Context, routeID string) (buses.Route, error) {
+	return buses.Route{}, semerr.NotFound("unknown route")
+}
+
+func (f fakeClient) Arrivals(ctx context.Context, stopID string, route string) ([]buses.Arrival, error) {
+	return []buses.Arrival{{
+		Route:    route30,
+		TripID:   "T1",
+		At:       time.Date(2025, 2, 5, 6, 12, 0, 0, time.UTC),
+		Estimate: buses.EstimateScheduled,
+	}}, nil
+}
+
+func (f fakeClient) Vehicle(ctx context.Context, busID string) (buses.Bus, error) {
+	return buses.Bus{ID: busID, Route: route30}, nil
+}
+
+func (f fakeClient) StaticVersion() string {
+	return "2025-02-01"
+}
+
+func newTestClient(t *testing.T) (*busesv1.Client, *httptest.Server) {
+	t.Helper()
+	testtooling.SetNewGlobalLoggerQuietly()
+	app, err := New(fakeClient{}, DefaultConfig())
+	require.NoError(t, err)
+	srv := httptest.NewServer(app.Mux)
+	t.Cleanup(srv.Close)
+	client, err := busesv1.NewClient(srv.URL + DefaultConfig().App.BaseURL)
+	require.NoError(t, err)
+	return client, srv
+}
+
+func TestBusesAPI(t *testing.T) {
+	client, srv := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("approaching buses", func(t *testing.T) {
+		resp, err := client.FindApproachingBuses(ctx, busesv1.NewOptFindApproachingBusesReq(busesv1.FindApproachingBusesReq{
+			Position: busesv1.NewOptDot(busesv1.Dot{Lat: busesv1.NewOptFloat32(34.684422), Lon: busesv1.NewOptFloat32(33.037147)}),
+		}))
+		require.NoError(t, err)
+		require.Len(t, resp.Buses, 1)
+		bus := resp.Buses[0]
+		require.Equal(t, "30", bus.Route.Value.ShortName.Value)
+		require.InDelta(t, 111, bus.Distance.Value, 1)
+		require.Equal(t, float32(180), bus.Heading.Value)
+	})
+
+	t.Run("position is required", func(t *testing.T) {
+		_, err := client.FindApproachingBuses(ctx, busesv1.NewOptFindApproachingBusesReq(busesv1.FindApproachingBusesReq{}))
+		var errResp *busesv1.GeneralErrorStatusCode
+		require.ErrorAs(t, err, &errResp)
+		require.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	})
+
+	t.Run("routes", func(t *testing.T) {
+		resp, err := client.ListRoutes(ctx)
+		require.NoError(t, err)
+		require.Len(t, resp.Routes, 1)
+		require.Equal(t, "10300011", resp.Routes[0].RouteID.Value)
+	})
+
+	t.Run("unknown route", func(t *testing.T) {
+		resp, err := client.GetRoute(ctx, busesv1.GetRouteParams{RouteID: "0"})
+		require.NoError(t, err)
+		require.IsType(t, &busesv1.GeneralError{}, resp)
+	})
+
+	t.Run("arrivals", func(t *testing.T) {
+		resp, err := client.GetStopArrivals(ctx, busesv1.GetStopArrivalsParams{StopID: "C", Route: busesv1.NewOptString("30")})
+		require.NoError(t, err)
+		arrivals := resp.(*busesv1.GetStopArrivalsOK).Arrivals
+		require.Len(t, arrivals, 1)
+		require.Equal(t, busesv1.ArrivalEstimateScheduled, arrivals[0].Estimate)
+		require.False(t, arrivals[0].BusID.Set)
+	})
+
+	t.Run("standing vehicle", func(t *testing.T) {
+		resp, err := client.GetVehicle(ctx, busesv1.GetVehicleParams{BusID: "123"})
+		require.NoError(t, err)
+		bus := resp.(*busesv1.Bus)
+		require.Equal(t, "123", bus.BusID.Value)
+		require.False(t, bus.Heading.Set)
+	})
+
+	t.Run("health", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/healthz")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var health map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&health))
+		require.Equal(t, "2025-02-01", health["gtfs_static_version"])
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/metrics")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,4 @@
+// Package busesv1 is generated by ogen from apispec/busesv1/buses-v1.yaml.
+package busesv1
+
+//go:generate go run github.com/ogen-go/ogen/cmd/ogen --target . --package busesv1 --clean ../../../../../apispec/busesv1/buses-v1.yaml
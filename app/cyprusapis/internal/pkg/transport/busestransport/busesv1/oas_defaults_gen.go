@@ -0,0 +1,11 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package busesv1
+
+// setDefaults set default value of fields.
+func (s *FindNearestStopsReq) setDefaults() {
+	{
+		val := int(5)
+		s.Limit.SetTo(val)
+	}
+}
@@ -0,0 +1,18 @@
+// Code generated by ogen, DO NOT EDIT.
+package busesv1
+
+type GetRouteRes interface {
+	getRouteRes()
+}
+
+type GetStopArrivalsRes interface {
+	getStopArrivalsRes()
+}
+
+type GetStopRes interface {
+	getStopRes()
+}
+
+type GetVehicleRes interface {
+	getVehicleRes()
+}
@@ -0,0 +1,333 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package busesv1
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/go-faster/errors"
+
+	"github.com/ogen-go/ogen/conv"
+	"github.com/ogen-go/ogen/middleware"
+	"github.com/ogen-go/ogen/ogenerrors"
+	"github.com/ogen-go/ogen/uri"
+	"github.com/ogen-go/ogen/validate"
+)
+
+// GetRouteParams is parameters of getRoute operation.
+type GetRouteParams struct {
+	// Route ID of the GTFS feed, e.g. 10300011.
+	RouteID string
+}
+
+func unpackGetRouteParams(packed middleware.Parameters) (params GetRouteParams) {
+	{
+		key := middleware.ParameterKey{
+			Name: "route_id",
+			In:   "path",
+		}
+		params.RouteID = packed[key].(string)
+	}
+	return params
+}
+
+func decodeGetRouteParams(args [1]string, argsEscaped bool, r *http.Request) (params GetRouteParams, _ error) {
+	// Decode path: route_id.
+	if err := func() error {
+		param := args[0]
+		if argsEscaped {
+			unescaped, err := url.PathUnescape(args[0])
+			if err != nil {
+				return errors.Wrap(err, "unescape path")
+			}
+			param = unescaped
+		}
+		if len(param) > 0 {
+			d := uri.NewPathDecoder(uri.PathDecoderConfig{
+				Param:   "route_id",
+				Value:   param,
+				Style:   uri.PathStyleSimple,
+				Explode: false,
+			})
+
+			if err := func() error {
+				val, err := d.DecodeValue()
+				if err != nil {
+					return err
+				}
+
+				c, err := conv.ToString(val)
+				if err != nil {
+					return err
+				}
+
+				params.RouteID = c
+				return nil
+			}(); err != nil {
+				return err
+			}
+		} else {
+			return validate.ErrFieldRequired
+		}
+		return nil
+	}(); err != nil {
+		return params, &ogenerrors.DecodeParamError{
+			Name: "route_id",
+			In:   "path",
+			Err:  err,
+		}
+	}
+	return params, nil
+}
+
+// GetStopParams is parameters of getStop operation.
+type GetStopParams struct {
+	// Stop ID of the GTFS feed.
+	StopID string
+}
+
+func unpackGetStopParams(packed middleware.Parameters) (params GetStopParams) {
+	{
+		key := middleware.ParameterKey{
+			Name: "stop_id",
+			In:   "path",
+		}
+		params.StopID = packed[key].(string)
+	}
+	return params
+}
+
+func decodeGetStopParams(args [1]string, argsEscaped bool, r *http.Request) (params GetStopParams, _ error) {
+	// Decode path: stop_id.
+	if err := func() error {
+		param := args[0]
+		if argsEscaped {
+			unescaped, err := url.PathUnescape(args[0])
+			if err != nil {
+				return errors.Wrap(err, "unescape path")
+			}
+			param = unescaped
+		}
+		if len(param) > 0 {
+			d := uri.NewPathDecoder(uri.PathDecoderConfig{
+				Param:   "stop_id",
+				Value:   param,
+				Style:   uri.PathStyleSimple,
+				Explode: false,
+			})
+
+			if err := func() error {
+				val, err := d.DecodeValue()
+				if err != nil {
+					return err
+				}
+
+				c, err := conv.ToString(val)
+				if err != nil {
+					return err
+				}
+
+				params.StopID = c
+				return nil
+			}(); err != nil {
+				return err
+			}
+		} else {
+			return validate.ErrFieldRequired
+		}
+		return nil
+	}(); err != nil {
+		return params, &ogenerrors.DecodeParamError{
+			Name: "stop_id",
+			In:   "path",
+			Err:  err,
+		}
+	}
+	return params, nil
+}
+
+// GetStopArrivalsParams is parameters of getStopArrivals operation.
+type GetStopArrivalsParams struct {
+	// Stop ID of the GTFS feed.
+	StopID string
+	// Short name or ID of the route, e.g. 30. Arrivals of all routes are returned when it is empty.
+	Route OptString
+}
+
+func unpackGetStopArrivalsParams(packed middleware.Parameters) (params GetStopArrivalsParams) {
+	{
+		key := middleware.ParameterKey{
+			Name: "stop_id",
+			In:   "path",
+		}
+		params.StopID = packed[key].(string)
+	}
+	{
+		key := middleware.ParameterKey{
+			Name: "route",
+			In:   "query",
+		}
+		if v, ok := packed[key]; ok {
+			params.Route = v.(OptString)
+		}
+	}
+	return params
+}
+
+func decodeGetStopArrivalsParams(args [1]string, argsEscaped bool, r *http.Request) (params GetStopArrivalsParams, _ error) {
+	q := uri.NewQueryDecoder(r.URL.Query())
+	// Decode path: stop_id.
+	if err := func() error {
+		param := args[0]
+		if argsEscaped {
+			unescaped, err := url.PathUnescape(args[0])
+			if err != nil {
+				return errors.Wrap(err, "unescape path")
+			}
+			param = unescaped
+		}
+		if len(param) > 0 {
+			d := uri.NewPathDecoder(uri.PathDecoderConfig{
+				Param:   "stop_id",
+				Value:   param,
+				Style:   uri.PathStyleSimple,
+				Explode: false,
+			})
+
+			if err := func() error {
+				val, err := d.DecodeValue()
+				if err != nil {
+					return err
+				}
+
+				c, err := conv.ToString(val)
+				if err != nil {
+					return err
+				}
+
+				params.StopID = c
+				return nil
+			}(); err != nil {
+				return err
+			}
+		} else {
+			return validate.ErrFieldRequired
+		}
+		return nil
+	}(); err != nil {
+		return params, &ogenerrors.DecodeParamError{
+			Name: "stop_id",
+			In:   "path",
+			Err:  err,
+		}
+	}
+	// Decode query: route.
+	if err := func() error {
+		cfg := uri.QueryParameterDecodingConfig{
+			Name:    "route",
+			Style:   uri.QueryStyleForm,
+			Explode: true,
+		}
+
+		if err := q.HasParam(cfg); err == nil {
+			if err := q.DecodeParam(cfg, func(d uri.Decoder) error {
+				var paramsDotRouteVal string
+				if err := func() error {
+					val, err := d.DecodeValue()
+					if err != nil {
+						return err
+					}
+
+					c, err := conv.ToString(val)
+					if err != nil {
+						return err
+					}
+
+					paramsDotRouteVal = c
+					return nil
+				}(); err != nil {
+					return err
+				}
+				params.Route.SetTo(paramsDotRouteVal)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		return params, &ogenerrors.DecodeParamError{
+			Name: "route",
+			In:   "query",
+			Err:  err,
+		}
+	}
+	return params, nil
+}
+
+// GetVehicleParams is parameters of getVehicle operation.
+type GetVehicleParams struct {
+	// Vehicle ID of the realtime feed.
+	BusID string
+}
+
+func unpackGetVehicleParams(packed middleware.Parameters) (params GetVehicleParams) {
+	{
+		key := middleware.ParameterKey{
+			Name: "bus_id",
+			In:   "path",
+		}
+		params.BusID = packed[key].(string)
+	}
+	return params
+}
+
+func decodeGetVehicleParams(args [1]string, argsEscaped bool, r *http.Request) (params GetVehicleParams, _ error) {
+	// Decode path: bus_id.
+	if err := func() error {
+		param := args[0]
+		if argsEscaped {
+			unescaped, err := url.PathUnescape(args[0])
+			if err != nil {
+				return errors.Wrap(err, "unescape path")
+			}
+			param = unescaped
+		}
+		if len(param) > 0 {
+			d := uri.NewPathDecoder(uri.PathDecoderConfig{
+				Param:   "bus_id",
+				Value:   param,
+				Style:   uri.PathStyleSimple,
+				Explode: false,
+			})
+
+			if err := func() error {
+				val, err := d.DecodeValue()
+				if err != nil {
+					return err
+				}
+
+				c, err := conv.ToString(val)
+				if err != nil {
+					return err
+				}
+
+				params.BusID = c
+				return nil
+			}(); err != nil {
+				return err
+			}
+		} else {
+			return validate.ErrFieldRequired
+		}
+		return nil
+	}(); err != nil {
+		return params, &ogenerrors.DecodeParamError{
+			Name: "bus_id",
+			In:   "path",
+			Err:  err,
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,798 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package busesv1
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+func (s *GeneralErrorStatusCode) Error() string {
+	return fmt.Sprintf("code %d: %+v", s.StatusCode, s.Response)
+}
+
+// Ref: #/components/schemas/Arrival
+type Arrival struct {
+	Route    BusRoute  `json:"route"`
+	TripID   string    `json:"trip_id"`
+	Headsign OptString `json:"headsign"`
+	// Empty when the bus is not tracked.
+	BusID     OptString `json:"bus_id"`
+	ArrivesAt time.Time `json:"arrives_at"`
+	// How the arrival is known:
+	// * realtime - predicted by the operator
+	// * progress - the timetable applied to the progress of the bus along its trip
+	// * scheduled - the timetable, the bus is not tracked.
+	Estimate ArrivalEstimate `json:"estimate"`
+}
+
+// GetRoute returns the value of Route.
+func (s *Arrival) GetRoute() BusRoute {
+	return s.Route
+}
+
+// GetTripID returns the value of TripID.
+func (s *Arrival) GetTripID() string {
+	return s.TripID
+}
+
+// GetHeadsign returns the value of Headsign.
+func (s *Arrival) GetHeadsign() OptString {
+	return s.Headsign
+}
+
+// GetBusID returns the value of BusID.
+func (s *Arrival) GetBusID() OptString {
+	return s.BusID
+}
+
+// GetArrivesAt returns the value of ArrivesAt.
+func (s *Arrival) GetArrivesAt() time.Time {
+	return s.ArrivesAt
+}
+
+// GetEstimate returns the value of Estimate.
+func (s *Arrival) GetEstimate() ArrivalEstimate {
+	return s.Estimate
+}
+
+// SetRoute sets the value of Route.
+func (s *Arrival) SetRoute(val BusRoute) {
+	s.Route = val
+}
+
+// SetTripID sets the value of TripID.
+func (s *Arrival) SetTripID(val string) {
+	s.TripID = val
+}
+
+// SetHeadsign sets the value of Headsign.
+func (s *Arrival) SetHeadsign(val OptString) {
+	s.Headsign = val
+}
+
+// SetBusID sets the value of BusID.
+func (s *Arrival) SetBusID(val OptString) {
+	s.BusID = val
+}
+
+// SetArrivesAt sets the value of ArrivesAt.
+func (s *Arrival) SetArrivesAt(val time.Time) {
+	s.ArrivesAt = val
+}
+
+// SetEstimate sets the value of Estimate.
+func (s *Arrival) SetEstimate(val ArrivalEstimate) {
+	s.Estimate = val
+}
+
+// How the arrival is known:
+// * realtime - predicted by the operator
+// * progress - the timetable applied to the progress of the bus along its trip
+// * scheduled - the timetable, the bus is not tracked.
+type ArrivalEstimate string
+
+const (
+	ArrivalEstimateRealtime  ArrivalEstimate = "realtime"
+	ArrivalEstimateProgress  ArrivalEstimate = "progress"
+	ArrivalEstimateScheduled ArrivalEstimate = "scheduled"
+)
+
+// AllValues returns all ArrivalEstimate values.
+func (ArrivalEstimate) AllValues() []ArrivalEstimate {
+	return []ArrivalEstimate{
+		ArrivalEstimateRealtime,
+		ArrivalEstimateProgress,
+		ArrivalEstimateScheduled,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ArrivalEstimate) MarshalText() ([]byte, error) {
+	switch s {
+	case ArrivalEstimateRealtime:
+		return []byte(s), nil
+	case ArrivalEstimateProgress:
+		return []byte(s), nil
+	case ArrivalEstimateScheduled:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ArrivalEstimate) UnmarshalText(data []byte) error {
+	switch ArrivalEstimate(data) {
+	case ArrivalEstimateRealtime:
+		*s = ArrivalEstimateRealtime
+		return nil
+	case ArrivalEstimateProgress:
+		*s = ArrivalEstimateProgress
+		return nil
+	case ArrivalEstimateScheduled:
+		*s = ArrivalEstimateScheduled
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Ref: #/components/schemas/Bus
+type Bus struct {
+	BusID    OptString   `json:"bus_id"`
+	Route    OptBusRoute `json:"route"`
+	TripID   OptString   `json:"trip_id"`
+	Position OptDot      `json:"position"`
+	// Degrees clockwise from north, absent when the bus does not move.
+	Heading OptFloat32 `json:"heading"`
+	// Meters per second, absent when the bus does not move.
+	Speed OptFloat32 `json:"speed"`
+	// Distance from the input position in meters.
+	Distance OptFloat32 `json:"distance"`
+}
+
+// GetBusID returns the value of BusID.
+func (s *Bus) GetBusID() OptString {
+	return s.BusID
+}
+
+// GetRoute returns the value of Route.
+func (s *Bus) GetRoute() OptBusRoute {
+	return s.Route
+}
+
+// GetTripID returns the value of TripID.
+func (s *Bus) GetTripID() OptString {
+	return s.TripID
+}
+
+// GetPosition returns the value of Position.
+func (s *Bus) GetPosition() OptDot {
+	return s.Position
+}
+
+// GetHeading returns the value of Heading.
+func (s *Bus) GetHeading() OptFloat32 {
+	return s.Heading
+}
+
+// GetSpeed returns the value of Speed.
+func (s *Bus) GetSpeed() OptFloat32 {
+	return s.Speed
+}
+
+// GetDistance returns the value of Distance.
+func (s *Bus) GetDistance() OptFloat32 {
+	return s.Distance
+}
+
+// SetBusID sets the value of BusID.
+func (s *Bus) SetBusID(val OptString) {
+	s.BusID = val
+}
+
+// SetRoute sets the value of Route.
+func (s *Bus) SetRoute(val OptBusRoute) {
+	s.Route = val
+}
+
+// SetTripID sets the value of TripID.
+func (s *Bus) SetTripID(val OptString) {
+	s.TripID = val
+}
+
+// SetPosition sets the value of Position.
+func (s *Bus) SetPosition(val OptDot) {
+	s.Position = val
+}
+
+// SetHeading sets the value of Heading.
+func (s *Bus) SetHeading(val OptFloat32) {
+	s.Heading = val
+}
+
+// SetSpeed sets the value of Speed.
+func (s *Bus) SetSpeed(val OptFloat32) {
+	s.Speed = val
+}
+
+// SetDistance sets the value of Distance.
+func (s *Bus) SetDistance(val OptFloat32) {
+	s.Distance = val
+}
+
+func (*Bus) getVehicleRes() {}
+
+// Ref: #/components/schemas/BusRoute
+type BusRoute struct {
+	RouteID   OptString `json:"route_id"`
+	ShortName OptString `json:"short_name"`
+	LongName  OptString `json:"long_name"`
+	// The feed of the route, e.g. cyprus. IDs are qualified with it as operator:id when several feeds are served.
+	Operator OptString `json:"operator"`
+}
+
+// GetRouteID returns the value of RouteID.
+func (s *BusRoute) GetRouteID() OptString {
+	return s.RouteID
+}
+
+// GetShortName returns the value of ShortName.
+func (s *BusRoute) GetShortName() OptString {
+	return s.ShortName
+}
+
+// GetLongName returns the value of LongName.
+func (s *BusRoute) GetLongName() OptString {
+	return s.LongName
+}
+
+// GetOperator returns the value of Operator.
+func (s *BusRoute) GetOperator() OptString {
+	return s.Operator
+}
+
+// SetRouteID sets the value of RouteID.
+func (s *BusRoute) SetRouteID(val OptString) {
+	s.RouteID = val
+}
+
+// SetShortName sets the value of ShortName.
+func (s *BusRoute) SetShortName(val OptString) {
+	s.ShortName = val
+}
+
+// SetLongName sets the value of LongName.
+func (s *BusRoute) SetLongName(val OptString) {
+	s.LongName = val
+}
+
+// SetOperator sets the value of Operator.
+func (s *BusRoute) SetOperator(val OptString) {
+	s.Operator = val
+}
+
+func (*BusRoute) getRouteRes() {}
+
+// Ref: #/components/schemas/Dot
+type Dot struct {
+	Lat OptFloat32 `json:"lat"`
+	Lon OptFloat32 `json:"lon"`
+}
+
+// GetLat returns the value of Lat.
+func (s *Dot) GetLat() OptFloat32 {
+	return s.Lat
+}
+
+// GetLon returns the value of Lon.
+func (s *Dot) GetLon() OptFloat32 {
+	return s.Lon
+}
+
+// SetLat sets the value of Lat.
+func (s *Dot) SetLat(val OptFloat32) {
+	s.Lat = val
+}
+
+// SetLon sets the value of Lon.
+func (s *Dot) SetLon(val OptFloat32) {
+	s.Lon = val
+}
+
+type FindApproachingBusesOK struct {
+	Buses []Bus `json:"buses"`
+}
+
+// GetBuses returns the value of Buses.
+func (s *FindApproachingBusesOK) GetBuses() []Bus {
+	return s.Buses
+}
+
+// SetBuses sets the value of Buses.
+func (s *FindApproachingBusesOK) SetBuses(val []Bus) {
+	s.Buses = val
+}
+
+type FindApproachingBusesReq struct {
+	Position OptDot `json:"position"`
+}
+
+// GetPosition returns the value of Position.
+func (s *FindApproachingBusesReq) GetPosition() OptDot {
+	return s.Position
+}
+
+// SetPosition sets the value of Position.
+func (s *FindApproachingBusesReq) SetPosition(val OptDot) {
+	s.Position = val
+}
+
+type FindNearestStopsOK struct {
+	Stops []Stop `json:"stops"`
+}
+
+// GetStops returns the value of Stops.
+func (s *FindNearestStopsOK) GetStops() []Stop {
+	return s.Stops
+}
+
+// SetStops sets the value of Stops.
+func (s *FindNearestStopsOK) SetStops(val []Stop) {
+	s.Stops = val
+}
+
+type FindNearestStopsReq struct {
+	Position Dot    `json:"position"`
+	Limit    OptInt `json:"limit"`
+}
+
+// GetPosition returns the value of Position.
+func (s *FindNearestStopsReq) GetPosition() Dot {
+	return s.Position
+}
+
+// GetLimit returns the value of Limit.
+func (s *FindNearestStopsReq) GetLimit() OptInt {
+	return s.Limit
+}
+
+// SetPosition sets the value of Position.
+func (s *FindNearestStopsReq) SetPosition(val Dot) {
+	s.Position = val
+}
+
+// SetLimit sets the value of Limit.
+func (s *FindNearestStopsReq) SetLimit(val OptInt) {
+	s.Limit = val
+}
+
+// Ref: #/components/schemas/GeneralError
+type GeneralError struct {
+	RequestID OptString `json:"request_id"`
+	// Error description.
+	Error string `json:"error"`
+}
+
+// GetRequestID returns the value of RequestID.
+func (s *GeneralError) GetRequestID() OptString {
+	return s.RequestID
+}
+
+// GetError returns the value of Error.
+func (s *GeneralError) GetError() string {
+	return s.Error
+}
+
+// SetRequestID sets the value of RequestID.
+func (s *GeneralError) SetRequestID(val OptString) {
+	s.RequestID = val
+}
+
+// SetError sets the value of Error.
+func (s *GeneralError) SetError(val string) {
+	s.Error = val
+}
+
+func (*GeneralError) getRouteRes()        {}
+func (*GeneralError) getStopArrivalsRes() {}
+func (*GeneralError) getStopRes()         {}
+func (*GeneralError) getVehicleRes()      {}
+
+// GeneralErrorStatusCode wraps GeneralError with StatusCode.
+type GeneralErrorStatusCode struct {
+	StatusCode int
+	Response   GeneralError
+}
+
+// GetStatusCode returns the value of StatusCode.
+func (s *GeneralErrorStatusCode) GetStatusCode() int {
+	return s.StatusCode
+}
+
+// GetResponse returns the value of Response.
+func (s *GeneralErrorStatusCode) GetResponse() GeneralError {
+	return s.Response
+}
+
+// SetStatusCode sets the value of StatusCode.
+func (s *GeneralErrorStatusCode) SetStatusCode(val int) {
+	s.StatusCode = val
+}
+
+// SetResponse sets the value of Response.
+func (s *GeneralErrorStatusCode) SetResponse(val GeneralError) {
+	s.Response = val
+}
+
+type GetStopArrivalsOK struct {
+	Arrivals []Arrival `json:"arrivals"`
+}
+
+// GetArrivals returns the value of Arrivals.
+func (s *GetStopArrivalsOK) GetArrivals() []Arrival {
+	return s.Arrivals
+}
+
+// SetArrivals sets the value of Arrivals.
+func (s *GetStopArrivalsOK) SetArrivals(val []Arrival) {
+	s.Arrivals = val
+}
+
+func (*GetStopArrivalsOK) getStopArrivalsRes() {}
+
+type ListRoutesOK struct {
+	Routes []BusRoute `json:"routes"`
+}
+
+// GetRoutes returns the value of Routes.
+func (s *ListRoutesOK) GetRoutes() []BusRoute {
+	return s.Routes
+}
+
+// SetRoutes sets the value of Routes.
+func (s *ListRoutesOK) SetRoutes(val []BusRoute) {
+	s.Routes = val
+}
+
+// NewOptBusRoute returns new OptBusRoute with value set to v.
+func NewOptBusRoute(v BusRoute) OptBusRoute {
+	return OptBusRoute{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptBusRoute is optional BusRoute.
+type OptBusRoute struct {
+	Value BusRoute
+	Set   bool
+}
+
+// IsSet returns true if OptBusRoute was set.
+func (o OptBusRoute) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptBusRoute) Reset() {
+	var v BusRoute
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptBusRoute) SetTo(v BusRoute) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptBusRoute) Get() (v BusRoute, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptBusRoute) Or(d BusRoute) BusRoute {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptDot returns new OptDot with value set to v.
+func NewOptDot(v Dot) OptDot {
+	return OptDot{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptDot is optional Dot.
+type OptDot struct {
+	Value Dot
+	Set   bool
+}
+
+// IsSet returns true if OptDot was set.
+func (o OptDot) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptDot) Reset() {
+	var v Dot
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptDot) SetTo(v Dot) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptDot) Get() (v Dot, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptDot) Or(d Dot) Dot {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptFindApproachingBusesReq returns new OptFindApproachingBusesReq with value set to v.
+func NewOptFindApproachingBusesReq(v FindApproachingBusesReq) OptFindApproachingBusesReq {
+	return OptFindApproachingBusesReq{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptFindApproachingBusesReq is optional FindApproachingBusesReq.
+type OptFindApproachingBusesReq struct {
+	Value FindApproachingBusesReq
+	Set   bool
+}
+
+// IsSet returns true if OptFindApproachingBusesReq was set.
+func (o OptFindApproachingBusesReq) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptFindApproachingBusesReq) Reset() {
+	var v FindApproachingBusesReq
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptFindApproachingBusesReq) SetTo(v FindApproachingBusesReq) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptFindApproachingBusesReq) Get() (v FindApproachingBusesReq, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptFindApproachingBusesReq) Or(d FindApproachingBusesReq) FindApproachingBusesReq {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptFloat32 returns new OptFloat32 with value set to v.
+func NewOptFloat32(v float32) OptFloat32 {
+	return OptFloat32{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptFloat32 is optional float32.
+type OptFloat32 struct {
+	Value float32
+	Set   bool
+}
+
+// IsSet returns true if OptFloat32 was set.
+func (o OptFloat32) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptFloat32) Reset() {
+	var v float32
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptFloat32) SetTo(v float32) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptFloat32) Get() (v float32, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptFloat32) Or(d float32) float32 {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInt returns new OptInt with value set to v.
+func NewOptInt(v int) OptInt {
+	return OptInt{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInt is optional int.
+type OptInt struct {
+	Value int
+	Set   bool
+}
+
+// IsSet returns true if OptInt was set.
+func (o OptInt) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInt) Reset() {
+	var v int
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInt) SetTo(v int) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInt) Get() (v int, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInt) Or(d int) int {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptString returns new OptString with value set to v.
+func NewOptString(v string) OptString {
+	return OptString{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptString is optional string.
+type OptString struct {
+	Value string
+	Set   bool
+}
+
+// IsSet returns true if OptString was set.
+func (o OptString) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptString) Reset() {
+	var v string
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptString) SetTo(v string) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptString) Get() (v string, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptString) Or(d string) string {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// Ref: #/components/schemas/Stop
+type Stop struct {
+	StopID OptString `json:"stop_id"`
+	Name   OptString `json:"name"`
+	// The feed of the stop, e.g. cyprus. IDs are qualified with it as operator:id when several feeds are served.
+	Operator OptString `json:"operator"`
+	Position OptDot    `json:"position"`
+	// Distance from the input position in meters.
+	Distance OptFloat32 `json:"distance"`
+}
+
+// GetStopID returns the value of StopID.
+func (s *Stop) GetStopID() OptString {
+	return s.StopID
+}
+
+// GetName returns the value of Name.
+func (s *Stop) GetName() OptString {
+	return s.Name
+}
+
+// GetOperator returns the value of Operator.
+func (s *Stop) GetOperator() OptString {
+	return s.Operator
+}
+
+// GetPosition returns the value of Position.
+func (s *Stop) GetPosition() OptDot {
+	return s.Position
+}
+
+// GetDistance returns the value of Distance.
+func (s *Stop) GetDistance() OptFloat32 {
+	return s.Distance
+}
+
+// SetStopID sets the value of StopID.
+func (s *Stop) SetStopID(val OptString) {
+	s.StopID = val
+}
+
+// SetName sets the value of Name.
+func (s *Stop) SetName(val OptString) {
+	s.Name = val
+}
+
+// SetOperator sets the value of Operator.
+func (s *Stop) SetOperator(val OptString) {
+	s.Operator = val
+}
+
+// SetPosition sets the value of Position.
+func (s *Stop) SetPosition(val OptDot) {
+	s.Position = val
+}
+
+// SetDistance sets the value of Distance.
+func (s *Stop) SetDistance(val OptFloat32) {
+	s.Distance = val
+}
+
+func (*Stop) getStopRes() {}
@@ -0,0 +1,80 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package busesv1
+
+import (
+	"context"
+)
+
+// Handler handles operations described by OpenAPI v3 specification.
+type Handler interface {
+	// FindApproachingBuses implements findApproachingBuses operation.
+	//
+	// Get a list of approaching buses.
+	//
+	// POST /find-approaching-routes
+	FindApproachingBuses(ctx context.Context, req OptFindApproachingBusesReq) (*FindApproachingBusesOK, error)
+	// FindNearestStops implements findNearestStops operation.
+	//
+	// Get a list of stops nearest to the position, the nearest first.
+	//
+	// POST /find-nearest-stops
+	FindNearestStops(ctx context.Context, req *FindNearestStopsReq) (*FindNearestStopsOK, error)
+	// GetRoute implements getRoute operation.
+	//
+	// Get the route of the timetable by its ID.
+	//
+	// GET /routes/{route_id}
+	GetRoute(ctx context.Context, params GetRouteParams) (GetRouteRes, error)
+	// GetStop implements getStop operation.
+	//
+	// Get the stop of the timetable by its ID.
+	//
+	// GET /stops/{stop_id}
+	GetStop(ctx context.Context, params GetStopParams) (GetStopRes, error)
+	// GetStopArrivals implements getStopArrivals operation.
+	//
+	// Get buses arriving at the stop, the soonest first.
+	// Arrivals are predicted by the operator when it reports trip updates,
+	// by the progress of the bus along its trip otherwise.
+	// Trips without a tracked bus come from the timetable.
+	//
+	// GET /stops/{stop_id}/arrivals
+	GetStopArrivals(ctx context.Context, params GetStopArrivalsParams) (GetStopArrivalsRes, error)
+	// GetVehicle implements getVehicle operation.
+	//
+	// Get the latest position of the bus with its heading and speed.
+	// Positions are polled in the background, the bus is not found when it is not in the latest poll.
+	//
+	// GET /vehicles/{bus_id}
+	GetVehicle(ctx context.Context, params GetVehicleParams) (GetVehicleRes, error)
+	// ListRoutes implements listRoutes operation.
+	//
+	// Get all routes of the timetable, ordered by the short name.
+	//
+	// GET /routes
+	ListRoutes(ctx context.Context) (*ListRoutesOK, error)
+	// NewError creates *GeneralErrorStatusCode from error returned by handler.
+	//
+	// Used for common default response.
+	NewError(ctx context.Context, err error) *GeneralErrorStatusCode
+}
+
+// Server implements http server based on OpenAPI v3 specification and
+// calls Handler to handle requests.
+type Server struct {
+	h Handler
+	baseServer
+}
+
+// NewServer creates new Server.
+func NewServer(h Handler, opts ...ServerOption) (*Server, error) {
+	s, err := newServerConfig(opts...).baseServer()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		h:          h,
+		baseServer: s,
+	}, nil
+}
@@ -0,0 +1,89 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package busesv1
+
+import (
+	"context"
+
+	ht "github.com/ogen-go/ogen/http"
+)
+
+// UnimplementedHandler is no-op Handler which returns http.ErrNotImplemented.
+type UnimplementedHandler struct{}
+
+var _ Handler = UnimplementedHandler{}
+
+// FindApproachingBuses implements findApproachingBuses operation.
+//
+// Get a list of approaching buses.
+//
+// POST /find-approaching-routes
+func (UnimplementedHandler) FindApproachingBuses(ctx context.Context, req OptFindApproachingBusesReq) (r *FindApproachingBusesOK, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// FindNearestStops implements findNearestStops operation.
+//
+// Get a list of stops nearest to the position, the nearest first.
+//
+// POST /find-nearest-stops
+func (UnimplementedHandler) FindNearestStops(ctx context.Context, req *FindNearestStopsReq) (r *FindNearestStopsOK, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// GetRoute implements getRoute operation.
+//
+// Get the route of the timetable by its ID.
+//
+// GET /routes/{route_id}
+func (UnimplementedHandler) GetRoute(ctx context.Context, params GetRouteParams) (r GetRouteRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// GetStop implements getStop operation.
+//
+// Get the stop of the timetable by its ID.
+//
+// GET /stops/{stop_id}
+func (UnimplementedHandler) GetStop(ctx context.Context, params GetStopParams) (r GetStopRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// GetStopArrivals implements getStopArrivals operation.
+//
+// Get buses arriving at the stop, the soonest first.
+// Arrivals are predicted by the operator when it reports trip updates,
+// by the progress of the bus along its trip otherwise.
+// Trips without a tracked bus come from the timetable.
+//
+// GET /stops/{stop_id}/arrivals
+func (UnimplementedHandler) GetStopArrivals(ctx context.Context, params GetStopArrivalsParams) (r GetStopArrivalsRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// GetVehicle implements getVehicle operation.
+//
+// Get the latest position of the bus with its heading and speed.
+// Positions are polled in the background, the bus is not found when it is not in the latest poll.
+//
+// GET /vehicles/{bus_id}
+func (UnimplementedHandler) GetVehicle(ctx context.Context, params GetVehicleParams) (r GetVehicleRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ListRoutes implements listRoutes operation.
+//
+// Get all routes of the timetable, ordered by the short name.
+//
+// GET /routes
+func (UnimplementedHandler) ListRoutes(ctx context.Context) (r *ListRoutesOK, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// NewError creates *GeneralErrorStatusCode from error returned by handler.
+//
+// Used for common default response.
+func (UnimplementedHandler) NewError(ctx context.Context, err error) (r *GeneralErrorStatusCode) {
+	r = new(GeneralErrorStatusCode)
+	return r
+}
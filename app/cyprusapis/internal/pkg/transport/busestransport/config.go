@@ -0,0 +1,15 @@
+package busestransport
+
+import (
+	"github.com/yanakipre/bot/internal/openapiapp"
+)
+
+type Config struct {
+	App openapiapp.Config `yaml:"app"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		App: openapiapp.DefaultConfig("/buses/v1", "0.0.0.0:8081", "busesv1"),
+	}
+}
@@ -0,0 +1,38 @@
+package chats
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+)
+
+var (
+	createChatID         *string
+	createTelegramChatID *string
+	createLocality       *string
+)
+
+var create = &cobra.Command{
+	Use:   "create",
+	Short: "create a chat to load threads into",
+	Example: `
+Create a chat about Limassol, messages are linked as https://t.me/limassolmed/<message id>:
+
+	telegramsearch chats create --chat-id limassolmed --telegram-chat-id limassolmed --locality Limassol
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := ctl.CreateChat(cmd.Context(), controllerv1models.ReqCreateChat{
+			ChatID:         *createChatID,
+			TelegramChatID: *createTelegramChatID,
+			Locality:       *createLocality,
+		})
+		return err
+	},
+}
+
+func init() {
+	createChatID = create.Flags().String("chat-id", "", "Chat ID")
+	createTelegramChatID = create.Flags().String("telegram-chat-id", "", "Public name of the chat in Telegram, used in links")
+	createLocality = create.Flags().String("locality", "", "What the chat is about, e.g. Limassol")
+	_ = create.MarkFlagRequired("chat-id")
+}
@@ -0,0 +1,58 @@
+package chats
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+)
+
+var (
+	deleteChatID *string
+	deleteYes    *bool
+)
+
+var errNotConfirmed = errors.New("deletion is not confirmed")
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete the chat with all its threads",
+	Example: `
+Delete the chat, the chat ID is asked again to confirm:
+
+	telegramsearch chats delete --chat-id kiprchat
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if !*deleteYes {
+			if _, err := fmt.Fprintf(
+				cmd.OutOrStdout(),
+				"All threads of %q will be deleted. Type the chat ID to confirm: ",
+				*deleteChatID,
+			); err != nil {
+				return err
+			}
+			answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if err != nil && answer == "" {
+				return errNotConfirmed
+			}
+			if strings.TrimSpace(answer) != *deleteChatID {
+				return errNotConfirmed
+			}
+		}
+
+		_, err := ctl.DeleteChat(ctx, controllerv1models.ReqDeleteChat{ChatID: *deleteChatID})
+		return err
+	},
+}
+
+func init() {
+	deleteChatID = deleteCmd.Flags().String("chat-id", "", "Chat ID")
+	deleteYes = deleteCmd.Flags().Bool("yes", false, "Do not ask for confirmation")
+	_ = deleteCmd.MarkFlagRequired("chat-id")
+}
@@ -0,0 +1,45 @@
+package chats
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var (
+	filteredChatID *string
+	filteredLimit  *int
+)
+
+var filtered = &cobra.Command{
+	Use:   "filtered",
+	Short: "show the most recent threads dropped by the thread filter, and why",
+	Example: `
+Check what was filtered out in the chat:
+
+	telegramsearch chats filtered --chat-id kiprchat --limit 20
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		resp, err := ctl.FilteredThreads(ctx, controllerv1models.ReqFilteredThreads{
+			ChatID: *filteredChatID,
+			Limit:  *filteredLimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, resp))
+		return err
+	},
+}
+
+func init() {
+	filteredChatID = filtered.Flags().String("chat-id", "", "Chat ID")
+	filteredLimit = filtered.Flags().Int("limit", 50, "How many threads to show")
+	_ = filtered.MarkFlagRequired("chat-id")
+}
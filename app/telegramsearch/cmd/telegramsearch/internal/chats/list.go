@@ -0,0 +1,26 @@
+package chats
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var list = &cobra.Command{
+	Use:   "list",
+	Short: "list chats",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		resp, err := ctl.ListChats(ctx, controllerv1models.ReqListChats{})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, resp))
+		return err
+	},
+}
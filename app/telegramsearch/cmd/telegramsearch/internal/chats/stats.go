@@ -0,0 +1,26 @@
+package chats
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var stats = &cobra.Command{
+	Use:   "stats",
+	Short: "count threads and embeddings of every chat, and show when the last message was sent",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		resp, err := ctl.ChatStats(ctx, controllerv1models.ReqChatStats{})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, resp))
+		return err
+	},
+}
@@ -0,0 +1,66 @@
+package chats
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var (
+	updateChatID         *string
+	updateNewChatID      *string
+	updateTelegramChatID *string
+	updateLocality       *string
+)
+
+var update = &cobra.Command{
+	Use:   "update",
+	Short: "rename the chat or change its metadata",
+	Long: `Only the given flags are changed.
+
+Locality is a part of the embedded threads, so their messages are rendered again in place,
+the embeddings are kept and nothing is paid for.
+Summaries made with the old locality stay until the threads are summarized again.
+`,
+	Example: `
+Rename the chat:
+
+	telegramsearch chats update --chat-id kiprchat --new-chat-id cyprus
+
+Set the link and locality:
+
+	telegramsearch chats update --chat-id cyprus --telegram-chat-id kiprchat --locality Cyprus
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		req := controllerv1models.ReqUpdateChat{ChatID: *updateChatID}
+		if cmd.Flags().Changed("new-chat-id") {
+			req.NewChatID = updateNewChatID
+		}
+		if cmd.Flags().Changed("telegram-chat-id") {
+			req.TelegramChatID = updateTelegramChatID
+		}
+		if cmd.Flags().Changed("locality") {
+			req.Locality = updateLocality
+		}
+		resp, err := ctl.UpdateChat(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, resp))
+		return err
+	},
+}
+
+func init() {
+	updateChatID = update.Flags().String("chat-id", "", "Chat ID")
+	updateNewChatID = update.Flags().String("new-chat-id", "", "New chat ID")
+	updateTelegramChatID = update.Flags().String("telegram-chat-id", "", "Public name of the chat in Telegram, used in links")
+	updateLocality = update.Flags().String("locality", "", "What the chat is about, e.g. Limassol")
+	_ = update.MarkFlagRequired("chat-id")
+}
@@ -3,10 +3,21 @@ package ctl
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/datagovcy"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/emsc"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/usgs"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/httpopenaiclient"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/staticconfig"
+	"github.com/yanakipre/bot/internal/buses/busregistry"
+	"github.com/yanakipre/bot/internal/geo"
 )
 
 func Init(ctx context.Context, staticConfig *staticconfig.Config) (*controllerv1.Ctl, error) {
@@ -18,9 +29,55 @@ func Init(ctx context.Context, staticConfig *staticconfig.Config) (*controllerv1
 
 	openai := httpopenaiclient.NewClient(staticConfig.OpenAI)
 
-	ctl, err := controllerv1.New(staticConfig.Ctlv1, openai, storageRW)
+	ctl, err := controllerv1.New(
+		staticConfig.Ctlv1,
+		openai,
+		storageRW,
+		earthquakeSources(staticConfig, earthquakeMemory{storage: storageRW}),
+		busregistry.New(staticConfig.Buses),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("error creating controller: %w", err)
 	}
 	return ctl, nil
 }
+
+// earthquakeSources go from the most authoritative for Cyprus.
+func earthquakeSources(staticConfig *staticconfig.Config, memory earthquakes.Memory) *earthquakes.Composite {
+	var sources []earthquakes.Source
+	if staticConfig.Earthquakes.ApiURL != "" {
+		sources = append(sources, earthquakes.Source{
+			Name:        datagovcy.Source,
+			Earthquaker: datagovcy.NewClient(staticConfig.Earthquakes),
+		})
+	}
+	if staticConfig.EMSC.ApiURL != "" {
+		sources = append(sources, earthquakes.Source{Name: emsc.Source, Earthquaker: emsc.NewClient(staticConfig.EMSC)})
+	}
+	if staticConfig.USGS.ApiURL != "" {
+		sources = append(sources, earthquakes.Source{Name: usgs.Source, Earthquaker: usgs.NewClient(staticConfig.USGS)})
+	}
+	return earthquakes.NewComposite(staticConfig.EarthquakesMerge, memory, sources...)
+}
+
+// earthquakeMemory recalls earthquakes the controller saved when polling.
+type earthquakeMemory struct {
+	storage *postgres.Storage
+}
+
+func (m earthquakeMemory) RecallEarthquakes(ctx context.Context, since time.Time) ([]earthquakes.Earthquake, error) {
+	resp, err := m.storage.FetchEarthquakesSince(ctx, storagemodels.ReqFetchEarthquakesSince{Since: since})
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(resp.Earthquakes, func(item storagemodels.Earthquake, _ int) earthquakes.Earthquake {
+		return earthquakes.Earthquake{
+			GUID:       item.GUID,
+			SourceRank: item.SourceRank,
+			Magnitude:  item.Magnitude,
+			When:       item.HappenedAt,
+			Location:   item.Location,
+			Position:   geo.Point{Lat: item.Latitude, Lon: item.Longitude},
+		}
+	}), nil
+}
@@ -0,0 +1,48 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	ctl2 "github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/ctl"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/staticconfig"
+	"github.com/yanakipre/bot/internal/clitooling"
+)
+
+var (
+	ctl            *controllerv1.Ctl
+	CmdsToRegister = []*cobra.Command{
+		extract,
+		top,
+	}
+)
+
+func Init(ctx context.Context, staticConfig *staticconfig.Config) error {
+	controller, err := ctl2.Init(ctx, staticConfig)
+	if err != nil {
+		return fmt.Errorf("error in controller init: %w", err)
+	}
+	ctl = controller
+	return nil
+}
+
+// Command represents directory command
+func Command(cfg *staticconfig.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "directory",
+		Short: "Directory of businesses, professionals and places recommended in chats.",
+		// PersistentPreRun will be executed for any subcommand.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// manually call parent cmd
+			if err := clitooling.RunParentPersistentPreRun(cmd, args); err != nil {
+				return err
+			}
+			return Init(context.TODO(), cfg)
+		},
+	}
+	cmd.AddCommand(CmdsToRegister...)
+	return cmd
+}
@@ -0,0 +1,44 @@
+package directory
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var extractLimit *int
+
+var extract = &cobra.Command{
+	Use:   "extract",
+	Short: "extract entities from threads not processed yet, it costs money",
+	Long: `Every thread is processed with a completion once.
+Threads changed by forgetting authors are processed again on the next run.
+`,
+	Example: `
+Try on a couple of threads first:
+
+	telegramsearch directory extract --limit 10
+
+Process the rest:
+
+	telegramsearch directory extract
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		result, err := ctl.ExtractEntities(ctx, controllerv1models.ReqExtractEntities{Limit: *extractLimit})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, result))
+		return err
+	},
+}
+
+func init() {
+	extractLimit = extract.Flags().Int("limit", 0, "How many threads to process, all when zero")
+}
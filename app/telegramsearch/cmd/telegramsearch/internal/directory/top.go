@@ -0,0 +1,47 @@
+package directory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var (
+	topTenant *string
+	topLimit  *int
+)
+
+var top = &cobra.Command{
+	Use:   "top",
+	Short: "show the most recommended entities of the category, like /top in the bot",
+	Example: `
+The last word is the locality of chats when it matches one:
+
+	telegramsearch directory top dentist limassol
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		result, err := ctl.TopEntities(ctx, controllerv1models.ReqTopEntities{
+			Tenant: *topTenant,
+			Query:  strings.Join(args, " "),
+			Limit:  *topLimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, result))
+		return err
+	},
+}
+
+func init() {
+	topTenant = top.Flags().String("tenant", "", "Use the tenant's chats only, all chats by default")
+	topLimit = top.Flags().Int("limit", 10, "How many entities to show")
+}
@@ -19,7 +19,8 @@ Query:
 		ctx := cmd.Context()
 
 		result, err := ctl.TryCompletion(ctx, controllerv1models.ReqTryCompletion{
-			Query: args[0],
+			Tenant: *tenant,
+			Query:  args[0],
 		})
 		if err != nil {
 			return err
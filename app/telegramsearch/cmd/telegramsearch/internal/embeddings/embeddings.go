@@ -11,11 +11,14 @@ import (
 )
 
 var (
-	ctl            *controllerv1.Ctl
+	ctl *controllerv1.Ctl
+	// tenant scopes the search to the tenant's chats.
+	tenant         *string
 	CmdsToRegister = []*cobra.Command{
 		completion,
 		try,
 		generate,
+		summarize,
 	}
 )
 
@@ -45,6 +48,7 @@ func Command(cfg *staticconfig.Config) *cobra.Command {
 			return Init(context.TODO(), cfg)
 		},
 	}
+	tenant = cmd.PersistentFlags().String("tenant", "", "Search in the tenant's chats only, all chats by default")
 	cmd.AddCommand(CmdsToRegister...)
 	return cmd
 }
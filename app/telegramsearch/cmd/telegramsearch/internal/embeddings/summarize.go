@@ -0,0 +1,45 @@
+package embeddings
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var summarizeLimit *int
+
+var summarize = &cobra.Command{
+	Use:   "summarize",
+	Short: "summarize embedded threads, and embed summaries instead, it costs money",
+	Long: `Every embedded thread without a summary is summarized with a completion.
+The summary is embedded instead of the thread, and is given to the model to answer questions.
+Threads embedded later, or changed by forgetting authors, are summarized on the next run.
+`,
+	Example: `
+Try on a couple of threads first:
+
+	telegramsearch embeddings summarize --limit 10
+
+Summarize the rest:
+
+	telegramsearch embeddings summarize
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		result, err := ctl.SummarizeThreads(ctx, controllerv1models.ReqSummarizeThreads{Limit: *summarizeLimit})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, result))
+		return err
+	},
+}
+
+func init() {
+	summarizeLimit = summarize.Flags().Int("limit", 0, "How many threads to summarize, all when zero")
+}
@@ -18,7 +18,10 @@ Query:
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 
-		result, err := ctl.TryEmbedding(ctx, controllerv1models.ReqTryEmbedding{Input: args[0]})
+		result, err := ctl.TryEmbedding(ctx, controllerv1models.ReqTryEmbedding{
+			Tenant: *tenant,
+			Input:  args[0],
+		})
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,162 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	ctl2 "github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/ctl"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/evaluation"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/staticconfig"
+	"github.com/yanakipre/bot/internal/clitooling"
+	"github.com/yanakipre/bot/internal/logger"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+	"go.uber.org/zap"
+)
+
+var (
+	ctl            *controllerv1.Ctl
+	questionsPath  *string
+	outputPath     *string
+	baselinePath   *string
+	k              *int
+	withCompletion *bool
+	tenant         *string
+)
+
+func Init(ctx context.Context, staticConfig *staticconfig.Config) error {
+	controller, err := ctl2.Init(ctx, staticConfig)
+	if err != nil {
+		return fmt.Errorf("error in controller init: %w", err)
+	}
+	ctl = controller
+	return nil
+}
+
+// Command represents eval command
+func Command(cfg *staticconfig.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate retrieval quality against a golden question set.",
+		Long: `Asks every question from the set, and reports recall@k, MRR,
+no-result rate and latency.
+
+Questions are read from YAML (with top-level "questions" key) or JSONL file:
+
+	{"id": "pediatrician", "question": "best pediatrician in Limassol", "expected_thread_ids": [1, 2]}
+	{"question": "where to swim with kids", "expected_keywords": ["beach"]}
+
+The report is stored on disk, so the next run can be compared against it with --baseline.
+`,
+		Example: `
+Evaluate and store the report:
+
+	telegramsearch eval --questions golden.yaml --output before.yaml
+
+Change thresholds, evaluate again and compare:
+
+	telegramsearch eval --questions golden.yaml --output after.yaml --baseline before.yaml
+`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// manually call parent cmd
+			if err := clitooling.RunParentPersistentPreRun(cmd, args); err != nil {
+				return err
+			}
+			return Init(context.TODO(), cfg)
+		},
+		RunE: run,
+	}
+	questionsPath = cmd.Flags().String("questions", "", "Golden questions, .yaml or .jsonl")
+	outputPath = cmd.Flags().String("output", "", "Where to store the report")
+	baselinePath = cmd.Flags().String("baseline", "", "Previous report to compare with")
+	k = cmd.Flags().Int("k", 10, "Consider only top k retrieved threads for recall")
+	withCompletion = cmd.Flags().Bool("completion", false, "Also generate completions, it costs money")
+	tenant = cmd.Flags().String("tenant", "", "Evaluate the tenant's chats only, all chats by default")
+	_ = cmd.MarkFlagRequired("questions")
+	return cmd
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	questions, err := evaluation.LoadQuestions(*questionsPath)
+	if err != nil {
+		return err
+	}
+	var baseline *evaluation.Report
+	if *baselinePath != "" {
+		// fail early, before spending money on the evaluation.
+		b, err := evaluation.LoadReport(*baselinePath)
+		if err != nil {
+			return err
+		}
+		baseline = &b
+	}
+
+	observations := make([]evaluation.Observation, 0, len(questions))
+	for _, q := range questions {
+		o, err := observe(ctx, q)
+		if err != nil {
+			return fmt.Errorf("question %q: %w", q.ID, err)
+		}
+		logger.Info(ctx, "evaluated question",
+			zap.String("question_id", q.ID),
+			zap.Int("retrieved", len(o.Retrieved)),
+			zap.Duration("latency", o.RetrievalLatency),
+		)
+		observations = append(observations, o)
+	}
+	report := evaluation.Evaluate(time.Now(), *k, observations)
+
+	if *outputPath != "" {
+		if err := evaluation.SaveReport(*outputPath, report); err != nil {
+			return err
+		}
+	} else {
+		if _, err := fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, report.Results)); err != nil {
+			return err
+		}
+	}
+
+	if baseline != nil {
+		return evaluation.WriteDiff(cmd.OutOrStdout(), *baseline, report)
+	}
+	return evaluation.WriteSummary(cmd.OutOrStdout(), report)
+}
+
+func observe(ctx context.Context, q evaluation.Question) (evaluation.Observation, error) {
+	o := evaluation.Observation{Question: q}
+
+	started := time.Now()
+	retrieved, err := ctl.TryEmbedding(ctx, controllerv1models.ReqTryEmbedding{
+		Tenant: *tenant,
+		Input:  q.Question,
+	})
+	if err != nil {
+		return o, err
+	}
+	o.RetrievalLatency = time.Since(started)
+	for _, r := range retrieved.Result {
+		o.Retrieved = append(o.Retrieved, evaluation.Retrieved{ThreadID: r.ThreadID, Text: r.Text})
+	}
+
+	if !*withCompletion {
+		return o, nil
+	}
+	started = time.Now()
+	completion, err := ctl.TryCompletion(ctx, controllerv1models.ReqTryCompletion{
+		Tenant: *tenant,
+		Query:  q.Question,
+		// cached answers would skew completions and latency.
+		NoCache: true,
+	})
+	if err != nil {
+		return o, err
+	}
+	o.CompletionLatency = time.Since(started)
+	o.Completion = completion.Response
+	return o, nil
+}
@@ -3,8 +3,12 @@ package internal
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/chats"
+	"github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/directory"
 	"github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/embeddings"
+	"github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/eval"
 	"github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/rootcmd"
+	"github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/serve"
 	"github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/telegram"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/staticconfig"
 	"github.com/yanakipre/bot/internal/logger"
@@ -24,7 +28,11 @@ func Execute() {
 func init() {
 	rootCmd = rootcmd.NewRootCmd(func(cmd *cobra.Command, cfg *staticconfig.Config) {
 		cmd.AddCommand(telegram.Command(cfg))
+		cmd.AddCommand(chats.Command(cfg))
 		cmd.AddCommand(embeddings.Command(cfg))
+		cmd.AddCommand(directory.Command(cfg))
+		cmd.AddCommand(eval.Command(cfg))
+		cmd.AddCommand(serve.Command(cfg))
 		cmd.AddCommand(versionCmd)
 		cmd.AddCommand(configgenCmd)
 	})
@@ -0,0 +1,80 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctl2 "github.com/yanakipre/bot/app/telegramsearch/cmd/telegramsearch/internal/ctl"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/staticconfig"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport"
+	"github.com/yanakipre/bot/internal/clitooling"
+)
+
+var ctl *controllerv1.Ctl
+
+func Init(ctx context.Context, staticConfig *staticconfig.Config) error {
+	controller, err := ctl2.Init(ctx, staticConfig)
+	if err != nil {
+		return fmt.Errorf("error in controller init: %w", err)
+	}
+	ctl = controller
+	return nil
+}
+
+// Command represents serve command
+func Command(cfg *staticconfig.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the search API.",
+		Long: `Serves apispec/searchv1/search-v1.yaml on search_api.app.addr,
+under search_api.app.base_url. Health is reported on /healthz.
+`,
+		Example: `
+Start the API:
+
+	telegramsearch serve
+
+Search:
+
+	curl -X POST localhost:8080/search/v1/search-through-chats -d '{"query": "pediatrician in Limassol"}'
+`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// manually call parent cmd
+			if err := clitooling.RunParentPersistentPreRun(cmd, args); err != nil {
+				return err
+			}
+			return Init(context.TODO(), cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
+			defer cancel()
+
+			if err := cfg.SearchAPI.App.Validate(); err != nil {
+				return err
+			}
+			if err := ctl.Ready(); err != nil {
+				return err
+			}
+			app, err := searchtransport.New(ctl, cfg.SearchAPI)
+			if err != nil {
+				return fmt.Errorf("new search api: %w", err)
+			}
+			go app.StartServer(ctx)
+
+			<-ctx.Done()
+
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			defer shutdownCancel()
+			app.ShutdownServer(shutdownCtx)
+			return nil
+		},
+	}
+}
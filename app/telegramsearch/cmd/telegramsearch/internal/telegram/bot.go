@@ -7,6 +7,7 @@ import (
 	"os/signal"
 
 	"github.com/spf13/cobra"
+	"github.com/tucnak/telebot"
 )
 
 var bot = &cobra.Command{
@@ -16,6 +17,10 @@ var bot = &cobra.Command{
 Start bot:
 
 	telegramsearch telegram bot
+
+Every bot from tenant_bots config is started as well, serving its tenant's chats only.
+Earthquake alerts are sent to subscribers by the bot they subscribed with.
+Bus positions are polled while the bot runs, a shared location shows buses approaching it.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
@@ -23,9 +28,22 @@ Start bot:
 		ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
 		defer cancel()
 
-		b, err := bottransport.New(ctx, ctl, cfg.TelegramTransport)
-		if err != nil {
-			return fmt.Errorf("new bot: %w", err)
+		if err := cfg.ValidateTenantBots(); err != nil {
+			return err
+		}
+		botConfigs := cfg.TenantBots
+		if cfg.TelegramTransport.Token.Unmask() != "" || len(botConfigs) == 0 {
+			botConfigs = append([]bottransport.Config{cfg.TelegramTransport}, botConfigs...)
+		}
+		bots := make([]*telebot.Bot, 0, len(botConfigs))
+		alerts := bottransport.NewEarthquakeAlerts(ctl)
+		for _, botCfg := range botConfigs {
+			b, err := bottransport.New(ctx, ctl, botCfg)
+			if err != nil {
+				return fmt.Errorf("new bot for tenant %q: %w", botCfg.Tenant, err)
+			}
+			bots = append(bots, b)
+			alerts.Add(b, botCfg)
 		}
 		//
 		//_ = telegram.NewClient(cfg.TelegramV2.AppID.Unmask(), cfg.TelegramV2.AppHash.Unmask(),
@@ -33,13 +51,17 @@ Start bot:
 		//		Logger: logger.FromContext(ctx).Named("tdbot"),
 		//	})
 
-		go func() {
-			b.Start()
-		}()
+		for _, b := range bots {
+			go b.Start()
+		}
+		go alerts.Run(ctx, cfg.Ctlv1.Earthquakes.PollInterval.Duration)
+		go ctl.TrackBuses(ctx)
 
 		<-ctx.Done()
 
-		b.Stop()
+		for _, b := range bots {
+			b.Stop()
+		}
 		return nil
 	},
 }
@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var forgetUserID *int64
+var forgetRequestedBy *string
+
+var forget = &cobra.Command{
+	Use:   "forget",
+	Short: "delete all messages of the telegram user and stop storing new ones",
+	Example: `
+Delete messages of the user who sent a data deletion request:
+
+	telegramsearch telegram forget --user-id 123456 --requested-by "email from 2024-06-01"
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		resp, err := ctl.ForgetAuthor(ctx, controllerv1models.ReqForgetAuthor{
+			TelegramUserID: *forgetUserID,
+			RequestedBy:    *forgetRequestedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, resp))
+		return err
+	},
+}
+
+func init() {
+	forgetUserID = forget.Flags().Int64("user-id", 0, "Telegram user ID")
+	forgetRequestedBy = forget.Flags().String("requested-by", "", "Who requested the deletion, written to the audit log")
+	_ = forget.MarkFlagRequired("user-id")
+	_ = forget.MarkFlagRequired("requested-by")
+}
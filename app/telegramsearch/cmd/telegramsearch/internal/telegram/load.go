@@ -11,6 +11,7 @@ import (
 
 var chatID *string
 var filename *string
+var createChat *bool
 
 var load = &cobra.Command{
 	Use:   "load",
@@ -27,6 +28,11 @@ Create a chat and load all messages into it:
 		if err != nil {
 			return fmt.Errorf("cannot open file: %w", err)
 		}
+		if *createChat {
+			if _, err := ctl.CreateChat(ctx, controllerv1models.ReqCreateChat{ChatID: *chatID}); err != nil {
+				return fmt.Errorf("cannot create chat: %w", err)
+			}
+		}
 
 		chatHistory, err := ctl.DumpChatHistory(ctx, controllerv1models.ReqDumpChatHistory{
 			ChatHistory: file,
@@ -44,4 +50,7 @@ Create a chat and load all messages into it:
 func init() {
 	chatID = load.Flags().String("chat-id", "", "Chat ID")
 	filename = load.Flags().String("filename", "", "Load file")
+	createChat = load.Flags().Bool("create", false, "Create the chat first, see \"telegramsearch chats create\" to set its metadata")
+	_ = load.MarkFlagRequired("chat-id")
+	_ = load.MarkFlagRequired("filename")
 }
@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/yamlfromstruct"
+)
+
+var tagChatID *string
+var tagCategories *[]string
+
+var tag = &cobra.Command{
+	Use:   "tag",
+	Short: "set categories of the chat, users choose them with /chats",
+	Example: `
+Tag the chat with categories:
+
+	telegramsearch telegram tag --chat-id limassolmed --categories medicine,kids
+
+Remove all categories:
+
+	telegramsearch telegram tag --chat-id limassolmed --categories ""
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		resp, err := ctl.SetChatCategories(ctx, controllerv1models.ReqSetChatCategories{
+			ChatID:     *tagChatID,
+			Categories: *tagCategories,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), yamlfromstruct.Generate(ctx, resp))
+		return err
+	},
+}
+
+func init() {
+	tagChatID = tag.Flags().String("chat-id", "", "Chat ID")
+	tagCategories = tag.Flags().StringSlice("categories", nil, "Comma separated categories, e.g. medicine,housing,cars")
+	_ = tag.MarkFlagRequired("chat-id")
+	_ = tag.MarkFlagRequired("categories")
+}
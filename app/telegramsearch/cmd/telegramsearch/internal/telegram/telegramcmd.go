@@ -16,6 +16,8 @@ var (
 	CmdsToRegister = []*cobra.Command{
 		bot,
 		load,
+		tag,
+		forget,
 	}
 )
 
@@ -0,0 +1,187 @@
+package earthquakes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/sourcegraph/conc/pool"
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/internal/encodingtooling"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+// MergeConfig tells when earthquakes reported by different sources are the same event.
+type MergeConfig struct {
+	TimeTolerance       encodingtooling.Duration `yaml:"time_tolerance"`
+	DistanceToleranceKm float64                  `yaml:"distance_tolerance_km"`
+	MagnitudeTolerance  float32                  `yaml:"magnitude_tolerance"`
+	// Memory is how long merged earthquakes are remembered,
+	// so an event keeps its GUID when a slower source reports it later.
+	Memory encodingtooling.Duration `yaml:"memory"`
+}
+
+// Memory recalls earthquakes returned before and stored by the caller, e.g. in the database,
+// so an event keeps its GUID after a restart.
+type Memory interface {
+	RecallEarthquakes(ctx context.Context, since time.Time) ([]Earthquake, error)
+}
+
+func DefaultMergeConfig() MergeConfig {
+	return MergeConfig{
+		TimeTolerance:       encodingtooling.Duration{Duration: time.Minute},
+		DistanceToleranceKm: 50,
+		MagnitudeTolerance:  1,
+		Memory:              encodingtooling.Duration{Duration: 24 * time.Hour},
+	}
+}
+
+// Source is an Earthquaker of Composite.
+type Source struct {
+	Name string
+	Earthquaker
+}
+
+var _ Earthquaker = (*Composite)(nil)
+
+// Composite queries sources in parallel and merges the same earthquake reported by several of them.
+// Sources go from the most authoritative, its magnitude and position are kept.
+type Composite struct {
+	cfg     MergeConfig
+	sources []Source
+	// memory is nil when earthquakes are remembered by the process only.
+	memory Memory
+
+	mu sync.Mutex
+	// remembered are earthquakes returned before, see MergeConfig.Memory.
+	remembered []Earthquake
+}
+
+// NewComposite remembers earthquakes in the memory, or in the process when it is nil.
+func NewComposite(cfg MergeConfig, memory Memory, sources ...Source) *Composite {
+	return &Composite{cfg: cfg, sources: sources, memory: memory}
+}
+
+// LatestNEarthquakes fails when all sources fail, failures of some of them are logged.
+func (c *Composite) LatestNEarthquakes(ctx context.Context, n int, minMagnitude float32) ([]Earthquake, error) {
+	if n < 0 {
+		return nil, ErrNegativeEarthquakes
+	}
+	// a source may report a lower magnitude than the authoritative one.
+	sourceMinMagnitude := max(0, minMagnitude-c.cfg.MagnitudeTolerance)
+	results := make([][]Earthquake, len(c.sources))
+	errs := make([]error, len(c.sources))
+	p := pool.New()
+	for i, s := range c.sources {
+		p.Go(func() {
+			results[i], errs[i] = s.LatestNEarthquakes(ctx, n, sourceMinMagnitude)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("source %s: %w", s.Name, errs[i])
+			}
+			for j := range results[i] {
+				results[i][j].SourceRank = i
+			}
+		})
+	}
+	p.Wait()
+	if err := errors.Join(errs...); err != nil {
+		if !slices.Contains(errs, nil) {
+			return nil, err
+		}
+		logger.Warn(ctx, "earthquake sources failed", zap.Error(err))
+	}
+
+	merged, err := c.merge(ctx, results)
+	if err != nil {
+		return nil, err
+	}
+	merged = lo.Filter(merged, func(item Earthquake, _ int) bool {
+		return item.Magnitude >= minMagnitude
+	})
+	slices.SortStableFunc(merged, func(a, b Earthquake) int {
+		return b.When.Compare(a.When)
+	})
+	return merged[:min(n, len(merged))], nil
+}
+
+func (c *Composite) merge(ctx context.Context, results [][]Earthquake) ([]Earthquake, error) {
+	var merged []Earthquake
+	for _, eqs := range results {
+		// a source reports an event once, its close events are different ones, e.g. aftershocks.
+		matched := make([]bool, len(merged))
+		for _, eq := range eqs {
+			i := c.indexOfSame(merged[:len(matched)], matched, eq)
+			if i == -1 {
+				merged = append(merged, eq)
+				continue
+			}
+			matched[i] = true
+			merged[i] = complete(merged[i], eq)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	since := time.Now().Add(-c.cfg.Memory.Duration)
+	c.remembered = lo.Filter(c.remembered, func(item Earthquake, _ int) bool {
+		return item.When.After(since)
+	})
+	known := c.remembered
+	if c.memory != nil {
+		recalled, err := c.memory.RecallEarthquakes(ctx, since)
+		if err != nil {
+			return nil, fmt.Errorf("recall earthquakes: %w", err)
+		}
+		known = recalled
+	}
+	claimed := make([]bool, len(known))
+	for i := range merged {
+		j := slices.IndexFunc(known, func(item Earthquake) bool {
+			return item.GUID == merged[i].GUID
+		})
+		if j == -1 {
+			j = c.indexOfSame(known, claimed, merged[i])
+		}
+		if j == -1 {
+			if c.memory == nil {
+				c.remembered = append(c.remembered, merged[i])
+			}
+			continue
+		}
+		claimed[j] = true
+		merged[i].GUID = known[j].GUID
+	}
+	return merged, nil
+}
+
+// indexOfSame returns the index of the first not taken earthquake that is the same as eq, or -1.
+func (c *Composite) indexOfSame(eqs []Earthquake, taken []bool, eq Earthquake) int {
+	for i := range eqs {
+		if !taken[i] && c.same(eqs[i], eq) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Composite) same(a, b Earthquake) bool {
+	return a.When.Sub(b.When).Abs() <= c.cfg.TimeTolerance.Duration &&
+		DistanceKm(a.Position, b.Position) <= c.cfg.DistanceToleranceKm &&
+		math.Abs(float64(a.Magnitude-b.Magnitude)) <= float64(c.cfg.MagnitudeTolerance)
+}
+
+// complete fills what the authoritative report lacks from the other one.
+func complete(authoritative, other Earthquake) Earthquake {
+	authoritative.URL = lo.CoalesceOrEmpty(authoritative.URL, other.URL)
+	authoritative.Place = lo.CoalesceOrEmpty(authoritative.Place, other.Place)
+	authoritative.MajorPlace = lo.CoalesceOrEmpty(authoritative.MajorPlace, other.MajorPlace)
+	authoritative.Location = lo.CoalesceOrEmpty(authoritative.MajorPlace, authoritative.Place, authoritative.Location)
+	authoritative.DepthKm = lo.CoalesceOrEmpty(authoritative.DepthKm, other.DepthKm)
+	return authoritative
+}
@@ -0,0 +1,194 @@
+package earthquakes_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/datagovcy"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/emsc"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/usgs"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/resttooling"
+	"github.com/yanakipre/bot/internal/testtooling"
+)
+
+func TestMain(m *testing.M) {
+	testtooling.SetNewGlobalLoggerQuietly()
+	os.Exit(m.Run())
+}
+
+// fixtureSources serves feeds of all sources from their fixtures.
+func fixtureSources(t *testing.T) []earthquakes.Source {
+	srv := httptest.NewServer(http.FileServer(http.Dir(".")))
+	t.Cleanup(srv.Close)
+
+	var emscCfg emsc.Config
+	emscCfg.Default()
+	emscCfg.ApiURL = srv.URL + "/emsc/response.json"
+	var usgsCfg usgs.Config
+	usgsCfg.Default()
+	usgsCfg.ApiURL = srv.URL + "/usgs/response.json"
+	return []earthquakes.Source{
+		{Name: datagovcy.Source, Earthquaker: datagovcy.NewClient(earthquakes.Config{
+			ApiURL:        srv.URL + "/datagovcy/response.xml",
+			HTTPTransport: resttooling.DefaultTransportConfig(),
+		})},
+		{Name: emsc.Source, Earthquaker: emsc.NewClient(emscCfg)},
+		{Name: usgs.Source, Earthquaker: usgs.NewClient(usgsCfg)},
+	}
+}
+
+func TestComposite(t *testing.T) {
+	composite := earthquakes.NewComposite(earthquakes.DefaultMergeConfig(), nil, fixtureSources(t)...)
+
+	eqs, err := composite.LatestNEarthquakes(context.Background(), 5, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	type brief struct {
+		GUID      string
+		Source    string
+		Magnitude float32
+		URL       string
+		Location  string
+	}
+	expected := []brief{
+		// reported by USGS only.
+		{"usgs:us7000p001", usgs.Source, 4, "https://earthquake.usgs.gov/earthquakes/eventpage/us7000p001", "60 km S of Larnaca, Cyprus"},
+		// GSD magnitude is preferred, the URL is taken from EMSC.
+		{"20250207-3", datagovcy.Source, 2.2, "https://www.seismicportal.eu/eventdetails.html?unid=20250207_0000101", "85.0 km N of Polis"},
+		// EMSC is preferred to USGS, the major place is taken from USGS.
+		{"emsc:20250206_0000055", emsc.Source, 3.1, "https://www.seismicportal.eu/eventdetails.html?unid=20250206_0000055", "45 km SW of Paphos, Cyprus"},
+		{"20250206-1", datagovcy.Source, 0.9, "", "20.0 km SE of Morphou"},
+		{"20250205-5", datagovcy.Source, 1.5, "https://www.seismicportal.eu/eventdetails.html?unid=20250205_0000032", "42.0 km W of Polis"},
+	}
+	if len(eqs) != len(expected) {
+		t.Fatalf("expected %d earthquakes, got %d: %+v", len(expected), len(eqs), eqs)
+	}
+	for i, eq := range eqs {
+		got := brief{eq.GUID, eq.Source, eq.Magnitude, eq.URL, eq.Location}
+		if got != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], got)
+		}
+	}
+
+	strong, err := composite.LatestNEarthquakes(context.Background(), 5, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(strong) != 2 || strong[0].Magnitude != 4 || strong[1].Magnitude != 3.1 {
+		t.Errorf("expected earthquakes of magnitude 4 and 3.1, got %+v", strong)
+	}
+}
+
+// earthquakesFunc returns what the test tells.
+type earthquakesFunc func() ([]earthquakes.Earthquake, error)
+
+func (f earthquakesFunc) LatestNEarthquakes(context.Context, int, float32) ([]earthquakes.Earthquake, error) {
+	return f()
+}
+
+func TestComposite_rememberedGUID(t *testing.T) {
+	now := time.Now().UTC()
+	larnaca := geo.Point{Lat: 34.9, Lon: 33.6}
+	var authoritative []earthquakes.Earthquake
+	composite := earthquakes.NewComposite(earthquakes.DefaultMergeConfig(), nil,
+		earthquakes.Source{Name: "slow", Earthquaker: earthquakesFunc(func() ([]earthquakes.Earthquake, error) {
+			return authoritative, nil
+		})},
+		earthquakes.Source{Name: "fast", Earthquaker: earthquakesFunc(func() ([]earthquakes.Earthquake, error) {
+			return []earthquakes.Earthquake{
+				{GUID: "fast:1", Magnitude: 4.4, When: now, Position: larnaca},
+				// an aftershock is a different earthquake.
+				{GUID: "fast:2", Magnitude: 4.1, When: now.Add(-10 * time.Second), Position: larnaca},
+			}, nil
+		})},
+		earthquakes.Source{Name: "broken", Earthquaker: earthquakesFunc(func() ([]earthquakes.Earthquake, error) {
+			return nil, errors.New("unavailable")
+		})},
+	)
+
+	eqs, err := composite.LatestNEarthquakes(context.Background(), 5, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(eqs) != 2 || eqs[0].GUID != "fast:1" || eqs[1].GUID != "fast:2" {
+		t.Fatalf("expected both earthquakes of the fast source, got %+v", eqs)
+	}
+
+	authoritative = []earthquakes.Earthquake{
+		{GUID: "slow:1", Magnitude: 4.6, When: now.Add(time.Second), Position: larnaca},
+	}
+	eqs, err = composite.LatestNEarthquakes(context.Background(), 5, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(eqs) != 2 {
+		t.Fatalf("expected 2 earthquakes, got %+v", eqs)
+	}
+	// the earthquake keeps its GUID, so it is not alerted again.
+	if eqs[0].GUID != "fast:1" || eqs[0].Magnitude != 4.6 {
+		t.Errorf("expected fast:1 of magnitude 4.6, got %+v", eqs[0])
+	}
+	if eqs[1].GUID != "fast:2" {
+		t.Errorf("expected fast:2, got %+v", eqs[1])
+	}
+}
+
+// earthquakesMemory recalls what the test tells.
+type earthquakesMemory []earthquakes.Earthquake
+
+func (m earthquakesMemory) RecallEarthquakes(context.Context, time.Time) ([]earthquakes.Earthquake, error) {
+	return m, nil
+}
+
+func TestComposite_memory(t *testing.T) {
+	now := time.Now().UTC()
+	larnaca := geo.Point{Lat: 34.9, Lon: 33.6}
+	// stored before the restart, when only the fast source reported the earthquake.
+	memory := earthquakesMemory{{GUID: "fast:1", Magnitude: 4.4, When: now, Position: larnaca}}
+	composite := earthquakes.NewComposite(earthquakes.DefaultMergeConfig(), memory,
+		earthquakes.Source{Name: "slow", Earthquaker: earthquakesFunc(func() ([]earthquakes.Earthquake, error) {
+			return []earthquakes.Earthquake{
+				{GUID: "slow:1", Magnitude: 4.6, When: now.Add(time.Second), Position: larnaca},
+			}, nil
+		})},
+		earthquakes.Source{Name: "fast", Earthquaker: earthquakesFunc(func() ([]earthquakes.Earthquake, error) {
+			return []earthquakes.Earthquake{
+				{GUID: "fast:1", Magnitude: 4.4, When: now, Position: larnaca},
+				{GUID: "fast:2", Magnitude: 4.1, When: now.Add(-10 * time.Second), Position: larnaca},
+			}, nil
+		})},
+	)
+
+	eqs, err := composite.LatestNEarthquakes(context.Background(), 5, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(eqs) != 2 {
+		t.Fatalf("expected 2 earthquakes, got %+v", eqs)
+	}
+	if eqs[0].GUID != "fast:1" || eqs[0].Magnitude != 4.6 || eqs[0].SourceRank != 0 {
+		t.Errorf("expected fast:1 of magnitude 4.6 by the slow source, got %+v", eqs[0])
+	}
+	if eqs[1].GUID != "fast:2" || eqs[1].SourceRank != 1 {
+		t.Errorf("expected fast:2 by the fast source, got %+v", eqs[1])
+	}
+}
+
+func TestComposite_allSourcesFail(t *testing.T) {
+	composite := earthquakes.NewComposite(earthquakes.DefaultMergeConfig(), nil,
+		earthquakes.Source{Name: "broken", Earthquaker: earthquakesFunc(func() ([]earthquakes.Earthquake, error) {
+			return nil, errors.New("unavailable")
+		})},
+	)
+	if _, err := composite.LatestNEarthquakes(context.Background(), 5, 0); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
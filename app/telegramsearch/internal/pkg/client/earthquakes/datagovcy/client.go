@@ -7,17 +7,30 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
-	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/logger"
 )
 
 const DateTimeTz = time.DateTime + " MST"
 
+// Source is the Geological Survey Department of Cyprus.
+const Source = "gsd"
+
+// timeLayouts of the event time, tried in order. Fractional seconds are accepted by all of them.
+var timeLayouts = []string{DateTimeTz, time.DateTime, time.RFC3339, "2006-01-02T15:04:05"}
+
 var _ earthquakes.Earthquaker = (*client)(nil)
 
 type client struct {
@@ -82,7 +95,27 @@ func NewClient(cfg earthquakes.Config) *client {
 	return client
 }
 
-var ErrNegativeEarthquakes = errors.New("n cannot be negative")
+var (
+	ErrNegativeEarthquakes = earthquakes.ErrNegativeEarthquakes
+	// ErrNoValidEarthquakes is returned when no item of the feed is parsed, the format has changed probably.
+	ErrNoValidEarthquakes = errors.New("no valid earthquakes in the feed")
+)
+
+// ItemError describes the feed item that is skipped.
+type ItemError struct {
+	// Index of the item in the feed.
+	Index int
+	Title string
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d %q: %v", e.Index, e.Title, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
 
 func (c *client) LatestNEarthquakes(ctx context.Context, n int, minMagnitude float32) ([]earthquakes.Earthquake, error) {
 	if n < 0 {
@@ -112,22 +145,62 @@ func (c *client) LatestNEarthquakes(ctx context.Context, n int, minMagnitude flo
 
 	eqs := make([]earthquakes.Earthquake, 0, n)
 
+	// invalid items are skipped, the rest of the feed is still useful.
+	var itemErrs []error
+	parsed := 0
 	for i := 0; len(eqs) < n && i < len(feed.Channel.Items); i++ {
 		v := feed.Channel.Items[i]
-		eq, err := parseDesc(v.Description)
+		eq, err := parseItem(v)
 		if err != nil {
-			return nil, fmt.Errorf("unmarshal datagovcy description: %w", err)
+			itemErrs = append(itemErrs, &ItemError{Index: i, Title: lo.FromPtr(v.Title), Err: err})
+			continue
 		}
+		parsed++
 		if eq.Magnitude < minMagnitude {
 			continue
 		}
 
 		eqs = append(eqs, eq)
 	}
+	if len(itemErrs) > 0 {
+		err := errors.Join(itemErrs...)
+		if parsed == 0 {
+			return nil, fmt.Errorf("%w: %w", ErrNoValidEarthquakes, err)
+		}
+		logger.Warn(ctx, "invalid earthquakes skipped", zap.Int("skipped", len(itemErrs)), zap.Error(err))
+	}
 
 	return eqs, nil
 }
 
+func parseItem(v rssItem) (earthquakes.Earthquake, error) {
+	eq, err := parseDesc(v.Description)
+	if err != nil {
+		return earthquakes.Earthquake{}, err
+	}
+	if eq.When.IsZero() && v.PubDate != nil {
+		// publication follows the event closely.
+		eq.When = v.PubDate.Time
+	}
+	if eq.When.IsZero() {
+		return earthquakes.Earthquake{}, errors.New("no time")
+	}
+	eq.Source = Source
+	eq.URL = strings.TrimSpace(lo.FromPtr(v.Link))
+	// the feed leaves guid empty, the event ID from the description identifies it then.
+	eq.GUID = lo.CoalesceOrEmpty(strings.TrimSpace(lo.FromPtr(v.Guid)), eq.SourceID, strings.TrimSpace(lo.FromPtr(v.Title)))
+	return eq, nil
+}
+
+var (
+	// coordinateRe matches "35.7833°N, 32.2653°E", hemispheres are optional for signed degrees.
+	coordinateRe = regexp.MustCompile(`^([-+]?\d+(?:\.\d+)?)\s*°?\s*([NSns]?)\s*,\s*([-+]?\d+(?:\.\d+)?)\s*°?\s*([EWew]?)$`)
+	// numberRe matches the leading number of "2.2 Ml" or "23.83 km".
+	numberRe = regexp.MustCompile(`^[-+]?\d+(?:\.\d+)?`)
+)
+
+// parseDesc reads the HTML table of the event, errors of all fields are returned.
+// Time, place and depth are optional, position and magnitude are required.
 func parseDesc(data []byte) (earthquakes.Earthquake, error) {
 	data = bytes.ReplaceAll(data, []byte{10}, []byte{})
 	tree, err := html.ParseFragment(bytes.NewReader(data), &html.Node{
@@ -139,92 +212,136 @@ func parseDesc(data []byte) (earthquakes.Earthquake, error) {
 		return earthquakes.Earthquake{}, fmt.Errorf("parsing html doc: %w", err)
 	}
 
-	// Finds first occurence of a node
-	var findNode func(n *html.Node, elem atom.Atom) *html.Node
-	findNode = func(n *html.Node, elem atom.Atom) *html.Node {
-		if n == nil {
-			return nil
+	fields := map[string]string{}
+	for _, v := range tree {
+		descFields(v, fields)
+	}
+
+	var eq earthquakes.Earthquake
+	var errs []error
+	eq.SourceID = fields["event"]
+	eq.Place = fields["place"]
+	eq.MajorPlace = fields["major place"]
+	eq.Location = lo.CoalesceOrEmpty(eq.MajorPlace, eq.Place)
+	if v, ok := fields["time"]; ok {
+		if eq.When, err = parseTime(v); err != nil {
+			errs = append(errs, err)
 		}
-		if n.Type == html.ElementNode && n.DataAtom == elem {
-			return n
+	}
+	if v, ok := fields["position"]; ok {
+		if eq.Position, err = parseCoordinate(v); err != nil {
+			errs = append(errs, err)
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			if node := findNode(c, elem); node != nil {
-				return node
-			}
+	} else {
+		errs = append(errs, errors.New("no position"))
+	}
+	if v, ok := fields["magnitude"]; ok {
+		if eq.Magnitude, err = parseNumber(v); err != nil {
+			errs = append(errs, fmt.Errorf("magnitude: %w", err))
 		}
-
-		return nil
+	} else {
+		errs = append(errs, errors.New("no magnitude"))
 	}
-
-	var eq earthquakes.Earthquake
-
-	// Parses values from HTML <table> to Earthquake struct
-	var f func(*html.Node) error
-	f = func(n *html.Node) error {
-		if n == nil {
-			return nil
+	if v, ok := fields["depth"]; ok {
+		if eq.DepthKm, err = parseNumber(v); err != nil {
+			errs = append(errs, fmt.Errorf("depth: %w", err))
 		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return earthquakes.Earthquake{}, err
+	}
+	return eq, nil
+}
 
-		if n.Type == html.ElementNode && n.DataAtom == atom.Tr {
-			th := findNode(n, atom.Th)
-			td := findNode(n, atom.Td)
-			if th == nil || th.FirstChild == nil || td == nil || td.FirstChild == nil {
-				return nil
-			}
-
-			// Lowers all letters and removes non-letter characters
-			thData := strings.TrimFunc(strings.ToLower(th.FirstChild.Data), func(r rune) bool {
-				return !unicode.IsLetter(r)
-			})
-			tdData := td.FirstChild.Data
-
-			switch thData {
-			case "time":
-				eq.When, err = time.Parse(DateTimeTz, tdData)
-				if err != nil {
-				}
-			case "position":
-				n, err := fmt.Sscanf(tdData, "%f°N, %f°E", &eq.Position.Latitude, &eq.Position.Longitude)
-				if err != nil {
-					return fmt.Errorf("scanning coordinate data: %w", err)
-				}
-				if n != 2 {
-					return errors.New("invalid format of scanned coordinate data")
-				}
-
-			case "place":
-			case "major place":
-				eq.Location = tdData
-			case "depth":
-			case "magnitude":
-				n, err := fmt.Sscanf(tdData, "%f", &eq.Magnitude)
-				if err != nil {
-					return fmt.Errorf("scanning magnitude data: %w", err)
-				}
-				if n != 1 {
-					return errors.New("invalid format of scanned magnitude data")
-				}
-			}
-
+// descFields collects non-empty <th> and <td> texts of table rows,
+// labels are lower-cased without punctuation, e.g. "Major Place:" is "major place".
+func descFields(n *html.Node, fields map[string]string) {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Tr {
+		th := findNode(n, atom.Th)
+		td := findNode(n, atom.Td)
+		if th == nil || td == nil {
+			return
 		}
+		label := strings.TrimFunc(strings.ToLower(nodeText(th)), func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})
+		// &nbsp; is a space as well.
+		value := strings.Join(strings.Fields(nodeText(td)), " ")
+		if label != "" && value != "" {
+			fields[label] = value
+		}
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		descFields(c, fields)
+	}
+}
 
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			err := f(c)
-			if err != nil {
-				return err
-			}
+// findNode finds the first occurrence of elem in n.
+func findNode(n *html.Node, elem atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == elem {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if node := findNode(c, elem); node != nil {
+			return node
 		}
+	}
+	return nil
+}
 
-		return nil
+// nodeText concatenates text of n and its descendants.
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(nodeText(c))
 	}
+	return sb.String()
+}
 
-	for _, v := range tree {
-		err = f(v)
-		if err != nil {
-			return earthquakes.Earthquake{}, err
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
 		}
 	}
+	return time.Time{}, fmt.Errorf("unknown time format %q", s)
+}
 
-	return eq, nil
+func parseCoordinate(s string) (geo.Point, error) {
+	m := coordinateRe.FindStringSubmatch(s)
+	if m == nil {
+		return geo.Point{}, fmt.Errorf("unknown position format %q", s)
+	}
+	lat, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return geo.Point{}, fmt.Errorf("latitude: %w", err)
+	}
+	lon, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return geo.Point{}, fmt.Errorf("longitude: %w", err)
+	}
+	if strings.EqualFold(m[2], "S") {
+		lat = -lat
+	}
+	if strings.EqualFold(m[4], "W") {
+		lon = -lon
+	}
+	p := geo.Point{Lat: lat, Lon: lon}
+	if !p.Valid() {
+		return geo.Point{}, fmt.Errorf("position out of range %q", s)
+	}
+	return p, nil
+}
+
+func parseNumber(s string) (float32, error) {
+	number := numberRe.FindString(s)
+	if number == "" {
+		return 0, fmt.Errorf("no number in %q", s)
+	}
+	v, err := strconv.ParseFloat(number, 32)
+	return float32(v), err
 }
@@ -9,12 +9,20 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/datagovcy"
+	"github.com/yanakipre/bot/internal/geo"
 	"github.com/yanakipre/bot/internal/resttooling"
+	"github.com/yanakipre/bot/internal/testtooling"
 )
 
+func TestMain(m *testing.M) {
+	testtooling.SetNewGlobalLoggerQuietly()
+	os.Exit(m.Run())
+}
+
 func testLatestNEarthQuakes(t *testing.T, client earthquakes.Earthquaker) {
 
 	tests := []struct {
@@ -94,3 +102,134 @@ func TestLatestNEarthQuakes(t *testing.T) {
 
 	testLatestNEarthQuakes(t, client)
 }
+
+// fixtureClient serves the feed from the file.
+func fixtureClient(t *testing.T, file string) earthquakes.Earthquaker {
+	srv := httptest.NewServer(http.FileServer(http.Dir(".")))
+	t.Cleanup(srv.Close)
+
+	return datagovcy.NewClient(earthquakes.Config{
+		ApiURL:        srv.URL + "/" + file,
+		HTTPTransport: resttooling.DefaultTransportConfig(),
+	})
+}
+
+func TestLatestNEarthQuakes_fields(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		file     string
+		expected []earthquakes.Earthquake
+	}{
+		{
+			name: "Cyprus",
+			file: "response.xml",
+			expected: []earthquakes.Earthquake{{
+				// guid of the feed is empty, the event ID is used.
+				GUID:       "20250207-3",
+				Source:     datagovcy.Source,
+				SourceID:   "20250207-3",
+				Magnitude:  2.2,
+				When:       time.Date(2025, 2, 7, 8, 13, 30, 270_000_000, time.UTC),
+				Location:   "85.0 km N of Polis",
+				Place:      "Mediterranean Sea",
+				MajorPlace: "85.0 km N of Polis",
+				DepthKm:    23.83,
+				Position:   geo.Point{Lat: 35.7833, Lon: 32.2653},
+			}},
+		},
+		{
+			name: "SouthernAndWestern",
+			file: "response_southwest.xml",
+			expected: []earthquakes.Earthquake{
+				{
+					GUID:      "20250301-1",
+					Source:    datagovcy.Source,
+					SourceID:  "20250301-1",
+					URL:       "http://www.gsd-seismology.org.cy/events/20250301-1",
+					Magnitude: 5.1,
+					When:      time.Date(2025, 3, 1, 9, 58, 1, 120_000_000, time.UTC),
+					Location:  "Chile",
+					Place:     "Chile",
+					DepthKm:   110.5,
+					Position:  geo.Point{Lat: -33.5, Lon: -70.25},
+				},
+				{
+					GUID:       "quake-2",
+					Source:     datagovcy.Source,
+					SourceID:   "20250301-0",
+					Magnitude:  3,
+					When:       time.Date(2025, 3, 1, 7, 59, 30, 0, time.UTC),
+					Location:   "10 km W of Somewhere",
+					Place:      "Brazil",
+					MajorPlace: "10 km W of Somewhere",
+					Position:   geo.Point{Lat: -12.5, Lon: -45.75},
+				},
+			},
+		},
+		{
+			name: "MalformedItemsSkipped",
+			file: "response_malformed.xml",
+			expected: []earthquakes.Earthquake{
+				{
+					GUID:      "20250301-2",
+					Source:    datagovcy.Source,
+					SourceID:  "20250301-2",
+					Magnitude: 3.3,
+					// no time in the description, the publication time is used.
+					When:     time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC),
+					Location: "Mediterranean Sea",
+					Place:    "Mediterranean Sea",
+					DepthKm:  12,
+					Position: geo.Point{Lat: 34.9, Lon: 32.8},
+				},
+				{
+					GUID:       "20250301-1",
+					Source:     datagovcy.Source,
+					SourceID:   "20250301-1",
+					Magnitude:  2.8,
+					When:       time.Date(2025, 3, 1, 5, 58, 1, 500_000_000, time.UTC),
+					Location:   "5.0 km S of Limassol",
+					Place:      "Limassol",
+					MajorPlace: "5.0 km S of Limassol",
+					DepthKm:    8.1,
+					Position:   geo.Point{Lat: 34.7, Lon: 33},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			eqs, err := fixtureClient(t, tc.file).LatestNEarthquakes(ctx, len(tc.expected), 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(eqs) != len(tc.expected) {
+				t.Fatalf("expected %d earthquakes, got %d: %+v", len(tc.expected), len(eqs), eqs)
+			}
+			for i := range eqs {
+				if !eqs[i].When.Equal(tc.expected[i].When) {
+					t.Errorf("expected time %v, got %v", tc.expected[i].When, eqs[i].When)
+				}
+				eqs[i].When = tc.expected[i].When
+				if eqs[i] != tc.expected[i] {
+					t.Errorf("expected %+v, got %+v", tc.expected[i], eqs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLatestNEarthQuakes_invalidFeed(t *testing.T) {
+	_, err := fixtureClient(t, "response_invalid.xml").LatestNEarthquakes(context.Background(), 5, 0)
+	if !errors.Is(err, datagovcy.ErrNoValidEarthquakes) {
+		t.Fatalf("expected error %v, got %v", datagovcy.ErrNoValidEarthquakes, err)
+	}
+	var itemErr *datagovcy.ItemError
+	if !errors.As(err, &itemErr) || itemErr.Title != "bad position" {
+		t.Errorf("expected the error of the item \"bad position\", got %v", err)
+	}
+}
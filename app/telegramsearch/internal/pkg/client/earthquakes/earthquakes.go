@@ -2,24 +2,48 @@ package earthquakes
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/yanakipre/bot/internal/geo"
 )
 
+var ErrNegativeEarthquakes = errors.New("n cannot be negative")
+
 // ;)
 type Earthquaker interface {
 	LatestNEarthquakes(ctx context.Context, n int, minMagnitude float32) ([]Earthquake, error)
 }
 
 type Earthquake struct {
+	// GUID identifies the event in the feed, alerts are deduplicated by it.
+	GUID string
+	// Source is the service that reported the earthquake, e.g. "gsd".
+	Source string
+	// SourceID is the ID of the event given by the seismology service, e.g. "20250207-3".
+	SourceID string
+	// SourceRank is the position of the source in Composite, 0 is the most authoritative.
+	SourceRank int
+	// URL of the event page, empty when the feed has none.
+	URL       string
 	Magnitude float32
 	When      time.Time
-	// Human-readable location of earthquake
+	// Human-readable location of earthquake: MajorPlace, or Place when it is empty.
 	Location string
+	// Place is the region, e.g. "Mediterranean Sea".
+	Place string
+	// MajorPlace is relative to the closest major place, e.g. "85.0 km N of Polis".
+	MajorPlace string
+	// DepthKm of the hypocenter.
+	DepthKm float32
 	// Geo coordinates
-	Position Coordinate
+	Position geo.Point
 }
 
-// TODO: #5 Extract to common package
-type Coordinate struct {
-	Latitude, Longitude float64
+// Cyprus is the center of the island, sources are queried around it.
+var Cyprus = geo.Point{Lat: 35.1264, Lon: 33.4299}
+
+// DistanceKm between a and b on the Earth surface.
+func DistanceKm(a, b geo.Point) float64 {
+	return geo.Distance(a, b) / 1000
 }
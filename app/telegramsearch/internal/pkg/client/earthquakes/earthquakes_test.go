@@ -0,0 +1,25 @@
+package earthquakes_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/internal/geo"
+)
+
+func TestDistanceKm(t *testing.T) {
+	limassol := geo.Point{Lat: 34.6786, Lon: 33.0413}
+	nicosia := geo.Point{Lat: 35.1856, Lon: 33.3823}
+
+	if d := earthquakes.DistanceKm(limassol, limassol); d != 0 {
+		t.Errorf("expected 0 km to itself, got %f", d)
+	}
+	// about 64 km in a straight line.
+	if d := earthquakes.DistanceKm(limassol, nicosia); math.Abs(d-64.5) > 1 {
+		t.Errorf("expected about 64.5 km from Limassol to Nicosia, got %f", d)
+	}
+	if a, b := earthquakes.DistanceKm(limassol, nicosia), earthquakes.DistanceKm(nicosia, limassol); a != b {
+		t.Errorf("expected symmetric distance, got %f and %f", a, b)
+	}
+}
@@ -0,0 +1,135 @@
+// Package emsc reads earthquakes from the European-Mediterranean Seismological Centre,
+// its FDSN event service answers with GeoJSON.
+package emsc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/internal/geo"
+)
+
+// Source is the European-Mediterranean Seismological Centre.
+const Source = "emsc"
+
+const eventURL = "https://www.seismicportal.eu/eventdetails.html?unid="
+
+// kmPerDegree of a great circle, FDSN radius is in degrees.
+const kmPerDegree = 111.195
+
+var _ earthquakes.Earthquaker = (*client)(nil)
+
+type client struct {
+	httpClient *http.Client
+	cfg        Config
+}
+
+type featureCollection struct {
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	ID         string     `json:"id"`
+	Properties properties `json:"properties"`
+}
+
+type properties struct {
+	UnID        string    `json:"unid"`
+	Time        time.Time `json:"time"`
+	Lat         float64   `json:"lat"`
+	Lon         float64   `json:"lon"`
+	Depth       float32   `json:"depth"`
+	Mag         float32   `json:"mag"`
+	FlynnRegion string    `json:"flynn_region"`
+}
+
+func NewClient(cfg Config) *client {
+	return &client{
+		httpClient: &http.Client{
+			Transport: cfg.HTTPTransport.Resolve(),
+		},
+		cfg: cfg,
+	}
+}
+
+func (c *client) LatestNEarthquakes(ctx context.Context, n int, minMagnitude float32) ([]earthquakes.Earthquake, error) {
+	if n < 0 {
+		return nil, earthquakes.ErrNegativeEarthquakes
+	}
+	if n == 0 {
+		return []earthquakes.Earthquake{}, nil
+	}
+
+	u, err := url.Parse(c.cfg.ApiURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("format", "json")
+	q.Set("orderby", "time")
+	q.Set("limit", strconv.Itoa(n))
+	q.Set("minmag", strconv.FormatFloat(float64(minMagnitude), 'f', -1, 32))
+	q.Set("lat", strconv.FormatFloat(c.cfg.Center.Lat, 'f', -1, 64))
+	q.Set("lon", strconv.FormatFloat(c.cfg.Center.Lon, 'f', -1, 64))
+	q.Set("maxradius", strconv.FormatFloat(c.cfg.RadiusKm/kmPerDegree, 'f', 3, 64))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// FDSN answers 204 when nothing matches.
+	if res.StatusCode == http.StatusNoContent {
+		return []earthquakes.Earthquake{}, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get earthquakes, unexpected status code: %d", res.StatusCode)
+	}
+
+	var collection featureCollection
+	if err := json.NewDecoder(res.Body).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("decoding json response body: %w", err)
+	}
+
+	eqs := make([]earthquakes.Earthquake, 0, min(n, len(collection.Features)))
+	for _, f := range collection.Features {
+		if len(eqs) == n {
+			break
+		}
+		p := f.Properties
+		if p.Mag < minMagnitude {
+			continue
+		}
+		id := strings.TrimSpace(p.UnID)
+		if id == "" {
+			id = f.ID
+		}
+		place := strings.TrimSpace(p.FlynnRegion)
+		eqs = append(eqs, earthquakes.Earthquake{
+			GUID:      Source + ":" + id,
+			Source:    Source,
+			SourceID:  id,
+			URL:       eventURL + url.QueryEscape(id),
+			Magnitude: p.Mag,
+			When:      p.Time,
+			Location:  place,
+			Place:     place,
+			DepthKm:   p.Depth,
+			Position:  geo.Point{Lat: p.Lat, Lon: p.Lon},
+		})
+	}
+	return eqs, nil
+}
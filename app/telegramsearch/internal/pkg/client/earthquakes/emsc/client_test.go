@@ -0,0 +1,98 @@
+package emsc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/emsc"
+	"github.com/yanakipre/bot/internal/geo"
+)
+
+func fixtureClient(t *testing.T, handler http.Handler) earthquakes.Earthquaker {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	var cfg emsc.Config
+	cfg.Default()
+	cfg.ApiURL = srv.URL + "/response.json"
+	return emsc.NewClient(cfg)
+}
+
+func TestLatestNEarthquakes(t *testing.T) {
+	var query string
+	client := fixtureClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		http.FileServer(http.Dir(".")).ServeHTTP(w, r)
+	}))
+
+	eqs, err := client.LatestNEarthquakes(context.Background(), 2, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "format=json&lat=35.1264&limit=2&lon=33.4299&maxradius=2.698&minmag=2&orderby=time"
+	if query != expectedQuery {
+		t.Errorf("expected query %q, got %q", expectedQuery, query)
+	}
+	expected := []earthquakes.Earthquake{
+		{
+			GUID:      "emsc:20250207_0000101",
+			Source:    emsc.Source,
+			SourceID:  "20250207_0000101",
+			URL:       "https://www.seismicportal.eu/eventdetails.html?unid=20250207_0000101",
+			Magnitude: 2.5,
+			When:      time.Date(2025, 2, 7, 8, 13, 31, 100_000_000, time.UTC),
+			Location:  "CYPRUS REGION",
+			Place:     "CYPRUS REGION",
+			DepthKm:   25,
+			Position:  geo.Point{Lat: 35.79, Lon: 32.28},
+		},
+		{
+			GUID:      "emsc:20250206_0000055",
+			Source:    emsc.Source,
+			SourceID:  "20250206_0000055",
+			URL:       "https://www.seismicportal.eu/eventdetails.html?unid=20250206_0000055",
+			Magnitude: 3.1,
+			When:      time.Date(2025, 2, 6, 22, 1, 2, 0, time.UTC),
+			Location:  "CYPRUS REGION",
+			Place:     "CYPRUS REGION",
+			DepthKm:   10,
+			Position:  geo.Point{Lat: 34.6, Lon: 32.1},
+		},
+	}
+	if len(eqs) != len(expected) {
+		t.Fatalf("expected %d earthquakes, got %d", len(expected), len(eqs))
+	}
+	for i := range eqs {
+		if !eqs[i].When.Equal(expected[i].When) {
+			t.Errorf("expected time %v, got %v", expected[i].When, eqs[i].When)
+		}
+		eqs[i].When = expected[i].When
+		if eqs[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], eqs[i])
+		}
+	}
+}
+
+func TestLatestNEarthquakes_noContent(t *testing.T) {
+	client := fixtureClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	eqs, err := client.LatestNEarthquakes(context.Background(), 5, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(eqs) != 0 {
+		t.Errorf("expected no earthquakes, got %d", len(eqs))
+	}
+
+	_, err = client.LatestNEarthquakes(context.Background(), -1, 0)
+	if !errors.Is(err, earthquakes.ErrNegativeEarthquakes) {
+		t.Errorf("expected error %v, got %v", earthquakes.ErrNegativeEarthquakes, err)
+	}
+}
@@ -0,0 +1,135 @@
+// Package usgs reads earthquakes from the U.S. Geological Survey,
+// its FDSN event service answers with GeoJSON.
+package usgs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/internal/geo"
+)
+
+// Source is the U.S. Geological Survey.
+const Source = "usgs"
+
+var _ earthquakes.Earthquaker = (*client)(nil)
+
+type client struct {
+	httpClient *http.Client
+	cfg        Config
+}
+
+type featureCollection struct {
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	ID         string     `json:"id"`
+	Properties properties `json:"properties"`
+	Geometry   geometry   `json:"geometry"`
+}
+
+type properties struct {
+	Mag   float32 `json:"mag"`
+	Place string  `json:"place"`
+	// Time is milliseconds since the epoch.
+	Time int64  `json:"time"`
+	URL  string `json:"url"`
+}
+
+type geometry struct {
+	// Coordinates are longitude, latitude and depth in km.
+	Coordinates []float64 `json:"coordinates"`
+}
+
+func NewClient(cfg Config) *client {
+	return &client{
+		httpClient: &http.Client{
+			Transport: cfg.HTTPTransport.Resolve(),
+		},
+		cfg: cfg,
+	}
+}
+
+func (c *client) LatestNEarthquakes(ctx context.Context, n int, minMagnitude float32) ([]earthquakes.Earthquake, error) {
+	if n < 0 {
+		return nil, earthquakes.ErrNegativeEarthquakes
+	}
+	if n == 0 {
+		return []earthquakes.Earthquake{}, nil
+	}
+
+	u, err := url.Parse(c.cfg.ApiURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("format", "geojson")
+	q.Set("orderby", "time")
+	q.Set("limit", strconv.Itoa(n))
+	q.Set("minmagnitude", strconv.FormatFloat(float64(minMagnitude), 'f', -1, 32))
+	q.Set("latitude", strconv.FormatFloat(c.cfg.Center.Lat, 'f', -1, 64))
+	q.Set("longitude", strconv.FormatFloat(c.cfg.Center.Lon, 'f', -1, 64))
+	q.Set("maxradiuskm", strconv.FormatFloat(c.cfg.RadiusKm, 'f', -1, 64))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// FDSN answers 204 when nothing matches.
+	if res.StatusCode == http.StatusNoContent {
+		return []earthquakes.Earthquake{}, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get earthquakes, unexpected status code: %d", res.StatusCode)
+	}
+
+	var collection featureCollection
+	if err := json.NewDecoder(res.Body).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("decoding json response body: %w", err)
+	}
+
+	eqs := make([]earthquakes.Earthquake, 0, min(n, len(collection.Features)))
+	for _, f := range collection.Features {
+		if len(eqs) == n {
+			break
+		}
+		p := f.Properties
+		if p.Mag < minMagnitude || len(f.Geometry.Coordinates) < 2 {
+			continue
+		}
+		eq := earthquakes.Earthquake{
+			GUID:       Source + ":" + f.ID,
+			Source:     Source,
+			SourceID:   f.ID,
+			URL:        p.URL,
+			Magnitude:  p.Mag,
+			When:       time.UnixMilli(p.Time).UTC(),
+			Location:   strings.TrimSpace(p.Place),
+			MajorPlace: strings.TrimSpace(p.Place),
+			Position: geo.Point{
+				Lat: f.Geometry.Coordinates[1],
+				Lon: f.Geometry.Coordinates[0],
+			},
+		}
+		if len(f.Geometry.Coordinates) > 2 {
+			eq.DepthKm = float32(f.Geometry.Coordinates[2])
+		}
+		eqs = append(eqs, eq)
+	}
+	return eqs, nil
+}
@@ -0,0 +1,53 @@
+package usgs_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/usgs"
+	"github.com/yanakipre/bot/internal/geo"
+)
+
+func TestLatestNEarthquakes(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		http.FileServer(http.Dir(".")).ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	var cfg usgs.Config
+	cfg.Default()
+	cfg.ApiURL = srv.URL + "/response.json"
+
+	eqs, err := usgs.NewClient(cfg).LatestNEarthquakes(context.Background(), 5, 3.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedQuery := "format=geojson&latitude=35.1264&limit=5&longitude=33.4299&maxradiuskm=300&minmagnitude=3.5&orderby=time"
+	if query != expectedQuery {
+		t.Errorf("expected query %q, got %q", expectedQuery, query)
+	}
+	// the other earthquake is weaker.
+	expected := earthquakes.Earthquake{
+		GUID:       "usgs:us7000p001",
+		Source:     usgs.Source,
+		SourceID:   "us7000p001",
+		URL:        "https://earthquake.usgs.gov/earthquakes/eventpage/us7000p001",
+		Magnitude:  4,
+		When:       time.Date(2025, 2, 7, 12, 0, 0, 0, time.UTC),
+		Location:   "60 km S of Larnaca, Cyprus",
+		MajorPlace: "60 km S of Larnaca, Cyprus",
+		DepthKm:    35.2,
+		Position:   geo.Point{Lat: 34, Lon: 33.5},
+	}
+	if len(eqs) != 1 {
+		t.Fatalf("expected 1 earthquake, got %d", len(eqs))
+	}
+	if eqs[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, eqs[0])
+	}
+}
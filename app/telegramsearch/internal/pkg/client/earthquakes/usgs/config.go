@@ -0,0 +1,29 @@
+package usgs
+
+import (
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/resttooling"
+)
+
+type Config struct {
+	// ApiURL is the FDSN event service, the source is disabled when it is empty.
+	ApiURL        string
+	HTTPTransport resttooling.Config
+	// Center and RadiusKm limit earthquakes to the area.
+	Center   geo.Point
+	RadiusKm float64
+}
+
+const defaultApiURL = "https://earthquake.usgs.gov/fdsnws/event/1/query"
+
+func (c *Config) Default() {
+	transport := resttooling.DefaultTransportConfig()
+	transport.ClientName = "usgs"
+	*c = Config{
+		ApiURL:        defaultApiURL,
+		HTTPTransport: transport,
+		Center:        earthquakes.Cyprus,
+		RadiusKm:      300,
+	}
+}
@@ -21,9 +21,14 @@ type Config struct {
 	Transport  resttooling.Config `yaml:"transport"`
 	Retries    resttooling.RetriesConfig
 	// Rate limits by handlers(slug)
-	RateLimiters   []ratelimiter.RateLimitByHandlersConfig `yaml:"rate_limiters"`
-	AskingAbout    string                                  `yaml:"asking_about"`
-	DoNotHighlight string                                  `json:"do_not_highlight"`
+	RateLimiters []ratelimiter.RateLimitByHandlersConfig `yaml:"rate_limiters"`
+}
+
+// WithHTTPClient returns a copy of the config with overridden HTTP client.
+// Useful in tests to plug in fakes, like openaifake.
+func (c Config) WithHTTPClient(httpClient *http.Client) Config {
+	c.httpClient = httpClient
+	return c
 }
 
 type EmbeddingConfig struct {
@@ -35,8 +40,6 @@ func DefaultConfig() Config {
 	tr.ResponseHeaderTimeout = encodingtooling.Duration{Duration: time.Minute}
 	tr.ClientName = "openapi"
 	return Config{
-		DoNotHighlight: "Cyprus",
-		AskingAbout:    "Cyprus",
 		EmbeddingConfig: EmbeddingConfig{
 			Model: openai.SmallEmbedding3,
 		},
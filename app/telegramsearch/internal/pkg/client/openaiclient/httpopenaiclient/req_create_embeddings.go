@@ -21,3 +21,8 @@ func (c *Client) CreateEmbeddings(ctx context.Context, req openaimodels.ReqCreat
 		Embeddings: got.Data,
 	}, nil
 }
+
+// EmbeddingModel creates embeddings, they are comparable only when created by the same model.
+func (c *Client) EmbeddingModel() string {
+	return string(c.cfg.EmbeddingConfig.Model)
+}
@@ -0,0 +1,275 @@
+// Package openaifake provides a deterministic fake of the OpenAI HTTP API.
+//
+// It is intended for tests only: embeddings are derived from the input text
+// (hashed bag-of-words), and chat completions are scripted.
+// Plug it into httpopenaiclient through Config.WithHTTPClient(server.HTTPClient()),
+// or serve it with httptest.NewServer.
+package openaifake
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/yanakipre/bot/internal/resttooling/roundtripper"
+)
+
+const (
+	embeddingsPath  = "/embeddings"
+	completionsPath = "/chat/completions"
+	// DefaultCompletion is returned when no ScriptedCompletion matches.
+	DefaultCompletion = "fake completion"
+)
+
+// ScriptedCompletion is returned as a completion,
+// when the content of any message in the request contains Contains.
+type ScriptedCompletion struct {
+	Contains string
+	Response string
+}
+
+// Server is a fake OpenAI API server.
+type Server struct {
+	mu          sync.Mutex
+	completions []ScriptedCompletion
+	// dimensions overrides the embedding size derived from the model.
+	dimensions int
+
+	embeddingRequests  []openai.EmbeddingRequestStrings
+	completionRequests []openai.ChatCompletionRequest
+}
+
+type Option func(s *Server)
+
+// WithCompletions sets the scripted completions. The first matching one wins.
+func WithCompletions(completions ...ScriptedCompletion) Option {
+	return func(s *Server) {
+		s.completions = append(s.completions, completions...)
+	}
+}
+
+// WithDimensions overrides the size of returned embeddings.
+func WithDimensions(dimensions int) Option {
+	return func(s *Server) {
+		s.dimensions = dimensions
+	}
+}
+
+func New(opts ...Option) *Server {
+	s := &Server{}
+	for i := range opts {
+		opts[i](s)
+	}
+	return s
+}
+
+// HTTPClient returns a client that serves all the requests with the fake without network.
+func (s *Server) HTTPClient() *http.Client {
+	return &http.Client{Transport: roundtripper.MockRoundTripper(s)}
+}
+
+// EmbeddingRequests returns all embedding requests received so far.
+func (s *Server) EmbeddingRequests() []openai.EmbeddingRequestStrings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]openai.EmbeddingRequestStrings(nil), s.embeddingRequests...)
+}
+
+// CompletionRequests returns all chat completion requests received so far.
+func (s *Server) CompletionRequests() []openai.ChatCompletionRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]openai.ChatCompletionRequest(nil), s.completionRequests...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "only POST is supported")
+		return
+	}
+	switch {
+	case strings.HasSuffix(r.URL.Path, embeddingsPath):
+		s.serveEmbeddings(w, r)
+	case strings.HasSuffix(r.URL.Path, completionsPath):
+		s.serveCompletions(w, r)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown path %q", r.URL.Path))
+	}
+}
+
+// embeddingRequest accepts both a single string and a list of strings as input.
+type embeddingRequest struct {
+	Input json.RawMessage       `json:"input"`
+	Model openai.EmbeddingModel `json:"model"`
+}
+
+func (s *Server) serveEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var req embeddingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("cannot decode request: %v", err))
+		return
+	}
+	var input []string
+	if err := json.Unmarshal(req.Input, &input); err != nil {
+		var single string
+		if err := json.Unmarshal(req.Input, &single); err != nil {
+			writeError(w, http.StatusBadRequest, "input must be a string or a list of strings")
+			return
+		}
+		input = []string{single}
+	}
+
+	s.mu.Lock()
+	s.embeddingRequests = append(s.embeddingRequests, openai.EmbeddingRequestStrings{
+		Input: input,
+		Model: req.Model,
+	})
+	dimensions := s.dimensions
+	s.mu.Unlock()
+	if dimensions == 0 {
+		dimensions = Dimensions(req.Model)
+	}
+
+	resp := openai.EmbeddingResponse{
+		Object: "list",
+		Model:  req.Model,
+		Data:   make([]openai.Embedding, len(input)),
+	}
+	for i := range input {
+		resp.Data[i] = openai.Embedding{
+			Object:    "embedding",
+			Index:     i,
+			Embedding: Embed(input[i], dimensions),
+		}
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) serveCompletions(w http.ResponseWriter, r *http.Request) {
+	var req openai.ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("cannot decode request: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	s.completionRequests = append(s.completionRequests, req)
+	response := s.scriptedResponse(req)
+	n := len(s.completionRequests)
+	s.mu.Unlock()
+
+	id := fmt.Sprintf("chatcmpl-fake-%d", n)
+	if req.Stream {
+		writeStream(w, id, req.Model, response)
+		return
+	}
+	writeJSON(w, openai.ChatCompletionResponse{
+		ID:     id,
+		Object: "chat.completion",
+		Model:  req.Model,
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: response,
+				},
+				FinishReason: openai.FinishReasonStop,
+			},
+		},
+	})
+}
+
+func (s *Server) scriptedResponse(req openai.ChatCompletionRequest) string {
+	for _, c := range s.completions {
+		for _, m := range req.Messages {
+			if strings.Contains(m.Content, c.Contains) {
+				return c.Response
+			}
+		}
+	}
+	return DefaultCompletion
+}
+
+// Dimensions returns the size of embeddings the real API returns for the model.
+func Dimensions(model openai.EmbeddingModel) int {
+	switch model {
+	case openai.LargeEmbedding3:
+		return 3072
+	default:
+		return 1536
+	}
+}
+
+// Embed returns a deterministic L2-normalized hashed bag-of-words embedding of text.
+// Texts sharing words are close to each other, texts without common words are orthogonal.
+func Embed(text string, dimensions int) []float32 {
+	v := make([]float64, dimensions)
+	for _, word := range Words(text) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(word))
+		sum := h.Sum64()
+		v[sum%uint64(dimensions)] += 1
+	}
+	var norm float64
+	for i := range v {
+		norm += v[i] * v[i]
+	}
+	norm = math.Sqrt(norm)
+	result := make([]float32, dimensions)
+	if norm == 0 {
+		return result
+	}
+	for i := range v {
+		result[i] = float32(v[i] / norm)
+	}
+	return result
+}
+
+// Words splits text into lower-cased words.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeStream responds with server-sent events, a word per chunk.
+func writeStream(w http.ResponseWriter, id, model, response string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	for _, word := range strings.SplitAfter(response, " ") {
+		chunk, _ := json.Marshal(openai.ChatCompletionStreamResponse{
+			ID:     id,
+			Object: "chat.completion.chunk",
+			Model:  model,
+			Choices: []openai.ChatCompletionStreamChoice{
+				{Delta: openai.ChatCompletionStreamChoiceDelta{Content: word}},
+			},
+		})
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", chunk)
+	}
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"message": msg,
+			"type":    "invalid_request_error",
+		},
+	})
+}
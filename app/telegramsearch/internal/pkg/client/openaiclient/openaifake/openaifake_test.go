@@ -0,0 +1,139 @@
+package openaifake_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/httpopenaiclient"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaifake"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaimodels"
+	"github.com/yanakipre/bot/internal/testtooling"
+)
+
+func dot(a, b []float32) float32 {
+	var r float32
+	for i := range a {
+		r += a[i] * b[i]
+	}
+	return r
+}
+
+func TestEmbed(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		expect func(t *testing.T, similarity float32)
+	}{
+		{
+			name: "same text is identical",
+			a:    "Best pediatrician in Limassol?",
+			b:    "best PEDIATRICIAN in limassol",
+			expect: func(t *testing.T, similarity float32) {
+				require.InDelta(t, 1, similarity, 1e-5)
+			},
+		},
+		{
+			name: "common words make texts closer",
+			a:    "pediatrician limassol",
+			b:    "pediatrician paphos",
+			expect: func(t *testing.T, similarity float32) {
+				require.Greater(t, similarity, float32(0.1))
+				require.Less(t, similarity, float32(0.9))
+			},
+		},
+		{
+			name: "empty text is a zero vector",
+			a:    "",
+			b:    "anything",
+			expect: func(t *testing.T, similarity float32) {
+				require.Zero(t, similarity)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := openaifake.Embed(tt.a, 1536)
+			b := openaifake.Embed(tt.b, 1536)
+			require.Len(t, a, 1536)
+			require.Equal(t, a, openaifake.Embed(tt.a, 1536), "must be deterministic")
+			tt.expect(t, dot(a, b))
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	testtooling.SetNewGlobalLoggerQuietly()
+	ctx := context.Background()
+
+	fake := openaifake.New(openaifake.WithCompletions(openaifake.ScriptedCompletion{
+		Contains: "pediatrician",
+		Response: "Говорят, что доктор X лучший.",
+	}))
+
+	cfg := httpopenaiclient.DefaultConfig()
+	cfg.EmbeddingConfig.Model = openai.LargeEmbedding3
+	c := httpopenaiclient.NewClient(cfg.WithHTTPClient(fake.HTTPClient()))
+
+	embeddings, err := c.CreateEmbeddings(ctx, openaimodels.ReqCreateEmbeddings{
+		Input: []string{"first", "second"},
+	})
+	require.NoError(t, err)
+	require.Len(t, embeddings.Embeddings, 2)
+	require.Len(t, embeddings.Embeddings[0].Embedding, 3072)
+	require.Equal(t, openaifake.Embed("second", 3072), embeddings.Embeddings[1].Embedding)
+
+	completion, err := c.CreateChatCompletion(ctx, openaimodels.ReqCreateChatCompletion{
+		SystemMessages: []string{"best pediatrician?"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Говорят, что доктор X лучший.", completion.Response)
+
+	completion, err = c.CreateChatCompletion(ctx, openaimodels.ReqCreateChatCompletion{
+		SystemMessages: []string{"something else"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, openaifake.DefaultCompletion, completion.Response)
+
+	require.Len(t, fake.EmbeddingRequests(), 1)
+	require.Len(t, fake.CompletionRequests(), 2)
+}
+
+func TestServer_stream(t *testing.T) {
+	testtooling.SetNewGlobalLoggerQuietly()
+
+	fake := openaifake.New(openaifake.WithCompletions(openaifake.ScriptedCompletion{
+		Contains: "pediatrician",
+		Response: "Говорят, что доктор X лучший.",
+	}))
+	c := httpopenaiclient.NewClient(httpopenaiclient.DefaultConfig().WithHTTPClient(fake.HTTPClient()))
+
+	var deltas []string
+	completion, err := c.CreateChatCompletion(context.Background(), openaimodels.ReqCreateChatCompletion{
+		SystemMessages: []string{"best pediatrician?"},
+		OnDelta:        func(text string) { deltas = append(deltas, text) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Говорят, что доктор X лучший.", completion.Response)
+	require.Equal(t, []string{"Говорят, ", "что ", "доктор ", "X ", "лучший."}, deltas)
+	require.True(t, fake.CompletionRequests()[0].Stream)
+}
+
+func TestServer_httptest(t *testing.T) {
+	testtooling.SetNewGlobalLoggerQuietly()
+
+	srv := httptest.NewServer(openaifake.New(openaifake.WithDimensions(8)))
+	defer srv.Close()
+
+	oaiCfg := openai.DefaultConfig("")
+	oaiCfg.BaseURL = srv.URL + "/v1"
+	resp, err := openai.NewClientWithConfig(oaiCfg).CreateEmbeddings(
+		context.Background(),
+		openai.EmbeddingRequestStrings{Input: []string{"hello"}, Model: openai.SmallEmbedding3},
+	)
+	require.NoError(t, err)
+	require.Len(t, resp.Data[0].Embedding, 8)
+}
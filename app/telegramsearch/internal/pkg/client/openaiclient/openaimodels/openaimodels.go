@@ -3,8 +3,10 @@ package openaimodels
 import "github.com/sashabaranov/go-openai"
 
 type ReqCreateChatCompletion struct {
-	Input         string
-	Conversations []string
+	// SystemMessages are rendered prompts, sent in order before the model responds.
+	SystemMessages []string
+	// OnDelta, when set, streams the completion: it is called with every next part of the response.
+	OnDelta func(text string)
 }
 
 type RespCreateChatCompletion struct {
@@ -0,0 +1,7 @@
+package dbmodels
+
+type CachedAnswer struct {
+	Response string
+	// UsedConversations is a JSON array of storagemodels.RespSimilaritySearch.
+	UsedConversations []byte
+}
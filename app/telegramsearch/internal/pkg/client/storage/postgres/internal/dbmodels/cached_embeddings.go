@@ -0,0 +1,7 @@
+package dbmodels
+
+import "github.com/pgvector/pgvector-go"
+
+type CachedEmbedding struct {
+	Embedding pgvector.Vector
+}
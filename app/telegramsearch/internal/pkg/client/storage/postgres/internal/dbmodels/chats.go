@@ -0,0 +1,20 @@
+package dbmodels
+
+import "time"
+
+type Chat struct {
+	ChatID         string
+	TelegramChatID string
+	Locality       string
+	// Categories is a JSON array, text[] is selected with to_json.
+	Categories []byte
+}
+
+type ChatStats struct {
+	Chat
+	Threads        int
+	Embeddings     int
+	Filtered       int
+	DroppedReplies int
+	LastMessageAt  *time.Time
+}
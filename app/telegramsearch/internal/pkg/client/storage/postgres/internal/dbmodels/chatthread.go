@@ -1,5 +1,7 @@
 package dbmodels
 
+import "time"
+
 type SimilaritySearch struct {
 	Message string
 }
@@ -8,4 +10,15 @@ type ChatThread struct {
 	ThreadID int64 `db:"thread_id"`
 	Body     []byte
 	ChatID   string
+	Locality string
+	// FilteredReason is nil for threads kept by the filter.
+	FilteredReason *string
+}
+
+type FilteredThread struct {
+	ThreadID            int64 `db:"thread_id"`
+	ChatID              string
+	FilteredReason      string
+	Body                []byte
+	MostRecentMessageAt time.Time
 }
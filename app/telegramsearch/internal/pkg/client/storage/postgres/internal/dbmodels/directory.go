@@ -0,0 +1,17 @@
+package dbmodels
+
+import "time"
+
+type DirectoryEntity struct {
+	Name     string
+	Kind     string
+	Category string
+	// Phones, Addresses and ChatIDs are JSON arrays, text[] is selected with to_json.
+	Phones          []byte
+	Addresses       []byte
+	ChatIDs         []byte `db:"chat_ids"`
+	Mentions        int
+	Positive        int
+	Negative        int
+	LastMentionedAt time.Time
+}
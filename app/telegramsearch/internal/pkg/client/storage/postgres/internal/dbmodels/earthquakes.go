@@ -0,0 +1,36 @@
+package dbmodels
+
+import "time"
+
+type Earthquake struct {
+	GUID       string `db:"guid"`
+	Magnitude  float32
+	HappenedAt time.Time
+	Location   string
+	Latitude   float64
+	Longitude  float64
+	SourceRank int
+}
+
+type SavedEarthquake struct {
+	Earthquake
+	// Inserted is false when the stored earthquake is replaced.
+	Inserted bool
+}
+
+type EarthquakeSubscription struct {
+	TelegramUserID int64
+	Tenant         string
+	MinMagnitude   float32
+	RadiusKm       float32 `db:"radius_km"`
+	// Latitude and Longitude are nil until the location is shared.
+	Latitude  *float64
+	Longitude *float64
+}
+
+type EarthquakeAlert struct {
+	Earthquake
+	TelegramUserID int64
+	Tenant         string
+	DistanceKm     float64 `db:"distance_km"`
+}
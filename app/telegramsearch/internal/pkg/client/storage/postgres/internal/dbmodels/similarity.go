@@ -3,8 +3,12 @@ package dbmodels
 import "time"
 
 type PGSimilarity struct {
+	ThreadID            int64 `db:"thread_id"`
+	ChatID              string
 	TelegramChatID      string
 	ConversationStarter string `db:"body"`
 	Message             string
+	// Summary is nil until the thread is summarized.
+	Summary             *string
 	MostRecentMessageAt time.Time
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/jmoiron/sqlx"
+
 	"github.com/yanakipre/bot/internal/encodingtooling"
 	"github.com/yanakipre/bot/internal/rdb"
 )
@@ -38,3 +40,10 @@ func (s *Storage) Ready(ctx context.Context) error {
 func (s *Storage) Close() error {
 	return s.db.Close()
 }
+
+// InTx runs f in a transaction, storage calls with the ctx passed to f are a part of it.
+func (s *Storage) InTx(ctx context.Context, f func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, rdb.TxOptions{F: func(ctx context.Context, _ *sqlx.Tx) error {
+		return f(ctx)
+	}})
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres/internal/dbmodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+
+	"github.com/yanakipre/bot/internal/sqltooling"
+)
+
+var queryFetchCachedAnswer = sqltooling.NewStmt(
+	"FetchCachedAnswer",
+	`
+SELECT response, used_conversations FROM cached_answers
+WHERE
+	tenant = :tenant
+	AND chat_ids = CAST(:chat_ids AS text[])
+	AND prompt_version = :prompt_version
+	AND expires_at > :now
+	AND embedding <-> :emb <= :max_distance
+ORDER BY embedding <-> :emb
+LIMIT 1
+`,
+	dbmodels.CachedAnswer{},
+)
+
+// FetchCachedAnswer returns the fresh answer to the most similar question asked in the same chats.
+func (s *Storage) FetchCachedAnswer(ctx context.Context, req models.ReqFetchCachedAnswer) (models.RespFetchCachedAnswer, error) {
+	rows := []dbmodels.CachedAnswer{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchCachedAnswer.Query, map[string]any{
+		"tenant":         req.Tenant,
+		"chat_ids":       textArray(req.ChatIDs),
+		"prompt_version": req.PromptVersion,
+		"now":            s.now(),
+		"emb":            pgvector.NewVector(req.Embedding[:2000]),
+		"max_distance":   req.MaxDistance,
+	}); err != nil {
+		return models.RespFetchCachedAnswer{}, err
+	}
+	if len(rows) == 0 {
+		return models.RespFetchCachedAnswer{}, nil
+	}
+	resp := models.RespFetchCachedAnswer{Found: true, Response: rows[0].Response}
+	if err := json.Unmarshal(rows[0].UsedConversations, &resp.UsedConversations); err != nil {
+		return models.RespFetchCachedAnswer{}, fmt.Errorf("unmarshal used conversations: %w", err)
+	}
+	return resp, nil
+}
+
+var queryCreateCachedAnswer = sqltooling.NewStmt(
+	"CreateCachedAnswer",
+	`
+WITH expired AS (
+	DELETE FROM cached_answers WHERE expires_at <= :now
+)
+INSERT INTO cached_answers
+	(tenant, chat_ids, prompt_version, embedding, response, used_conversations, created_at, expires_at)
+VALUES (
+	:tenant, CAST(:chat_ids AS text[]), :prompt_version, :emb, :response, CAST(:used_conversations AS JSONB),
+	:now, :expires_at
+);
+`,
+	nil,
+)
+
+// CreateCachedAnswer caches the answer and deletes expired ones.
+func (s *Storage) CreateCachedAnswer(ctx context.Context, req models.ReqCreateCachedAnswer) (models.RespCreateCachedAnswer, error) {
+	usedConversations, err := json.Marshal(req.UsedConversations)
+	if err != nil {
+		return models.RespCreateCachedAnswer{}, err
+	}
+	now := s.now()
+	if _, err := s.db.ExecContext(ctx, queryCreateCachedAnswer.Query, map[string]any{
+		"tenant":             req.Tenant,
+		"chat_ids":           textArray(req.ChatIDs),
+		"prompt_version":     req.PromptVersion,
+		"emb":                pgvector.NewVector(req.Embedding[:2000]),
+		"response":           req.Response,
+		"used_conversations": usedConversations,
+		"now":                now,
+		"expires_at":         now.Add(req.TTL),
+	}); err != nil {
+		return models.RespCreateCachedAnswer{}, err
+	}
+	return models.RespCreateCachedAnswer{}, nil
+}
+
+var queryDeleteCachedAnswers = sqltooling.NewStmt(
+	"DeleteCachedAnswers",
+	`
+DELETE FROM cached_answers
+WHERE
+	:all_chats
+	OR cardinality(chat_ids) = 0
+	OR chat_ids && CAST(:chat_ids AS text[]);
+`,
+	nil,
+)
+
+// DeleteCachedAnswers invalidates answers which could use threads of the changed chats.
+func (s *Storage) DeleteCachedAnswers(ctx context.Context, req models.ReqDeleteCachedAnswers) (models.RespDeleteCachedAnswers, error) {
+	if _, err := s.db.ExecContext(ctx, queryDeleteCachedAnswers.Query, map[string]any{
+		"all_chats": len(req.ChatIDs) == 0,
+		"chat_ids":  textArray(req.ChatIDs),
+	}); err != nil {
+		return models.RespDeleteCachedAnswers{}, err
+	}
+	return models.RespDeleteCachedAnswers{}, nil
+}
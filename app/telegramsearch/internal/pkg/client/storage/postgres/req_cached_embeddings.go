@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres/internal/dbmodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+
+	"github.com/yanakipre/bot/internal/sqltooling"
+)
+
+var queryFetchCachedEmbedding = sqltooling.NewStmt(
+	"FetchCachedEmbedding",
+	`
+SELECT embedding FROM cached_embeddings
+WHERE model = :model AND text_hash = :text_hash AND expires_at > :now
+`,
+	dbmodels.CachedEmbedding{},
+)
+
+func (s *Storage) FetchCachedEmbedding(ctx context.Context, req models.ReqFetchCachedEmbedding) (models.RespFetchCachedEmbedding, error) {
+	rows := []dbmodels.CachedEmbedding{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchCachedEmbedding.Query, map[string]any{
+		"model":     req.Model,
+		"text_hash": req.TextHash,
+		"now":       s.now(),
+	}); err != nil {
+		return models.RespFetchCachedEmbedding{}, err
+	}
+	if len(rows) == 0 {
+		return models.RespFetchCachedEmbedding{}, nil
+	}
+	return models.RespFetchCachedEmbedding{Found: true, Embedding: rows[0].Embedding.Slice()}, nil
+}
+
+var queryCreateCachedEmbedding = sqltooling.NewStmt(
+	"CreateCachedEmbedding",
+	`
+WITH expired AS (
+	DELETE FROM cached_embeddings
+	WHERE expires_at <= :now AND (model, text_hash) <> (:model, :text_hash)
+)
+INSERT INTO cached_embeddings
+	(model, text_hash, embedding, created_at, expires_at)
+VALUES (:model, :text_hash, :embedding, :now, :expires_at)
+ON CONFLICT (model, text_hash) DO UPDATE SET
+	embedding = EXCLUDED.embedding,
+	created_at = EXCLUDED.created_at,
+	expires_at = EXCLUDED.expires_at;
+`,
+	nil,
+)
+
+// CreateCachedEmbedding caches the embedding, replacing an expired one, and deletes other expired ones.
+func (s *Storage) CreateCachedEmbedding(ctx context.Context, req models.ReqCreateCachedEmbedding) (models.RespCreateCachedEmbedding, error) {
+	now := s.now()
+	if _, err := s.db.ExecContext(ctx, queryCreateCachedEmbedding.Query, map[string]any{
+		"model":      req.Model,
+		"text_hash":  req.TextHash,
+		"embedding":  pgvector.NewVector(req.Embedding),
+		"now":        now,
+		"expires_at": now.Add(req.TTL),
+	}); err != nil {
+		return models.RespCreateCachedEmbedding{}, err
+	}
+	return models.RespCreateCachedEmbedding{}, nil
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres/internal/dbmodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+
+	"github.com/yanakipre/bot/internal/semerr"
+	"github.com/yanakipre/bot/internal/sqltooling"
+)
+
+var queryFetchChats = sqltooling.NewStmt(
+	"FetchChats",
+	`
+SELECT chat_id, telegram_chat_id, locality, to_json(categories) AS categories FROM chats ORDER BY chat_id;
+`,
+	dbmodels.Chat{},
+)
+
+func (s *Storage) FetchChats(ctx context.Context, _ models.ReqFetchChats) (models.RespFetchChats, error) {
+	rows := []dbmodels.Chat{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchChats.Query, map[string]any{}); err != nil {
+		return models.RespFetchChats{}, err
+	}
+	resp := models.RespFetchChats{Chats: make([]models.Chat, 0, len(rows))}
+	for _, row := range rows {
+		chat, err := chatFromRow(row)
+		if err != nil {
+			return models.RespFetchChats{}, err
+		}
+		resp.Chats = append(resp.Chats, chat)
+	}
+	return resp, nil
+}
+
+var querySetChatCategories = sqltooling.NewStmt(
+	"SetChatCategories",
+	`
+UPDATE chats SET categories = :categories WHERE chat_id = :chat_id;
+`,
+	nil,
+)
+
+func (s *Storage) SetChatCategories(ctx context.Context, req models.ReqSetChatCategories) (models.RespSetChatCategories, error) {
+	categories := req.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	res, err := s.db.ExecContext(ctx, querySetChatCategories.Query, map[string]any{
+		"chat_id":    req.ChatID,
+		"categories": categories,
+	})
+	if err != nil {
+		return models.RespSetChatCategories{}, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.RespSetChatCategories{}, err
+	}
+	if affected == 0 {
+		return models.RespSetChatCategories{}, semerr.NotFound(fmt.Sprintf("chat %q not found", req.ChatID))
+	}
+	return models.RespSetChatCategories{}, nil
+}
+
+var queryFetchUserCategories = sqltooling.NewStmt(
+	"FetchUserCategories",
+	`
+SELECT category FROM user_chat_categories WHERE user_id = :user_id AND tenant = :tenant ORDER BY category;
+`,
+	nil,
+)
+
+func (s *Storage) FetchUserCategories(ctx context.Context, req models.ReqFetchUserCategories) (models.RespFetchUserCategories, error) {
+	categories := []string{}
+	if err := s.db.SelectContext(ctx, &categories, queryFetchUserCategories.Query, map[string]any{
+		"user_id": req.UserID,
+		"tenant":  req.Tenant,
+	}); err != nil {
+		return models.RespFetchUserCategories{}, err
+	}
+	return models.RespFetchUserCategories{Categories: categories}, nil
+}
+
+var queryToggleUserCategory = sqltooling.NewStmt(
+	"ToggleUserCategory",
+	`
+WITH deleted AS (
+	DELETE FROM user_chat_categories
+	WHERE user_id = :user_id AND tenant = :tenant AND category = :category
+	RETURNING 1
+)
+INSERT INTO user_chat_categories (user_id, tenant, category)
+SELECT :user_id, :tenant, :category
+WHERE NOT EXISTS (SELECT 1 FROM deleted);
+`,
+	nil,
+)
+
+func (s *Storage) ToggleUserCategory(ctx context.Context, req models.ReqToggleUserCategory) (models.RespToggleUserCategory, error) {
+	res, err := s.db.ExecContext(ctx, queryToggleUserCategory.Query, map[string]any{
+		"user_id":  req.UserID,
+		"tenant":   req.Tenant,
+		"category": req.Category,
+	})
+	if err != nil {
+		return models.RespToggleUserCategory{}, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return models.RespToggleUserCategory{}, err
+	}
+	return models.RespToggleUserCategory{Enabled: inserted == 1}, nil
+}
@@ -2,28 +2,223 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/samber/lo"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres/internal/dbmodels"
 	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
 
+	"github.com/yanakipre/bot/internal/semerr"
 	"github.com/yanakipre/bot/internal/sqltooling"
 )
 
 var queryCreateChat = sqltooling.NewStmt(
 	"CreateChat",
 	`
-INSERT INTO chats (chat_id)
+INSERT INTO chats (chat_id, telegram_chat_id, locality)
 VALUES (
-        :chat_id
-);
+        :chat_id, :telegram_chat_id, :locality
+)
+ON CONFLICT DO NOTHING;
 `,
 	nil,
 )
 
 func (s *Storage) CreateChat(ctx context.Context, req models.ReqCreateChat) (models.RespCreateChat, error) {
-	_, err := s.db.ExecContext(ctx, queryCreateChat.Query, map[string]any{
-		"chat_id": req.ChatID,
+	telegramChatID := req.TelegramChatID
+	if telegramChatID == "" {
+		telegramChatID = "empty"
+	}
+	res, err := s.db.ExecContext(ctx, queryCreateChat.Query, map[string]any{
+		"chat_id":          req.ChatID,
+		"telegram_chat_id": telegramChatID,
+		"locality":         req.Locality,
 	})
 	if err != nil {
 		return models.RespCreateChat{}, err
 	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.RespCreateChat{}, err
+	}
+	if affected == 0 {
+		return models.RespCreateChat{}, semerr.AlreadyExists(fmt.Sprintf("chat %q already exists", req.ChatID))
+	}
 	return models.RespCreateChat{}, nil
 }
+
+var queryUpdateChat = sqltooling.NewStmt(
+	"UpdateChat",
+	`
+UPDATE chats SET
+	chat_id = COALESCE(CAST(:new_chat_id AS text), chat_id),
+	telegram_chat_id = COALESCE(CAST(:telegram_chat_id AS text), telegram_chat_id),
+	locality = COALESCE(CAST(:locality AS text), locality)
+WHERE chat_id = :chat_id;
+`,
+	nil,
+)
+
+func (s *Storage) UpdateChat(ctx context.Context, req models.ReqUpdateChat) (models.RespUpdateChat, error) {
+	res, err := s.db.ExecContext(ctx, queryUpdateChat.Query, map[string]any{
+		"chat_id":          req.ChatID,
+		"new_chat_id":      req.NewChatID,
+		"telegram_chat_id": req.TelegramChatID,
+		"locality":         req.Locality,
+	})
+	if pgErr := (*pgconn.PgError)(nil); errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return models.RespUpdateChat{}, semerr.AlreadyExists(fmt.Sprintf("chat %q already exists", *req.NewChatID))
+	}
+	if err != nil {
+		return models.RespUpdateChat{}, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.RespUpdateChat{}, err
+	}
+	if affected == 0 {
+		return models.RespUpdateChat{}, semerr.NotFound(fmt.Sprintf("chat %q not found", req.ChatID))
+	}
+	return models.RespUpdateChat{}, nil
+}
+
+var queryDeleteChat = sqltooling.NewStmt(
+	"DeleteChat",
+	`
+DELETE FROM chats WHERE chat_id = :chat_id;
+`,
+	nil,
+)
+
+// DeleteChat deletes the chat with its threads and embeddings.
+func (s *Storage) DeleteChat(ctx context.Context, req models.ReqDeleteChat) (models.RespDeleteChat, error) {
+	res, err := s.db.ExecContext(ctx, queryDeleteChat.Query, map[string]any{
+		"chat_id": req.ChatID,
+	})
+	if err != nil {
+		return models.RespDeleteChat{}, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.RespDeleteChat{}, err
+	}
+	if affected == 0 {
+		return models.RespDeleteChat{}, semerr.NotFound(fmt.Sprintf("chat %q not found", req.ChatID))
+	}
+	return models.RespDeleteChat{}, nil
+}
+
+var queryFetchEmbeddedChatThreads = sqltooling.NewStmt(
+	"FetchEmbeddedChatThreads",
+	`
+SELECT t.thread_id, t.body, t.chat_id, c.locality
+FROM embeddings e
+	JOIN chatthreads t ON e.thread_id = t.thread_id
+	JOIN chats c ON t.chat_id = c.chat_id
+WHERE e.chat_id = :chat_id
+ORDER BY t.thread_id;
+`,
+	dbmodels.ChatThread{},
+)
+
+// FetchEmbeddedChatThreads returns threads of the chat that have embeddings.
+func (s *Storage) FetchEmbeddedChatThreads(ctx context.Context, req models.ReqFetchEmbeddedChatThreads) (models.RespFetchEmbeddedChatThreads, error) {
+	rows := []dbmodels.ChatThread{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchEmbeddedChatThreads.Query, map[string]any{
+		"chat_id": req.ChatID,
+	}); err != nil {
+		return models.RespFetchEmbeddedChatThreads{}, err
+	}
+	return models.RespFetchEmbeddedChatThreads{
+		Threads: lo.Map(rows, func(item dbmodels.ChatThread, _ int) models.ChatThreadToGenerateEmbedding {
+			return models.ChatThreadToGenerateEmbedding{
+				ChatID:   item.ChatID,
+				Locality: item.Locality,
+				ThreadID: item.ThreadID,
+				Body:     item.Body,
+			}
+		}),
+	}, nil
+}
+
+var queryUpdateEmbeddingMessage = sqltooling.NewStmt(
+	"UpdateEmbeddingMessage",
+	`
+UPDATE embeddings SET message = :message WHERE thread_id = :thread_id;
+`,
+	nil,
+)
+
+// UpdateEmbeddingMessage replaces the message shown to the user, the embedding and the summary are kept.
+func (s *Storage) UpdateEmbeddingMessage(ctx context.Context, req models.ReqUpdateEmbeddingMessage) (models.RespUpdateEmbeddingMessage, error) {
+	if _, err := s.db.ExecContext(ctx, queryUpdateEmbeddingMessage.Query, map[string]any{
+		"thread_id": req.ThreadID,
+		"message":   req.Message,
+	}); err != nil {
+		return models.RespUpdateEmbeddingMessage{}, err
+	}
+	return models.RespUpdateEmbeddingMessage{}, nil
+}
+
+var queryFetchChatStats = sqltooling.NewStmt(
+	"FetchChatStats",
+	`
+SELECT
+	c.chat_id,
+	c.telegram_chat_id,
+	c.locality,
+	to_json(c.categories) AS categories,
+	(SELECT count(*) FROM chatthreads t WHERE t.chat_id = c.chat_id) AS threads,
+	(SELECT count(*) FROM embeddings e WHERE e.chat_id = c.chat_id) AS embeddings,
+	(SELECT count(*) FROM chatthreads t WHERE t.chat_id = c.chat_id AND t.filtered_reason IS NOT NULL) AS filtered,
+	(
+		SELECT COALESCE(sum(CAST(r.value AS int)), 0)
+		FROM chatthreads t, jsonb_each_text(t.dropped_replies) r
+		WHERE t.chat_id = c.chat_id
+	) AS dropped_replies,
+	(SELECT max(t.most_recent_message_at) FROM chatthreads t WHERE t.chat_id = c.chat_id) AS last_message_at
+FROM chats c
+ORDER BY c.chat_id;
+`,
+	dbmodels.ChatStats{},
+)
+
+func (s *Storage) FetchChatStats(ctx context.Context, _ models.ReqFetchChatStats) (models.RespFetchChatStats, error) {
+	rows := []dbmodels.ChatStats{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchChatStats.Query, map[string]any{}); err != nil {
+		return models.RespFetchChatStats{}, err
+	}
+	resp := models.RespFetchChatStats{Chats: make([]models.ChatStats, 0, len(rows))}
+	for _, row := range rows {
+		chat, err := chatFromRow(row.Chat)
+		if err != nil {
+			return models.RespFetchChatStats{}, err
+		}
+		resp.Chats = append(resp.Chats, models.ChatStats{
+			Chat:           chat,
+			Threads:        row.Threads,
+			Embeddings:     row.Embeddings,
+			Filtered:       row.Filtered,
+			DroppedReplies: row.DroppedReplies,
+			LastMessageAt:  row.LastMessageAt,
+		})
+	}
+	return resp, nil
+}
+
+func chatFromRow(row dbmodels.Chat) (models.Chat, error) {
+	chat := models.Chat{
+		ChatID:         models.ChatID(row.ChatID),
+		TelegramChatID: row.TelegramChatID,
+		Locality:       row.Locality,
+	}
+	if err := json.Unmarshal(row.Categories, &chat.Categories); err != nil {
+		return models.Chat{}, fmt.Errorf("cannot parse categories of chat %q: %w", row.ChatID, err)
+	}
+	return chat, nil
+}
@@ -13,7 +13,9 @@ import (
 var queryFetchChatThreadToGenerateEmbedding = sqltooling.NewStmt(
 	"FetchChatThreadToGenerateEmbedding",
 	`
-SELECT * FROM chatthreads WHERE thread_id NOT IN (SELECT thread_id FROM embeddings) LIMIT 2000;
+SELECT t.thread_id, t.body, t.chat_id, c.locality
+FROM chatthreads t JOIN chats c ON (c.chat_id = t.chat_id)
+WHERE t.filtered_reason IS NULL AND t.thread_id NOT IN (SELECT thread_id FROM embeddings) LIMIT 2000;
 `,
 	dbmodels.ChatThread{},
 )
@@ -27,6 +29,7 @@ func (s *Storage) FetchChatThreadToGenerateEmbedding(ctx context.Context, req mo
 		Threads: lo.Map(rows, func(item dbmodels.ChatThread, _ int) models.ChatThreadToGenerateEmbedding {
 			return models.ChatThreadToGenerateEmbedding{
 				ChatID:   item.ChatID,
+				Locality: item.Locality,
 				ThreadID: item.ThreadID,
 				Body:     item.Body,
 			}
@@ -37,11 +40,18 @@ func (s *Storage) FetchChatThreadToGenerateEmbedding(ctx context.Context, req mo
 var queryCreateChatThread = sqltooling.NewStmt(
 	"CreateChatThread",
 	`
-INSERT INTO chatthreads
-	(chat_id, body)
-VALUES (
-        :chat_id, CAST(:body as JSONB)
-);
+WITH t AS (
+	INSERT INTO chatthreads
+		(chat_id, body, most_recent_message_at, filtered_reason, dropped_replies)
+	VALUES (
+	        :chat_id, CAST(:body as JSONB), :most_recent_message_at, NULLIF(:filtered_reason, ''),
+	        CAST(:dropped_replies AS JSONB)
+	)
+	RETURNING thread_id
+)
+INSERT INTO chatthread_authors (thread_id, author_key)
+SELECT t.thread_id, a.author_key FROM t, unnest(CAST(:author_keys AS text[])) a(author_key)
+ON CONFLICT DO NOTHING;
 `,
 	nil,
 )
@@ -51,13 +61,67 @@ func (s *Storage) CreateChatThread(ctx context.Context, req models.ReqCreateChat
 	if err != nil {
 		return models.RespCreateChatThread{}, err
 	}
+	// NULL when no replies were dropped.
+	var droppedReplies *string
+	if len(req.DroppedReplies) > 0 {
+		b, err := json.Marshal(req.DroppedReplies)
+		if err != nil {
+			return models.RespCreateChatThread{}, err
+		}
+		droppedReplies = lo.ToPtr(string(b))
+	}
 
 	_, err = s.db.ExecContext(ctx, queryCreateChatThread.Query, map[string]any{
-		"chat_id": req.ChatID,
-		"body":    marshal,
+		"chat_id":                req.ChatID,
+		"body":                   marshal,
+		"most_recent_message_at": req.MostRecentMessageAt,
+		"author_keys":            textArray(req.AuthorKeys),
+		"filtered_reason":        req.FilteredReason,
+		"dropped_replies":        droppedReplies,
 	})
 	if err != nil {
 		return models.RespCreateChatThread{}, err
 	}
 	return models.RespCreateChatThread{}, nil
 }
+
+// textArray never returns nil, so that text[] parameters are empty arrays instead of NULL.
+func textArray(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+var queryFetchFilteredThreads = sqltooling.NewStmt(
+	"FetchFilteredThreads",
+	`
+SELECT thread_id, chat_id, filtered_reason, body, most_recent_message_at FROM chatthreads
+WHERE chat_id = :chat_id AND filtered_reason IS NOT NULL
+ORDER BY most_recent_message_at DESC, thread_id DESC
+LIMIT :limit;
+`,
+	dbmodels.FilteredThread{},
+)
+
+// FetchFilteredThreads returns the most recent threads of the chat the filter dropped.
+func (s *Storage) FetchFilteredThreads(ctx context.Context, req models.ReqFetchFilteredThreads) (models.RespFetchFilteredThreads, error) {
+	rows := []dbmodels.FilteredThread{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchFilteredThreads.Query, map[string]any{
+		"chat_id": req.ChatID,
+		"limit":   req.Limit,
+	}); err != nil {
+		return models.RespFetchFilteredThreads{}, err
+	}
+	return models.RespFetchFilteredThreads{
+		Threads: lo.Map(rows, func(item dbmodels.FilteredThread, _ int) models.FilteredThread {
+			return models.FilteredThread{
+				ThreadID:            item.ThreadID,
+				ChatID:              item.ChatID,
+				FilteredReason:      item.FilteredReason,
+				Body:                item.Body,
+				MostRecentMessageAt: item.MostRecentMessageAt,
+			}
+		}),
+	}, nil
+}
@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres/internal/dbmodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	"github.com/yanakipre/bot/internal/sqltooling"
+)
+
+var queryFetchThreadsToExtractEntities = sqltooling.NewStmt(
+	"FetchThreadsToExtractEntities",
+	`
+SELECT t.thread_id, t.body, t.chat_id, c.locality
+FROM chatthreads t JOIN chats c ON (c.chat_id = t.chat_id)
+WHERE t.entities_extracted_at IS NULL AND t.filtered_reason IS NULL
+ORDER BY t.thread_id
+LIMIT :limit;
+`,
+	dbmodels.ChatThread{},
+)
+
+// FetchThreadsToExtractEntities returns threads entities were not extracted from yet.
+func (s *Storage) FetchThreadsToExtractEntities(ctx context.Context, req models.ReqFetchThreadsToExtractEntities) (models.RespFetchThreadsToExtractEntities, error) {
+	rows := []dbmodels.ChatThread{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchThreadsToExtractEntities.Query, map[string]any{
+		"limit": req.Limit,
+	}); err != nil {
+		return models.RespFetchThreadsToExtractEntities{}, err
+	}
+	resp := models.RespFetchThreadsToExtractEntities{Threads: make([]models.ChatThreadToGenerateEmbedding, len(rows))}
+	for i, item := range rows {
+		resp.Threads[i] = models.ChatThreadToGenerateEmbedding{
+			ChatID:   item.ChatID,
+			Locality: item.Locality,
+			ThreadID: item.ThreadID,
+			Body:     item.Body,
+		}
+	}
+	return resp, nil
+}
+
+// Entities of the thread are matched to existing ones by category and normalized name.
+// Mentions of entities not in the thread anymore are deleted.
+// Contacts are kept with mentions, so contacts of deleted and rewritten threads are gone with them.
+var querySaveThreadEntities = sqltooling.NewStmt(
+	"SaveThreadEntities",
+	`
+WITH input AS (
+	SELECT * FROM jsonb_to_recordset(CAST(:entities AS JSONB)) AS x(
+		kind text, category text, name text, normalized_name text, phones jsonb, addresses jsonb, sentiment smallint
+	)
+), upserted AS (
+	INSERT INTO entities (chat_id, kind, category, name, normalized_name)
+	SELECT :chat_id, kind, category, name, normalized_name
+	FROM input
+	ON CONFLICT (chat_id, category, normalized_name) DO UPDATE
+		SET
+			kind = EXCLUDED.kind,
+			name = EXCLUDED.name
+	RETURNING entity_id, category, normalized_name
+), mentioned AS (
+	INSERT INTO entity_mentions (entity_id, thread_id, sentiment, mentioned_at, phones, addresses)
+	SELECT
+		u.entity_id, t.thread_id, i.sentiment, t.most_recent_message_at,
+		ARRAY(SELECT jsonb_array_elements_text(i.phones)),
+		ARRAY(SELECT jsonb_array_elements_text(i.addresses))
+	FROM upserted u
+		JOIN input i ON (i.category = u.category AND i.normalized_name = u.normalized_name)
+		JOIN chatthreads t ON (t.thread_id = :thread_id)
+	ON CONFLICT (entity_id, thread_id) DO UPDATE
+		SET
+			sentiment = EXCLUDED.sentiment,
+			mentioned_at = EXCLUDED.mentioned_at,
+			phones = EXCLUDED.phones,
+			addresses = EXCLUDED.addresses
+), unmentioned AS (
+	DELETE FROM entity_mentions
+	WHERE thread_id = :thread_id AND entity_id NOT IN (SELECT entity_id FROM upserted)
+)
+UPDATE chatthreads SET entities_extracted_at = :now WHERE thread_id = :thread_id;
+`,
+	nil,
+)
+
+// SaveThreadEntities replaces mentions of entities in the thread, and marks the thread processed.
+func (s *Storage) SaveThreadEntities(ctx context.Context, req models.ReqSaveThreadEntities) (models.RespSaveThreadEntities, error) {
+	entities := make([]map[string]any, len(req.Entities))
+	for i, e := range req.Entities {
+		entities[i] = map[string]any{
+			"kind":            e.Kind,
+			"category":        e.Category,
+			"name":            e.Name,
+			"normalized_name": e.NormalizedName,
+			"phones":          textArray(e.Phones),
+			"addresses":       textArray(e.Addresses),
+			"sentiment":       e.Sentiment,
+		}
+	}
+	marshal, err := json.Marshal(entities)
+	if err != nil {
+		return models.RespSaveThreadEntities{}, err
+	}
+	if _, err := s.db.ExecContext(ctx, querySaveThreadEntities.Query, map[string]any{
+		"thread_id": req.ThreadID,
+		"chat_id":   req.ChatID,
+		"entities":  marshal,
+		"now":       s.now(),
+	}); err != nil {
+		return models.RespSaveThreadEntities{}, err
+	}
+	return models.RespSaveThreadEntities{}, nil
+}
+
+// Entities with the same normalized name in different chats are the same entity.
+// The most recommended entities go first, then the most mentioned, then the most recently mentioned.
+var queryFetchTopEntities = sqltooling.NewStmt(
+	"FetchTopEntities",
+	`
+WITH matched AS (
+	SELECT e.entity_id, e.normalized_name
+	FROM entities e JOIN chats c ON (c.chat_id = e.chat_id)
+	WHERE
+		strpos(e.category, :category) > 0
+		AND (:all_chats OR e.chat_id = ANY(:chat_ids))
+		AND (:locality = '' OR lower(c.locality) = lower(:locality))
+), ranked AS (
+	SELECT
+		x.normalized_name,
+		array_agg(DISTINCT x.entity_id) AS entity_ids,
+		count(DISTINCT m.thread_id) AS mentions,
+		count(DISTINCT m.thread_id) FILTER (WHERE m.sentiment > 0) AS positive,
+		count(DISTINCT m.thread_id) FILTER (WHERE m.sentiment < 0) AS negative,
+		max(m.mentioned_at) AS last_mentioned_at
+	FROM matched x JOIN entity_mentions m ON (m.entity_id = x.entity_id)
+	GROUP BY x.normalized_name
+)
+SELECT
+	(SELECT e.name FROM entities e WHERE e.entity_id = ANY(r.entity_ids) ORDER BY e.entity_id DESC LIMIT 1) AS name,
+	(SELECT e.kind FROM entities e WHERE e.entity_id = ANY(r.entity_ids) ORDER BY e.entity_id DESC LIMIT 1) AS kind,
+	(SELECT e.category FROM entities e WHERE e.entity_id = ANY(r.entity_ids) ORDER BY e.entity_id DESC LIMIT 1) AS category,
+	to_json(ARRAY(
+		SELECT DISTINCT p FROM entity_mentions m, unnest(m.phones) p WHERE m.entity_id = ANY(r.entity_ids) ORDER BY p
+	)) AS phones,
+	to_json(ARRAY(
+		SELECT DISTINCT a FROM entity_mentions m, unnest(m.addresses) a WHERE m.entity_id = ANY(r.entity_ids) ORDER BY a
+	)) AS addresses,
+	to_json(ARRAY(
+		SELECT DISTINCT e.chat_id FROM entities e WHERE e.entity_id = ANY(r.entity_ids) ORDER BY e.chat_id
+	)) AS chat_ids,
+	r.mentions, r.positive, r.negative, r.last_mentioned_at
+FROM ranked r
+ORDER BY r.positive - r.negative DESC, r.mentions DESC, r.last_mentioned_at DESC, r.normalized_name
+LIMIT :limit;
+`,
+	dbmodels.DirectoryEntity{},
+)
+
+// FetchTopEntities ranks entities of the category by recommendations.
+func (s *Storage) FetchTopEntities(ctx context.Context, req models.ReqFetchTopEntities) (models.RespFetchTopEntities, error) {
+	rows := []dbmodels.DirectoryEntity{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchTopEntities.Query, map[string]any{
+		"category":  req.Category,
+		"locality":  req.Locality,
+		"all_chats": len(req.ChatIDs) == 0,
+		"chat_ids":  textArray(req.ChatIDs),
+		"limit":     req.Limit,
+	}); err != nil {
+		return models.RespFetchTopEntities{}, err
+	}
+	resp := models.RespFetchTopEntities{Entities: make([]models.DirectoryEntity, len(rows))}
+	for i, row := range rows {
+		e := models.DirectoryEntity{
+			Name:            row.Name,
+			Kind:            row.Kind,
+			Category:        row.Category,
+			Mentions:        row.Mentions,
+			Positive:        row.Positive,
+			Negative:        row.Negative,
+			LastMentionedAt: row.LastMentionedAt,
+		}
+		for _, arr := range []struct {
+			raw []byte
+			dst *[]string
+		}{
+			{row.Phones, &e.Phones},
+			{row.Addresses, &e.Addresses},
+			{row.ChatIDs, &e.ChatIDs},
+		} {
+			if err := json.Unmarshal(arr.raw, arr.dst); err != nil {
+				return models.RespFetchTopEntities{}, fmt.Errorf("unmarshal entity %q: %w", row.Name, err)
+			}
+		}
+		resp.Entities[i] = e
+	}
+	return resp, nil
+}
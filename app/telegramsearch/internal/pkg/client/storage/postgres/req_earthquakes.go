@@ -0,0 +1,386 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres/internal/dbmodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	"github.com/yanakipre/bot/internal/semerr"
+	"github.com/yanakipre/bot/internal/sqltooling"
+)
+
+// An earthquake is replaced when a more authoritative source reports it,
+// its subscribers are looked for again, see PlanEarthquakeAlerts.
+var querySaveEarthquakes = sqltooling.NewStmt(
+	"SaveEarthquakes",
+	`
+INSERT INTO earthquakes (guid, magnitude, happened_at, location, latitude, longitude, source_rank, created_at)
+SELECT guid, magnitude, happened_at, location, latitude, longitude, source_rank, :now
+FROM jsonb_to_recordset(CAST(:earthquakes AS JSONB)) AS x(
+	guid text, magnitude real, happened_at timestamptz, location text, latitude double precision, longitude double precision,
+	source_rank smallint
+)
+ON CONFLICT (guid) DO UPDATE
+	SET
+		magnitude = EXCLUDED.magnitude,
+		happened_at = EXCLUDED.happened_at,
+		location = EXCLUDED.location,
+		latitude = EXCLUDED.latitude,
+		longitude = EXCLUDED.longitude,
+		source_rank = EXCLUDED.source_rank,
+		alerts_planned_at = NULL
+	WHERE EXCLUDED.source_rank < earthquakes.source_rank
+RETURNING guid, magnitude, happened_at, location, latitude, longitude, source_rank, (xmax = 0) AS inserted;
+`,
+	dbmodels.SavedEarthquake{},
+)
+
+// SaveEarthquakes stores earthquakes not seen before and replaces ones reported by a less authoritative source.
+func (s *Storage) SaveEarthquakes(ctx context.Context, req models.ReqSaveEarthquakes) (models.RespSaveEarthquakes, error) {
+	earthquakes := make([]map[string]any, len(req.Earthquakes))
+	for i, e := range req.Earthquakes {
+		earthquakes[i] = map[string]any{
+			"guid":        e.GUID,
+			"magnitude":   e.Magnitude,
+			"happened_at": e.HappenedAt,
+			"location":    e.Location,
+			"latitude":    e.Latitude,
+			"longitude":   e.Longitude,
+			"source_rank": e.SourceRank,
+		}
+	}
+	marshal, err := json.Marshal(earthquakes)
+	if err != nil {
+		return models.RespSaveEarthquakes{}, err
+	}
+	rows := []dbmodels.SavedEarthquake{}
+	if err := s.db.SelectContext(ctx, &rows, querySaveEarthquakes.Query, map[string]any{
+		"earthquakes": marshal,
+		"now":         s.now(),
+	}); err != nil {
+		return models.RespSaveEarthquakes{}, err
+	}
+	var resp models.RespSaveEarthquakes
+	for _, row := range rows {
+		e := toEarthquakes([]dbmodels.Earthquake{row.Earthquake})[0]
+		if row.Inserted {
+			resp.New = append(resp.New, e)
+		} else {
+			resp.Replaced = append(resp.Replaced, e)
+		}
+	}
+	return resp, nil
+}
+
+var queryFetchEarthquakesSince = sqltooling.NewStmt(
+	"FetchEarthquakesSince",
+	`
+SELECT guid, magnitude, happened_at, location, latitude, longitude, source_rank
+FROM earthquakes
+WHERE happened_at > :since
+ORDER BY happened_at;
+`,
+	dbmodels.Earthquake{},
+)
+
+// FetchEarthquakesSince returns earthquakes happened after Since, the oldest first.
+func (s *Storage) FetchEarthquakesSince(ctx context.Context, req models.ReqFetchEarthquakesSince) (models.RespFetchEarthquakesSince, error) {
+	rows := []dbmodels.Earthquake{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchEarthquakesSince.Query, map[string]any{
+		"since": req.Since,
+	}); err != nil {
+		return models.RespFetchEarthquakesSince{}, err
+	}
+	return models.RespFetchEarthquakesSince{Earthquakes: toEarthquakes(rows)}, nil
+}
+
+var queryFetchEarthquakesToAlert = sqltooling.NewStmt(
+	"FetchEarthquakesToAlert",
+	`
+SELECT guid, magnitude, happened_at, location, latitude, longitude
+FROM earthquakes
+WHERE alerts_planned_at IS NULL AND happened_at > :since
+ORDER BY happened_at;
+`,
+	dbmodels.Earthquake{},
+)
+
+// FetchEarthquakesToAlert returns earthquakes subscribers were not found for yet.
+func (s *Storage) FetchEarthquakesToAlert(ctx context.Context, req models.ReqFetchEarthquakesToAlert) (models.RespFetchEarthquakesToAlert, error) {
+	rows := []dbmodels.Earthquake{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchEarthquakesToAlert.Query, map[string]any{
+		"since": req.Since,
+	}); err != nil {
+		return models.RespFetchEarthquakesToAlert{}, err
+	}
+	return models.RespFetchEarthquakesToAlert{Earthquakes: toEarthquakes(rows)}, nil
+}
+
+var queryPlanEarthquakeAlerts = sqltooling.NewStmt(
+	"PlanEarthquakeAlerts",
+	`
+WITH planned AS (
+	INSERT INTO earthquake_alerts (guid, telegram_user_id, tenant, distance_km, created_at)
+	SELECT :guid, telegram_user_id, tenant, distance_km, :now
+	FROM jsonb_to_recordset(CAST(:alerts AS JSONB)) AS x(telegram_user_id bigint, tenant text, distance_km double precision)
+	ON CONFLICT DO NOTHING
+)
+UPDATE earthquakes SET alerts_planned_at = :now WHERE guid = :guid;
+`,
+	nil,
+)
+
+// PlanEarthquakeAlerts stores alerts to send about the earthquake, it is not returned by FetchEarthquakesToAlert then.
+func (s *Storage) PlanEarthquakeAlerts(ctx context.Context, req models.ReqPlanEarthquakeAlerts) (models.RespPlanEarthquakeAlerts, error) {
+	alerts := make([]map[string]any, len(req.Alerts))
+	for i, a := range req.Alerts {
+		alerts[i] = map[string]any{
+			"telegram_user_id": a.TelegramUserID,
+			"tenant":           a.Tenant,
+			"distance_km":      a.DistanceKm,
+		}
+	}
+	marshal, err := json.Marshal(alerts)
+	if err != nil {
+		return models.RespPlanEarthquakeAlerts{}, err
+	}
+	if _, err := s.db.ExecContext(ctx, queryPlanEarthquakeAlerts.Query, map[string]any{
+		"guid":   req.GUID,
+		"alerts": marshal,
+		"now":    s.now(),
+	}); err != nil {
+		return models.RespPlanEarthquakeAlerts{}, err
+	}
+	return models.RespPlanEarthquakeAlerts{}, nil
+}
+
+var queryFetchUnsentEarthquakeAlerts = sqltooling.NewStmt(
+	"FetchUnsentEarthquakeAlerts",
+	`
+SELECT
+	e.guid, e.magnitude, e.happened_at, e.location, e.latitude, e.longitude,
+	a.telegram_user_id, a.tenant, a.distance_km
+FROM earthquake_alerts a JOIN earthquakes e ON (e.guid = a.guid)
+WHERE a.sent_at IS NULL AND e.happened_at > :since
+ORDER BY e.happened_at, a.telegram_user_id, a.tenant;
+`,
+	dbmodels.EarthquakeAlert{},
+)
+
+// FetchUnsentEarthquakeAlerts returns alerts not sent yet, the oldest earthquakes first.
+func (s *Storage) FetchUnsentEarthquakeAlerts(ctx context.Context, req models.ReqFetchUnsentEarthquakeAlerts) (models.RespFetchUnsentEarthquakeAlerts, error) {
+	rows := []dbmodels.EarthquakeAlert{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchUnsentEarthquakeAlerts.Query, map[string]any{
+		"since": req.Since,
+	}); err != nil {
+		return models.RespFetchUnsentEarthquakeAlerts{}, err
+	}
+	resp := models.RespFetchUnsentEarthquakeAlerts{Alerts: make([]models.EarthquakeAlert, len(rows))}
+	for i, row := range rows {
+		resp.Alerts[i] = models.EarthquakeAlert{
+			PlannedEarthquakeAlert: models.PlannedEarthquakeAlert{
+				TelegramUserID: row.TelegramUserID,
+				Tenant:         row.Tenant,
+				DistanceKm:     row.DistanceKm,
+			},
+			Earthquake: toEarthquakes([]dbmodels.Earthquake{row.Earthquake})[0],
+		}
+	}
+	return resp, nil
+}
+
+var queryMarkEarthquakeAlertSent = sqltooling.NewStmt(
+	"MarkEarthquakeAlertSent",
+	`
+UPDATE earthquake_alerts SET sent_at = :now
+WHERE guid = :guid AND telegram_user_id = :telegram_user_id AND tenant = :tenant;
+`,
+	nil,
+)
+
+func (s *Storage) MarkEarthquakeAlertSent(ctx context.Context, req models.ReqMarkEarthquakeAlertSent) (models.RespMarkEarthquakeAlertSent, error) {
+	if _, err := s.db.ExecContext(ctx, queryMarkEarthquakeAlertSent.Query, map[string]any{
+		"guid":             req.GUID,
+		"telegram_user_id": req.TelegramUserID,
+		"tenant":           req.Tenant,
+		"now":              s.now(),
+	}); err != nil {
+		return models.RespMarkEarthquakeAlertSent{}, err
+	}
+	return models.RespMarkEarthquakeAlertSent{}, nil
+}
+
+var queryFetchRecentEarthquakes = sqltooling.NewStmt(
+	"FetchRecentEarthquakes",
+	`
+SELECT guid, magnitude, happened_at, location, latitude, longitude
+FROM earthquakes
+WHERE magnitude >= :min_magnitude
+ORDER BY happened_at DESC
+LIMIT :limit;
+`,
+	dbmodels.Earthquake{},
+)
+
+// FetchRecentEarthquakes returns the latest earthquakes first.
+func (s *Storage) FetchRecentEarthquakes(ctx context.Context, req models.ReqFetchRecentEarthquakes) (models.RespFetchRecentEarthquakes, error) {
+	rows := []dbmodels.Earthquake{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchRecentEarthquakes.Query, map[string]any{
+		"min_magnitude": req.MinMagnitude,
+		"limit":         req.Limit,
+	}); err != nil {
+		return models.RespFetchRecentEarthquakes{}, err
+	}
+	return models.RespFetchRecentEarthquakes{Earthquakes: toEarthquakes(rows)}, nil
+}
+
+func toEarthquakes(rows []dbmodels.Earthquake) []models.Earthquake {
+	r := make([]models.Earthquake, len(rows))
+	for i, item := range rows {
+		r[i] = models.Earthquake{
+			GUID:       item.GUID,
+			Magnitude:  item.Magnitude,
+			HappenedAt: item.HappenedAt,
+			Location:   item.Location,
+			Latitude:   item.Latitude,
+			Longitude:  item.Longitude,
+			SourceRank: item.SourceRank,
+		}
+	}
+	return r
+}
+
+// The location is reset, it is asked again with new parameters.
+var queryUpsertEarthquakeSubscription = sqltooling.NewStmt(
+	"UpsertEarthquakeSubscription",
+	`
+INSERT INTO earthquake_subscriptions (telegram_user_id, tenant, min_magnitude, radius_km, created_at, updated_at)
+VALUES (:telegram_user_id, :tenant, :min_magnitude, :radius_km, :now, :now)
+ON CONFLICT (telegram_user_id, tenant) DO UPDATE
+	SET
+		min_magnitude = EXCLUDED.min_magnitude,
+		radius_km = EXCLUDED.radius_km,
+		latitude = NULL,
+		longitude = NULL,
+		updated_at = EXCLUDED.updated_at;
+`,
+	nil,
+)
+
+// UpsertEarthquakeSubscription subscribes the user, the subscription is active when the location is set.
+func (s *Storage) UpsertEarthquakeSubscription(ctx context.Context, req models.ReqUpsertEarthquakeSubscription) (models.RespUpsertEarthquakeSubscription, error) {
+	if _, err := s.db.ExecContext(ctx, queryUpsertEarthquakeSubscription.Query, map[string]any{
+		"telegram_user_id": req.TelegramUserID,
+		"tenant":           req.Tenant,
+		"min_magnitude":    req.MinMagnitude,
+		"radius_km":        req.RadiusKm,
+		"now":              s.now(),
+	}); err != nil {
+		return models.RespUpsertEarthquakeSubscription{}, err
+	}
+	return models.RespUpsertEarthquakeSubscription{}, nil
+}
+
+var queryLocateEarthquakeSubscription = sqltooling.NewStmt(
+	"LocateEarthquakeSubscription",
+	`
+UPDATE earthquake_subscriptions
+SET latitude = :latitude, longitude = :longitude, updated_at = :now
+WHERE telegram_user_id = :telegram_user_id AND tenant = :tenant AND latitude IS NULL
+RETURNING telegram_user_id, tenant, min_magnitude, radius_km, latitude, longitude;
+`,
+	dbmodels.EarthquakeSubscription{},
+)
+
+// LocateEarthquakeSubscription sets the location of the subscription waiting for it.
+func (s *Storage) LocateEarthquakeSubscription(ctx context.Context, req models.ReqLocateEarthquakeSubscription) (models.RespLocateEarthquakeSubscription, error) {
+	rows := []dbmodels.EarthquakeSubscription{}
+	if err := s.db.SelectContext(ctx, &rows, queryLocateEarthquakeSubscription.Query, map[string]any{
+		"telegram_user_id": req.TelegramUserID,
+		"tenant":           req.Tenant,
+		"latitude":         req.Latitude,
+		"longitude":        req.Longitude,
+		"now":              s.now(),
+	}); err != nil {
+		return models.RespLocateEarthquakeSubscription{}, err
+	}
+	if len(rows) == 0 {
+		return models.RespLocateEarthquakeSubscription{}, semerr.NotFound(
+			fmt.Sprintf("no earthquake subscription of %d waits for the location", req.TelegramUserID),
+		)
+	}
+	return models.RespLocateEarthquakeSubscription{Subscription: toEarthquakeSubscription(rows[0])}, nil
+}
+
+var queryDeleteEarthquakeSubscription = sqltooling.NewStmt(
+	"DeleteEarthquakeSubscription",
+	`
+WITH unsent AS (
+	DELETE FROM earthquake_alerts
+	WHERE telegram_user_id = :telegram_user_id AND tenant = :tenant AND sent_at IS NULL
+)
+DELETE FROM earthquake_subscriptions WHERE telegram_user_id = :telegram_user_id AND tenant = :tenant;
+`,
+	nil,
+)
+
+// DeleteEarthquakeSubscription unsubscribes the user, alerts not sent yet are not sent.
+func (s *Storage) DeleteEarthquakeSubscription(ctx context.Context, req models.ReqDeleteEarthquakeSubscription) (models.RespDeleteEarthquakeSubscription, error) {
+	res, err := s.db.ExecContext(ctx, queryDeleteEarthquakeSubscription.Query, map[string]any{
+		"telegram_user_id": req.TelegramUserID,
+		"tenant":           req.Tenant,
+	})
+	if err != nil {
+		return models.RespDeleteEarthquakeSubscription{}, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return models.RespDeleteEarthquakeSubscription{}, err
+	}
+	if affected == 0 {
+		return models.RespDeleteEarthquakeSubscription{}, semerr.NotFound(
+			fmt.Sprintf("no earthquake subscription of %d", req.TelegramUserID),
+		)
+	}
+	return models.RespDeleteEarthquakeSubscription{}, nil
+}
+
+var queryFetchEarthquakeSubscriptions = sqltooling.NewStmt(
+	"FetchEarthquakeSubscriptions",
+	`
+SELECT telegram_user_id, tenant, min_magnitude, radius_km, latitude, longitude
+FROM earthquake_subscriptions
+WHERE latitude IS NOT NULL AND min_magnitude <= :magnitude;
+`,
+	dbmodels.EarthquakeSubscription{},
+)
+
+// FetchEarthquakeSubscriptions returns subscriptions with a location, alerted about the magnitude.
+func (s *Storage) FetchEarthquakeSubscriptions(ctx context.Context, req models.ReqFetchEarthquakeSubscriptions) (models.RespFetchEarthquakeSubscriptions, error) {
+	rows := []dbmodels.EarthquakeSubscription{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchEarthquakeSubscriptions.Query, map[string]any{
+		"magnitude": req.Magnitude,
+	}); err != nil {
+		return models.RespFetchEarthquakeSubscriptions{}, err
+	}
+	resp := models.RespFetchEarthquakeSubscriptions{Subscriptions: make([]models.EarthquakeSubscription, len(rows))}
+	for i, item := range rows {
+		resp.Subscriptions[i] = toEarthquakeSubscription(item)
+	}
+	return resp, nil
+}
+
+func toEarthquakeSubscription(item dbmodels.EarthquakeSubscription) models.EarthquakeSubscription {
+	r := models.EarthquakeSubscription{
+		TelegramUserID: item.TelegramUserID,
+		Tenant:         item.Tenant,
+		MinMagnitude:   item.MinMagnitude,
+		RadiusKm:       item.RadiusKm,
+	}
+	if item.Latitude != nil && item.Longitude != nil {
+		r.Latitude, r.Longitude = *item.Latitude, *item.Longitude
+	}
+	return r
+}
@@ -15,15 +15,16 @@ var querySimilaritySearch = sqltooling.NewStmt(
 	`
 SELECT * FROM
 (
-	SELECT e.message, e.embedding, t.most_recent_message_at, t.body, c.telegram_chat_id
+	SELECT e.message, e.summary, e.embedding, t.thread_id, t.chat_id, t.most_recent_message_at, t.body, c.telegram_chat_id
 	FROM embeddings e
 		JOIN chatthreads t ON e.thread_id = t.thread_id
 		JOIN chats c ON t.chat_id = c.chat_id
 	WHERE
 		most_recent_message_at >= :since
 		AND most_recent_message_at < :upto
+		AND (:all_chats OR e.chat_id = ANY(:chat_ids))
 	ORDER BY embedding <-> :emb
-	LIMIT :limit
+	LIMIT :limit OFFSET :offset
 ) t
 `,
 	dbmodels.PGSimilarity{},
@@ -36,15 +37,21 @@ func (s *Storage) FetchSimilaritySearch(ctx context.Context, req models.ReqSimil
 		"since":     req.Since,
 		"upto":      req.UpTo,
 		"limit":     req.Limit,
+		"offset":    req.Offset,
 		"emb":       pgvector.NewVector(req.Embedding[:2000]),
+		"all_chats": len(req.ChatIDs) == 0,
+		"chat_ids":  req.ChatIDs,
 	}); err != nil {
 		return nil, err
 	}
 	return lo.Map(rows, func(item dbmodels.PGSimilarity, _ int) models.RespSimilaritySearch {
 		return models.RespSimilaritySearch{
+			ThreadID:            item.ThreadID,
+			ChatID:              item.ChatID,
 			TelegramChatID:      item.TelegramChatID,
 			ConversationStarter: item.ConversationStarter,
 			Message:             item.Message,
+			Summary:             lo.FromPtr(item.Summary),
 			MostRecentMessageAt: item.MostRecentMessageAt,
 		}
 	}), nil
@@ -54,12 +61,13 @@ var queryUpsertEmbedding = sqltooling.NewStmt(
 	"UpsertEmbedding",
 	`
 INSERT INTO embeddings
-	(thread_id, chat_id, message, embedding)
-VALUES (:thread_id, :chat_id, :message, :embedding)
+	(thread_id, chat_id, message, embedding, summary)
+VALUES (:thread_id, :chat_id, :message, :embedding, NULLIF(:summary, ''))
 ON CONFLICT (thread_id) DO UPDATE
 	SET
 		embedding = EXCLUDED.embedding,
-		message = EXCLUDED.message;
+		message = EXCLUDED.message,
+		summary = EXCLUDED.summary;
 `,
 	nil,
 )
@@ -70,8 +78,43 @@ func (s *Storage) UpsertEmbedding(ctx context.Context, req models.ReqUpsertEmbed
 		"chat_id":   req.ChatID,
 		"message":   req.Message,
 		"embedding": pgvector.NewVector(req.Embedding[:2000]),
+		"summary":   req.Summary,
 	}); err != nil {
 		return models.RespUpsertEmbedding{}, err
 	}
 	return models.RespUpsertEmbedding{}, nil
 }
+
+var queryFetchThreadsToSummarize = sqltooling.NewStmt(
+	"FetchThreadsToSummarize",
+	`
+SELECT t.thread_id, t.body, t.chat_id, c.locality
+FROM embeddings e
+	JOIN chatthreads t ON e.thread_id = t.thread_id
+	JOIN chats c ON t.chat_id = c.chat_id
+WHERE e.summary IS NULL
+ORDER BY t.thread_id
+LIMIT :limit;
+`,
+	dbmodels.ChatThread{},
+)
+
+// FetchThreadsToSummarize returns embedded threads without a summary.
+func (s *Storage) FetchThreadsToSummarize(ctx context.Context, req models.ReqFetchThreadsToSummarize) (models.RespFetchThreadsToSummarize, error) {
+	rows := []dbmodels.ChatThread{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchThreadsToSummarize.Query, map[string]any{
+		"limit": req.Limit,
+	}); err != nil {
+		return models.RespFetchThreadsToSummarize{}, err
+	}
+	return models.RespFetchThreadsToSummarize{
+		Threads: lo.Map(rows, func(item dbmodels.ChatThread, _ int) models.ChatThreadToGenerateEmbedding {
+			return models.ChatThreadToGenerateEmbedding{
+				ChatID:   item.ChatID,
+				Locality: item.Locality,
+				ThreadID: item.ThreadID,
+				Body:     item.Body,
+			}
+		}),
+	}, nil
+}
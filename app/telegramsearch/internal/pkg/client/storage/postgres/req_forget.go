@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/samber/lo"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres/internal/dbmodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+
+	"github.com/yanakipre/bot/internal/sqltooling"
+)
+
+var queryFetchAuthorThreads = sqltooling.NewStmt(
+	"FetchAuthorThreads",
+	`
+SELECT t.thread_id, t.chat_id, t.body, t.filtered_reason, c.locality
+FROM chatthreads t JOIN chats c ON (c.chat_id = t.chat_id)
+WHERE
+	t.thread_id IN (SELECT thread_id FROM chatthread_authors WHERE author_key = :author_key)
+	OR t.body @> CAST(:legacy_body AS JSONB)
+ORDER BY t.thread_id;
+`,
+	dbmodels.ChatThread{},
+)
+
+// FetchAuthorThreads returns threads with messages of the author.
+// Threads stored before authors were indexed are found by from_id in the body.
+func (s *Storage) FetchAuthorThreads(ctx context.Context, req models.ReqFetchAuthorThreads) (models.RespFetchAuthorThreads, error) {
+	legacyBody, err := json.Marshal([]map[string]string{{"from_id": req.FromID}})
+	if err != nil {
+		return models.RespFetchAuthorThreads{}, err
+	}
+	rows := []dbmodels.ChatThread{}
+	if err := s.db.SelectContext(ctx, &rows, queryFetchAuthorThreads.Query, map[string]any{
+		"author_key":  req.AuthorKey,
+		"legacy_body": legacyBody,
+	}); err != nil {
+		return models.RespFetchAuthorThreads{}, err
+	}
+	return models.RespFetchAuthorThreads{
+		Threads: lo.Map(rows, func(item dbmodels.ChatThread, _ int) models.ChatThreadToGenerateEmbedding {
+			return models.ChatThreadToGenerateEmbedding{
+				ChatID:         item.ChatID,
+				Locality:       item.Locality,
+				ThreadID:       item.ThreadID,
+				Body:           item.Body,
+				FilteredReason: lo.FromPtr(item.FilteredReason),
+			}
+		}),
+	}, nil
+}
+
+var queryUpdateChatThread = sqltooling.NewStmt(
+	"UpdateChatThread",
+	`
+WITH updated AS (
+	UPDATE chatthreads
+	SET body = CAST(:body AS JSONB), most_recent_message_at = :most_recent_message_at, entities_extracted_at = NULL
+	WHERE thread_id = :thread_id
+	RETURNING thread_id
+), unindexed AS (
+	DELETE FROM chatthread_authors
+	WHERE thread_id IN (SELECT thread_id FROM updated) AND author_key = :removed_author_key
+), unmentioned AS (
+	DELETE FROM entity_mentions WHERE thread_id IN (SELECT thread_id FROM updated)
+)
+DELETE FROM embeddings WHERE thread_id IN (SELECT thread_id FROM updated);
+`,
+	nil,
+)
+
+// UpdateChatThread rewrites the thread body and removes its embedding and entities to be generated again.
+func (s *Storage) UpdateChatThread(ctx context.Context, req models.ReqUpdateChatThread) (models.RespUpdateChatThread, error) {
+	marshal, err := json.Marshal(req.Body)
+	if err != nil {
+		return models.RespUpdateChatThread{}, err
+	}
+	if _, err := s.db.ExecContext(ctx, queryUpdateChatThread.Query, map[string]any{
+		"thread_id":              req.ThreadID,
+		"body":                   marshal,
+		"most_recent_message_at": req.MostRecentMessageAt,
+		"removed_author_key":     req.RemovedAuthorKey,
+	}); err != nil {
+		return models.RespUpdateChatThread{}, err
+	}
+	return models.RespUpdateChatThread{}, nil
+}
+
+var queryDeleteChatThread = sqltooling.NewStmt(
+	"DeleteChatThread",
+	`
+DELETE FROM chatthreads WHERE thread_id = :thread_id;
+`,
+	nil,
+)
+
+// DeleteChatThread deletes the thread with its embedding and authors.
+func (s *Storage) DeleteChatThread(ctx context.Context, req models.ReqDeleteChatThread) (models.RespDeleteChatThread, error) {
+	if _, err := s.db.ExecContext(ctx, queryDeleteChatThread.Query, map[string]any{
+		"thread_id": req.ThreadID,
+	}); err != nil {
+		return models.RespDeleteChatThread{}, err
+	}
+	return models.RespDeleteChatThread{}, nil
+}
+
+var queryDeleteUserCategories = sqltooling.NewStmt(
+	"DeleteUserCategories",
+	`
+DELETE FROM user_chat_categories WHERE user_id = :user_id;
+`,
+	nil,
+)
+
+func (s *Storage) DeleteUserCategories(ctx context.Context, req models.ReqDeleteUserCategories) (models.RespDeleteUserCategories, error) {
+	if _, err := s.db.ExecContext(ctx, queryDeleteUserCategories.Query, map[string]any{
+		"user_id": req.UserID,
+	}); err != nil {
+		return models.RespDeleteUserCategories{}, err
+	}
+	return models.RespDeleteUserCategories{}, nil
+}
+
+var queryCreateForgetRequest = sqltooling.NewStmt(
+	"CreateForgetRequest",
+	`
+INSERT INTO forget_requests
+	(author_key, requested_by, threads_deleted, threads_updated, created_at)
+VALUES (:author_key, :requested_by, :threads_deleted, :threads_updated, :created_at);
+`,
+	nil,
+)
+
+// CreateForgetRequest writes the audit log entry. The author stays opted out afterwards.
+func (s *Storage) CreateForgetRequest(ctx context.Context, req models.ReqCreateForgetRequest) (models.RespCreateForgetRequest, error) {
+	if _, err := s.db.ExecContext(ctx, queryCreateForgetRequest.Query, map[string]any{
+		"author_key":      req.AuthorKey,
+		"requested_by":    req.RequestedBy,
+		"threads_deleted": req.ThreadsDeleted,
+		"threads_updated": req.ThreadsUpdated,
+		"created_at":      s.now(),
+	}); err != nil {
+		return models.RespCreateForgetRequest{}, err
+	}
+	return models.RespCreateForgetRequest{}, nil
+}
+
+var queryFetchForgottenAuthors = sqltooling.NewStmt(
+	"FetchForgottenAuthors",
+	`
+SELECT DISTINCT author_key FROM forget_requests;
+`,
+	nil,
+)
+
+func (s *Storage) FetchForgottenAuthors(ctx context.Context, _ models.ReqFetchForgottenAuthors) (models.RespFetchForgottenAuthors, error) {
+	keys := []string{}
+	if err := s.db.SelectContext(ctx, &keys, queryFetchForgottenAuthors.Query, map[string]any{}); err != nil {
+		return models.RespFetchForgottenAuthors{}, err
+	}
+	return models.RespFetchForgottenAuthors{AuthorKeys: keys}, nil
+}
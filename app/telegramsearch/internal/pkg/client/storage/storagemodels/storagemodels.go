@@ -9,11 +9,18 @@ type ReqSimilaritySearch struct {
 	Since        time.Time
 	UpTo         time.Time
 	Limit        int
+	Offset       int
+	// ChatIDs limits the search to the chats. Empty means all chats.
+	ChatIDs []string
 }
 
 type RespSimilaritySearch struct {
+	ThreadID int64
+	ChatID   string
 	// This is message generated for the prompt.
 	Message string
+	// Summary of the thread, empty until the thread is summarized.
+	Summary string
 	// Telegram chat ID.
 	TelegramChatID string
 	// This is the original first message of the thread.
@@ -26,6 +33,8 @@ type ReqUpsertEmbedding struct {
 	Message   string
 	ChatID    string
 	ThreadID  int64
+	// Summary is embedded instead of the thread when set.
+	Summary string
 }
 
 type RespUpsertEmbedding struct {
@@ -34,14 +43,26 @@ type RespUpsertEmbedding struct {
 type ChatID string
 
 type ChatThreadToGenerateEmbedding struct {
-	ChatID   string
+	ChatID string
+	// Locality of the chat, empty when not set.
+	Locality string
 	ThreadID int64
 	Body     []byte
+	// FilteredReason is set for threads the filter dropped, they are never embedded.
+	FilteredReason string
 }
 
 type ReqFetchChatThreadToGenerateEmbedding struct {
 }
 
+type ReqFetchThreadsToSummarize struct {
+	Limit int
+}
+
+type RespFetchThreadsToSummarize struct {
+	Threads []ChatThreadToGenerateEmbedding
+}
+
 type RespFetchChatThreadToGenerateEmbedding struct {
 	Threads []ChatThreadToGenerateEmbedding
 }
@@ -49,14 +70,445 @@ type RespFetchChatThreadToGenerateEmbedding struct {
 type ReqCreateChatThread struct {
 	ChatID ChatID
 	Body   any
+	// MostRecentMessageAt is the date of the last message in the thread.
+	MostRecentMessageAt time.Time
+	// AuthorKeys index the thread by authors of its messages, see redaction.Redactor.AuthorKey.
+	AuthorKeys []string
+	// FilteredReason is why the filter dropped the thread, empty when kept.
+	FilteredReason string
+	// DroppedReplies counts replies the filter dropped from the kept thread, per reason.
+	DroppedReplies map[string]int
 }
 
 type RespCreateChatThread struct {
 }
 
+type ReqFetchFilteredThreads struct {
+	ChatID ChatID
+	Limit  int
+}
+
+type RespFetchFilteredThreads struct {
+	Threads []FilteredThread
+}
+
+type FilteredThread struct {
+	ThreadID            int64
+	ChatID              string
+	FilteredReason      string
+	Body                []byte
+	MostRecentMessageAt time.Time
+}
+
 type ReqCreateChat struct {
 	ChatID ChatID
+	// TelegramChatID is used in links to messages, "empty" when not set.
+	TelegramChatID string
+	Locality       string
 }
 
 type RespCreateChat struct {
 }
+
+type ReqUpdateChat struct {
+	ChatID ChatID
+	// Nil fields are left as is.
+	NewChatID      *ChatID
+	TelegramChatID *string
+	Locality       *string
+}
+
+type RespUpdateChat struct {
+}
+
+type ReqDeleteChat struct {
+	ChatID ChatID
+}
+
+type RespDeleteChat struct {
+}
+
+type ReqFetchEmbeddedChatThreads struct {
+	ChatID ChatID
+}
+
+type RespFetchEmbeddedChatThreads struct {
+	Threads []ChatThreadToGenerateEmbedding
+}
+
+type ReqUpdateEmbeddingMessage struct {
+	ThreadID int64
+	Message  string
+}
+
+type RespUpdateEmbeddingMessage struct {
+}
+
+type ReqFetchChatStats struct {
+}
+
+type RespFetchChatStats struct {
+	Chats []ChatStats
+}
+
+type ChatStats struct {
+	Chat
+	Threads    int
+	Embeddings int
+	// Filtered threads are not embedded.
+	Filtered int
+	// DroppedReplies were dropped by the filter from threads it kept.
+	DroppedReplies int
+	// LastMessageAt is nil when the chat has no threads.
+	LastMessageAt *time.Time
+}
+
+type Chat struct {
+	ChatID         ChatID
+	TelegramChatID string
+	// Locality is what the chat is about, e.g. "Limassol". Empty when not set.
+	Locality string
+	// Categories are topics of the chat, e.g. medicine, housing or cars.
+	Categories []string
+}
+
+type ReqFetchChats struct {
+}
+
+type RespFetchChats struct {
+	Chats []Chat
+}
+
+type ReqSetChatCategories struct {
+	ChatID     ChatID
+	Categories []string
+}
+
+type RespSetChatCategories struct {
+}
+
+type ReqFetchUserCategories struct {
+	UserID int64
+	// Tenant is the bot the categories were chosen in, empty for the default one.
+	Tenant string
+}
+
+type RespFetchUserCategories struct {
+	// Categories the user wants to search in. Empty means all.
+	Categories []string
+}
+
+type ReqToggleUserCategory struct {
+	UserID   int64
+	Tenant   string
+	Category string
+}
+
+type RespToggleUserCategory struct {
+	// Enabled is true when the category was added, false when removed.
+	Enabled bool
+}
+
+type ReqFetchAuthorThreads struct {
+	AuthorKey string
+	// FromID finds threads stored before authors were indexed.
+	FromID string
+}
+
+type RespFetchAuthorThreads struct {
+	Threads []ChatThreadToGenerateEmbedding
+}
+
+type ReqUpdateChatThread struct {
+	ThreadID            int64
+	Body                any
+	MostRecentMessageAt time.Time
+	// RemovedAuthorKey is not an author of the thread anymore.
+	RemovedAuthorKey string
+}
+
+type RespUpdateChatThread struct {
+}
+
+type ReqDeleteChatThread struct {
+	ThreadID int64
+}
+
+type RespDeleteChatThread struct {
+}
+
+type ReqDeleteUserCategories struct {
+	UserID int64
+}
+
+type RespDeleteUserCategories struct {
+}
+
+type ReqCreateForgetRequest struct {
+	AuthorKey      string
+	RequestedBy    string
+	ThreadsDeleted int
+	ThreadsUpdated int
+}
+
+type RespCreateForgetRequest struct {
+}
+
+type ReqFetchForgottenAuthors struct {
+}
+
+type RespFetchForgottenAuthors struct {
+	AuthorKeys []string
+}
+
+type ReqFetchCachedAnswer struct {
+	Tenant string
+	// ChatIDs are sorted chats the answer was searched in, empty for all chats.
+	ChatIDs       []string
+	PromptVersion string
+	Embedding     []float32
+	// MaxDistance between the question and the cached one.
+	MaxDistance float64
+}
+
+type RespFetchCachedAnswer struct {
+	// Found is false when no fresh answer to a similar question is cached.
+	Found             bool
+	Response          string
+	UsedConversations []RespSimilaritySearch
+}
+
+type ReqCreateCachedAnswer struct {
+	Tenant            string
+	ChatIDs           []string
+	PromptVersion     string
+	Embedding         []float32
+	Response          string
+	UsedConversations []RespSimilaritySearch
+	TTL               time.Duration
+}
+
+type RespCreateCachedAnswer struct {
+}
+
+type ReqDeleteCachedAnswers struct {
+	// ChatIDs changed. Answers searched in any of them, or in all chats, are deleted.
+	// Empty deletes everything.
+	ChatIDs []string
+}
+
+type RespDeleteCachedAnswers struct {
+}
+
+type ReqFetchCachedEmbedding struct {
+	Model string
+	// TextHash identifies the normalized text.
+	TextHash string
+}
+
+type RespFetchCachedEmbedding struct {
+	Found     bool
+	Embedding []float32
+}
+
+type ReqCreateCachedEmbedding struct {
+	Model     string
+	TextHash  string
+	Embedding []float32
+	TTL       time.Duration
+}
+
+type RespCreateCachedEmbedding struct {
+}
+
+type ReqFetchThreadsToExtractEntities struct {
+	Limit int
+}
+
+type RespFetchThreadsToExtractEntities struct {
+	Threads []ChatThreadToGenerateEmbedding
+}
+
+// ExtractedEntity is mentioned in the thread, see directory.Entity.
+type ExtractedEntity struct {
+	Kind           string
+	Category       string
+	Name           string
+	NormalizedName string
+	Phones         []string
+	Addresses      []string
+	// Sentiment is -1 for negative, 0 for neutral and 1 for positive mentions.
+	Sentiment int
+}
+
+type ReqSaveThreadEntities struct {
+	ThreadID int64
+	ChatID   string
+	Entities []ExtractedEntity
+}
+
+type RespSaveThreadEntities struct {
+}
+
+type ReqFetchTopEntities struct {
+	// Category matches entity categories containing it literally, "%" and "_" are not wildcards.
+	Category string
+	// Locality of chats, any when empty.
+	Locality string
+	// ChatIDs limit entities to these chats, all chats when empty.
+	ChatIDs []string
+	Limit   int
+}
+
+type DirectoryEntity struct {
+	Name      string
+	Kind      string
+	Category  string
+	Phones    []string
+	Addresses []string
+	// ChatIDs the entity is mentioned in.
+	ChatIDs []string
+	// Mentions counts threads the entity is mentioned in.
+	Mentions        int
+	Positive        int
+	Negative        int
+	LastMentionedAt time.Time
+}
+
+type RespFetchTopEntities struct {
+	Entities []DirectoryEntity
+}
+
+type Earthquake struct {
+	GUID       string
+	Magnitude  float32
+	HappenedAt time.Time
+	Location   string
+	Latitude   float64
+	Longitude  float64
+	// SourceRank of the source reported the earthquake, 0 is the most authoritative.
+	SourceRank int
+}
+
+type ReqSaveEarthquakes struct {
+	Earthquakes []Earthquake
+}
+
+type RespSaveEarthquakes struct {
+	// New are earthquakes not seen before.
+	New []Earthquake
+	// Replaced are earthquakes seen before, now reported by a more authoritative source.
+	Replaced []Earthquake
+}
+
+type ReqFetchEarthquakesSince struct {
+	Since time.Time
+}
+
+type RespFetchEarthquakesSince struct {
+	Earthquakes []Earthquake
+}
+
+type ReqFetchEarthquakesToAlert struct {
+	// Since skips older earthquakes, they are not worth alerting about.
+	Since time.Time
+}
+
+type RespFetchEarthquakesToAlert struct {
+	// Earthquakes alerts about which are not planned yet.
+	Earthquakes []Earthquake
+}
+
+type PlannedEarthquakeAlert struct {
+	TelegramUserID int64
+	Tenant         string
+	DistanceKm     float64
+}
+
+type ReqPlanEarthquakeAlerts struct {
+	GUID   string
+	Alerts []PlannedEarthquakeAlert
+}
+
+type RespPlanEarthquakeAlerts struct {
+}
+
+type ReqFetchUnsentEarthquakeAlerts struct {
+	// Since skips alerts about older earthquakes, they are not sent anymore.
+	Since time.Time
+}
+
+type EarthquakeAlert struct {
+	PlannedEarthquakeAlert
+	Earthquake Earthquake
+}
+
+type RespFetchUnsentEarthquakeAlerts struct {
+	Alerts []EarthquakeAlert
+}
+
+type ReqMarkEarthquakeAlertSent struct {
+	GUID           string
+	TelegramUserID int64
+	Tenant         string
+}
+
+type RespMarkEarthquakeAlertSent struct {
+}
+
+type ReqFetchRecentEarthquakes struct {
+	MinMagnitude float32
+	Limit        int
+}
+
+type RespFetchRecentEarthquakes struct {
+	Earthquakes []Earthquake
+}
+
+type EarthquakeSubscription struct {
+	TelegramUserID int64
+	// Tenant of the bot the user subscribed with.
+	Tenant       string
+	MinMagnitude float32
+	RadiusKm     float32
+	Latitude     float64
+	Longitude    float64
+}
+
+type ReqUpsertEarthquakeSubscription struct {
+	TelegramUserID int64
+	Tenant         string
+	MinMagnitude   float32
+	RadiusKm       float32
+}
+
+type RespUpsertEarthquakeSubscription struct {
+}
+
+type ReqLocateEarthquakeSubscription struct {
+	TelegramUserID int64
+	Tenant         string
+	Latitude       float64
+	Longitude      float64
+}
+
+type RespLocateEarthquakeSubscription struct {
+	Subscription EarthquakeSubscription
+}
+
+type ReqDeleteEarthquakeSubscription struct {
+	TelegramUserID int64
+	Tenant         string
+}
+
+type RespDeleteEarthquakeSubscription struct {
+}
+
+type ReqFetchEarthquakeSubscriptions struct {
+	// Magnitude of the earthquake, subscriptions with greater minimum magnitude are skipped.
+	Magnitude float32
+}
+
+type RespFetchEarthquakeSubscriptions struct {
+	Subscriptions []EarthquakeSubscription
+}
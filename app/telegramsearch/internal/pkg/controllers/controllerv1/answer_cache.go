@@ -0,0 +1,124 @@
+package controllerv1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	"github.com/yanakipre/bot/internal/encodingtooling"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+// AnswerCacheConfig configures reusing answers to the same and similar questions.
+// Cached answers are stored in postgres, so they are shared between replicas.
+type AnswerCacheConfig struct {
+	Enabled bool                     `yaml:"enabled"`
+	TTL     encodingtooling.Duration `yaml:"ttl"`
+	// MaxDistance between embeddings of questions to reuse the answer, 0 reuses only the same question.
+	MaxDistance float64 `yaml:"max_distance"`
+}
+
+func DefaultAnswerCacheConfig() AnswerCacheConfig {
+	return AnswerCacheConfig{
+		Enabled:     true,
+		TTL:         encodingtooling.Duration{Duration: 24 * time.Hour},
+		MaxDistance: 0.25, // empirical value, rephrased questions are within it
+	}
+}
+
+func (c *AnswerCacheConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.TTL.Duration <= 0 {
+		return errors.New("answer_cache ttl must be positive")
+	}
+	if c.MaxDistance < 0 {
+		return fmt.Errorf("answer_cache max_distance must not be negative, got %v", c.MaxDistance)
+	}
+	return nil
+}
+
+// answerScope is what the answer depends on besides the question.
+type answerScope struct {
+	tenant string
+	// chatIDs are sorted, empty for all chats.
+	chatIDs       []string
+	promptVersion string
+}
+
+func newAnswerScope(tenant string, chatIDs []string, allChats bool, promptVersion string) answerScope {
+	s := answerScope{tenant: tenant, promptVersion: promptVersion}
+	if !allChats {
+		s.chatIDs = slices.Clone(chatIDs)
+		slices.Sort(s.chatIDs)
+		s.chatIDs = slices.Compact(s.chatIDs)
+	}
+	return s
+}
+
+// cachedAnswer returns the answer to a similar question.
+// Failures are logged, the answer is generated then.
+func (c *Ctl) cachedAnswer(
+	ctx context.Context,
+	scope answerScope,
+	embedding []float32,
+) (storagemodels.RespFetchCachedAnswer, bool) {
+	if !c.cfg.AnswerCache.Enabled {
+		return storagemodels.RespFetchCachedAnswer{}, false
+	}
+	cached, err := c.storageRW.FetchCachedAnswer(ctx, storagemodels.ReqFetchCachedAnswer{
+		Tenant:        scope.tenant,
+		ChatIDs:       scope.chatIDs,
+		PromptVersion: scope.promptVersion,
+		Embedding:     embedding,
+		MaxDistance:   c.cfg.AnswerCache.MaxDistance,
+	})
+	if err != nil {
+		logger.Error(ctx, fmt.Errorf("failed to fetch cached answer: %w", err))
+		return storagemodels.RespFetchCachedAnswer{}, false
+	}
+	return cached, cached.Found
+}
+
+func (c *Ctl) cacheAnswer(
+	ctx context.Context,
+	scope answerScope,
+	embedding []float32,
+	response string,
+	usedConversations []storagemodels.RespSimilaritySearch,
+) {
+	if !c.cfg.AnswerCache.Enabled {
+		return
+	}
+	if _, err := c.storageRW.CreateCachedAnswer(ctx, storagemodels.ReqCreateCachedAnswer{
+		Tenant:            scope.tenant,
+		ChatIDs:           scope.chatIDs,
+		PromptVersion:     scope.promptVersion,
+		Embedding:         embedding,
+		Response:          response,
+		UsedConversations: usedConversations,
+		TTL:               c.cfg.AnswerCache.TTL.Duration,
+	}); err != nil {
+		logger.Error(ctx, fmt.Errorf("failed to cache answer: %w", err))
+	}
+}
+
+// invalidateAnswers deletes cached answers which could use threads of the chats.
+// Empty chatIDs invalidate everything.
+func (c *Ctl) invalidateAnswers(ctx context.Context, chatIDs []string) error {
+	chatIDs = lo.Uniq(chatIDs)
+	if _, err := c.storageRW.DeleteCachedAnswers(ctx, storagemodels.ReqDeleteCachedAnswers{
+		ChatIDs: chatIDs,
+	}); err != nil {
+		return fmt.Errorf("invalidate cached answers: %w", err)
+	}
+	logger.Info(ctx, "cached answers invalidated", zap.Strings("chat_ids", chatIDs))
+	return nil
+}
@@ -3,6 +3,10 @@ package controllerv1
 import (
 	"time"
 
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/directory"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/prompts"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/redaction"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/threadfilter"
 	"github.com/yanakipre/bot/internal/encodingtooling"
 )
 
@@ -14,6 +18,25 @@ type Config struct {
 	StaleResponsesText string
 	FreshResponsesText string
 	StaleThreshold     encodingtooling.Duration
+	Prompts            prompts.Config `yaml:"prompts"`
+	// Redaction removes personal data before storing and sending it to the LLM.
+	Redaction redaction.Config `yaml:"redaction"`
+	// AnswerCache reuses answers to similar questions.
+	AnswerCache AnswerCacheConfig `yaml:"answer_cache"`
+	// EmbeddingCache reuses embeddings of the same texts.
+	EmbeddingCache EmbeddingCacheConfig `yaml:"embedding_cache"`
+	// Summarization makes compact summaries of threads, they are embedded and given to the model.
+	Summarization SummarizationConfig `yaml:"summarization"`
+	// Directory extracts businesses, professionals and places recommended in threads.
+	Directory directory.Config `yaml:"directory"`
+	// Earthquakes alerts subscribers about earthquakes near them.
+	Earthquakes EarthquakesConfig `yaml:"earthquakes"`
+	// Buses finds buses approaching the shared location.
+	Buses BusesConfig `yaml:"buses"`
+	// ThreadFilter drops ads, spam and low content threads and replies when history is loaded.
+	ThreadFilter threadfilter.Config `yaml:"thread_filter"`
+	// Tenants share the deployment. Requests without a tenant search in all chats.
+	Tenants []TenantConfig `yaml:"tenants"`
 }
 
 func DefaultConfig() Config {
@@ -23,6 +46,11 @@ func DefaultConfig() Config {
 
 Чтобы задать вопрос, просто напишите его в чат. Он постарается найти наиболее подходящий ответ на ваш вопрос.
 Бот не выдумывает от себя, все его данные собраны от живых людей.
+Командой /chats можно выбрать темы чатов, в которых искать ответы.
+Командой /top можно узнать, кого чаще всего рекомендуют, например: /top dentist limassol
+Командой /quakes можно посмотреть последние землетрясения, а командой /quakesubscribe подписаться на уведомления о них.
+Отправьте геопозицию, и бот покажет автобусы, которые едут к вам.
+Командой /forgetme можно удалить все ваши сообщения из собранных ботом обсуждений.
 
 Я, разработчик, буду очень признателен, если вы поделитесь своими впечатлениями о боте и порекомендуете его своим друзьям, если он вам полезен.
 
@@ -36,6 +64,15 @@ func DefaultConfig() Config {
 Если вам есть что прокомментировать или добавить - пишите в чате https://t.me/+8trW_-0GEFI1NTE0 или в https://substack.com/home/post/p-148053843
 `,
 		StaleThreshold:     encodingtooling.Duration{time.Hour * 24 * 365 * 2},
+		Prompts:            prompts.DefaultConfig(),
+		Redaction:          redaction.DefaultConfig(),
+		AnswerCache:        DefaultAnswerCacheConfig(),
+		EmbeddingCache:     DefaultEmbeddingCacheConfig(),
+		Summarization:      DefaultSummarizationConfig(),
+		Directory:          directory.DefaultConfig(),
+		Earthquakes:        DefaultEarthquakesConfig(),
+		Buses:              DefaultBusesConfig(),
+		ThreadFilter:       threadfilter.DefaultConfig(),
 		StaleResponsesText: "В ответе не использовано информации свежее чем от %s",
 		FreshResponsesText: "Обсуждений: %d",
 		NoResultsAnswer: "К сожалению, у меня недостаточно информации чтобы ответить на данный вопрос." +
@@ -1,8 +1,13 @@
 package controllerv1
 
 import (
+	"fmt"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/httpopenaiclient"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/prompts"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/redaction"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/threadfilter"
 	"time"
 
 	"github.com/jellydator/ttlcache/v3"
@@ -14,13 +19,36 @@ type Ctl struct {
 	cfg                    Config
 	openai                 *httpopenaiclient.Client
 	storageRW              *postgres.Storage
+	earthquaker            earthquakes.Earthquaker
+	buses                  BusTracker
+	prompts                *prompts.Prompts
+	redactor               *redaction.Redactor
+	threadFilter           *threadfilter.Filter
 }
 
 func New(
 	cfg Config,
 	openai *httpopenaiclient.Client,
 	storageRW *postgres.Storage,
+	earthquaker earthquakes.Earthquaker,
+	buses BusTracker,
 ) (*Ctl, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	registerMetrics()
+	p, err := prompts.New(cfg.Prompts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load prompts: %w", err)
+	}
+	redactor, err := redaction.New(cfg.Redaction)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure redaction: %w", err)
+	}
+	threadFilter, err := threadfilter.New(cfg.ThreadFilter)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure thread filter: %w", err)
+	}
 	cache := ttlcache.New[int, ExplainedMessage](
 		ttlcache.WithTTL[int, ExplainedMessage](30*time.Minute),
 		// 200 MiB capacity
@@ -32,6 +60,11 @@ func New(
 		cfg:                    cfg,
 		openai:                 openai,
 		storageRW:              storageRW,
+		earthquaker:            earthquaker,
+		buses:                  buses,
+		prompts:                p,
+		redactor:               redactor,
+		threadFilter:           threadFilter,
 	}, nil
 }
 
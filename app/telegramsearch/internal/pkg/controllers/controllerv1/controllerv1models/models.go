@@ -1,29 +1,80 @@
 package controllerv1models
 
 import (
+	"time"
+
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
 )
 
 type ReqTryEmbedding struct {
-	Input string
+	// Tenant scopes the search to the tenant's chats. Empty searches in all chats.
+	Tenant string
+	Input  string
+	// ChatIDs narrow the search down to the chats. Empty searches in all chats of the tenant.
+	ChatIDs []string
+	// Since and Until limit the most recent message of threads, zero values do not limit.
+	Since time.Time
+	Until time.Time
+	// Limit enables paging: threads are returned ordered by similarity, Offset of them skipped.
+	// Without Limit the most similar threads of every period are returned.
+	Limit  int
+	Offset int
 }
 
 type EmbeddingResponse struct {
-	Text string `yaml:"text"`
+	ThreadID            int64           `yaml:"thread_id"`
+	Text                string          `yaml:"text"`
+	ChatID              string          `yaml:"chat_id"`
+	TelegramChatID      string          `yaml:"telegram_chat_id"`
+	URL                 string          `yaml:"url"`
+	MostRecentMessageAt time.Time       `yaml:"most_recent_message_at"`
+	Messages            []ThreadMessage `yaml:"messages"`
+}
+
+type ThreadMessage struct {
+	ID   int64     `yaml:"id"`
+	Text string    `yaml:"text"`
+	Date time.Time `yaml:"date"`
+	URL  string    `yaml:"url"`
 }
 
 type RespTryEmbedding struct {
 	Result []EmbeddingResponse `yaml:"result"`
+	// HasMore is true when paging and the next page is not empty.
+	HasMore bool `yaml:"has_more"`
 }
 
 type ReqTryCompletion struct {
+	// Tenant scopes the search to the tenant's chats. Empty searches in all chats.
+	Tenant   string
 	SenderID int
 	Query    string
+	// NoCache generates the answer even when a similar question is cached, and does not cache it.
+	NoCache bool
+	// OnDelta, when set, is called with every next part of the response as it is generated.
+	// All the parts together are the Response.
+	OnDelta func(text string)
 }
 
 type RespTryCompletion struct {
 	Response          string `yaml:"response"`
 	UsedConversations []storagemodels.RespSimilaritySearch
+	// PromptVersion is empty when no completion was requested.
+	PromptVersion string `yaml:"prompt_version"`
+	// Cached is true when the answer to a similar question is reused.
+	Cached bool `yaml:"cached"`
+	// Stale is true when all the used conversations are older than the stale threshold.
+	Stale bool `yaml:"stale"`
+	// Sources link the used conversations, the most recent first.
+	Sources []AnswerSource `yaml:"sources"`
+}
+
+// AnswerSource is a conversation the answer is based on.
+type AnswerSource struct {
+	URL                 string    `yaml:"url"`
+	MostRecentMessageAt time.Time `yaml:"most_recent_message_at"`
+	// Snippet is the beginning of the conversation.
+	Snippet string `yaml:"snippet"`
 }
 
 type ReqGenerateEmbeddings struct {
@@ -32,6 +83,15 @@ type ReqGenerateEmbeddings struct {
 type RespGenerateEmbeddings struct {
 }
 
+type ReqSummarizeThreads struct {
+	// Limit is how many threads to summarize, all of them when zero.
+	Limit int
+}
+
+type RespSummarizeThreads struct {
+	Summarized int `yaml:"summarized"`
+}
+
 type ReqDumpChatHistory struct {
 	ChatID      string
 	ChatHistory []byte
@@ -39,3 +99,271 @@ type ReqDumpChatHistory struct {
 
 type RespDumpChatHistory struct {
 }
+
+type Chat struct {
+	ChatID string `yaml:"chat_id"`
+	// TelegramChatID is used in links to messages.
+	TelegramChatID string `yaml:"telegram_chat_id"`
+	// Locality is what the chat is about, e.g. "Limassol".
+	Locality   string   `yaml:"locality"`
+	Categories []string `yaml:"categories"`
+}
+
+type ReqListChats struct {
+}
+
+type RespListChats struct {
+	Chats []Chat `yaml:"chats"`
+}
+
+type ReqCreateChat struct {
+	ChatID         string
+	TelegramChatID string
+	Locality       string
+}
+
+type RespCreateChat struct {
+}
+
+type ReqUpdateChat struct {
+	ChatID string
+	// Nil fields are left as is.
+	NewChatID      *string
+	TelegramChatID *string
+	Locality       *string
+}
+
+type RespUpdateChat struct {
+	// EmbeddingsUpdated counts embeddings shown with the new locality.
+	EmbeddingsUpdated int `yaml:"embeddings_updated"`
+}
+
+type ReqDeleteChat struct {
+	ChatID string
+}
+
+type RespDeleteChat struct {
+}
+
+type ReqChatStats struct {
+}
+
+type ChatStats struct {
+	Chat       `yaml:",inline"`
+	Threads    int `yaml:"threads"`
+	Embeddings int `yaml:"embeddings"`
+	// Filtered threads were dropped by the thread filter and are not searched.
+	Filtered int `yaml:"filtered"`
+	// DroppedReplies were dropped by the thread filter from threads it kept.
+	DroppedReplies int        `yaml:"dropped_replies"`
+	LastMessageAt  *time.Time `yaml:"last_message_at,omitempty"`
+}
+
+type RespChatStats struct {
+	Chats []ChatStats `yaml:"chats"`
+}
+
+type ReqFilteredThreads struct {
+	ChatID string
+	Limit  int
+}
+
+type FilteredThread struct {
+	ThreadID            int64     `yaml:"thread_id"`
+	Reason              string    `yaml:"reason"`
+	MostRecentMessageAt time.Time `yaml:"most_recent_message_at"`
+	// Starter is the text of the conversation starter.
+	Starter string `yaml:"starter"`
+}
+
+type RespFilteredThreads struct {
+	Threads []FilteredThread `yaml:"threads"`
+}
+
+type ReqSetChatCategories struct {
+	ChatID     string
+	Categories []string
+}
+
+type RespSetChatCategories struct {
+	// Categories are normalized: lower-cased, without duplicates.
+	Categories []string `yaml:"categories"`
+}
+
+type ReqChatCategories struct {
+	Tenant string
+	UserID int64
+}
+
+type ChatCategory struct {
+	Name string `yaml:"name"`
+	// Selected categories are searched in. When none are selected, all chats are searched in.
+	Selected bool `yaml:"selected"`
+}
+
+type RespChatCategories struct {
+	Categories []ChatCategory `yaml:"categories"`
+}
+
+type ReqToggleChatCategory struct {
+	Tenant   string
+	UserID   int64
+	Category string
+}
+
+type ReqForgetAuthor struct {
+	TelegramUserID int64
+	// RequestedBy is written to the audit log, e.g. "bot" or a ticket reference.
+	RequestedBy string
+}
+
+type RespForgetAuthor struct {
+	ThreadsDeleted int `yaml:"threads_deleted"`
+	ThreadsUpdated int `yaml:"threads_updated"`
+}
+
+type ReqExtractEntities struct {
+	// Limit is how many threads to process, all of them when zero.
+	Limit int
+}
+
+type RespExtractEntities struct {
+	Threads  int `yaml:"threads"`
+	Entities int `yaml:"entities"`
+	// Failed threads have unparsable responses, they are not processed again.
+	Failed int `yaml:"failed"`
+}
+
+type ReqTopEntities struct {
+	// Tenant scopes the directory to the tenant's chats. Empty uses all chats.
+	Tenant   string
+	SenderID int64
+	// Query is parsed into Category and Locality when set, e.g. "dentist limassol".
+	Query    string
+	Category string
+	// Locality of chats, any when empty.
+	Locality string
+	Limit    int
+}
+
+type DirectoryEntity struct {
+	Name      string   `yaml:"name"`
+	Kind      string   `yaml:"kind"`
+	Category  string   `yaml:"category"`
+	Phones    []string `yaml:"phones"`
+	Addresses []string `yaml:"addresses"`
+	ChatIDs   []string `yaml:"chat_ids"`
+	// Mentions counts threads the entity is mentioned in.
+	Mentions        int       `yaml:"mentions"`
+	Positive        int       `yaml:"positive"`
+	Negative        int       `yaml:"negative"`
+	LastMentionedAt time.Time `yaml:"last_mentioned_at"`
+}
+
+type RespTopEntities struct {
+	Category string            `yaml:"category"`
+	Locality string            `yaml:"locality"`
+	Entities []DirectoryEntity `yaml:"entities"`
+}
+
+type Earthquake struct {
+	GUID       string    `yaml:"guid"`
+	Magnitude  float32   `yaml:"magnitude"`
+	HappenedAt time.Time `yaml:"happened_at"`
+	// Location is human-readable, e.g. "85.0 km N of Polis".
+	Location  string  `yaml:"location"`
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+}
+
+type ReqPollEarthquakes struct {
+}
+
+// EarthquakeAlert is sent to the subscriber by the bot of the tenant.
+type EarthquakeAlert struct {
+	TelegramUserID int64
+	Tenant         string
+	Earthquake     Earthquake
+	DistanceKm     float64
+}
+
+type RespPollEarthquakes struct {
+	// New counts earthquakes not seen before.
+	New int
+	// Alerts are not sent yet, including ones failed to send before.
+	Alerts []EarthquakeAlert
+}
+
+type ReqMarkEarthquakeAlertSent struct {
+	GUID           string
+	TelegramUserID int64
+	Tenant         string
+}
+
+type RespMarkEarthquakeAlertSent struct {
+}
+
+type ReqRecentEarthquakes struct {
+	// Limit is how many earthquakes to return, the configured number when zero.
+	Limit int
+}
+
+type RespRecentEarthquakes struct {
+	Earthquakes []Earthquake `yaml:"earthquakes"`
+}
+
+type ReqSubscribeEarthquakes struct {
+	Tenant         string
+	TelegramUserID int64
+	// MinMagnitude and RadiusKm are configured defaults when zero.
+	MinMagnitude float32
+	RadiusKm     float32
+}
+
+type RespSubscribeEarthquakes struct {
+	MinMagnitude float32
+	RadiusKm     float32
+}
+
+type ReqLocateEarthquakeSubscription struct {
+	Tenant         string
+	TelegramUserID int64
+	Latitude       float64
+	Longitude      float64
+}
+
+type RespLocateEarthquakeSubscription struct {
+	MinMagnitude float32
+	RadiusKm     float32
+}
+
+type ReqUnsubscribeEarthquakes struct {
+	Tenant         string
+	TelegramUserID int64
+}
+
+type RespUnsubscribeEarthquakes struct {
+}
+
+type ReqApproachingBuses struct {
+	Latitude  float64
+	Longitude float64
+}
+
+type ApproachingBus struct {
+	BusID string
+	// Route is the short name, e.g. "30", RouteName is the long one.
+	Route          string
+	RouteName      string
+	DistanceMeters float64
+	// ETA is zero when the bus is too slow to estimate it.
+	ETA time.Duration
+	// Stop is the nearest stop the bus arrives at by ETA,
+	// empty when ETA is rough: the straight distance by the speed of the bus.
+	Stop     string
+	RoughETA bool
+}
+
+type RespApproachingBuses struct {
+	Buses []ApproachingBus
+}
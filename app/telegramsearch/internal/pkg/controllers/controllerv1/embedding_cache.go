@@ -0,0 +1,91 @@
+package controllerv1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaimodels"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	"github.com/yanakipre/bot/internal/encodingtooling"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+// EmbeddingCacheConfig configures reusing embeddings of the same texts,
+// e.g. repeated questions or threads loaded again.
+type EmbeddingCacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TTL bounds the cache, embeddings of texts not seen again
+	// and of models no longer used are deleted after it.
+	TTL encodingtooling.Duration `yaml:"ttl"`
+}
+
+func DefaultEmbeddingCacheConfig() EmbeddingCacheConfig {
+	return EmbeddingCacheConfig{
+		Enabled: true,
+		TTL:     encodingtooling.Duration{Duration: 30 * 24 * time.Hour},
+	}
+}
+
+func (c *EmbeddingCacheConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.TTL.Duration <= 0 {
+		return errors.New("embedding_cache ttl must be positive")
+	}
+	return nil
+}
+
+// createEmbedding returns the cached embedding of the text, or creates and caches it.
+// Cache failures are logged, the embedding is created then.
+func (c *Ctl) createEmbedding(ctx context.Context, text string) ([]float32, error) {
+	model := c.openai.EmbeddingModel()
+	textHash := embeddingTextHash(text)
+	if c.cfg.EmbeddingCache.Enabled {
+		cached, err := c.storageRW.FetchCachedEmbedding(ctx, storagemodels.ReqFetchCachedEmbedding{
+			Model:    model,
+			TextHash: textHash,
+		})
+		if err != nil {
+			logger.Error(ctx, fmt.Errorf("failed to fetch cached embedding: %w", err))
+		}
+		if cached.Found {
+			EmbeddingCacheRequests.WithLabelValues(model, "hit").Inc()
+			return cached.Embedding, nil
+		}
+		EmbeddingCacheRequests.WithLabelValues(model, "miss").Inc()
+	}
+	resp, err := c.openai.CreateEmbeddings(ctx, openaimodels.ReqCreateEmbeddings{
+		Input: []string{text},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) == 0 {
+		return nil, errors.New("no embeddings created")
+	}
+	embedding := resp.Embeddings[0].Embedding
+	if c.cfg.EmbeddingCache.Enabled {
+		if _, err := c.storageRW.CreateCachedEmbedding(ctx, storagemodels.ReqCreateCachedEmbedding{
+			Model:     model,
+			TextHash:  textHash,
+			Embedding: embedding,
+			TTL:       c.cfg.EmbeddingCache.TTL.Duration,
+		}); err != nil {
+			logger.Error(ctx, fmt.Errorf("failed to cache embedding: %w", err))
+		}
+	}
+	return embedding, nil
+}
+
+// embeddingTextHash identifies the text regardless of case and whitespace.
+func embeddingTextHash(text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
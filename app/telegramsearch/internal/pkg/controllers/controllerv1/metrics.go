@@ -0,0 +1,34 @@
+package controllerv1
+
+import (
+	"sync"
+
+	"github.com/yanakipre/bot/internal/promtooling"
+)
+
+var registerMetricsOnce sync.Once
+
+// registerMetrics is called by New, tests create many controllers.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		promtooling.MustRegister(EmbeddingCacheRequests, ThreadsFiltered, RepliesDropped)
+	})
+}
+
+var EmbeddingCacheRequests = promtooling.NewCounterVec(
+	"embedding_cache_requests",
+	"Number of embedding cache lookups, per model and result: hit or miss",
+	[]string{"model", "result"},
+)
+
+var ThreadsFiltered = promtooling.NewCounterVec(
+	"threads_filtered",
+	"Number of loaded threads the filter dropped, per reason",
+	[]string{"reason"},
+)
+
+var RepliesDropped = promtooling.NewCounterVec(
+	"replies_dropped",
+	"Number of replies the filter dropped from loaded threads it kept, per reason",
+	[]string{"reason"},
+)
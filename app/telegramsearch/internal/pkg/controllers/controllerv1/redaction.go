@@ -0,0 +1,70 @@
+package controllerv1
+
+import (
+	"context"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+)
+
+// authorKeys is a set of redaction.Redactor.AuthorKey.
+type authorKeys map[string]struct{}
+
+// forgottenAuthors returns authors who asked to forget them, their messages are never stored again.
+func (c *Ctl) forgottenAuthors(ctx context.Context) (authorKeys, error) {
+	resp, err := c.storageRW.FetchForgottenAuthors(ctx, storagemodels.ReqFetchForgottenAuthors{})
+	if err != nil {
+		return nil, err
+	}
+	r := make(authorKeys, len(resp.AuthorKeys))
+	for _, k := range resp.AuthorKeys {
+		r[k] = struct{}{}
+	}
+	return r, nil
+}
+
+// optedOut reports whether messages of the author must be removed entirely.
+func (c *Ctl) optedOut(m serializedChatMessage, forgotten authorKeys) bool {
+	if c.redactor.OptedOut(m.FromId) {
+		return true
+	}
+	_, ok := forgotten[m.AuthorKey]
+	return ok && m.AuthorKey != ""
+}
+
+// redactMessages removes messages of opted-out users and redacts personal data in the rest.
+func (c *Ctl) redactMessages(msgs []serializedChatMessage, forgotten authorKeys) []serializedChatMessage {
+	r := make([]serializedChatMessage, 0, len(msgs))
+	for _, m := range msgs {
+		if m.AuthorKey == "" {
+			// from_id is not redacted yet.
+			m.AuthorKey = c.redactor.AuthorKey(m.FromId)
+		}
+		if c.optedOut(m, forgotten) {
+			continue
+		}
+		m.FromId = c.redactor.UserID(m.FromId)
+		entities := make([]TextEntity, len(m.TextEntities))
+		for i, e := range m.TextEntities {
+			entities[i] = TextEntity{
+				Type: e.Type,
+				Text: c.redactor.Entity(e.Type, e.Text),
+			}
+		}
+		m.TextEntities = entities
+		r = append(r, m)
+	}
+	return r
+}
+
+// redactThread redacts the thread stored before redaction was configured.
+// Replies to the removed conversation starter make no sense, so nothing is left then.
+func (c *Ctl) redactThread(t thread, forgotten authorKeys) thread {
+	if len(t) == 0 {
+		return nil
+	}
+	r := c.redactMessages(t, forgotten)
+	if len(r) == 0 || r[0].ID != t[0].ID {
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,129 @@
+package controllerv1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/logger"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// BusTracker finds buses, Run keeps their positions fresh, see busregistry.Registry.
+type BusTracker interface {
+	buses.Client
+	Run(ctx context.Context)
+}
+
+// BusesConfig configures the lookup of buses approaching the location shared with the bot.
+type BusesConfig struct {
+	// Limit is how many nearest buses are shown.
+	Limit int `yaml:"limit"`
+	// StopRadiusMeters is the distance to the nearest stop, arrivals at it give the ETA of buses.
+	StopRadiusMeters float64 `yaml:"stop_radius_meters"`
+	// MinSpeed is the speed in meters per second the rough ETA is estimated from,
+	// when the bus is not found arriving at the nearest stop.
+	// Slower buses are stuck in traffic or at a stop and the ETA would be misleading.
+	MinSpeed float64 `yaml:"min_speed"`
+}
+
+func DefaultBusesConfig() BusesConfig {
+	return BusesConfig{
+		Limit:            5,
+		StopRadiusMeters: 300,
+		MinSpeed:         1,
+	}
+}
+
+func (c *BusesConfig) Validate() error {
+	if c.Limit <= 0 {
+		return fmt.Errorf("buses limit must be positive, got %d", c.Limit)
+	}
+	if c.StopRadiusMeters <= 0 {
+		return fmt.Errorf("buses stop radius must be positive, got %g", c.StopRadiusMeters)
+	}
+	if c.MinSpeed <= 0 {
+		return fmt.Errorf("buses min speed must be positive, got %g", c.MinSpeed)
+	}
+	return nil
+}
+
+// TrackBuses polls bus positions until ctx is done, so ApproachingBuses answers at once.
+func (c *Ctl) TrackBuses(ctx context.Context) {
+	c.buses.Run(ctx)
+}
+
+// ApproachingBuses returns buses heading to the location, the nearest first.
+// The ETA is the arrival at the nearest stop, or the straight distance by the speed of the bus.
+func (c *Ctl) ApproachingBuses(ctx context.Context, req models.ReqApproachingBuses) (models.RespApproachingBuses, error) {
+	here := geo.Point{Lat: req.Latitude, Lon: req.Longitude}
+	nearest, err := c.buses.GetNearest(ctx, here)
+	if err != nil {
+		return models.RespApproachingBuses{}, fmt.Errorf("nearest buses: %w", err)
+	}
+	var (
+		stop     buses.Stop
+		arrivals map[string]time.Time
+	)
+	if len(nearest) > 0 {
+		stop, arrivals = c.stopArrivals(ctx, here)
+	}
+	now := time.Now()
+	approaching := lo.Map(nearest, func(item buses.Bus, _ int) models.ApproachingBus {
+		distance := geo.Distance(here, item.Position)
+		bus := models.ApproachingBus{
+			BusID:          item.ID,
+			Route:          item.Route.ShortName,
+			RouteName:      item.Route.LongName,
+			DistanceMeters: distance,
+		}
+		if at, ok := arrivals[item.ID]; ok {
+			// the bus boarding at the stop arrives now.
+			bus.ETA = max(at.Sub(now), time.Second)
+			bus.Stop = stop.Name
+		} else if item.Moving && item.Speed >= c.cfg.Buses.MinSpeed {
+			bus.ETA = time.Duration(distance / item.Speed * float64(time.Second))
+			bus.RoughETA = true
+		}
+		return bus
+	})
+	sort.SliceStable(approaching, func(i, j int) bool {
+		return approaching[i].DistanceMeters < approaching[j].DistanceMeters
+	})
+	return models.RespApproachingBuses{Buses: approaching[:min(len(approaching), c.cfg.Buses.Limit)]}, nil
+}
+
+// stopArrivals are the soonest arrivals of buses by their IDs at the stop within StopRadiusMeters.
+// The ETA is rough without them, so failures are logged only,
+// e.g. the timetable of routes only has no stops.
+func (c *Ctl) stopArrivals(ctx context.Context, here geo.Point) (buses.Stop, map[string]time.Time) {
+	stops, err := c.buses.NearestStops(ctx, here, 1)
+	if err != nil {
+		if !semerr.IsFailedPrecondition(err) {
+			logger.Warn(ctx, "nearest stop lookup failed", zap.Error(err))
+		}
+		return buses.Stop{}, nil
+	}
+	if len(stops) == 0 || geo.Distance(here, stops[0].Position) > c.cfg.Buses.StopRadiusMeters {
+		return buses.Stop{}, nil
+	}
+	arrivals, err := c.buses.Arrivals(ctx, stops[0].ID, "")
+	if err != nil {
+		logger.Warn(ctx, "arrivals lookup failed", zap.Error(err), zap.String("stop_id", stops[0].ID))
+		return buses.Stop{}, nil
+	}
+	r := map[string]time.Time{}
+	for _, a := range arrivals {
+		if _, ok := r[a.BusID]; a.BusID != "" && !ok {
+			r[a.BusID] = a.At
+		}
+	}
+	return stops[0], r
+}
@@ -0,0 +1,108 @@
+package controllerv1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+type busesFake struct {
+	buses.Client
+	nearest  []buses.Bus
+	stops    []buses.Stop
+	arrivals []buses.Arrival
+}
+
+func (f busesFake) GetNearest(context.Context, geo.Point) ([]buses.Bus, error) {
+	return f.nearest, nil
+}
+
+func (f busesFake) NearestStops(context.Context, geo.Point, int) ([]buses.Stop, error) {
+	if f.stops == nil {
+		return nil, semerr.FailedPrecondition("the timetable has no stops")
+	}
+	return f.stops[:1], nil
+}
+
+func (f busesFake) Arrivals(context.Context, string, string) ([]buses.Arrival, error) {
+	return f.arrivals, nil
+}
+
+func (f busesFake) Run(context.Context) {}
+
+func TestCtl_ApproachingBuses(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Buses.Limit = 2
+	here := geo.Point{Lat: 34.684422, Lon: 33.037147}
+	route := buses.Route{ID: "10300011", ShortName: "30", LongName: "Le Meridien - Old Port"}
+	c := Ctl{cfg: cfg, buses: busesFake{nearest: []buses.Bus{
+		// about 222 meters north, at 10 m/s.
+		{ID: "far", Route: route, Position: geo.Point{Lat: here.Lat + 0.002, Lon: here.Lon}, Moving: true, Speed: 10},
+		// about 111 meters north, stuck in traffic.
+		{ID: "stuck", Route: route, Position: geo.Point{Lat: here.Lat + 0.001, Lon: here.Lon}, Moving: true, Speed: 0.5},
+		{ID: "farthest", Route: route, Position: geo.Point{Lat: here.Lat + 0.003, Lon: here.Lon}, Moving: true, Speed: 10},
+	}}}
+
+	resp, err := c.ApproachingBuses(context.Background(), models.ReqApproachingBuses{
+		Latitude:  here.Lat,
+		Longitude: here.Lon,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Buses, 2)
+
+	require.Equal(t, "stuck", resp.Buses[0].BusID)
+	require.Equal(t, "30", resp.Buses[0].Route)
+	require.InDelta(t, 111, resp.Buses[0].DistanceMeters, 1)
+	require.Zero(t, resp.Buses[0].ETA)
+
+	require.Equal(t, "far", resp.Buses[1].BusID)
+	require.InDelta(t, 22*time.Second, resp.Buses[1].ETA, float64(time.Second))
+	require.True(t, resp.Buses[1].RoughETA, "there are no stops")
+}
+
+func TestCtl_ApproachingBuses_stopArrivals(t *testing.T) {
+	here := geo.Point{Lat: 34.684422, Lon: 33.037147}
+	route := buses.Route{ID: "10300011", ShortName: "30"}
+	stop := buses.Stop{ID: "B", Name: "Mesa Geitonia", Position: geo.Point{Lat: here.Lat, Lon: here.Lon + 0.001}}
+	fake := busesFake{
+		nearest: []buses.Bus{
+			{ID: "arriving", Route: route, Position: geo.Point{Lat: here.Lat + 0.002, Lon: here.Lon}, Moving: true, Speed: 10},
+			{ID: "untracked", Route: route, Position: geo.Point{Lat: here.Lat + 0.003, Lon: here.Lon}, Moving: true, Speed: 10},
+		},
+		stops: []buses.Stop{stop},
+		arrivals: []buses.Arrival{
+			{Stop: stop, Route: route, BusID: "arriving", At: time.Now().Add(3 * time.Minute)},
+			{Stop: stop, Route: route, At: time.Now().Add(5 * time.Minute)},
+			// the next trip of the same bus.
+			{Stop: stop, Route: route, BusID: "arriving", At: time.Now().Add(40 * time.Minute)},
+		},
+	}
+	c := Ctl{cfg: DefaultConfig(), buses: fake}
+
+	resp, err := c.ApproachingBuses(context.Background(), models.ReqApproachingBuses{
+		Latitude:  here.Lat,
+		Longitude: here.Lon,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Buses, 2)
+	require.Equal(t, "arriving", resp.Buses[0].BusID)
+	require.InDelta(t, 3*time.Minute, resp.Buses[0].ETA, float64(time.Second), "the soonest arrival")
+	require.False(t, resp.Buses[0].RoughETA)
+	require.Equal(t, "Mesa Geitonia", resp.Buses[0].Stop)
+	require.True(t, resp.Buses[1].RoughETA, "the bus does not arrive at the stop")
+
+	fake.stops[0].Position = geo.Point{Lat: here.Lat, Lon: here.Lon + 0.01}
+	resp, err = c.ApproachingBuses(context.Background(), models.ReqApproachingBuses{
+		Latitude:  here.Lat,
+		Longitude: here.Lon,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Buses[0].RoughETA, "the stop is farther than the radius")
+}
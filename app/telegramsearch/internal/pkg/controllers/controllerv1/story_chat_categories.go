@@ -0,0 +1,161 @@
+package controllerv1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// SetChatCategories tags the chat with topics users can choose from with /chats.
+func (c *Ctl) SetChatCategories(ctx context.Context, req models.ReqSetChatCategories) (models.RespSetChatCategories, error) {
+	categories := make([]string, 0, len(req.Categories))
+	for _, category := range req.Categories {
+		category = normalizeCategory(category)
+		if category == "" || slices.Contains(categories, category) {
+			continue
+		}
+		categories = append(categories, category)
+	}
+	if _, err := c.storageRW.SetChatCategories(ctx, storagemodels.ReqSetChatCategories{
+		ChatID:     storagemodels.ChatID(req.ChatID),
+		Categories: categories,
+	}); err != nil {
+		return models.RespSetChatCategories{}, err
+	}
+	return models.RespSetChatCategories{Categories: categories}, nil
+}
+
+// ChatCategories lists categories of the tenant's chats and marks the ones the user searches in.
+func (c *Ctl) ChatCategories(ctx context.Context, req models.ReqChatCategories) (models.RespChatCategories, error) {
+	tenant, err := c.tenant(req.Tenant)
+	if err != nil {
+		return models.RespChatCategories{}, err
+	}
+	return c.chatCategories(ctx, tenant, req.UserID)
+}
+
+// ToggleChatCategory adds the category to the user's search or removes it from there.
+// It returns the updated categories.
+func (c *Ctl) ToggleChatCategory(ctx context.Context, req models.ReqToggleChatCategory) (models.RespChatCategories, error) {
+	tenant, err := c.tenant(req.Tenant)
+	if err != nil {
+		return models.RespChatCategories{}, err
+	}
+	chats, err := c.tenantChats(ctx, tenant)
+	if err != nil {
+		return models.RespChatCategories{}, err
+	}
+	category := normalizeCategory(req.Category)
+	if !slices.Contains(categoriesOf(chats), category) {
+		return models.RespChatCategories{}, semerr.NotFound(fmt.Sprintf("category %q not found", req.Category))
+	}
+	if _, err := c.storageRW.ToggleUserCategory(ctx, storagemodels.ReqToggleUserCategory{
+		UserID:   req.UserID,
+		Tenant:   tenant.Name,
+		Category: category,
+	}); err != nil {
+		return models.RespChatCategories{}, err
+	}
+	return c.chatCategories(ctx, tenant, req.UserID)
+}
+
+func (c *Ctl) chatCategories(ctx context.Context, tenant TenantConfig, userID int64) (models.RespChatCategories, error) {
+	chats, err := c.tenantChats(ctx, tenant)
+	if err != nil {
+		return models.RespChatCategories{}, err
+	}
+	selected, err := c.storageRW.FetchUserCategories(ctx, storagemodels.ReqFetchUserCategories{
+		UserID: userID,
+		Tenant: tenant.Name,
+	})
+	if err != nil {
+		return models.RespChatCategories{}, err
+	}
+	all := categoriesOf(chats)
+	resp := models.RespChatCategories{Categories: make([]models.ChatCategory, 0, len(all))}
+	for _, category := range all {
+		resp.Categories = append(resp.Categories, models.ChatCategory{
+			Name:     category,
+			Selected: slices.Contains(selected.Categories, category),
+		})
+	}
+	return resp, nil
+}
+
+// searchChats returns chats to search in for the user.
+// When all is true, there are no restrictions, and chatIDs are empty.
+// Selected categories the tenant's chats do not have anymore are ignored,
+// the user searches in all chats of the tenant when none is left.
+func (c *Ctl) searchChats(ctx context.Context, tenant TenantConfig, userID int64) (chatIDs []string, all bool, err error) {
+	if userID == 0 {
+		// public chats and CLI have no preferences.
+		return tenant.ChatIDs, len(tenant.ChatIDs) == 0, nil
+	}
+	selected, err := c.storageRW.FetchUserCategories(ctx, storagemodels.ReqFetchUserCategories{
+		UserID: userID,
+		Tenant: tenant.Name,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(selected.Categories) == 0 {
+		return tenant.ChatIDs, len(tenant.ChatIDs) == 0, nil
+	}
+	chats, err := c.tenantChats(ctx, tenant)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, chat := range chats {
+		for _, category := range chat.Categories {
+			if slices.Contains(selected.Categories, category) {
+				chatIDs = append(chatIDs, string(chat.ChatID))
+				break
+			}
+		}
+	}
+	if len(chatIDs) == 0 {
+		return tenant.ChatIDs, len(tenant.ChatIDs) == 0, nil
+	}
+	return chatIDs, false, nil
+}
+
+// tenantChats returns chats the tenant searches in.
+func (c *Ctl) tenantChats(ctx context.Context, tenant TenantConfig) ([]storagemodels.Chat, error) {
+	resp, err := c.storageRW.FetchChats(ctx, storagemodels.ReqFetchChats{})
+	if err != nil {
+		return nil, err
+	}
+	if len(tenant.ChatIDs) == 0 {
+		return resp.Chats, nil
+	}
+	chats := make([]storagemodels.Chat, 0, len(tenant.ChatIDs))
+	for _, chat := range resp.Chats {
+		if slices.Contains(tenant.ChatIDs, string(chat.ChatID)) {
+			chats = append(chats, chat)
+		}
+	}
+	return chats, nil
+}
+
+// categoriesOf returns sorted unique categories of the chats.
+func categoriesOf(chats []storagemodels.Chat) []string {
+	var categories []string
+	for _, chat := range chats {
+		for _, category := range chat.Categories {
+			if !slices.Contains(categories, category) {
+				categories = append(categories, category)
+			}
+		}
+	}
+	slices.Sort(categories)
+	return categories
+}
+
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
@@ -0,0 +1,199 @@
+package controllerv1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/samber/lo"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+func (c *Ctl) ListChats(ctx context.Context, _ models.ReqListChats) (models.RespListChats, error) {
+	resp, err := c.storageRW.FetchChats(ctx, storagemodels.ReqFetchChats{})
+	if err != nil {
+		return models.RespListChats{}, err
+	}
+	return models.RespListChats{Chats: lo.Map(resp.Chats, func(item storagemodels.Chat, _ int) models.Chat {
+		return chatFromStorage(item)
+	})}, nil
+}
+
+// ChatStats counts threads and embeddings of every chat.
+func (c *Ctl) ChatStats(ctx context.Context, _ models.ReqChatStats) (models.RespChatStats, error) {
+	resp, err := c.storageRW.FetchChatStats(ctx, storagemodels.ReqFetchChatStats{})
+	if err != nil {
+		return models.RespChatStats{}, err
+	}
+	return models.RespChatStats{Chats: lo.Map(resp.Chats, func(item storagemodels.ChatStats, _ int) models.ChatStats {
+		return models.ChatStats{
+			Chat:           chatFromStorage(item.Chat),
+			Threads:        item.Threads,
+			Embeddings:     item.Embeddings,
+			Filtered:       item.Filtered,
+			DroppedReplies: item.DroppedReplies,
+			LastMessageAt:  item.LastMessageAt,
+		}
+	})}, nil
+}
+
+// FilteredThreads lists the most recent threads the thread filter dropped, to audit the filter.
+func (c *Ctl) FilteredThreads(ctx context.Context, req models.ReqFilteredThreads) (models.RespFilteredThreads, error) {
+	resp, err := c.storageRW.FetchFilteredThreads(ctx, storagemodels.ReqFetchFilteredThreads{
+		ChatID: storagemodels.ChatID(req.ChatID),
+		Limit:  req.Limit,
+	})
+	if err != nil {
+		return models.RespFilteredThreads{}, err
+	}
+	r := models.RespFilteredThreads{Threads: make([]models.FilteredThread, 0, len(resp.Threads))}
+	for _, item := range resp.Threads {
+		var t thread
+		if err := json.Unmarshal(item.Body, &t); err != nil {
+			return r, fmt.Errorf("unmarshal thread %d: %w", item.ThreadID, err)
+		}
+		var starter string
+		if len(t) > 0 {
+			starter = t[0].getText()
+		}
+		r.Threads = append(r.Threads, models.FilteredThread{
+			ThreadID:            item.ThreadID,
+			Reason:              item.FilteredReason,
+			MostRecentMessageAt: item.MostRecentMessageAt,
+			Starter:             starter,
+		})
+	}
+	return r, nil
+}
+
+func chatFromStorage(chat storagemodels.Chat) models.Chat {
+	return models.Chat{
+		ChatID:         string(chat.ChatID),
+		TelegramChatID: chat.TelegramChatID,
+		Locality:       chat.Locality,
+		Categories:     chat.Categories,
+	}
+}
+
+func (c *Ctl) CreateChat(ctx context.Context, req models.ReqCreateChat) (models.RespCreateChat, error) {
+	if req.ChatID == "" {
+		return models.RespCreateChat{}, semerr.InvalidInput("chat ID is required")
+	}
+	if _, err := c.storageRW.CreateChat(ctx, storagemodels.ReqCreateChat{
+		ChatID:         storagemodels.ChatID(req.ChatID),
+		TelegramChatID: req.TelegramChatID,
+		Locality:       req.Locality,
+	}); err != nil {
+		return models.RespCreateChat{}, err
+	}
+	return models.RespCreateChat{}, nil
+}
+
+// UpdateChat renames the chat or changes its metadata.
+// Locality is a part of messages shown with embeddings, they are rendered again,
+// the embeddings and paid summaries are kept.
+func (c *Ctl) UpdateChat(ctx context.Context, req models.ReqUpdateChat) (models.RespUpdateChat, error) {
+	if req.NewChatID != nil && *req.NewChatID == "" {
+		return models.RespUpdateChat{}, semerr.InvalidInput("new chat ID must not be empty")
+	}
+	var newChatID *storagemodels.ChatID
+	if req.NewChatID != nil {
+		if err := c.checkRename(req.ChatID, *req.NewChatID); err != nil {
+			return models.RespUpdateChat{}, err
+		}
+		newChatID = lo.ToPtr(storagemodels.ChatID(*req.NewChatID))
+	}
+	forgotten, err := c.forgottenAuthors(ctx)
+	if err != nil {
+		return models.RespUpdateChat{}, err
+	}
+	chatID := lo.FromPtrOr(req.NewChatID, req.ChatID)
+	resp := models.RespUpdateChat{}
+	if err := c.storageRW.InTx(ctx, func(ctx context.Context) error {
+		if _, err := c.storageRW.UpdateChat(ctx, storagemodels.ReqUpdateChat{
+			ChatID:         storagemodels.ChatID(req.ChatID),
+			NewChatID:      newChatID,
+			TelegramChatID: req.TelegramChatID,
+			Locality:       req.Locality,
+		}); err != nil {
+			return err
+		}
+		if req.Locality == nil {
+			return nil
+		}
+		updated, err := c.renderEmbeddingMessages(ctx, storagemodels.ChatID(chatID), forgotten)
+		if err != nil {
+			return fmt.Errorf("cannot update embeddings: %w", err)
+		}
+		resp.EmbeddingsUpdated = updated
+		return nil
+	}); err != nil {
+		return models.RespUpdateChat{}, err
+	}
+	// cached answers refer to the chat by its ID, and link its messages.
+	if err := c.invalidateAnswers(ctx, []string{req.ChatID, chatID}); err != nil {
+		return models.RespUpdateChat{}, err
+	}
+	return resp, nil
+}
+
+// checkRename rejects renaming a chat a tenant searches in, it would silently drop out of the tenant.
+// The new ID is added to chat_ids of the tenant first, the old one can be removed after the rename.
+func (c *Ctl) checkRename(chatID, newChatID string) error {
+	for _, tenant := range c.cfg.Tenants {
+		if slices.Contains(tenant.ChatIDs, chatID) && !slices.Contains(tenant.ChatIDs, newChatID) {
+			return semerr.FailedPrecondition(fmt.Sprintf(
+				"chat %q is in chat_ids of tenant %q, add %q there before renaming", chatID, tenant.Name, newChatID,
+			))
+		}
+	}
+	return nil
+}
+
+// renderEmbeddingMessages renders messages of the embedded threads of the chat with its current locality.
+func (c *Ctl) renderEmbeddingMessages(ctx context.Context, chatID storagemodels.ChatID, forgotten authorKeys) (int, error) {
+	resp, err := c.storageRW.FetchEmbeddedChatThreads(ctx, storagemodels.ReqFetchEmbeddedChatThreads{ChatID: chatID})
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, item := range resp.Threads {
+		var t thread
+		if err := json.Unmarshal(item.Body, &t); err != nil {
+			return 0, fmt.Errorf("unmarshal thread %d: %w", item.ThreadID, err)
+		}
+		t = c.redactThread(t, forgotten)
+		if len(t) == 0 {
+			continue
+		}
+		msg, err := t.ForShowingToTheUser(lo.CoalesceOrEmpty(item.Locality, item.ChatID))
+		if err != nil {
+			return 0, err
+		}
+		if _, err := c.storageRW.UpdateEmbeddingMessage(ctx, storagemodels.ReqUpdateEmbeddingMessage{
+			ThreadID: item.ThreadID,
+			Message:  msg,
+		}); err != nil {
+			return 0, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// DeleteChat deletes the chat with all its threads.
+func (c *Ctl) DeleteChat(ctx context.Context, req models.ReqDeleteChat) (models.RespDeleteChat, error) {
+	if _, err := c.storageRW.DeleteChat(ctx, storagemodels.ReqDeleteChat{
+		ChatID: storagemodels.ChatID(req.ChatID),
+	}); err != nil {
+		return models.RespDeleteChat{}, err
+	}
+	if err := c.invalidateAnswers(ctx, []string{req.ChatID}); err != nil {
+		return models.RespDeleteChat{}, err
+	}
+	return models.RespDeleteChat{}, nil
+}
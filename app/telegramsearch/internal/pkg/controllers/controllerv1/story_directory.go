@@ -0,0 +1,152 @@
+package controllerv1
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaimodels"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/directory"
+	"github.com/yanakipre/bot/internal/logger"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// ExtractEntities extracts businesses, professionals and places from threads not processed yet.
+func (c *Ctl) ExtractEntities(ctx context.Context, req models.ReqExtractEntities) (models.RespExtractEntities, error) {
+	var extracted, failed atomic.Int64
+	processed, err := c.runThreadJob(ctx, threadJob{
+		limit:       req.Limit,
+		concurrency: c.cfg.Directory.Concurrency,
+		fetch: func(ctx context.Context, limit int) ([]storagemodels.ChatThreadToGenerateEmbedding, error) {
+			resp, err := c.storageRW.FetchThreadsToExtractEntities(ctx, storagemodels.ReqFetchThreadsToExtractEntities{
+				Limit: limit,
+			})
+			return resp.Threads, err
+		},
+		process: func(ctx context.Context, item storagemodels.ChatThreadToGenerateEmbedding, t thread) error {
+			entities, ok, err := c.extractEntities(ctx, item, t)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				failed.Add(1)
+			}
+			extracted.Add(int64(len(entities)))
+			return nil
+		},
+	})
+	return models.RespExtractEntities{
+		Threads:  processed,
+		Entities: int(extracted.Load()),
+		Failed:   int(failed.Load()),
+	}, err
+}
+
+// extractEntities saves entities of the thread, ok is false when the response is unparsable.
+// Entities of forgotten authors are not extracted, they are never shown to the model either.
+func (c *Ctl) extractEntities(
+	ctx context.Context,
+	item storagemodels.ChatThreadToGenerateEmbedding,
+	t thread,
+) (entities []directory.Entity, ok bool, err error) {
+	ok = true
+	if len(t) > 0 {
+		msg, err := t.ForShowingToTheUser(lo.CoalesceOrEmpty(item.Locality, item.ChatID))
+		if err != nil {
+			return nil, false, err
+		}
+		completion, err := c.openai.CreateChatCompletion(ctx, openaimodels.ReqCreateChatCompletion{
+			SystemMessages: []string{c.cfg.Directory.Prompt, msg},
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("extract entities of thread %d: %w", item.ThreadID, err)
+		}
+		entities, err = directory.Parse(completion.Response)
+		if err != nil {
+			// the thread is not processed again, the model would fail on it again.
+			logger.Error(ctx, fmt.Errorf("extract entities of thread %d: %w", item.ThreadID, err))
+			ok = false
+		}
+	}
+	if _, err := c.storageRW.SaveThreadEntities(ctx, storagemodels.ReqSaveThreadEntities{
+		ThreadID: item.ThreadID,
+		ChatID:   item.ChatID,
+		Entities: lo.Map(entities, func(e directory.Entity, _ int) storagemodels.ExtractedEntity {
+			return storagemodels.ExtractedEntity{
+				Kind:           string(e.Kind),
+				Category:       e.Category,
+				Name:           e.Name,
+				NormalizedName: e.NormalizedName,
+				Phones:         e.Phones,
+				Addresses:      e.Addresses,
+				Sentiment:      int(e.Sentiment),
+			}
+		}),
+	}); err != nil {
+		return nil, false, err
+	}
+	logger.Debug(ctx, "entities extracted",
+		zap.Int64("thread_id", item.ThreadID),
+		zap.Int("count", len(entities)),
+	)
+	return entities, ok, nil
+}
+
+// TopEntities ranks entities of the category by how often they are recommended.
+func (c *Ctl) TopEntities(ctx context.Context, req models.ReqTopEntities) (models.RespTopEntities, error) {
+	tenant, err := c.tenant(req.Tenant)
+	if err != nil {
+		return models.RespTopEntities{}, err
+	}
+	category, locality := directory.NormalizeCategory(req.Category), req.Locality
+	if req.Query != "" {
+		chats, err := c.tenantChats(ctx, tenant)
+		if err != nil {
+			return models.RespTopEntities{}, err
+		}
+		category, locality = directory.ParseQuery(req.Query, lo.Uniq(lo.Map(chats, func(item storagemodels.Chat, _ int) string {
+			return item.Locality
+		})))
+	}
+	if category == "" {
+		return models.RespTopEntities{}, semerr.InvalidInput("category is required")
+	}
+	resp := models.RespTopEntities{Category: category, Locality: locality}
+	chatIDs, _, err := c.searchChats(ctx, tenant, req.SenderID)
+	if err != nil {
+		return resp, fmt.Errorf("chats to search in: %w", err)
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = 10
+	}
+	found, err := c.storageRW.FetchTopEntities(ctx, storagemodels.ReqFetchTopEntities{
+		Category: category,
+		Locality: locality,
+		ChatIDs:  chatIDs,
+		Limit:    limit,
+	})
+	if err != nil {
+		return resp, err
+	}
+	resp.Entities = lo.Map(found.Entities, func(item storagemodels.DirectoryEntity, _ int) models.DirectoryEntity {
+		return models.DirectoryEntity{
+			Name:            item.Name,
+			Kind:            item.Kind,
+			Category:        item.Category,
+			Phones:          item.Phones,
+			Addresses:       item.Addresses,
+			ChatIDs:         item.ChatIDs,
+			Mentions:        item.Mentions,
+			Positive:        item.Positive,
+			Negative:        item.Negative,
+			LastMentionedAt: item.LastMentionedAt,
+		}
+	})
+	return resp, nil
+}
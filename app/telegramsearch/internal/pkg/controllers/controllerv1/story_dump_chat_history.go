@@ -20,6 +20,8 @@ import (
 )
 
 type TextEntity struct {
+	// Type is "plain", "phone", "email", "mention", etc.
+	Type string `json:"type,omitempty"`
 	Text string `json:"text"`
 }
 
@@ -40,6 +42,10 @@ type serializedChatMessage struct {
 	text         string          `json:"-"`
 	// Reply to some message with ID
 	Reply int64 `json:"reply_to_message_id,omitempty"`
+	// AuthorKey identifies the author when FromId is redacted.
+	AuthorKey string `json:"author_key,omitempty"`
+	// ViaBot is the bot the message was sent with.
+	ViaBot string `json:"via_bot,omitempty"`
 }
 
 func (s *serializedChatMessage) getText() string {
@@ -105,6 +111,27 @@ func processTemplate(data data) (string, error) {
 	return processed.String(), nil
 }
 
+// Date returns when the message was sent.
+func (s *serializedChatMessage) Date() (time.Time, error) {
+	i, err := strconv.ParseInt(s.DateUnix, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse message date: %w", err)
+	}
+	return time.Unix(i, 0), nil
+}
+
+// MostRecentMessageAt returns the date of the last message in the thread.
+func (t thread) MostRecentMessageAt() (time.Time, error) {
+	return t[len(t)-1].Date()
+}
+
+// AuthorKeys returns unique keys of the thread authors.
+func (t thread) AuthorKeys() []string {
+	return lo.Uniq(lo.FilterMap(t, func(item serializedChatMessage, _ int) (string, bool) {
+		return item.AuthorKey, item.AuthorKey != ""
+	}))
+}
+
 func (t thread) ForShowingToTheUser(locality string) (string, error) {
 	i, err := strconv.ParseInt(t[0].DateUnix, 10, 64)
 	if err != nil {
@@ -156,7 +183,7 @@ func findThreads(lg logger.Logger, input []serializedChatMessage) foundThreads {
 }
 
 // high mem consumption path, let gc work
-func (c *Ctl) threadsHighMem(ctx context.Context, req models.ReqDumpChatHistory) (foundThreads, error) {
+func (c *Ctl) threadsHighMem(ctx context.Context, req models.ReqDumpChatHistory) ([]filteredThread, error) {
 	lg := logger.FromContext(ctx)
 	var result results
 
@@ -167,14 +194,16 @@ func (c *Ctl) threadsHighMem(ctx context.Context, req models.ReqDumpChatHistory)
 	msgs := lo.Filter(result.Messages, func(item serializedChatMessage, index int) bool {
 		return item.Type == ChatMessageTypeMessage
 	})
+	forgotten, err := c.forgottenAuthors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get forgotten authors: %w", err)
+	}
+	// replies to removed messages are skipped like replies to deleted ones.
+	msgs = c.redactMessages(msgs, forgotten)
 	threads := lo.Filter(findThreads(lg, msgs), func(item thread, index int) bool {
 		return len(item) > 1 // skip threads of len 1 because no answers means no opinions
 	})
-	r := make(foundThreads, len(threads))
-	for i := range threads {
-		r[i] = threads[i]
-	}
-	return r, nil
+	return c.filterThreads(threads, msgs), nil
 }
 
 func (c *Ctl) DumpChatHistory(ctx context.Context, req models.ReqDumpChatHistory) (models.RespDumpChatHistory, error) {
@@ -186,9 +215,26 @@ func (c *Ctl) DumpChatHistory(ctx context.Context, req models.ReqDumpChatHistory
 	for i := range threads {
 		t := threads[i]
 		p.Go(func(ctx context.Context) error {
-			_, err := c.storageRW.CreateChatThread(ctx, storagemodels.ReqCreateChatThread{
-				ChatID: storagemodels.ChatID(req.ChatID),
-				Body:   t,
+			if t.reason == "" {
+				t.reason = c.classifyThread(ctx, t.thread)
+			}
+			if t.reason != "" {
+				ThreadsFiltered.WithLabelValues(string(t.reason)).Inc()
+			}
+			for reason, n := range t.dropped {
+				RepliesDropped.WithLabelValues(reason).Add(float64(n))
+			}
+			mostRecentMessageAt, err := t.thread.MostRecentMessageAt()
+			if err != nil {
+				return err
+			}
+			_, err = c.storageRW.CreateChatThread(ctx, storagemodels.ReqCreateChatThread{
+				ChatID:              storagemodels.ChatID(req.ChatID),
+				Body:                t.thread,
+				MostRecentMessageAt: mostRecentMessageAt,
+				AuthorKeys:          t.thread.AuthorKeys(),
+				FilteredReason:      string(t.reason),
+				DroppedReplies:      t.dropped,
 			})
 			return err
 		})
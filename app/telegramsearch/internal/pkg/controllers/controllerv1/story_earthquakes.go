@@ -0,0 +1,237 @@
+package controllerv1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/encodingtooling"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/logger"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// EarthquakesConfig configures earthquake alerts. The bot polls the feed, see PollEarthquakes.
+type EarthquakesConfig struct {
+	// PollInterval is how often the feed is polled.
+	PollInterval encodingtooling.Duration `yaml:"poll_interval"`
+	// FeedSize is how many latest earthquakes are fetched from the feed.
+	FeedSize int `yaml:"feed_size"`
+	// MaxAlertAge skips alerts about older earthquakes, e.g. when the feed is polled the first time.
+	MaxAlertAge encodingtooling.Duration `yaml:"max_alert_age"`
+	// DefaultMinMagnitude and DefaultRadiusKm are used when the subscriber does not choose them.
+	DefaultMinMagnitude float32 `yaml:"default_min_magnitude"`
+	DefaultRadiusKm     float32 `yaml:"default_radius_km"`
+	// RecentLimit is how many earthquakes /quakes shows.
+	RecentLimit int `yaml:"recent_limit"`
+}
+
+func DefaultEarthquakesConfig() EarthquakesConfig {
+	return EarthquakesConfig{
+		PollInterval:        encodingtooling.Duration{Duration: time.Minute},
+		FeedSize:            20,
+		MaxAlertAge:         encodingtooling.Duration{Duration: time.Hour},
+		DefaultMinMagnitude: 3,
+		DefaultRadiusKm:     100,
+		RecentLimit:         10,
+	}
+}
+
+func (c *EarthquakesConfig) Validate() error {
+	if c.PollInterval.Duration <= 0 {
+		return errors.New("earthquakes poll interval must be positive")
+	}
+	if c.FeedSize <= 0 {
+		return fmt.Errorf("earthquakes feed size must be positive, got %d", c.FeedSize)
+	}
+	if c.RecentLimit <= 0 {
+		return fmt.Errorf("earthquakes recent limit must be positive, got %d", c.RecentLimit)
+	}
+	return validateEarthquakeSubscription(c.DefaultMinMagnitude, c.DefaultRadiusKm)
+}
+
+// maxRadiusKm is about a half of the Earth circumference, everything is closer.
+const maxRadiusKm = 20000
+
+func validateEarthquakeSubscription(minMagnitude, radiusKm float32) error {
+	if minMagnitude < 0 || minMagnitude > 10 {
+		return semerr.InvalidInput(fmt.Sprintf("magnitude must be from 0 to 10, got %g", minMagnitude))
+	}
+	if radiusKm <= 0 || radiusKm > maxRadiusKm {
+		return semerr.InvalidInput(fmt.Sprintf("radius must be from 0 to %d km, got %g", maxRadiusKm, radiusKm))
+	}
+	return nil
+}
+
+// PollEarthquakes fetches the feed, stores new earthquakes, plans alerts about them
+// for subscribers within the radius, and returns alerts not sent yet.
+// Alerts are returned until they are marked sent, see MarkEarthquakeAlertSent,
+// or the earthquake is older than MaxAlertAge.
+func (c *Ctl) PollEarthquakes(ctx context.Context, _ models.ReqPollEarthquakes) (models.RespPollEarthquakes, error) {
+	latest, err := c.earthquaker.LatestNEarthquakes(ctx, c.cfg.Earthquakes.FeedSize, 0)
+	if err != nil {
+		return models.RespPollEarthquakes{}, fmt.Errorf("fetch earthquakes: %w", err)
+	}
+	saved, err := c.storageRW.SaveEarthquakes(ctx, storagemodels.ReqSaveEarthquakes{
+		Earthquakes: lo.FilterMap(latest, func(item earthquakes.Earthquake, _ int) (storagemodels.Earthquake, bool) {
+			return storagemodels.Earthquake{
+				GUID:       item.GUID,
+				Magnitude:  item.Magnitude,
+				HappenedAt: item.When,
+				Location:   item.Location,
+				Latitude:   item.Position.Lat,
+				Longitude:  item.Position.Lon,
+				SourceRank: item.SourceRank,
+			}, item.GUID != ""
+		}),
+	})
+	if err != nil {
+		return models.RespPollEarthquakes{}, err
+	}
+	since := time.Now().Add(-c.cfg.Earthquakes.MaxAlertAge.Duration)
+	// earthquakes not planned because of a failure are planned by the next poll.
+	toAlert, err := c.storageRW.FetchEarthquakesToAlert(ctx, storagemodels.ReqFetchEarthquakesToAlert{Since: since})
+	if err != nil {
+		return models.RespPollEarthquakes{}, err
+	}
+	for _, e := range toAlert.Earthquakes {
+		if err := c.planEarthquakeAlerts(ctx, e); err != nil {
+			return models.RespPollEarthquakes{}, fmt.Errorf("plan alerts about %q: %w", e.GUID, err)
+		}
+	}
+	unsent, err := c.storageRW.FetchUnsentEarthquakeAlerts(ctx, storagemodels.ReqFetchUnsentEarthquakeAlerts{Since: since})
+	if err != nil {
+		return models.RespPollEarthquakes{}, err
+	}
+	resp := models.RespPollEarthquakes{
+		New: len(saved.New),
+		Alerts: lo.Map(unsent.Alerts, func(item storagemodels.EarthquakeAlert, _ int) models.EarthquakeAlert {
+			return models.EarthquakeAlert{
+				TelegramUserID: item.TelegramUserID,
+				Tenant:         item.Tenant,
+				Earthquake:     toEarthquake(item.Earthquake),
+				DistanceKm:     item.DistanceKm,
+			}
+		}),
+	}
+	logger.Info(ctx, "earthquakes polled",
+		zap.Int("new", resp.New),
+		zap.Int("replaced", len(saved.Replaced)),
+		zap.Int("alerts", len(resp.Alerts)),
+	)
+	return resp, nil
+}
+
+// planEarthquakeAlerts stores alerts to subscribers within the radius of the earthquake.
+func (c *Ctl) planEarthquakeAlerts(ctx context.Context, e storagemodels.Earthquake) error {
+	subscriptions, err := c.storageRW.FetchEarthquakeSubscriptions(ctx, storagemodels.ReqFetchEarthquakeSubscriptions{
+		Magnitude: e.Magnitude,
+	})
+	if err != nil {
+		return err
+	}
+	epicenter := geo.Point{Lat: e.Latitude, Lon: e.Longitude}
+	var alerts []storagemodels.PlannedEarthquakeAlert
+	for _, s := range subscriptions.Subscriptions {
+		distance := earthquakes.DistanceKm(epicenter, geo.Point{Lat: s.Latitude, Lon: s.Longitude})
+		if distance > float64(s.RadiusKm) {
+			continue
+		}
+		alerts = append(alerts, storagemodels.PlannedEarthquakeAlert{
+			TelegramUserID: s.TelegramUserID,
+			Tenant:         s.Tenant,
+			DistanceKm:     distance,
+		})
+	}
+	_, err = c.storageRW.PlanEarthquakeAlerts(ctx, storagemodels.ReqPlanEarthquakeAlerts{GUID: e.GUID, Alerts: alerts})
+	return err
+}
+
+// MarkEarthquakeAlertSent stops returning the alert from PollEarthquakes.
+func (c *Ctl) MarkEarthquakeAlertSent(ctx context.Context, req models.ReqMarkEarthquakeAlertSent) (models.RespMarkEarthquakeAlertSent, error) {
+	_, err := c.storageRW.MarkEarthquakeAlertSent(ctx, storagemodels.ReqMarkEarthquakeAlertSent{
+		GUID:           req.GUID,
+		TelegramUserID: req.TelegramUserID,
+		Tenant:         req.Tenant,
+	})
+	return models.RespMarkEarthquakeAlertSent{}, err
+}
+
+// RecentEarthquakes returns the latest polled earthquakes first.
+func (c *Ctl) RecentEarthquakes(ctx context.Context, req models.ReqRecentEarthquakes) (models.RespRecentEarthquakes, error) {
+	recent, err := c.storageRW.FetchRecentEarthquakes(ctx, storagemodels.ReqFetchRecentEarthquakes{
+		Limit: lo.CoalesceOrEmpty(req.Limit, c.cfg.Earthquakes.RecentLimit),
+	})
+	if err != nil {
+		return models.RespRecentEarthquakes{}, err
+	}
+	return models.RespRecentEarthquakes{Earthquakes: lo.Map(recent.Earthquakes, func(item storagemodels.Earthquake, _ int) models.Earthquake {
+		return toEarthquake(item)
+	})}, nil
+}
+
+func toEarthquake(e storagemodels.Earthquake) models.Earthquake {
+	return models.Earthquake{
+		GUID:       e.GUID,
+		Magnitude:  e.Magnitude,
+		HappenedAt: e.HappenedAt,
+		Location:   e.Location,
+		Latitude:   e.Latitude,
+		Longitude:  e.Longitude,
+	}
+}
+
+// SubscribeEarthquakes replaces the subscription of the user.
+// Alerts are sent once the location is shared, see LocateEarthquakeSubscription.
+func (c *Ctl) SubscribeEarthquakes(ctx context.Context, req models.ReqSubscribeEarthquakes) (models.RespSubscribeEarthquakes, error) {
+	minMagnitude := lo.CoalesceOrEmpty(req.MinMagnitude, c.cfg.Earthquakes.DefaultMinMagnitude)
+	radiusKm := lo.CoalesceOrEmpty(req.RadiusKm, c.cfg.Earthquakes.DefaultRadiusKm)
+	if err := validateEarthquakeSubscription(minMagnitude, radiusKm); err != nil {
+		return models.RespSubscribeEarthquakes{}, err
+	}
+	if _, err := c.storageRW.UpsertEarthquakeSubscription(ctx, storagemodels.ReqUpsertEarthquakeSubscription{
+		TelegramUserID: req.TelegramUserID,
+		Tenant:         req.Tenant,
+		MinMagnitude:   minMagnitude,
+		RadiusKm:       radiusKm,
+	}); err != nil {
+		return models.RespSubscribeEarthquakes{}, err
+	}
+	return models.RespSubscribeEarthquakes{MinMagnitude: minMagnitude, RadiusKm: radiusKm}, nil
+}
+
+// LocateEarthquakeSubscription activates the subscription waiting for the location.
+// It returns semerr.NotFound when there is no such subscription, the location is meant for something else then.
+func (c *Ctl) LocateEarthquakeSubscription(
+	ctx context.Context,
+	req models.ReqLocateEarthquakeSubscription,
+) (models.RespLocateEarthquakeSubscription, error) {
+	located, err := c.storageRW.LocateEarthquakeSubscription(ctx, storagemodels.ReqLocateEarthquakeSubscription{
+		TelegramUserID: req.TelegramUserID,
+		Tenant:         req.Tenant,
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+	})
+	if err != nil {
+		return models.RespLocateEarthquakeSubscription{}, err
+	}
+	return models.RespLocateEarthquakeSubscription{
+		MinMagnitude: located.Subscription.MinMagnitude,
+		RadiusKm:     located.Subscription.RadiusKm,
+	}, nil
+}
+
+func (c *Ctl) UnsubscribeEarthquakes(ctx context.Context, req models.ReqUnsubscribeEarthquakes) (models.RespUnsubscribeEarthquakes, error) {
+	_, err := c.storageRW.DeleteEarthquakeSubscription(ctx, storagemodels.ReqDeleteEarthquakeSubscription{
+		TelegramUserID: req.TelegramUserID,
+		Tenant:         req.Tenant,
+	})
+	return models.RespUnsubscribeEarthquakes{}, err
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaimodels"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
 	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/prompts"
 	"slices"
 	"strings"
 	"sync"
@@ -20,15 +21,63 @@ import (
 	"go.uber.org/zap"
 )
 
+// TryCompletion answers the question from the conversations similar to it.
 func (c *Ctl) TryCompletion(ctx context.Context, req models.ReqTryCompletion) (models.RespTryCompletion, error) {
-	logger.Info(ctx, "user asked for completion", zap.String("q", req.Query))
+	if req.OnDelta == nil {
+		return c.tryCompletion(ctx, req)
+	}
+	var streamed strings.Builder
+	onDelta := req.OnDelta
+	req.OnDelta = func(text string) {
+		streamed.WriteString(text)
+		onDelta(text)
+	}
+	resp, err := c.tryCompletion(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	// cached answers, answers without results and notes are not generated, stream what is left.
+	if rest, ok := strings.CutPrefix(resp.Response, streamed.String()); ok && rest != "" {
+		onDelta(rest)
+	}
+	return resp, nil
+}
 
-	queryResponse, err := c.openai.CreateEmbeddings(ctx, openaimodels.ReqCreateEmbeddings{
-		Input: []string{req.Query},
-	})
+func (c *Ctl) tryCompletion(ctx context.Context, req models.ReqTryCompletion) (models.RespTryCompletion, error) {
+	logger.Info(ctx, "user asked for completion", zap.String("q", req.Query), zap.String("tenant", req.Tenant))
+	tenant, err := c.tenant(req.Tenant)
+	if err != nil {
+		return models.RespTryCompletion{}, err
+	}
+	chatIDs, allChats, err := c.searchChats(ctx, tenant, int64(req.SenderID))
+	if err != nil {
+		return models.RespTryCompletion{}, fmt.Errorf("chats to search in: %w", err)
+	}
+
+	queryEmbedding, err := c.createEmbedding(ctx, req.Query)
 	if err != nil {
 		return models.RespTryCompletion{}, fmt.Errorf("create embeddings: %w", err)
 	}
+	scope := newAnswerScope(tenant.Name, chatIDs, allChats, c.prompts.Version(int64(req.SenderID)))
+	if !req.NoCache {
+		if cached, ok := c.cachedAnswer(ctx, scope, queryEmbedding); ok {
+			logger.Info(ctx, "answer cache hit", zap.String("prompt_version", scope.promptVersion))
+			sources, err := c.sourcedMessages(cached.UsedConversations)
+			if err != nil {
+				logger.Error(ctx, fmt.Errorf("failed to get sources: %w", err))
+			}
+			c.saveCacheItem(req.SenderID, sources)
+			stale, _ := c.onlyStale(time.Now(), cached.UsedConversations)
+			return models.RespTryCompletion{
+				Response:          cached.Response,
+				UsedConversations: cached.UsedConversations,
+				PromptVersion:     scope.promptVersion,
+				Cached:            true,
+				Stale:             stale,
+				Sources:           answerSources(sources),
+			}, nil
+		}
+	}
 	ctxWithCancel, cancel := context.WithCancel(ctx)
 	defer cancel()
 	p := pool.New().WithContext(ctxWithCancel).WithCancelOnError()
@@ -56,7 +105,8 @@ func (c *Ctl) TryCompletion(ctx context.Context, req models.ReqTryCompletion) (m
 		p.Go(func(ctx context.Context) error {
 			search, err := c.storageRW.FetchSimilaritySearch(ctx, storagemodels.ReqSimilaritySearch{
 				CutThreshold: 0.5, // empirical value
-				Embedding:    queryResponse.Embeddings[0].Embedding,
+				ChatIDs:      chatIDs,
+				Embedding:    queryEmbedding,
 				Since:        l,
 				UpTo:         r,
 				Limit:        20,
@@ -101,21 +151,29 @@ func (c *Ctl) TryCompletion(ctx context.Context, req models.ReqTryCompletion) (m
 		return int(a.MostRecentMessageAt.Sub(b.MostRecentMessageAt).Nanoseconds())
 	})
 	slices.Reverse(searchResults)
-	completion, err := c.openai.CreateChatCompletion(ctx, openaimodels.ReqCreateChatCompletion{
-		Input: req.Query,
+	prompt, err := c.prompts.Render(int64(req.SenderID), prompts.Vars{
+		Locality:       tenant.Locality,
+		DoNotHighlight: tenant.DoNotHighlight,
+		Language:       tenant.Language,
+		Date:           now.Format(time.DateOnly),
+		Question:       c.redactor.Text(req.Query),
 		Conversations: lo.Map(searchResults, func(item storagemodels.RespSimilaritySearch, _ int) string {
-			logger.Info(ctx, "used for response", zap.String("thread", item.Message))
-			return item.Message
+			// summaries are shorter and have less noise.
+			conversation := lo.CoalesceOrEmpty(item.Summary, item.Message)
+			logger.Info(ctx, "used for response", zap.String("thread", conversation))
+			// threads stored before redaction was configured.
+			return c.redactor.Text(conversation)
 		}),
 	})
-	onlyStaleResponses := true
-	notStaleAfter := now.Add(-1 * c.cfg.StaleThreshold.Duration)
-	for i := range searchResults {
-		if searchResults[i].MostRecentMessageAt.After(notStaleAfter) {
-			onlyStaleResponses = false
-			break
-		}
+	if err != nil {
+		return models.RespTryCompletion{}, fmt.Errorf("render prompt: %w", err)
 	}
+	completion, err := c.openai.CreateChatCompletion(ctx, openaimodels.ReqCreateChatCompletion{
+		SystemMessages: []string{prompt.System, prompt.Context},
+		OnDelta:        req.OnDelta,
+	})
+	logger.Info(ctx, "completion created", zap.String("prompt_version", prompt.Version), zap.Error(err))
+	onlyStaleResponses, notStaleAfter := c.onlyStale(now, searchResults)
 	userResponse := completion.Response
 	if onlyStaleResponses {
 		userResponse = completion.Response + "\n" + fmt.Sprintf(c.cfg.StaleResponsesText, notStaleAfter.Format(time.DateOnly))
@@ -127,27 +185,58 @@ func (c *Ctl) TryCompletion(ctx context.Context, req models.ReqTryCompletion) (m
 	}
 
 	// update cache
-	if err := c.saveCacheItem(req.SenderID, searchResults); err != nil {
-		logger.Error(ctx, fmt.Errorf("failed to save cache item: %w", err))
+	sources, err := c.sourcedMessages(searchResults)
+	if err != nil {
+		logger.Error(ctx, fmt.Errorf("failed to get sources: %w", err))
+	}
+	c.saveCacheItem(req.SenderID, sources)
+	if !req.NoCache {
+		c.cacheAnswer(ctx, scope, queryEmbedding, userResponse, searchResults)
 	}
 
 	return models.RespTryCompletion{
 		Response:          userResponse,
 		UsedConversations: searchResults,
+		PromptVersion:     prompt.Version,
+		Stale:             onlyStaleResponses,
+		Sources:           answerSources(sources),
 	}, nil
 }
 
-func (c *Ctl) saveCacheItem(senderID int, conversations []storagemodels.RespSimilaritySearch) error {
-	cacheItem := ExplainedMessage{
-		Sources: make([]SourcedMessage, 0, len(conversations)),
+// onlyStale reports whether all the conversations are older than the stale threshold,
+// and when the threshold is.
+func (c *Ctl) onlyStale(now time.Time, conversations []storagemodels.RespSimilaritySearch) (bool, time.Time) {
+	notStaleAfter := now.Add(-1 * c.cfg.StaleThreshold.Duration)
+	for i := range conversations {
+		if conversations[i].MostRecentMessageAt.After(notStaleAfter) {
+			return false, notStaleAfter
+		}
 	}
+	return true, notStaleAfter
+}
+
+func answerSources(sources []SourcedMessage) []models.AnswerSource {
+	return lo.Map(sources, func(item SourcedMessage, _ int) models.AnswerSource {
+		return models.AnswerSource{
+			URL:                 item.URL,
+			MostRecentMessageAt: item.MostRecentMessageAt,
+			Snippet:             item.FirstLetters,
+		}
+	})
+}
+
+func (c *Ctl) saveCacheItem(senderID int, sources []SourcedMessage) {
+	logger.Warn(context.Background(), "saving cache item", zap.Int("count", len(sources)))
+	c.explainedMessagesCache.Set(senderID, ExplainedMessage{Sources: sources}, ttlcache.DefaultTTL)
+}
 
+// sourcedMessages links the first conversations.
+func (c *Ctl) sourcedMessages(conversations []storagemodels.RespSimilaritySearch) ([]SourcedMessage, error) {
 	limit := 15
 	if len(conversations) < limit {
 		limit = len(conversations)
 	}
-	logger.Warn(context.Background(), "saving cache item", zap.Int("count", limit), zap.Int("len", len(conversations)))
-
+	sources := make([]SourcedMessage, 0, limit)
 	for _, conv := range conversations[:limit] {
 		// to get the first letters from the Message
 		// extract at least 40 symbols,
@@ -156,9 +245,9 @@ func (c *Ctl) saveCacheItem(senderID int, conversations []storagemodels.RespSimi
 		var s []serializedChatMessage
 		err := json.Unmarshal([]byte(conv.ConversationStarter), &s)
 		if err != nil {
-			return fmt.Errorf("failed to unmarshal conversation starter: %w", err)
+			return nil, fmt.Errorf("failed to unmarshal conversation starter: %w", err)
 		}
-		firstLetters := s[0].getText()
+		firstLetters := c.redactor.Text(s[0].getText())
 		if len(firstLetters) > 60 {
 			lastDot := strings.Index(firstLetters, ".")
 			if lastDot != -1 {
@@ -168,12 +257,11 @@ func (c *Ctl) saveCacheItem(senderID int, conversations []storagemodels.RespSimi
 		if len(firstLetters) > 120 {
 			firstLetters = firstLetters[:100]
 		}
-		cacheItem.Sources = append(cacheItem.Sources, SourcedMessage{
-			URL:                 fmt.Sprintf("https://t.me/%s/%d", conv.TelegramChatID, s[0].ID),
+		sources = append(sources, SourcedMessage{
+			URL:                 messageURL(conv.TelegramChatID, s[0].ID),
 			MostRecentMessageAt: conv.MostRecentMessageAt,
 			FirstLetters:        firstLetters,
 		})
 	}
-	c.explainedMessagesCache.Set(senderID, cacheItem, ttlcache.DefaultTTL)
-	return nil
+	return sources, nil
 }
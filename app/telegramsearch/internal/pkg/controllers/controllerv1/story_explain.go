@@ -31,6 +31,11 @@ func (e ExplainedMessage) ForUser() string {
 	)
 }
 
+// messageURL links to the message in Telegram.
+func messageURL(telegramChatID string, messageID int64) string {
+	return fmt.Sprintf("https://t.me/%s/%d", telegramChatID, messageID)
+}
+
 type SourcedMessage struct {
 	URL                 string
 	MostRecentMessageAt time.Time
@@ -0,0 +1,133 @@
+package controllerv1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+// ForgetAuthor removes every stored message of the Telegram user.
+// Threads started by the user, or left without answers, are deleted.
+// Other threads are rewritten without the user's messages and their embeddings are generated again.
+// The request is written to the audit log, and the user's messages are never stored again.
+func (c *Ctl) ForgetAuthor(ctx context.Context, req models.ReqForgetAuthor) (models.RespForgetAuthor, error) {
+	if req.TelegramUserID == 0 {
+		return models.RespForgetAuthor{}, fmt.Errorf("telegram user id is required")
+	}
+	if req.RequestedBy == "" {
+		return models.RespForgetAuthor{}, fmt.Errorf("requested by is required")
+	}
+	fromID := fmt.Sprintf("user%d", req.TelegramUserID)
+	authorKey := c.redactor.AuthorKey(fromID)
+
+	found, err := c.storageRW.FetchAuthorThreads(ctx, storagemodels.ReqFetchAuthorThreads{
+		AuthorKey: authorKey,
+		FromID:    fromID,
+	})
+	if err != nil {
+		return models.RespForgetAuthor{}, fmt.Errorf("fetch author threads: %w", err)
+	}
+	var resp models.RespForgetAuthor
+	var toDelete []int64
+	var toUpdate []storagemodels.ReqUpdateChatThread
+	toRegenerate := make([]storagemodels.ChatThreadToGenerateEmbedding, 0, len(found.Threads))
+	for _, stored := range found.Threads {
+		var t thread
+		if err := json.Unmarshal(stored.Body, &t); err != nil {
+			return resp, fmt.Errorf("unmarshal thread %d: %w", stored.ThreadID, err)
+		}
+		left := make(thread, 0, len(t))
+		for _, m := range t {
+			if m.AuthorKey == authorKey || m.FromId == fromID {
+				continue
+			}
+			left = append(left, m)
+		}
+		if len(left) < 2 || left[0].ID != t[0].ID {
+			toDelete = append(toDelete, stored.ThreadID)
+			continue
+		}
+		mostRecentMessageAt, err := left.MostRecentMessageAt()
+		if err != nil {
+			return resp, err
+		}
+		toUpdate = append(toUpdate, storagemodels.ReqUpdateChatThread{
+			ThreadID:            stored.ThreadID,
+			Body:                left,
+			MostRecentMessageAt: mostRecentMessageAt,
+			RemovedAuthorKey:    authorKey,
+		})
+		body, err := json.Marshal(left)
+		if err != nil {
+			return resp, err
+		}
+		stored.Body = body
+		if stored.FilteredReason != "" {
+			// filtered threads have no embeddings.
+			continue
+		}
+		toRegenerate = append(toRegenerate, stored)
+	}
+
+	// the author's data is removed at once, or stays for the request to be repeated.
+	err = c.storageRW.InTx(ctx, func(ctx context.Context) error {
+		for _, threadID := range toDelete {
+			if _, err := c.storageRW.DeleteChatThread(ctx, storagemodels.ReqDeleteChatThread{
+				ThreadID: threadID,
+			}); err != nil {
+				return fmt.Errorf("delete thread %d: %w", threadID, err)
+			}
+		}
+		for _, update := range toUpdate {
+			if _, err := c.storageRW.UpdateChatThread(ctx, update); err != nil {
+				return fmt.Errorf("update thread %d: %w", update.ThreadID, err)
+			}
+		}
+		if _, err := c.storageRW.DeleteUserCategories(ctx, storagemodels.ReqDeleteUserCategories{
+			UserID: req.TelegramUserID,
+		}); err != nil {
+			return fmt.Errorf("delete user categories: %w", err)
+		}
+		if _, err := c.storageRW.CreateForgetRequest(ctx, storagemodels.ReqCreateForgetRequest{
+			AuthorKey:      authorKey,
+			RequestedBy:    req.RequestedBy,
+			ThreadsDeleted: len(toDelete),
+			ThreadsUpdated: len(toUpdate),
+		}); err != nil {
+			return fmt.Errorf("write audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+	resp.ThreadsDeleted = len(toDelete)
+	resp.ThreadsUpdated = len(toUpdate)
+
+	// updated threads lost their embeddings, the ones failed here are generated by the next GenerateEmbeddings.
+	forgotten, err := c.forgottenAuthors(ctx)
+	if err != nil {
+		return resp, err
+	}
+	if err := c.generateEmbeddings(ctx, toRegenerate, forgotten); err != nil {
+		return resp, fmt.Errorf("generate embeddings: %w", err)
+	}
+	// answers and explained messages may quote the author.
+	if err := c.invalidateAnswers(ctx, nil); err != nil {
+		return resp, err
+	}
+	c.explainedMessagesCache.DeleteAll()
+
+	logger.Info(ctx, "author forgotten",
+		zap.String("requested_by", req.RequestedBy),
+		zap.Int("threads_deleted", resp.ThreadsDeleted),
+		zap.Int("threads_updated", resp.ThreadsUpdated),
+	)
+	return resp, nil
+}
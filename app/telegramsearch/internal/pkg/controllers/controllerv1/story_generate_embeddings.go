@@ -3,16 +3,20 @@ package controllerv1
 import (
 	"context"
 	"encoding/json"
-	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaimodels"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
 	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
 
+	"github.com/samber/lo"
 	"github.com/sourcegraph/conc/pool"
 	"github.com/yanakipre/bot/internal/logger"
+	"go.uber.org/zap"
 )
 
 func (c *Ctl) GenerateEmbeddings(ctx context.Context, req models.ReqGenerateEmbeddings) (models.RespGenerateEmbeddings, error) {
-	lg := logger.FromContext(ctx)
+	forgotten, err := c.forgottenAuthors(ctx)
+	if err != nil {
+		return models.RespGenerateEmbeddings{}, err
+	}
 	for {
 		threadsToGenerateFrom, err := c.storageRW.FetchChatThreadToGenerateEmbedding(ctx, storagemodels.ReqFetchChatThreadToGenerateEmbedding{})
 		if err != nil {
@@ -21,41 +25,61 @@ func (c *Ctl) GenerateEmbeddings(ctx context.Context, req models.ReqGenerateEmbe
 		if len(threadsToGenerateFrom.Threads) == 0 {
 			break // no more
 		}
-		p := pool.New().WithMaxGoroutines(100).WithContext(ctx)
-		for i := range threadsToGenerateFrom.Threads {
-			proccess := threadsToGenerateFrom.Threads[i]
-			p.Go(func(ctx context.Context) error {
-				var t thread
-				err := json.Unmarshal(proccess.Body, &t)
-				if err != nil {
-					return err
-				}
-				if len(t) < 2 {
-					return nil // we don't want threads without answers
-				}
-				queryResponse, err := c.openai.CreateEmbeddings(ctx, openaimodels.ReqCreateEmbeddings{
-					Input: []string{t.ForEmbedding()},
-				})
-				msg, err := t.ForShowingToTheUser(proccess.ChatID)
-				if err != nil {
-					return err
-				}
-				if len(queryResponse.Embeddings) == 0 {
-					lg.Warn("skipped, no embeddings")
-					return nil
-				}
-				_, err = c.storageRW.UpsertEmbedding(ctx, storagemodels.ReqUpsertEmbedding{
-					Embedding: queryResponse.Embeddings[0].Embedding,
-					Message:   msg,
-					ChatID:    proccess.ChatID,
-					ThreadID:  proccess.ThreadID,
-				})
-				return err
-			})
-		}
-		if err := p.Wait(); err != nil {
+		if err := c.generateEmbeddings(ctx, threadsToGenerateFrom.Threads, forgotten); err != nil {
 			return models.RespGenerateEmbeddings{}, err
 		}
 	}
 	return models.RespGenerateEmbeddings{}, nil
 }
+
+func (c *Ctl) generateEmbeddings(
+	ctx context.Context,
+	threads []storagemodels.ChatThreadToGenerateEmbedding,
+	forgotten authorKeys,
+) error {
+	if len(threads) == 0 {
+		return nil
+	}
+	lg := logger.FromContext(ctx)
+	p := pool.New().WithMaxGoroutines(100).WithContext(ctx)
+	for i := range threads {
+		proccess := threads[i]
+		p.Go(func(ctx context.Context) error {
+			var t thread
+			err := json.Unmarshal(proccess.Body, &t)
+			if err != nil {
+				return err
+			}
+			t = c.redactThread(t, forgotten)
+			if len(t) < 2 {
+				// we don't want threads without answers,
+				// such threads are left only when authors opted out after the thread was stored.
+				lg.Info("thread without answers deleted", zap.Int64("thread_id", proccess.ThreadID))
+				_, err := c.storageRW.DeleteChatThread(ctx, storagemodels.ReqDeleteChatThread{ThreadID: proccess.ThreadID})
+				return err
+			}
+			embedding, err := c.createEmbedding(ctx, t.ForEmbedding())
+			if err != nil {
+				return err
+			}
+			msg, err := t.ForShowingToTheUser(lo.CoalesceOrEmpty(proccess.Locality, proccess.ChatID))
+			if err != nil {
+				return err
+			}
+			_, err = c.storageRW.UpsertEmbedding(ctx, storagemodels.ReqUpsertEmbedding{
+				Embedding: embedding,
+				Message:   msg,
+				ChatID:    proccess.ChatID,
+				ThreadID:  proccess.ThreadID,
+			})
+			return err
+		})
+	}
+	if err := p.Wait(); err != nil {
+		return err
+	}
+	// answers could miss the new threads.
+	return c.invalidateAnswers(ctx, lo.Map(threads, func(item storagemodels.ChatThreadToGenerateEmbedding, _ int) string {
+		return item.ChatID
+	}))
+}
@@ -4,6 +4,11 @@ import (
 	"context"
 )
 
-func (c *Ctl) Help(_ context.Context) string {
-	return c.cfg.HelpText
+// Help returns the text for the tenant, falling back to the global one for unknown tenants.
+func (c *Ctl) Help(_ context.Context, tenant string) string {
+	t, err := c.tenant(tenant)
+	if err != nil {
+		return c.cfg.HelpText
+	}
+	return t.HelpText
 }
@@ -4,6 +4,11 @@ import (
 	"context"
 )
 
-func (c *Ctl) News(_ context.Context) string {
-	return c.cfg.NewsText
+// News returns the text for the tenant, falling back to the global one for unknown tenants.
+func (c *Ctl) News(_ context.Context, tenant string) string {
+	t, err := c.tenant(tenant)
+	if err != nil {
+		return c.cfg.NewsText
+	}
+	return t.NewsText
 }
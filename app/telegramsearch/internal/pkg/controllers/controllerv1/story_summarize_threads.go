@@ -0,0 +1,135 @@
+package controllerv1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaimodels"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+// SummarizationConfig configures "embeddings summarize", a completion and an embedding per thread.
+type SummarizationConfig struct {
+	// Prompt asks for the summary of the conversation given after it.
+	Prompt string `yaml:"prompt"`
+	// Concurrency is how many threads are summarized at once.
+	Concurrency int `yaml:"concurrency"`
+}
+
+func DefaultSummarizationConfig() SummarizationConfig {
+	return SummarizationConfig{
+		Prompt: "You are given a conversation from a chat where people ask each other for advice." +
+			" Write a compact factual summary of it in the language of the conversation." +
+			" Start with what was asked, where and when." +
+			" Keep every named entity: people, businesses, places, addresses, prices and contacts," +
+			" and every recommendation with the date it was given." +
+			" Skip greetings, thanks and messages off topic." +
+			" Do not add anything that is not in the conversation.",
+		Concurrency: 10,
+	}
+}
+
+func (c *SummarizationConfig) Validate() error {
+	if c.Prompt == "" {
+		return errors.New("summarization prompt is required")
+	}
+	if c.Concurrency <= 0 {
+		return fmt.Errorf("summarization concurrency must be positive, got %d", c.Concurrency)
+	}
+	return nil
+}
+
+// SummarizeThreads summarizes embedded threads without a summary,
+// and embeds the summary instead of the thread.
+func (c *Ctl) SummarizeThreads(ctx context.Context, req models.ReqSummarizeThreads) (models.RespSummarizeThreads, error) {
+	var summarized atomic.Int64
+	var mu sync.Mutex
+	changed := map[string]struct{}{}
+	_, err := c.runThreadJob(ctx, threadJob{
+		limit:       req.Limit,
+		concurrency: c.cfg.Summarization.Concurrency,
+		fetch: func(ctx context.Context, limit int) ([]storagemodels.ChatThreadToGenerateEmbedding, error) {
+			resp, err := c.storageRW.FetchThreadsToSummarize(ctx, storagemodels.ReqFetchThreadsToSummarize{Limit: limit})
+			return resp.Threads, err
+		},
+		process: func(ctx context.Context, item storagemodels.ChatThreadToGenerateEmbedding, t thread) error {
+			ok, err := c.summarizeThread(ctx, item, t)
+			if err != nil {
+				return err
+			}
+			if ok {
+				summarized.Add(1)
+			}
+			mu.Lock()
+			changed[item.ChatID] = struct{}{}
+			mu.Unlock()
+			return nil
+		},
+	})
+	resp := models.RespSummarizeThreads{Summarized: int(summarized.Load())}
+	if len(changed) == 0 {
+		return resp, err
+	}
+	// answers are given from summaries now.
+	return resp, errors.Join(err, c.invalidateAnswers(ctx, lo.Keys(changed)))
+}
+
+// summarizeThread embeds the summary of the thread, ok is false when it is deleted instead,
+// as nobody answers in it anymore.
+func (c *Ctl) summarizeThread(
+	ctx context.Context,
+	item storagemodels.ChatThreadToGenerateEmbedding,
+	t thread,
+) (ok bool, err error) {
+	if len(t) < 2 {
+		// authors opted out after the thread was embedded.
+		logger.Info(ctx, "thread without answers deleted", zap.Int64("thread_id", item.ThreadID))
+		_, err := c.storageRW.DeleteChatThread(ctx, storagemodels.ReqDeleteChatThread{ThreadID: item.ThreadID})
+		return false, err
+	}
+	msg, err := t.ForShowingToTheUser(lo.CoalesceOrEmpty(item.Locality, item.ChatID))
+	if err != nil {
+		return false, err
+	}
+	summary, err := c.summarize(ctx, msg)
+	if err != nil {
+		return false, fmt.Errorf("summarize thread %d: %w", item.ThreadID, err)
+	}
+	embedding, err := c.createEmbedding(ctx, summary)
+	if err != nil {
+		return false, err
+	}
+	if _, err := c.storageRW.UpsertEmbedding(ctx, storagemodels.ReqUpsertEmbedding{
+		Embedding: embedding,
+		Message:   msg,
+		Summary:   summary,
+		ChatID:    item.ChatID,
+		ThreadID:  item.ThreadID,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Ctl) summarize(ctx context.Context, msg string) (string, error) {
+	completion, err := c.openai.CreateChatCompletion(ctx, openaimodels.ReqCreateChatCompletion{
+		SystemMessages: []string{c.cfg.Summarization.Prompt, msg},
+	})
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(completion.Response)
+	if summary == "" {
+		return "", errors.New("empty summary")
+	}
+	return summary, nil
+}
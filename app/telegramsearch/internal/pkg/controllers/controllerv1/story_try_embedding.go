@@ -2,27 +2,93 @@ package controllerv1
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaimodels"
+	"fmt"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
 	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
 	"sync"
 	"time"
 
-	"github.com/samber/lo"
 	"github.com/sourcegraph/conc/pool"
 	"github.com/yanakipre/bot/internal/logger"
 	"go.uber.org/zap"
 )
 
 func (c *Ctl) TryEmbedding(ctx context.Context, req models.ReqTryEmbedding) (models.RespTryEmbedding, error) {
-	queryResponse, err := c.openai.CreateEmbeddings(ctx, openaimodels.ReqCreateEmbeddings{
-		Input: []string{req.Input},
-	})
+	tenant, err := c.tenant(req.Tenant)
+	if err != nil {
+		return models.RespTryEmbedding{}, err
+	}
+	chatIDs, ok := tenant.narrowChats(req.ChatIDs)
+	if !ok {
+		// none of the requested chats belongs to the tenant.
+		return models.RespTryEmbedding{}, nil
+	}
+	queryEmbedding, err := c.createEmbedding(ctx, req.Input)
 	if err != nil {
 		return models.RespTryEmbedding{}, err
 	}
 
+	var searchResults []storagemodels.RespSimilaritySearch
+	var hasMore bool
+	if req.Limit > 0 {
+		searchResults, hasMore, err = c.searchPage(ctx, req, chatIDs, queryEmbedding)
+	} else {
+		searchResults, err = c.searchByPeriods(ctx, req, chatIDs, queryEmbedding)
+	}
+	if err != nil {
+		return models.RespTryEmbedding{}, err
+	}
+
+	result := make([]models.EmbeddingResponse, 0, len(searchResults))
+	for _, item := range searchResults {
+		r, err := c.embeddingResponse(item)
+		if err != nil {
+			return models.RespTryEmbedding{}, err
+		}
+		result = append(result, r)
+	}
+	return models.RespTryEmbedding{Result: result, HasMore: hasMore}, nil
+}
+
+// searchPage returns threads ordered by similarity, skipping req.Offset of them.
+func (c *Ctl) searchPage(
+	ctx context.Context,
+	req models.ReqTryEmbedding,
+	chatIDs []string,
+	queryEmbedding []float32,
+) ([]storagemodels.RespSimilaritySearch, bool, error) {
+	until := req.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	search, err := c.storageRW.FetchSimilaritySearch(ctx, storagemodels.ReqSimilaritySearch{
+		ChatIDs:   chatIDs,
+		Embedding: queryEmbedding,
+		Since:     req.Since,
+		UpTo:      until,
+		// one more to know if there is the next page.
+		Limit:  req.Limit + 1,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(search) > req.Limit {
+		return search[:req.Limit], true, nil
+	}
+	return search, false, nil
+}
+
+// searchByPeriods returns the most similar threads of every period,
+// so that recent threads are not crowded out by older ones.
+func (c *Ctl) searchByPeriods(
+	ctx context.Context,
+	req models.ReqTryEmbedding,
+	chatIDs []string,
+	queryEmbedding []float32,
+) ([]storagemodels.RespSimilaritySearch, error) {
 	ctxWithCancel, cancel := context.WithCancel(ctx)
 	defer cancel()
 	p := pool.New().WithContext(ctxWithCancel).WithCancelOnError()
@@ -45,10 +111,20 @@ func (c *Ctl) TryEmbedding(ctx context.Context, req models.ReqTryEmbedding) (mod
 		}
 		l := ranges[i-1]
 		r := ranges[i]
+		if !req.Since.IsZero() && l.Before(req.Since) {
+			l = req.Since
+		}
+		if !req.Until.IsZero() && r.After(req.Until) {
+			r = req.Until
+		}
+		if !l.Before(r) {
+			continue // the period is filtered out
+		}
 		p.Go(func(ctx context.Context) error {
 			search, err := c.storageRW.FetchSimilaritySearch(ctx, storagemodels.ReqSimilaritySearch{
 				CutThreshold: 0.6, // empirical value
-				Embedding:    queryResponse.Embeddings[0].Embedding,
+				ChatIDs:      chatIDs,
+				Embedding:    queryEmbedding,
 				Since:        l,
 				UpTo:         r,
 				Limit:        20,
@@ -77,15 +153,41 @@ func (c *Ctl) TryEmbedding(ctx context.Context, req models.ReqTryEmbedding) (mod
 			return nil
 		})
 	}
-	if err = p.Wait(); err != nil {
-		return models.RespTryEmbedding{}, err
+	if err := p.Wait(); err != nil {
+		return nil, err
 	}
+	return searchResults, nil
+}
 
-	return models.RespTryEmbedding{
-		Result: lo.Map(searchResults, func(item storagemodels.RespSimilaritySearch, _ int) models.EmbeddingResponse {
-			return models.EmbeddingResponse{
-				Text: item.Message,
-			}
-		}),
-	}, nil
+// embeddingResponse adds messages of the thread with links to them.
+func (c *Ctl) embeddingResponse(item storagemodels.RespSimilaritySearch) (models.EmbeddingResponse, error) {
+	var t thread
+	if err := json.Unmarshal([]byte(item.ConversationStarter), &t); err != nil {
+		return models.EmbeddingResponse{}, fmt.Errorf("unmarshal thread %d: %w", item.ThreadID, err)
+	}
+	r := models.EmbeddingResponse{
+		ThreadID:            item.ThreadID,
+		Text:                item.Message,
+		ChatID:              item.ChatID,
+		TelegramChatID:      item.TelegramChatID,
+		MostRecentMessageAt: item.MostRecentMessageAt,
+		Messages:            make([]models.ThreadMessage, 0, len(t)),
+	}
+	if len(t) > 0 {
+		r.URL = messageURL(item.TelegramChatID, t[0].ID)
+	}
+	for i := range t {
+		date, err := t[i].Date()
+		if err != nil {
+			return models.EmbeddingResponse{}, err
+		}
+		r.Messages = append(r.Messages, models.ThreadMessage{
+			ID: t[i].ID,
+			// threads stored before redaction was configured.
+			Text: c.redactor.Text(t[i].getText()),
+			Date: date,
+			URL:  messageURL(item.TelegramChatID, t[i].ID),
+		})
+	}
+	return r, nil
 }
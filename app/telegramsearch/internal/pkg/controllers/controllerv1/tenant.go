@@ -0,0 +1,112 @@
+package controllerv1
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// TenantConfig describes a community served by the same deployment,
+// e.g. Cyprus, Thailand and Georgia, each with its own chats and bot.
+type TenantConfig struct {
+	Name string `yaml:"name"`
+	// ChatIDs are chats.chat_id the tenant searches in.
+	ChatIDs []string `yaml:"chat_ids"`
+	// Locality, DoNotHighlight and Language override the prompts config when set.
+	Locality       string `yaml:"locality"`
+	DoNotHighlight string `yaml:"do_not_highlight"`
+	Language       string `yaml:"language"`
+	// HelpText and NewsText default to the global ones.
+	HelpText string `yaml:"help_text"`
+	NewsText string `yaml:"news_text"`
+}
+
+// narrowChats narrows chats of the tenant down to the requested ones.
+// It returns false when none of the requested chats belongs to the tenant.
+func (t TenantConfig) narrowChats(requested []string) ([]string, bool) {
+	if len(requested) == 0 {
+		return t.ChatIDs, true
+	}
+	if len(t.ChatIDs) == 0 {
+		return requested, true
+	}
+	chatIDs := lo.Intersect(t.ChatIDs, requested)
+	return chatIDs, len(chatIDs) > 0
+}
+
+func (c *Config) Validate() error {
+	seen := make(map[string]struct{}, len(c.Tenants))
+	for _, t := range c.Tenants {
+		if t.Name == "" {
+			return errors.New("tenant name is required")
+		}
+		if _, ok := seen[t.Name]; ok {
+			return fmt.Errorf("duplicate tenant %q", t.Name)
+		}
+		seen[t.Name] = struct{}{}
+		if len(t.ChatIDs) == 0 {
+			return fmt.Errorf("tenant %q has no chat_ids", t.Name)
+		}
+	}
+	if err := c.Summarization.Validate(); err != nil {
+		return err
+	}
+	if err := c.Directory.Validate(); err != nil {
+		return err
+	}
+	if err := c.Earthquakes.Validate(); err != nil {
+		return err
+	}
+	if err := c.Buses.Validate(); err != nil {
+		return err
+	}
+	if err := c.AnswerCache.Validate(); err != nil {
+		return err
+	}
+	if err := c.EmbeddingCache.Validate(); err != nil {
+		return err
+	}
+	return c.Prompts.Validate()
+}
+
+// HasTenant reports whether the tenant is configured. Empty name is the default tenant.
+func (c *Config) HasTenant(name string) bool {
+	if name == "" {
+		return true
+	}
+	for _, t := range c.Tenants {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tenant returns the tenant config with defaults filled in.
+// The default tenant (empty name) searches in all chats.
+func (c *Ctl) tenant(name string) (TenantConfig, error) {
+	t := TenantConfig{}
+	if name != "" {
+		found := false
+		for i := range c.cfg.Tenants {
+			if c.cfg.Tenants[i].Name == name {
+				t = c.cfg.Tenants[i]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return TenantConfig{}, semerr.NotFound(fmt.Sprintf("unknown tenant %q", name))
+		}
+	}
+	if t.HelpText == "" {
+		t.HelpText = c.cfg.HelpText
+	}
+	if t.NewsText == "" {
+		t.NewsText = c.cfg.NewsText
+	}
+	return t, nil
+}
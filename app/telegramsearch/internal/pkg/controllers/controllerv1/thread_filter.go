@@ -0,0 +1,90 @@
+package controllerv1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaimodels"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/threadfilter"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+// filteredThread is a thread with the replies the filter kept.
+// Threads the filter dropped are kept as is with the reason, for audit.
+type filteredThread struct {
+	thread thread
+	reason threadfilter.Reason
+	// dropped counts replies dropped from the kept thread, per reason.
+	dropped map[string]int
+}
+
+func filterMessage(m serializedChatMessage) threadfilter.Message {
+	return threadfilter.Message{
+		AuthorKey: m.AuthorKey,
+		ViaBot:    m.ViaBot != "",
+		Parts: lo.Map(m.TextEntities, func(item TextEntity, _ int) threadfilter.Part {
+			return threadfilter.Part{Type: item.Type, Text: item.Text}
+		}),
+	}
+}
+
+// filterThreads applies heuristics. Authors are judged by all their messages in msgs.
+func (c *Ctl) filterThreads(threads foundThreads, msgs []serializedChatMessage) []filteredThread {
+	spammers := c.threadFilter.Spammers(lo.Map(msgs, func(item serializedChatMessage, _ int) threadfilter.Message {
+		return filterMessage(item)
+	}))
+	r := make([]filteredThread, 0, len(threads))
+	for _, t := range threads {
+		keep, reason := c.threadFilter.Thread(lo.Map(t, func(item serializedChatMessage, _ int) threadfilter.Message {
+			return filterMessage(item)
+		}), spammers)
+		if reason != "" {
+			r = append(r, filteredThread{thread: t, reason: reason})
+			continue
+		}
+		kept := make(thread, 0, len(keep)+1)
+		kept = append(kept, t[0])
+		var dropped map[string]int
+		// keep is ascending.
+		next := 0
+		for i := 1; i < len(t); i++ {
+			if next < len(keep) && keep[next] == i {
+				kept = append(kept, t[i])
+				next++
+				continue
+			}
+			if dropped == nil {
+				dropped = map[string]int{}
+			}
+			dropped[string(c.threadFilter.Reply(filterMessage(t[i]), spammers))]++
+		}
+		r = append(r, filteredThread{thread: kept, dropped: dropped})
+	}
+	return r
+}
+
+// classifyThread asks the LLM whether the thread is spam, when the classifier is enabled.
+// The thread is kept when the LLM fails.
+func (c *Ctl) classifyThread(ctx context.Context, t thread) threadfilter.Reason {
+	cfg := c.threadFilter.Classifier()
+	if !cfg.Enabled {
+		return ""
+	}
+	completion, err := c.openai.CreateChatCompletion(ctx, openaimodels.ReqCreateChatCompletion{
+		SystemMessages: []string{cfg.Prompt, t.ForEmbedding()},
+	})
+	if err != nil {
+		logger.Error(ctx, fmt.Errorf("cannot classify thread: %w", err))
+		return ""
+	}
+	answer := strings.ToLower(strings.TrimSpace(completion.Response))
+	if strings.HasPrefix(answer, strings.ToLower(cfg.SpamAnswer)) {
+		logger.Info(ctx, "thread classified as spam", zap.String("starter", t[0].getText()))
+		return threadfilter.ReasonClassifier
+	}
+	return ""
+}
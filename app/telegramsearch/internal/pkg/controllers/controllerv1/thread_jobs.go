@@ -0,0 +1,72 @@
+package controllerv1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/sourcegraph/conc/pool"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+)
+
+// threadJobBatch is how many threads an offline job fetches at once.
+const threadJobBatch = 500
+
+// threadJob asks the LLM about stored threads offline, see Ctl.runThreadJob.
+type threadJob struct {
+	// limit is how many threads to process, all of them when zero.
+	limit int
+	// concurrency is how many threads are processed at once.
+	concurrency int
+	// fetch returns up to limit threads not processed yet.
+	fetch func(ctx context.Context, limit int) ([]storagemodels.ChatThreadToGenerateEmbedding, error)
+	// process gets the thread with forgotten authors redacted.
+	// It must leave the thread processed, otherwise it is fetched again.
+	process func(ctx context.Context, item storagemodels.ChatThreadToGenerateEmbedding, t thread) error
+}
+
+// runThreadJob processes threads in batches until there are no more or the limit is reached,
+// and returns how many were processed.
+func (c *Ctl) runThreadJob(ctx context.Context, job threadJob) (int, error) {
+	forgotten, err := c.forgottenAuthors(ctx)
+	if err != nil {
+		return 0, err
+	}
+	processed := 0
+	for job.limit == 0 || processed < job.limit {
+		limit := threadJobBatch
+		if job.limit != 0 {
+			limit = min(limit, job.limit-processed)
+		}
+		threads, err := job.fetch(ctx, limit)
+		if err != nil {
+			return processed, err
+		}
+		if len(threads) == 0 {
+			break // no more
+		}
+		var done atomic.Int64
+		p := pool.New().WithMaxGoroutines(job.concurrency).WithContext(ctx)
+		for _, item := range threads {
+			p.Go(func(ctx context.Context) error {
+				var t thread
+				if err := json.Unmarshal(item.Body, &t); err != nil {
+					return fmt.Errorf("unmarshal thread %d: %w", item.ThreadID, err)
+				}
+				if err := job.process(ctx, item, c.redactThread(t, forgotten)); err != nil {
+					return err
+				}
+				done.Add(1)
+				return nil
+			})
+		}
+		err = p.Wait()
+		processed += int(done.Load())
+		if err != nil {
+			return processed, err
+		}
+	}
+	return processed, nil
+}
@@ -0,0 +1,197 @@
+// Package directory extracts businesses, professionals and places recommended in chat threads.
+//
+// The LLM is asked to list entities of the thread as JSON, see Config.Prompt.
+// Parse validates and normalizes the response, so mentions of the same entity are merged.
+package directory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/samber/lo"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/redaction"
+)
+
+type Kind string
+
+const (
+	KindBusiness     Kind = "business"
+	KindProfessional Kind = "professional"
+	KindPlace        Kind = "place"
+)
+
+// Sentiment of the mention: how the entity was recommended.
+type Sentiment int
+
+const (
+	SentimentNegative Sentiment = -1
+	SentimentNeutral  Sentiment = 0
+	SentimentPositive Sentiment = 1
+)
+
+// Config of "directory extract", a completion per thread.
+type Config struct {
+	// Prompt describes the entities to list in the conversation given after it,
+	// and the JSON to answer with, see Parse.
+	Prompt string `yaml:"prompt"`
+	// Concurrency is how many threads entities are extracted from at once.
+	Concurrency int `yaml:"concurrency"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Prompt: "You are given a conversation from a chat where people ask each other for advice." +
+			" List businesses, professionals and places recommended or discussed in it." +
+			` Answer with JSON only: {"entities": [{"kind": "business", "category": "dentist",` +
+			` "name": "Smile Clinic", "phones": ["+357 25 000000"], "addresses": ["Makariou 1, Limassol"],` +
+			` "sentiment": "positive"}]}.` +
+			` kind is one of "business", "professional" or "place".` +
+			" category is what the entity is, one or two lower-case words in English, e.g. dentist, pediatrician, beach." +
+			` sentiment is "positive" when the entity is recommended, "negative" when people warn against it,` +
+			` and "neutral" otherwise.` +
+			" Use only phones and addresses from the conversation, skip redacted ones like [phone]." +
+			` Answer {"entities": []} when there are none.`,
+		Concurrency: 10,
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.Prompt == "" {
+		return errors.New("directory prompt is required")
+	}
+	if c.Concurrency <= 0 {
+		return fmt.Errorf("directory concurrency must be positive, got %d", c.Concurrency)
+	}
+	return nil
+}
+
+// Entity is mentioned in the thread.
+type Entity struct {
+	Kind     Kind
+	Category string
+	Name     string
+	// NormalizedName merges mentions of the same entity, see NormalizeName.
+	NormalizedName string
+	Phones         []string
+	Addresses      []string
+	Sentiment      Sentiment
+}
+
+type rawEntity struct {
+	Kind      string   `json:"kind"`
+	Category  string   `json:"category"`
+	Name      string   `json:"name"`
+	Phones    []string `json:"phones"`
+	Addresses []string `json:"addresses"`
+	Sentiment string   `json:"sentiment"`
+}
+
+// Parse reads entities from the LLM response.
+// Entities without a name or a category, or of unknown kind are skipped.
+// Entities with the same category and normalized name are merged.
+func Parse(response string) ([]Entity, error) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no JSON object in the response: %q", response)
+	}
+	var raw struct {
+		Entities []rawEntity `json:"entities"`
+	}
+	// models wrap JSON in markdown code blocks and text sometimes.
+	if err := json.Unmarshal([]byte(response[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse entities: %w", err)
+	}
+	r := make([]Entity, 0, len(raw.Entities))
+	for _, e := range raw.Entities {
+		entity := Entity{
+			Kind:           Kind(strings.ToLower(strings.TrimSpace(e.Kind))),
+			Category:       NormalizeCategory(e.Category),
+			Name:           strings.Join(strings.Fields(e.Name), " "),
+			NormalizedName: NormalizeName(e.Name),
+			Phones:         cleanValues(e.Phones),
+			Addresses:      cleanValues(e.Addresses),
+			Sentiment:      parseSentiment(e.Sentiment),
+		}
+		if entity.NormalizedName == "" || entity.Category == "" {
+			continue
+		}
+		if entity.Kind != KindBusiness && entity.Kind != KindProfessional && entity.Kind != KindPlace {
+			continue
+		}
+		_, i, found := lo.FindIndexOf(r, func(item Entity) bool {
+			return item.Category == entity.Category && item.NormalizedName == entity.NormalizedName
+		})
+		if !found {
+			r = append(r, entity)
+			continue
+		}
+		r[i].Phones = lo.Uniq(append(r[i].Phones, entity.Phones...))
+		r[i].Addresses = lo.Uniq(append(r[i].Addresses, entity.Addresses...))
+		r[i].Sentiment = mergeSentiment(r[i].Sentiment, entity.Sentiment)
+	}
+	return r, nil
+}
+
+// NormalizeName drops case, punctuation and repeated spaces: "Dr. Smith" and "dr smith" are the same.
+func NormalizeName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// NormalizeCategory lower-cases the category and drops repeated spaces.
+func NormalizeCategory(category string) string {
+	return strings.Join(strings.Fields(strings.ToLower(category)), " ")
+}
+
+func parseSentiment(s string) Sentiment {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "positive":
+		return SentimentPositive
+	case "negative":
+		return SentimentNegative
+	default:
+		return SentimentNeutral
+	}
+}
+
+// mergeSentiment of mentions in the same thread, any warning wins.
+func mergeSentiment(a, b Sentiment) Sentiment {
+	if a == SentimentNegative || b == SentimentNegative {
+		return SentimentNegative
+	}
+	return max(a, b)
+}
+
+// cleanValues drops empty values and values with redacted personal data, e.g. [phone]:
+// threads are redacted when they are loaded, only allowlisted contacts are left in them.
+func cleanValues(values []string) []string {
+	r := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.Join(strings.Fields(v), " ")
+		if v != "" && !redaction.ContainsPlaceholder(v) {
+			r = append(r, v)
+		}
+	}
+	return lo.Uniq(r)
+}
+
+// ParseQuery splits "/top" query into the category and the locality.
+// The last word is the locality when it is one of localities, e.g. "dentist limassol".
+func ParseQuery(query string, localities []string) (category, locality string) {
+	words := strings.Fields(query)
+	if len(words) > 1 {
+		last := words[len(words)-1]
+		for _, l := range localities {
+			if l != "" && strings.EqualFold(l, last) {
+				return NormalizeCategory(strings.Join(words[:len(words)-1], " ")), l
+			}
+		}
+	}
+	return NormalizeCategory(query), ""
+}
@@ -0,0 +1,130 @@
+package directory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/directory"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []directory.Entity
+		wantErr  bool
+	}{
+		{
+			name: "entities in a code block",
+			response: "```json\n" + `{"entities": [
+				{"kind": "business", "category": "Dentist", "name": " Smile  Clinic ", "phones": ["+357 25 000000", ""], "addresses": ["Makariou 1"], "sentiment": "positive"},
+				{"kind": "place", "category": "beach", "name": "Dasoudi", "sentiment": "neutral"}
+			]}` + "\n```",
+			want: []directory.Entity{
+				{
+					Kind:           directory.KindBusiness,
+					Category:       "dentist",
+					Name:           "Smile Clinic",
+					NormalizedName: "smile clinic",
+					Phones:         []string{"+357 25 000000"},
+					Addresses:      []string{"Makariou 1"},
+					Sentiment:      directory.SentimentPositive,
+				},
+				{
+					Kind:           directory.KindPlace,
+					Category:       "beach",
+					Name:           "Dasoudi",
+					NormalizedName: "dasoudi",
+					Phones:         []string{},
+					Addresses:      []string{},
+					Sentiment:      directory.SentimentNeutral,
+				},
+			},
+		},
+		{
+			name: "mentions of the same entity are merged, warnings win",
+			response: `{"entities": [
+				{"kind": "professional", "category": "pediatrician", "name": "Dr. Smith", "phones": ["1"], "sentiment": "positive"},
+				{"kind": "professional", "category": "pediatrician", "name": "dr smith", "phones": ["1", "2"], "sentiment": "negative"}
+			]}`,
+			want: []directory.Entity{{
+				Kind:           directory.KindProfessional,
+				Category:       "pediatrician",
+				Name:           "Dr. Smith",
+				NormalizedName: "dr smith",
+				Phones:         []string{"1", "2"},
+				Addresses:      []string{},
+				Sentiment:      directory.SentimentNegative,
+			}},
+		},
+		{
+			name: "redacted contacts are dropped",
+			response: `{"entities": [
+				{"kind": "business", "category": "plumber", "name": "Pipes",
+				 "phones": ["[phone]", "[phone:kdmaeopb]", "+357 25 000000"], "addresses": ["[username] will show you"]}
+			]}`,
+			want: []directory.Entity{{
+				Kind:           directory.KindBusiness,
+				Category:       "plumber",
+				Name:           "Pipes",
+				NormalizedName: "pipes",
+				Phones:         []string{"+357 25 000000"},
+				Addresses:      []string{},
+				Sentiment:      directory.SentimentNeutral,
+			}},
+		},
+		{
+			name: "invalid entities are skipped",
+			response: `{"entities": [
+				{"kind": "person", "category": "friend", "name": "Bob"},
+				{"kind": "business", "category": "", "name": "Shop"},
+				{"kind": "business", "category": "shop", "name": "..."}
+			]}`,
+			want: []directory.Entity{},
+		},
+		{
+			name:     "no entities",
+			response: `{"entities": []}`,
+			want:     []directory.Entity{},
+		},
+		{
+			name:     "not JSON",
+			response: "I can't find any businesses here.",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := directory.Parse(tt.response)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	localities := []string{"Limassol", "Paphos", ""}
+	tests := []struct {
+		query        string
+		wantCategory string
+		wantLocality string
+	}{
+		{query: "dentist limassol", wantCategory: "dentist", wantLocality: "Limassol"},
+		{query: "Kids Dentist  PAPHOS", wantCategory: "kids dentist", wantLocality: "Paphos"},
+		{query: "dentist", wantCategory: "dentist"},
+		{query: "limassol", wantCategory: "limassol"},
+		{query: "dentist larnaca", wantCategory: "dentist larnaca"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			category, locality := directory.ParseQuery(tt.query, localities)
+			require.Equal(t, tt.wantCategory, category)
+			require.Equal(t, tt.wantLocality, locality)
+		})
+	}
+}
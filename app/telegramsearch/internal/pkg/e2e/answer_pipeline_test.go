@@ -0,0 +1,402 @@
+package e2e
+
+import (
+	"context"
+	_ "embed"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/rekby/fixenv"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/httpopenaiclient"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaifake"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/storagemodels"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/projectpath"
+	"github.com/yanakipre/bot/internal/rdb/rdbtesttooling"
+	"github.com/yanakipre/bot/internal/secret"
+	"github.com/yanakipre/bot/internal/testtooling"
+)
+
+//go:embed fixtures/history.json
+var history []byte
+
+var schemaPath = path.Join(projectpath.RootPath, "app/telegramsearch/telegramsearch-db/db.sql")
+
+// TestMain is required to setup fixenv for the package
+func TestMain(m *testing.M) {
+	var exitCode int
+
+	testtooling.SetNewGlobalLoggerQuietly()
+
+	_, cancel := fixenv.CreateMainTestEnv(nil)
+	defer func() {
+		cancel()
+		os.Exit(exitCode)
+	}()
+
+	exitCode = m.Run()
+}
+
+// fixtureStorage returns storage with empty schema.
+func fixtureStorage(t *testing.T) *postgres.Storage {
+	testtooling.SkipShort(t)
+	if os.Getenv(rdbtesttooling.DsnEnvVar) == "" {
+		t.Skipf("%s with pgvector installed is required", rdbtesttooling.DsnEnvVar)
+	}
+	db := testtooling.FixtureDBWithSchema(fixenv.New(t), schemaPath)
+
+	cfg := postgres.Default()
+	cfg.RDB.DSN = db.DSN()
+	cfg.RDB.SearchPath = "public"
+	storage := postgres.New(cfg)
+	require.NoError(t, storage.Ready(context.Background()))
+	t.Cleanup(func() { _ = storage.Close() })
+	return storage
+}
+
+func fixtureCtl(t *testing.T, fake *openaifake.Server, ctlCfg controllerv1.Config) *controllerv1.Ctl {
+	return fixtureCtlWithEarthquakes(t, fake, ctlCfg, &earthquakesFake{})
+}
+
+func fixtureCtlWithEarthquakes(
+	t *testing.T,
+	fake *openaifake.Server,
+	ctlCfg controllerv1.Config,
+	quakes earthquakes.Earthquaker,
+) *controllerv1.Ctl {
+	storage := fixtureStorage(t)
+	if ctlCfg.Redaction.HashSalt.Unmask() == "" {
+		ctlCfg.Redaction.HashSalt = secret.NewString("salt")
+	}
+
+	cfg := httpopenaiclient.DefaultConfig()
+	// storage keeps 2000 dimensions, small model has less.
+	cfg.EmbeddingConfig.Model = openai.LargeEmbedding3
+	openaiClient := httpopenaiclient.NewClient(cfg.WithHTTPClient(fake.HTTPClient()))
+
+	// buses are not looked up by the pipelines.
+	ctl, err := controllerv1.New(ctlCfg, openaiClient, storage, quakes, nil)
+	require.NoError(t, err)
+	require.NoError(t, ctl.Ready())
+
+	_, err = storage.CreateChat(context.Background(), storagemodels.ReqCreateChat{ChatID: "limassol"})
+	require.NoError(t, err)
+	return ctl
+}
+
+func TestAnswerPipeline(t *testing.T) {
+	const answer = "Говорят, что доктор X лучший педиатр."
+	fake := openaifake.New(openaifake.WithCompletions(openaifake.ScriptedCompletion{
+		Contains: "Doctor X",
+		Response: answer,
+	}))
+	ctl := fixtureCtl(t, fake, controllerv1.DefaultConfig())
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+	// two threads with answers, the one without answers is skipped.
+	require.Len(t, fake.EmbeddingRequests(), 2)
+
+	const senderID = 42
+	completion, err := ctl.TryCompletion(ctx, models.ReqTryCompletion{
+		SenderID: senderID,
+		Query:    "pediatrician in Limassol",
+	})
+	require.NoError(t, err)
+	require.Contains(t, completion.Response, answer)
+	require.Equal(t, "v1", completion.PromptVersion)
+	require.Len(t, completion.UsedConversations, 2)
+	// the answer is stale, because fixture is old enough.
+	require.Contains(t, completion.Response, "В ответе не использовано информации свежее чем от")
+
+	completions := fake.CompletionRequests()
+	require.Len(t, completions, 1)
+	require.Contains(t, completions[0].Messages[1].Content, "Doctor X is the best pediatrician")
+	require.Contains(t, completions[0].Messages[1].Content, "call [phone]", "personal phones are redacted")
+
+	explained, err := ctl.ExplainMessage(ctx, senderID)
+	require.NoError(t, err)
+	require.Contains(t, explained, "https://t.me/empty/1")
+}
+
+func TestAnswerPipeline_tenants(t *testing.T) {
+	fake := openaifake.New()
+	cfg := controllerv1.DefaultConfig()
+	cfg.Tenants = []controllerv1.TenantConfig{
+		{Name: "cyprus", ChatIDs: []string{"limassol"}, Locality: "Limassol"},
+		{Name: "thailand", ChatIDs: []string{"bangkok"}, Locality: "Bangkok", HelpText: "Thailand help"},
+	}
+	ctl := fixtureCtl(t, fake, cfg)
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+
+	const query = "pediatrician in Limassol"
+	found, err := ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Tenant: "cyprus", Input: query})
+	require.NoError(t, err)
+	require.Len(t, found.Result, 2)
+
+	found, err = ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Tenant: "thailand", Input: query})
+	require.NoError(t, err)
+	require.Empty(t, found.Result, "thailand must not see cyprus chats")
+
+	completion, err := ctl.TryCompletion(ctx, models.ReqTryCompletion{Tenant: "thailand", Query: query})
+	require.NoError(t, err)
+	require.Equal(t, cfg.NoResultsAnswer, completion.Response)
+	require.Empty(t, fake.CompletionRequests())
+
+	_, err = ctl.TryCompletion(ctx, models.ReqTryCompletion{Tenant: "cyprus", Query: query})
+	require.NoError(t, err)
+	completions := fake.CompletionRequests()
+	require.Len(t, completions, 1)
+	require.Contains(t, completions[0].Messages[1].Content, "User is definitely asking about Limassol.")
+
+	_, err = ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Tenant: "georgia", Input: query})
+	require.ErrorContains(t, err, `unknown tenant "georgia"`)
+
+	require.Equal(t, "Thailand help", ctl.Help(ctx, "thailand"))
+	require.Equal(t, cfg.HelpText, ctl.Help(ctx, "cyprus"))
+}
+
+func TestChatCategories(t *testing.T) {
+	fake := openaifake.New()
+	cfg := controllerv1.DefaultConfig()
+	cfg.Tenants = []controllerv1.TenantConfig{{Name: "paphos", ChatIDs: []string{"limassol"}}}
+	ctl := fixtureCtl(t, fake, cfg)
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+
+	tagged, err := ctl.SetChatCategories(ctx, models.ReqSetChatCategories{
+		ChatID:     "limassol",
+		Categories: []string{" Medicine", "kids", "medicine"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"medicine", "kids"}, tagged.Categories)
+
+	_, err = ctl.SetChatCategories(ctx, models.ReqSetChatCategories{ChatID: "unknown", Categories: []string{"cars"}})
+	require.ErrorContains(t, err, `chat "unknown" not found`)
+
+	const userID = 42
+	categories, err := ctl.ChatCategories(ctx, models.ReqChatCategories{UserID: userID})
+	require.NoError(t, err)
+	require.Equal(t, []models.ChatCategory{{Name: "kids"}, {Name: "medicine"}}, categories.Categories)
+
+	categories, err = ctl.ToggleChatCategory(ctx, models.ReqToggleChatCategory{UserID: userID, Category: "medicine"})
+	require.NoError(t, err)
+	require.Equal(t, []models.ChatCategory{{Name: "kids"}, {Name: "medicine", Selected: true}}, categories.Categories)
+
+	const query = "pediatrician in Limassol"
+	completion, err := ctl.TryCompletion(ctx, models.ReqTryCompletion{SenderID: userID, Query: query})
+	require.NoError(t, err)
+	require.Len(t, completion.UsedConversations, 2)
+
+	_, err = ctl.ToggleChatCategory(ctx, models.ReqToggleChatCategory{UserID: userID, Category: "cars"})
+	require.ErrorContains(t, err, `category "cars" not found`)
+
+	// the selection is made in the bot of the default tenant, other tenants keep theirs.
+	categories, err = ctl.ChatCategories(ctx, models.ReqChatCategories{Tenant: "paphos", UserID: userID})
+	require.NoError(t, err)
+	require.Equal(t, []models.ChatCategory{{Name: "kids"}, {Name: "medicine"}}, categories.Categories)
+	categories, err = ctl.ToggleChatCategory(ctx, models.ReqToggleChatCategory{Tenant: "paphos", UserID: userID, Category: "kids"})
+	require.NoError(t, err)
+	require.Equal(t, []models.ChatCategory{{Name: "kids", Selected: true}, {Name: "medicine"}}, categories.Categories)
+	categories, err = ctl.ChatCategories(ctx, models.ReqChatCategories{UserID: userID})
+	require.NoError(t, err)
+	require.Equal(t, []models.ChatCategory{{Name: "kids"}, {Name: "medicine", Selected: true}}, categories.Categories)
+
+	// the chat has no categories anymore, the user's selection matches nothing, so all chats are searched.
+	_, err = ctl.SetChatCategories(ctx, models.ReqSetChatCategories{ChatID: "limassol"})
+	require.NoError(t, err)
+	completion, err = ctl.TryCompletion(ctx, models.ReqTryCompletion{SenderID: userID, Query: query, NoCache: true})
+	require.NoError(t, err)
+	require.Len(t, completion.UsedConversations, 2)
+
+	// other users have no preferences and search everywhere.
+	completion, err = ctl.TryCompletion(ctx, models.ReqTryCompletion{SenderID: userID + 1, Query: query, NoCache: true})
+	require.NoError(t, err)
+	require.Len(t, completion.UsedConversations, 2)
+}
+
+func TestRedaction_optedOut(t *testing.T) {
+	fake := openaifake.New()
+	cfg := controllerv1.DefaultConfig()
+	cfg.Redaction.OptedOut = []string{"user2"}
+	ctl := fixtureCtl(t, fake, cfg)
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+
+	// the only answer about the pediatrician is removed, so the thread has no answers.
+	requests := fake.EmbeddingRequests()
+	require.Len(t, requests, 1)
+	require.Contains(t, requests[0].Input[0], "Dasoudi beach")
+}
+
+func TestForgetAuthor(t *testing.T) {
+	fake := openaifake.New()
+	ctl := fixtureCtl(t, fake, controllerv1.DefaultConfig())
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+	require.Len(t, fake.EmbeddingRequests(), 2)
+
+	// user1 started the pediatrician thread and answered about the beach.
+	forgotten, err := ctl.ForgetAuthor(ctx, models.ReqForgetAuthor{TelegramUserID: 1, RequestedBy: "test"})
+	require.NoError(t, err)
+	require.Equal(t, models.RespForgetAuthor{ThreadsDeleted: 2}, forgotten)
+
+	const query = "pediatrician in Limassol"
+	found, err := ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Input: query})
+	require.NoError(t, err)
+	require.Empty(t, found.Result)
+
+	// the history is loaded again, but user1 stays forgotten.
+	_, err = ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+	found, err = ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Input: query})
+	require.NoError(t, err)
+	require.Empty(t, found.Result)
+}
+
+func TestForgetAuthor_answer(t *testing.T) {
+	fake := openaifake.New()
+	ctl := fixtureCtl(t, fake, controllerv1.DefaultConfig())
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+
+	// user2 only answered, the thread is left without answers.
+	forgotten, err := ctl.ForgetAuthor(ctx, models.ReqForgetAuthor{TelegramUserID: 2, RequestedBy: "test"})
+	require.NoError(t, err)
+	require.Equal(t, models.RespForgetAuthor{ThreadsDeleted: 1}, forgotten)
+
+	found, err := ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Input: "pediatrician in Limassol"})
+	require.NoError(t, err)
+	require.Len(t, found.Result, 1)
+}
+
+func TestAnswerCache(t *testing.T) {
+	fake := openaifake.New()
+	cfg := controllerv1.DefaultConfig()
+	ctl := fixtureCtl(t, fake, cfg)
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+
+	const query = "pediatrician in Limassol"
+	first, err := ctl.TryCompletion(ctx, models.ReqTryCompletion{SenderID: 1, Query: query})
+	require.NoError(t, err)
+	require.False(t, first.Cached)
+
+	// another user asks the same question.
+	second, err := ctl.TryCompletion(ctx, models.ReqTryCompletion{SenderID: 2, Query: query})
+	require.NoError(t, err)
+	require.True(t, second.Cached)
+	require.Equal(t, first.Response, second.Response)
+	require.Len(t, second.UsedConversations, len(first.UsedConversations))
+	require.Len(t, fake.CompletionRequests(), 1)
+
+	explained, err := ctl.ExplainMessage(ctx, 2)
+	require.NoError(t, err)
+	require.Contains(t, explained, "https://t.me/empty/1")
+
+	noCache, err := ctl.TryCompletion(ctx, models.ReqTryCompletion{SenderID: 2, Query: query, NoCache: true})
+	require.NoError(t, err)
+	require.False(t, noCache.Cached)
+	require.Len(t, fake.CompletionRequests(), 2)
+
+	// new threads invalidate answers.
+	_, err = ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+	third, err := ctl.TryCompletion(ctx, models.ReqTryCompletion{SenderID: 3, Query: query})
+	require.NoError(t, err)
+	require.False(t, third.Cached)
+	require.Len(t, fake.CompletionRequests(), 3)
+}
+
+func TestEmbeddingCache(t *testing.T) {
+	fake := openaifake.New()
+	ctl := fixtureCtl(t, fake, controllerv1.DefaultConfig())
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+			ChatID:      "limassol",
+			ChatHistory: history,
+		})
+		require.NoError(t, err)
+		_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+		require.NoError(t, err)
+	}
+	// unchanged threads loaded again are not embedded again.
+	require.Len(t, fake.EmbeddingRequests(), 2)
+
+	found, err := ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Input: "pediatrician in Limassol"})
+	require.NoError(t, err)
+	require.NotEmpty(t, found.Result)
+	again, err := ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Input: " Pediatrician  in limassol"})
+	require.NoError(t, err)
+	require.Equal(t, found, again)
+	require.Len(t, fake.EmbeddingRequests(), 3)
+}
@@ -0,0 +1,79 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaifake"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+func TestChats(t *testing.T) {
+	fake := openaifake.New()
+	ctl := fixtureCtl(t, fake, controllerv1.DefaultConfig())
+	ctx := context.Background()
+
+	_, err := ctl.CreateChat(ctx, models.ReqCreateChat{ChatID: "limassol"})
+	require.True(t, semerr.IsAlreadyExists(err), "got %v", err)
+
+	_, err = ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{ChatID: "limassol", ChatHistory: history})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+	require.Len(t, fake.EmbeddingRequests(), 2)
+
+	updated, err := ctl.UpdateChat(ctx, models.ReqUpdateChat{
+		ChatID:         "limassol",
+		NewChatID:      lo.ToPtr("cyprus"),
+		TelegramChatID: lo.ToPtr("limassolmed"),
+		Locality:       lo.ToPtr("Limassol"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.EmbeddingsUpdated)
+
+	stats, err := ctl.ChatStats(ctx, models.ReqChatStats{})
+	require.NoError(t, err)
+	require.Len(t, stats.Chats, 1)
+	require.Equal(t, "cyprus", stats.Chats[0].ChatID)
+	require.Equal(t, 2, stats.Chats[0].Threads, "threads are renamed with the chat")
+	require.Equal(t, 2, stats.Chats[0].Embeddings, "embeddings are kept")
+	require.NotNil(t, stats.Chats[0].LastMessageAt)
+
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+	require.Len(t, fake.EmbeddingRequests(), 2, "nothing to embed again")
+
+	found, err := ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Input: "pediatrician in Limassol", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, found.Result, 2)
+	require.Contains(t, found.Result[0].Text, "в чате про Limassol")
+	require.Equal(t, "cyprus", found.Result[0].ChatID)
+	require.Contains(t, found.Result[0].URL, "https://t.me/limassolmed/")
+
+	_, err = ctl.DeleteChat(ctx, models.ReqDeleteChat{ChatID: "cyprus"})
+	require.NoError(t, err)
+	_, err = ctl.DeleteChat(ctx, models.ReqDeleteChat{ChatID: "cyprus"})
+	require.True(t, semerr.IsNotFound(err), "got %v", err)
+	stats, err = ctl.ChatStats(ctx, models.ReqChatStats{})
+	require.NoError(t, err)
+	require.Empty(t, stats.Chats)
+}
+
+func TestRenameTenantChat(t *testing.T) {
+	cfg := controllerv1.DefaultConfig()
+	cfg.Tenants = []controllerv1.TenantConfig{{Name: "cyprus", ChatIDs: []string{"limassol"}}}
+	ctl := fixtureCtl(t, openaifake.New(), cfg)
+	ctx := context.Background()
+
+	rename := models.ReqUpdateChat{ChatID: "limassol", NewChatID: lo.ToPtr("lemesos")}
+	_, err := ctl.UpdateChat(ctx, rename)
+	require.True(t, semerr.IsFailedPrecondition(err), "the tenant would lose the chat, got %v", err)
+
+	_, err = ctl.UpdateChat(ctx, models.ReqUpdateChat{ChatID: "limassol", Locality: lo.ToPtr("Limassol")})
+	require.NoError(t, err, "other changes are allowed")
+}
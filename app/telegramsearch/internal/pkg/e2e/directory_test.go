@@ -0,0 +1,83 @@
+package e2e
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaifake"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport/searchv1"
+)
+
+func TestDirectory(t *testing.T) {
+	fake := openaifake.New(openaifake.WithCompletions(
+		openaifake.ScriptedCompletion{
+			Contains: "Doctor X is the best pediatrician",
+			Response: "```json\n" + `{"entities": [{"kind": "professional", "category": "Pediatrician",` +
+				` "name": "Doctor X", "phones": ["+357 99 000000", "[phone]"], "sentiment": "positive"}]}` + "\n```",
+		},
+		openaifake.ScriptedCompletion{
+			Contains: "Dasoudi beach",
+			Response: `{"entities": [{"kind": "place", "category": "beach", "name": "Dasoudi", "sentiment": "positive"}]}`,
+		},
+	))
+	ctl := fixtureCtl(t, fake, controllerv1.DefaultConfig())
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{ChatID: "limassol", ChatHistory: history})
+	require.NoError(t, err)
+	_, err = ctl.UpdateChat(ctx, models.ReqUpdateChat{ChatID: "limassol", Locality: lo.ToPtr("Limassol")})
+	require.NoError(t, err)
+
+	extracted, err := ctl.ExtractEntities(ctx, models.ReqExtractEntities{})
+	require.NoError(t, err)
+	require.Equal(t, models.RespExtractEntities{Threads: 2, Entities: 2}, extracted)
+	extracted, err = ctl.ExtractEntities(ctx, models.ReqExtractEntities{})
+	require.NoError(t, err)
+	require.Zero(t, extracted.Threads, "threads are processed once")
+
+	top, err := ctl.TopEntities(ctx, models.ReqTopEntities{Query: "pediatrician limassol"})
+	require.NoError(t, err)
+	require.Equal(t, "pediatrician", top.Category)
+	require.Equal(t, "Limassol", top.Locality)
+	require.Len(t, top.Entities, 1)
+	require.Equal(t, "Doctor X", top.Entities[0].Name)
+	require.Equal(t, []string{"limassol"}, top.Entities[0].ChatIDs)
+	require.Equal(t, 1, top.Entities[0].Positive)
+	require.Equal(t, []string{"+357 99 000000"}, top.Entities[0].Phones, "redacted phones are not contacts")
+
+	for _, category := range []string{"%", "_"} {
+		top, err = ctl.TopEntities(ctx, models.ReqTopEntities{Category: category})
+		require.NoError(t, err)
+		require.Empty(t, top.Entities, "%q is not a wildcard", category)
+	}
+
+	top, err = ctl.TopEntities(ctx, models.ReqTopEntities{Category: "pediatrician", Locality: "Paphos"})
+	require.NoError(t, err)
+	require.Empty(t, top.Entities)
+
+	app, err := searchtransport.New(ctl, searchtransport.DefaultConfig())
+	require.NoError(t, err)
+	srv := httptest.NewServer(app.Mux)
+	t.Cleanup(srv.Close)
+	client, err := searchv1.NewClient(srv.URL + searchtransport.DefaultConfig().App.BaseURL)
+	require.NoError(t, err)
+	beaches, err := client.TopEntities(ctx, &searchv1.TopEntitiesRequest{Category: "beach"})
+	require.NoError(t, err)
+	require.Len(t, beaches.Entities, 1)
+	require.Equal(t, "Dasoudi", beaches.Entities[0].Name)
+	require.Equal(t, "place", beaches.Entities[0].Kind)
+
+	// the thread has no answers without user2, so it is deleted with its mentions and their contacts.
+	_, err = ctl.ForgetAuthor(ctx, models.ReqForgetAuthor{TelegramUserID: 2, RequestedBy: "test"})
+	require.NoError(t, err)
+	top, err = ctl.TopEntities(ctx, models.ReqTopEntities{Category: "pediatrician"})
+	require.NoError(t, err)
+	require.Empty(t, top.Entities)
+}
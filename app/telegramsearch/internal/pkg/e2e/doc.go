@@ -0,0 +1,6 @@
+// Package e2e contains end-to-end tests of the answer pipeline:
+// load chat history, generate embeddings, answer the question.
+//
+// OpenAI is replaced with openaifake. Postgres must have pgvector installed,
+// so tests run only when TEST_DATABASE_URL points to such a server.
+package e2e
@@ -0,0 +1,154 @@
+package e2e
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaifake"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// earthquakesFake returns the latest earthquakes set by the test.
+type earthquakesFake struct {
+	mu     sync.Mutex
+	latest []earthquakes.Earthquake
+}
+
+func (f *earthquakesFake) set(latest ...earthquakes.Earthquake) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latest = latest
+}
+
+func (f *earthquakesFake) LatestNEarthquakes(_ context.Context, n int, minMagnitude float32) ([]earthquakes.Earthquake, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := make([]earthquakes.Earthquake, 0, n)
+	for _, e := range f.latest {
+		if len(r) < n && e.Magnitude >= minMagnitude {
+			r = append(r, e)
+		}
+	}
+	return r, nil
+}
+
+func TestEarthquakeAlerts(t *testing.T) {
+	quakes := &earthquakesFake{}
+	ctl := fixtureCtlWithEarthquakes(t, openaifake.New(), controllerv1.DefaultConfig(), quakes)
+	ctx := context.Background()
+
+	limassol := geo.Point{Lat: 34.6786, Lon: 33.0413}
+	paphos := geo.Point{Lat: 34.7720, Lon: 32.4297}
+	old := earthquakes.Earthquake{
+		GUID: "old", Magnitude: 5, When: time.Now().Add(-24 * time.Hour), Location: "Limassol", Position: limassol,
+	}
+	quakes.set(old)
+	polled, err := ctl.PollEarthquakes(ctx, models.ReqPollEarthquakes{})
+	require.NoError(t, err)
+	require.Equal(t, 1, polled.New)
+
+	const (
+		near = 1 // subscribed to Limassol within 20 km
+		far  = 2 // subscribed to Limassol, but to strong earthquakes only
+	)
+	_, err = ctl.SubscribeEarthquakes(ctx, models.ReqSubscribeEarthquakes{TelegramUserID: near, RadiusKm: 20})
+	require.NoError(t, err)
+	subscribed, err := ctl.SubscribeEarthquakes(ctx, models.ReqSubscribeEarthquakes{TelegramUserID: far, MinMagnitude: 6})
+	require.NoError(t, err)
+	require.Equal(t, models.RespSubscribeEarthquakes{MinMagnitude: 6, RadiusKm: 100}, subscribed, "default radius")
+	_, err = ctl.SubscribeEarthquakes(ctx, models.ReqSubscribeEarthquakes{TelegramUserID: far, MinMagnitude: 11})
+	require.True(t, semerr.IsInvalidInput(err), "got %v", err)
+
+	for _, userID := range []int64{near, far} {
+		_, err = ctl.LocateEarthquakeSubscription(ctx, models.ReqLocateEarthquakeSubscription{
+			TelegramUserID: userID,
+			Latitude:       limassol.Lat,
+			Longitude:      limassol.Lon,
+		})
+		require.NoError(t, err)
+	}
+	_, err = ctl.LocateEarthquakeSubscription(ctx, models.ReqLocateEarthquakeSubscription{TelegramUserID: near})
+	require.True(t, semerr.IsNotFound(err), "the location is already set, got %v", err)
+
+	inLimassol := earthquakes.Earthquake{
+		GUID: "limassol", Magnitude: 4.2, When: time.Now().Add(-time.Minute), Location: "Limassol", Position: limassol,
+	}
+	inPaphos := earthquakes.Earthquake{
+		GUID: "paphos", Magnitude: 4.5, When: time.Now(), Location: "Paphos", Position: paphos,
+	}
+	quakes.set(inPaphos, inLimassol, old)
+	polled, err = ctl.PollEarthquakes(ctx, models.ReqPollEarthquakes{})
+	require.NoError(t, err)
+	require.Equal(t, 2, polled.New)
+	require.Len(t, polled.Alerts, 1, "Paphos is farther than 20 km, the old earthquake is not new")
+	require.Equal(t, int64(near), polled.Alerts[0].TelegramUserID)
+	require.Equal(t, "limassol", polled.Alerts[0].Earthquake.GUID)
+	require.Zero(t, polled.Alerts[0].DistanceKm)
+
+	polled, err = ctl.PollEarthquakes(ctx, models.ReqPollEarthquakes{})
+	require.NoError(t, err)
+	require.Zero(t, polled.New)
+	require.Len(t, polled.Alerts, 1, "the alert is not marked sent, it is retried")
+	alert := polled.Alerts[0]
+	_, err = ctl.MarkEarthquakeAlertSent(ctx, models.ReqMarkEarthquakeAlertSent{
+		GUID:           alert.Earthquake.GUID,
+		TelegramUserID: alert.TelegramUserID,
+		Tenant:         alert.Tenant,
+	})
+	require.NoError(t, err)
+	polled, err = ctl.PollEarthquakes(ctx, models.ReqPollEarthquakes{})
+	require.NoError(t, err)
+	require.Empty(t, polled.Alerts, "alerts are sent once")
+
+	recent, err := ctl.RecentEarthquakes(ctx, models.ReqRecentEarthquakes{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, recent.Earthquakes, 2)
+	require.Equal(t, "paphos", recent.Earthquakes[0].GUID)
+	require.Equal(t, "limassol", recent.Earthquakes[1].GUID)
+
+	// a less authoritative source reports first.
+	strong := earthquakes.Earthquake{
+		GUID: "strong", SourceRank: 1, Magnitude: 5.5, When: time.Now(), Location: "Limassol", Position: limassol,
+	}
+	quakes.set(strong)
+	polled, err = ctl.PollEarthquakes(ctx, models.ReqPollEarthquakes{})
+	require.NoError(t, err)
+	require.Len(t, polled.Alerts, 1)
+	require.Equal(t, int64(near), polled.Alerts[0].TelegramUserID)
+	_, err = ctl.MarkEarthquakeAlertSent(ctx, models.ReqMarkEarthquakeAlertSent{GUID: "strong", TelegramUserID: near})
+	require.NoError(t, err)
+
+	authoritative := strong
+	authoritative.SourceRank, authoritative.Magnitude = 0, 6.2
+	quakes.set(authoritative)
+	polled, err = ctl.PollEarthquakes(ctx, models.ReqPollEarthquakes{})
+	require.NoError(t, err)
+	require.Zero(t, polled.New)
+	require.Len(t, polled.Alerts, 1, "the magnitude is replaced, the alerted subscriber is not alerted again")
+	require.Equal(t, int64(far), polled.Alerts[0].TelegramUserID)
+	require.Equal(t, float32(6.2), polled.Alerts[0].Earthquake.Magnitude)
+
+	quakes.set(strong)
+	_, err = ctl.PollEarthquakes(ctx, models.ReqPollEarthquakes{})
+	require.NoError(t, err)
+	recent, err = ctl.RecentEarthquakes(ctx, models.ReqRecentEarthquakes{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, float32(6.2), recent.Earthquakes[0].Magnitude, "a less authoritative source does not replace it")
+
+	_, err = ctl.UnsubscribeEarthquakes(ctx, models.ReqUnsubscribeEarthquakes{TelegramUserID: near})
+	require.NoError(t, err)
+	quakes.set(earthquakes.Earthquake{
+		GUID: "again", Magnitude: 4, When: time.Now(), Location: "Limassol", Position: limassol,
+	})
+	polled, err = ctl.PollEarthquakes(ctx, models.ReqPollEarthquakes{})
+	require.NoError(t, err)
+	require.Empty(t, polled.Alerts)
+}
@@ -0,0 +1,224 @@
+package e2e
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaifake"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport/searchv1"
+	"github.com/yanakipre/bot/internal/resttooling"
+	"github.com/yanakipre/bot/internal/secret"
+)
+
+// fixtureSearchAPI serves the search API over loaded history.
+func fixtureSearchAPI(t *testing.T, fake *openaifake.Server, cfg searchtransport.Config) string {
+	ctl := fixtureCtl(t, fake, controllerv1.DefaultConfig())
+	ctx := context.Background()
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{
+		ChatID:      "limassol",
+		ChatHistory: history,
+	})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+
+	app, err := searchtransport.New(ctl, cfg)
+	require.NoError(t, err)
+	srv := httptest.NewServer(app.Mux)
+	t.Cleanup(srv.Close)
+	return srv.URL + cfg.App.BaseURL
+}
+
+func TestSearchAPI(t *testing.T) {
+	url := fixtureSearchAPI(t, openaifake.New(), searchtransport.DefaultConfig())
+	client, err := searchv1.NewClient(url)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	const query = "pediatrician in Limassol"
+	first, err := client.SearchThroughChats(ctx, &searchv1.SearchThroughChatsRequest{
+		Query: query,
+		Limit: searchv1.NewOptInt(1),
+	})
+	require.NoError(t, err)
+	require.Len(t, first.ChatThreads, 1)
+	require.Equal(t, searchv1.NewOptInt(1), first.NextOffset)
+	thread := first.ChatThreads[0]
+	require.Equal(t, "limassol", thread.ChatID)
+	require.Equal(t, "https://t.me/empty/1", thread.URL)
+	require.Len(t, thread.Messages, 2)
+	require.Equal(t, "https://t.me/empty/2", thread.Messages[1].URL)
+	require.Contains(t, thread.Messages[1].Message, "[phone]", "personal phones are redacted")
+
+	second, err := client.SearchThroughChats(ctx, &searchv1.SearchThroughChatsRequest{
+		Query:  query,
+		Limit:  searchv1.NewOptInt(1),
+		Offset: first.NextOffset,
+	})
+	require.NoError(t, err)
+	require.Len(t, second.ChatThreads, 1)
+	require.False(t, second.NextOffset.Set, "the last page")
+	require.NotEqual(t, thread.ThreadID, second.ChatThreads[0].ThreadID)
+
+	other, err := client.SearchThroughChats(ctx, &searchv1.SearchThroughChatsRequest{
+		Query:   query,
+		ChatIds: []string{"paphos"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, other.ChatThreads, "only requested chats are searched")
+
+	_, err = client.SearchThroughChats(ctx, &searchv1.SearchThroughChatsRequest{
+		Query:  query,
+		Tenant: searchv1.NewOptString("georgia"),
+	})
+	var apiErr *searchv1.GeneralErrorStatusCode
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	require.Contains(t, apiErr.Response.Error, `unknown tenant "georgia"`)
+}
+
+func TestSearchAPI_invalidRequest(t *testing.T) {
+	url := fixtureSearchAPI(t, openaifake.New(), searchtransport.DefaultConfig())
+
+	resp, err := http.Post(url+"/search-through-chats", "application/json", strings.NewReader(`{"query": ""}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+const (
+	widgetKey = "widget-secret"
+	answer    = "Говорят, что доктор X лучший педиатр."
+)
+
+func fixtureAnswerAPI(t *testing.T) string {
+	fake := openaifake.New(openaifake.WithCompletions(openaifake.ScriptedCompletion{
+		Contains: "Doctor X",
+		Response: answer,
+	}))
+	cfg := searchtransport.DefaultConfig()
+	cfg.APIKeys = []searchtransport.APIKey{{Name: "widget", Key: secret.NewString(widgetKey)}}
+	return fixtureSearchAPI(t, fake, cfg)
+}
+
+// bearer authenticates requests of the generated client.
+type bearer string
+
+func (b bearer) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	resttooling.SetBearerToken(string(b), r)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestAnswerAPI(t *testing.T) {
+	url := fixtureAnswerAPI(t)
+	ctx := context.Background()
+	req := &searchv1.AnswerRequest{Query: "pediatrician in Limassol"}
+
+	client, err := searchv1.NewClient(url, searchv1.WithClient(&http.Client{Transport: bearer(widgetKey)}))
+	require.NoError(t, err)
+	resp, err := client.Answer(ctx, req)
+	require.NoError(t, err)
+	require.Contains(t, resp.Response, answer)
+	require.True(t, resp.Stale, "fixture is old enough")
+	require.False(t, resp.Cached)
+	require.Len(t, resp.Sources, 2)
+	urls := []string{resp.Sources[0].URL, resp.Sources[1].URL}
+	require.ElementsMatch(t, []string{"https://t.me/empty/1", "https://t.me/empty/3"}, urls)
+
+	resp, err = client.Answer(ctx, req)
+	require.NoError(t, err)
+	require.True(t, resp.Cached)
+	require.Len(t, resp.Sources, 2)
+
+	for name, key := range map[string]string{"no key": "", "unknown key": "guess"} {
+		t.Run(name, func(t *testing.T) {
+			var transport http.RoundTripper = http.DefaultTransport
+			if key != "" {
+				transport = bearer(key)
+			}
+			client, err := searchv1.NewClient(url, searchv1.WithClient(&http.Client{Transport: transport}))
+			require.NoError(t, err)
+			_, err = client.Answer(ctx, req)
+			var apiErr *searchv1.GeneralErrorStatusCode
+			require.True(t, errors.As(err, &apiErr))
+			require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+		})
+	}
+}
+
+type event struct {
+	name string
+	data string
+}
+
+func readEvents(t *testing.T, body io.Reader) []event {
+	var events []event
+	var e event
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			events = append(events, e)
+			e = event{}
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func postAnswerStream(t *testing.T, url, body string) *http.Response {
+	r, err := http.NewRequest(http.MethodPost, url+"/answer/stream", strings.NewReader(body))
+	require.NoError(t, err)
+	r.Header.Set("Content-Type", "application/json")
+	resttooling.SetBearerToken(widgetKey, r)
+	resp, err := http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestAnswerAPI_stream(t *testing.T) {
+	url := fixtureAnswerAPI(t)
+
+	resp := postAnswerStream(t, url, `{"query": "pediatrician in Limassol"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := readEvents(t, resp.Body)
+	require.Greater(t, len(events), 2, "the answer is streamed by parts")
+	var streamed strings.Builder
+	for _, e := range events[:len(events)-1] {
+		require.Equal(t, "delta", e.name)
+		var delta struct{ Text string }
+		require.NoError(t, json.Unmarshal([]byte(e.data), &delta))
+		streamed.WriteString(delta.Text)
+	}
+	done := events[len(events)-1]
+	require.Equal(t, "done", done.name)
+	var result searchv1.AnswerResponse
+	require.NoError(t, result.UnmarshalJSON([]byte(done.data)))
+	require.Contains(t, result.Response, answer)
+	require.Equal(t, result.Response, streamed.String())
+	require.Len(t, result.Sources, 2)
+
+	resp = postAnswerStream(t, url, `{"query": "pediatrician in Limassol", "tenant": "georgia"}`)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "errors before the stream are responded as usual")
+}
@@ -0,0 +1,51 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaifake"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+)
+
+func TestSummarizeThreads(t *testing.T) {
+	const (
+		pediatrician = "Doctor X is recommended as the best pediatrician in Limassol, June 2024."
+		beach        = "Dasoudi beach is calm in the morning, June 2024."
+	)
+	fake := openaifake.New(openaifake.WithCompletions(
+		openaifake.ScriptedCompletion{Contains: "Doctor X is the best pediatrician", Response: pediatrician},
+		openaifake.ScriptedCompletion{Contains: "Dasoudi beach is calm", Response: beach},
+	))
+	ctl := fixtureCtl(t, fake, controllerv1.DefaultConfig())
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{ChatID: "limassol", ChatHistory: history})
+	require.NoError(t, err)
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+
+	summarized, err := ctl.SummarizeThreads(ctx, models.ReqSummarizeThreads{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, summarized.Summarized)
+	summarized, err = ctl.SummarizeThreads(ctx, models.ReqSummarizeThreads{})
+	require.NoError(t, err)
+	require.Equal(t, 1, summarized.Summarized, "summarized threads are skipped")
+	require.Len(t, fake.CompletionRequests(), 2)
+	require.Len(t, fake.EmbeddingRequests(), 4, "summaries are embedded")
+
+	_, err = ctl.TryCompletion(ctx, models.ReqTryCompletion{Query: "pediatrician in Limassol", NoCache: true})
+	require.NoError(t, err)
+	completions := fake.CompletionRequests()
+	prompt := completions[len(completions)-1].Messages[1].Content
+	require.Contains(t, prompt, pediatrician)
+	require.Contains(t, prompt, beach)
+	require.NotContains(t, prompt, "В ответ на нее состоялся следующий диалог", "summaries replace threads")
+
+	found, err := ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Input: "pediatrician in Limassol", Limit: 10})
+	require.NoError(t, err)
+	require.Contains(t, found.Result[0].Text, "Doctor X is the best pediatrician", "full threads are shown")
+}
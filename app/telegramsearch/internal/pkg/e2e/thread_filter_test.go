@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/openaifake"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+)
+
+var spamHistory = []byte(`{
+  "messages": [
+    {"id": 1, "type": "message", "date_unixtime": "1717200000", "from_id": "user1",
+     "text_entities": [{"type": "plain", "text": "Can anyone recommend a pediatrician in Limassol?"}]},
+    {"id": 2, "type": "message", "date_unixtime": "1717200100", "from_id": "user2", "reply_to_message_id": 1,
+     "text_entities": [{"type": "plain", "text": "+1"}]},
+    {"id": 3, "type": "message", "date_unixtime": "1717200200", "from_id": "spammer", "reply_to_message_id": 1,
+     "text_entities": [{"type": "plain", "text": "Пассивный доход без вложений, пишите в лс"}]},
+    {"id": 4, "type": "message", "date_unixtime": "1717203600", "from_id": "user3", "reply_to_message_id": 1,
+     "text_entities": [{"type": "plain", "text": "Doctor X is the best pediatrician"}]},
+    {"id": 5, "type": "message", "date_unixtime": "1717290000", "from_id": "spammer",
+     "text_entities": [{"type": "plain", "text": "Заработок от 500 евро в день, пишите в личку"}]},
+    {"id": 6, "type": "message", "date_unixtime": "1717290100", "from_id": "user1", "reply_to_message_id": 5,
+     "text_entities": [{"type": "plain", "text": "Reported to admins, this is a scam"}]},
+    {"id": 7, "type": "message", "date_unixtime": "1717300000", "from_id": "user4",
+     "text_entities": [{"type": "plain", "text": "Where to swim with kids?"}]},
+    {"id": 8, "type": "message", "date_unixtime": "1717300100", "from_id": "user5", "reply_to_message_id": 7,
+     "text_entities": [{"type": "plain", "text": "Thanks!"}]},
+    {"id": 9, "type": "message", "date_unixtime": "1717310000", "from_id": "user6", "via_bot": "@gamebot",
+     "text_entities": [{"type": "plain", "text": "Play the game with me and win prizes"}]},
+    {"id": 10, "type": "message", "date_unixtime": "1717310100", "from_id": "user1", "reply_to_message_id": 9,
+     "text_entities": [{"type": "plain", "text": "No games here please"}]},
+    {"id": 11, "type": "message", "date_unixtime": "1717320000", "from_id": "user7",
+     "text_entities": [{"type": "plain", "text": "Luxury villa for rent, best price on the island"}]},
+    {"id": 12, "type": "message", "date_unixtime": "1717320100", "from_id": "user8", "reply_to_message_id": 11,
+     "text_entities": [{"type": "plain", "text": "How much per month?"}]}
+  ]
+}`)
+
+func TestThreadFilter(t *testing.T) {
+	fake := openaifake.New(openaifake.WithCompletions(openaifake.ScriptedCompletion{
+		Contains: "Luxury villa",
+		Response: "spam",
+	}))
+	cfg := controllerv1.DefaultConfig()
+	cfg.ThreadFilter.Classifier.Enabled = true
+	ctl := fixtureCtl(t, fake, cfg)
+	ctx := context.Background()
+
+	_, err := ctl.DumpChatHistory(ctx, models.ReqDumpChatHistory{ChatID: "limassol", ChatHistory: spamHistory})
+	require.NoError(t, err)
+
+	filtered, err := ctl.FilteredThreads(ctx, models.ReqFilteredThreads{ChatID: "limassol", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t,
+		map[string]string{
+			"Luxury villa for rent, best price on the island": "classifier",
+			"Play the game with me and win prizes":            "bot",
+			"Where to swim with kids?":                        "no_answers",
+			"Заработок от 500 евро в день, пишите в личку":    "spammer",
+		},
+		lo.SliceToMap(filtered.Threads, func(item models.FilteredThread) (string, string) {
+			return item.Starter, item.Reason
+		}),
+	)
+
+	stats, err := ctl.ChatStats(ctx, models.ReqChatStats{})
+	require.NoError(t, err)
+	require.Equal(t, 5, stats.Chats[0].Threads)
+	require.Equal(t, 4, stats.Chats[0].Filtered)
+	require.Equal(t, 2, stats.Chats[0].DroppedReplies, "+1 and spam are dropped from the pediatrician thread")
+
+	_, err = ctl.GenerateEmbeddings(ctx, models.ReqGenerateEmbeddings{})
+	require.NoError(t, err)
+	found, err := ctl.TryEmbedding(ctx, models.ReqTryEmbedding{Input: "pediatrician in Limassol", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, found.Result, 1, "filtered threads are not searched")
+	require.Contains(t, found.Result[0].Text, "Doctor X is the best pediatrician")
+	require.NotContains(t, found.Result[0].Text, "+1")
+	require.NotContains(t, found.Result[0].Text, "Пассивный доход", "spam replies are dropped")
+}
@@ -0,0 +1,132 @@
+package evaluation
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteSummary writes human-readable summary of the report.
+func WriteSummary(w io.Writer, r Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	rows := [][2]string{
+		{"questions", fmt.Sprint(r.Summary.Questions)},
+		{fmt.Sprintf("recall@%d", r.Summary.K), fmt.Sprintf("%.3f", r.Summary.RecallAtK)},
+		{"mrr", fmt.Sprintf("%.3f", r.Summary.MRR)},
+		{"no_result_rate", fmt.Sprintf("%.3f", r.Summary.NoResultRate)},
+		{"latency_mean", r.Summary.LatencyMean.String()},
+		{"latency_p50", r.Summary.LatencyP50.String()},
+		{"latency_p95", r.Summary.LatencyP95.String()},
+		{"completion_latency_mean", r.Summary.CompletionLatency.String()},
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1]); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteDiff writes human-readable comparison of the current report against the baseline.
+func WriteDiff(w io.Writer, baseline, current Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintf(tw, "metric\tbaseline\tcurrent\tdelta\n"); err != nil {
+		return err
+	}
+	floats := []struct {
+		name string
+		b, c float64
+	}{
+		{name: "recall@k", b: baseline.Summary.RecallAtK, c: current.Summary.RecallAtK},
+		{name: "mrr", b: baseline.Summary.MRR, c: current.Summary.MRR},
+		{name: "no_result_rate", b: baseline.Summary.NoResultRate, c: current.Summary.NoResultRate},
+	}
+	for _, f := range floats {
+		if _, err := fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%+.3f\n", f.name, f.b, f.c, f.c-f.b); err != nil {
+			return err
+		}
+	}
+	durations := []struct {
+		name string
+		b, c time.Duration
+	}{
+		{"latency_p50", baseline.Summary.LatencyP50.Duration, current.Summary.LatencyP50.Duration},
+		{"latency_p95", baseline.Summary.LatencyP95.Duration, current.Summary.LatencyP95.Duration},
+	}
+	for _, d := range durations {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.name, d.b, d.c, signedDuration(d.c-d.b)); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if baseline.Summary.K != current.Summary.K {
+		if _, err := fmt.Fprintf(w, "\nwarning: k differs, baseline %d, current %d\n",
+			baseline.Summary.K, current.Summary.K); err != nil {
+			return err
+		}
+	}
+
+	base := make(map[string]QuestionResult, len(baseline.Results))
+	for _, r := range baseline.Results {
+		base[r.ID] = r
+	}
+	var changed, added []string
+	for _, c := range current.Results {
+		b, ok := base[c.ID]
+		delete(base, c.ID)
+		if !ok {
+			added = append(added, c.ID)
+			continue
+		}
+		if b.ReciprocalRank != c.ReciprocalRank || b.RecallAtK != c.RecallAtK {
+			changed = append(changed, fmt.Sprintf(
+				"%s: reciprocal rank %.3f -> %.3f, recall@k %.3f -> %.3f",
+				c.ID, b.ReciprocalRank, c.ReciprocalRank, b.RecallAtK, c.RecallAtK,
+			))
+		}
+	}
+	sections := []struct {
+		title string
+		lines []string
+	}{
+		{"changed questions", changed},
+		{"new questions", added},
+		{"removed questions", keysOf(base, baseline.Results)},
+	}
+	for _, s := range sections {
+		if len(s.lines) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n%s:\n", s.title); err != nil {
+			return err
+		}
+		for _, l := range s.lines {
+			if _, err := fmt.Fprintf(w, "  %s\n", l); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// keysOf returns keys left in m, in the order of results.
+func keysOf(m map[string]QuestionResult, results []QuestionResult) []string {
+	var r []string
+	for _, res := range results {
+		if _, ok := m[res.ID]; ok {
+			r = append(r, res.ID)
+		}
+	}
+	return r
+}
+
+func signedDuration(d time.Duration) string {
+	if d >= 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
@@ -0,0 +1,109 @@
+// Package evaluation measures retrieval quality against golden question sets.
+package evaluation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Question is a golden question with expectations about retrieved threads.
+type Question struct {
+	// ID identifies the question between runs. Defaults to the question itself.
+	ID       string `yaml:"id"       json:"id"`
+	Question string `yaml:"question" json:"question"`
+	// ExpectedThreadIDs are chatthreads.thread_id values relevant to the question.
+	ExpectedThreadIDs []int64 `yaml:"expected_thread_ids" json:"expected_thread_ids"`
+	// ExpectedKeywords make any retrieved thread containing at least one of them relevant.
+	// Useful when the thread IDs are not known or change between reloads.
+	ExpectedKeywords []string `yaml:"expected_keywords" json:"expected_keywords"`
+}
+
+func (q Question) validate() error {
+	if q.Question == "" {
+		return errors.New("question is empty")
+	}
+	if len(q.ExpectedThreadIDs) == 0 && len(q.ExpectedKeywords) == 0 {
+		return fmt.Errorf("question %q has neither expected thread ids nor keywords", q.Question)
+	}
+	return nil
+}
+
+type questionSet struct {
+	Questions []Question `yaml:"questions"`
+}
+
+// LoadQuestions reads questions from a YAML file (with top-level "questions" key)
+// or from a JSONL file with a question per line. The format is chosen by extension.
+func LoadQuestions(path string) ([]Question, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("cannot read questions: %w", err)
+	}
+	var questions []Question
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl":
+		questions, err = parseJSONL(data)
+	case ".yaml", ".yml":
+		questions, err = parseYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported questions file extension: %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(questions))
+	for i := range questions {
+		if err := questions[i].validate(); err != nil {
+			return nil, err
+		}
+		if questions[i].ID == "" {
+			questions[i].ID = questions[i].Question
+		}
+		if _, ok := seen[questions[i].ID]; ok {
+			return nil, fmt.Errorf("duplicate question id %q", questions[i].ID)
+		}
+		seen[questions[i].ID] = struct{}{}
+	}
+	return questions, nil
+}
+
+func parseYAML(data []byte) ([]Question, error) {
+	var set questionSet
+	if err := yaml.UnmarshalStrict(data, &set); err != nil {
+		return nil, fmt.Errorf("cannot parse questions: %w", err)
+	}
+	return set.Questions, nil
+}
+
+func parseJSONL(data []byte) ([]Question, error) {
+	var questions []Question
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var q Question
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&q); err != nil {
+			return nil, fmt.Errorf("cannot parse question on line %d: %w", line, err)
+		}
+		questions = append(questions, q)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read questions: %w", err)
+	}
+	return questions, nil
+}
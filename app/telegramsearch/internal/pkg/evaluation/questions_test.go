@@ -0,0 +1,85 @@
+package evaluation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadQuestions(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		expect  []Question
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "questions.yaml",
+			content: `questions:
+  - id: pediatrician
+    question: best pediatrician in Limassol
+    expected_thread_ids: [1, 2]
+  - question: where to swim
+    expected_keywords: [beach]
+`,
+			expect: []Question{
+				{ID: "pediatrician", Question: "best pediatrician in Limassol", ExpectedThreadIDs: []int64{1, 2}},
+				{ID: "where to swim", Question: "where to swim", ExpectedKeywords: []string{"beach"}},
+			},
+		},
+		{
+			name: "jsonl",
+			file: "questions.jsonl",
+			content: `{"id": "a", "question": "q1", "expected_thread_ids": [3]}
+
+{"question": "q2", "expected_keywords": ["k"]}
+`,
+			expect: []Question{
+				{ID: "a", Question: "q1", ExpectedThreadIDs: []int64{3}},
+				{ID: "q2", Question: "q2", ExpectedKeywords: []string{"k"}},
+			},
+		},
+		{
+			name:    "no expectations",
+			file:    "questions.jsonl",
+			content: `{"question": "q1"}`,
+			wantErr: "neither expected thread ids nor keywords",
+		},
+		{
+			name: "duplicate ids",
+			file: "questions.jsonl",
+			content: `{"id": "a", "question": "q1", "expected_thread_ids": [3]}
+{"id": "a", "question": "q2", "expected_thread_ids": [3]}`,
+			wantErr: "duplicate question id",
+		},
+		{
+			name:    "unknown field",
+			file:    "questions.jsonl",
+			content: `{"question": "q1", "expected_threads": [3]}`,
+			wantErr: "unknown field",
+		},
+		{
+			name:    "unsupported extension",
+			file:    "questions.csv",
+			content: `q1`,
+			wantErr: "unsupported questions file extension",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			got, err := LoadQuestions(path)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, got)
+		})
+	}
+}
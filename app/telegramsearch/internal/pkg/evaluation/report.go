@@ -0,0 +1,190 @@
+package evaluation
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/yanakipre/bot/internal/encodingtooling"
+)
+
+// Retrieved is a single thread returned by the retrieval, in the order of returning.
+type Retrieved struct {
+	ThreadID int64
+	Text     string
+}
+
+// Observation is what happened when the question was asked.
+type Observation struct {
+	Question         Question
+	Retrieved        []Retrieved
+	RetrievalLatency time.Duration
+	// Completion is empty when completion was not requested.
+	Completion        string
+	CompletionLatency time.Duration
+}
+
+// QuestionResult holds the metrics for a single question.
+type QuestionResult struct {
+	ID string `yaml:"id"`
+	// RecallAtK is the share of expected threads found in the top K.
+	// For keyword-only questions it is 1 when any relevant thread is found in the top K.
+	RecallAtK      float64 `yaml:"recall_at_k"`
+	ReciprocalRank float64 `yaml:"reciprocal_rank"`
+	// FirstRelevantRank is 1-based, 0 means no relevant thread was retrieved.
+	FirstRelevantRank int                      `yaml:"first_relevant_rank"`
+	Retrieved         int                      `yaml:"retrieved"`
+	RetrievedThreads  []int64                  `yaml:"retrieved_threads"`
+	RetrievalLatency  encodingtooling.Duration `yaml:"retrieval_latency"`
+	Completion        string                   `yaml:"completion,omitempty"`
+	CompletionLatency encodingtooling.Duration `yaml:"completion_latency"`
+}
+
+// Summary aggregates metrics over all questions.
+type Summary struct {
+	Questions         int                      `yaml:"questions"`
+	K                 int                      `yaml:"k"`
+	RecallAtK         float64                  `yaml:"recall_at_k"`
+	MRR               float64                  `yaml:"mrr"`
+	NoResultRate      float64                  `yaml:"no_result_rate"`
+	LatencyMean       encodingtooling.Duration `yaml:"latency_mean"`
+	LatencyP50        encodingtooling.Duration `yaml:"latency_p50"`
+	LatencyP95        encodingtooling.Duration `yaml:"latency_p95"`
+	CompletionLatency encodingtooling.Duration `yaml:"completion_latency_mean"`
+}
+
+// Report is the result of a single evaluation run. It is stored on disk to compare runs.
+type Report struct {
+	CreatedAt time.Time        `yaml:"created_at"`
+	Summary   Summary          `yaml:"summary"`
+	Results   []QuestionResult `yaml:"results"`
+}
+
+// Evaluate computes metrics for observations considering only the top k retrieved threads.
+func Evaluate(now time.Time, k int, observations []Observation) Report {
+	r := Report{
+		CreatedAt: now,
+		Summary:   Summary{Questions: len(observations), K: k},
+		Results:   make([]QuestionResult, 0, len(observations)),
+	}
+	if len(observations) == 0 {
+		return r
+	}
+	latencies := make([]time.Duration, 0, len(observations))
+	var noResults int
+	var latencySum, completionLatencySum time.Duration
+	for _, o := range observations {
+		res := evaluateQuestion(k, o)
+		r.Results = append(r.Results, res)
+		r.Summary.RecallAtK += res.RecallAtK
+		r.Summary.MRR += res.ReciprocalRank
+		if res.Retrieved == 0 {
+			noResults++
+		}
+		latencies = append(latencies, o.RetrievalLatency)
+		latencySum += o.RetrievalLatency
+		completionLatencySum += o.CompletionLatency
+	}
+	n := float64(len(observations))
+	r.Summary.RecallAtK /= n
+	r.Summary.MRR /= n
+	r.Summary.NoResultRate = float64(noResults) / n
+	slices.Sort(latencies)
+	r.Summary.LatencyMean = encodingtooling.NewDuration(latencySum / time.Duration(len(latencies)))
+	r.Summary.LatencyP50 = encodingtooling.NewDuration(percentile(latencies, 0.5))
+	r.Summary.LatencyP95 = encodingtooling.NewDuration(percentile(latencies, 0.95))
+	r.Summary.CompletionLatency = encodingtooling.NewDuration(
+		completionLatencySum / time.Duration(len(observations)),
+	)
+	return r
+}
+
+func evaluateQuestion(k int, o Observation) QuestionResult {
+	res := QuestionResult{
+		ID:                o.Question.ID,
+		Retrieved:         len(o.Retrieved),
+		RetrievedThreads:  make([]int64, 0, len(o.Retrieved)),
+		RetrievalLatency:  encodingtooling.NewDuration(o.RetrievalLatency),
+		Completion:        o.Completion,
+		CompletionLatency: encodingtooling.NewDuration(o.CompletionLatency),
+	}
+	expected := make(map[int64]struct{}, len(o.Question.ExpectedThreadIDs))
+	for _, id := range o.Question.ExpectedThreadIDs {
+		expected[id] = struct{}{}
+	}
+	foundExpected := make(map[int64]struct{}, len(expected))
+	anyRelevantInTopK := false
+	for i, r := range o.Retrieved {
+		res.RetrievedThreads = append(res.RetrievedThreads, r.ThreadID)
+		_, isExpected := expected[r.ThreadID]
+		relevant := isExpected || containsAny(r.Text, o.Question.ExpectedKeywords)
+		if relevant && res.FirstRelevantRank == 0 {
+			res.FirstRelevantRank = i + 1
+			res.ReciprocalRank = 1 / float64(i+1)
+		}
+		if i >= k {
+			continue
+		}
+		if isExpected {
+			foundExpected[r.ThreadID] = struct{}{}
+		}
+		if relevant {
+			anyRelevantInTopK = true
+		}
+	}
+	switch {
+	case len(expected) > 0:
+		res.RecallAtK = float64(len(foundExpected)) / float64(len(expected))
+	case anyRelevantInTopK:
+		res.RecallAtK = 1
+	}
+	return res
+}
+
+func containsAny(text string, keywords []string) bool {
+	text = strings.ToLower(text)
+	for _, k := range keywords {
+		if strings.Contains(text, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// percentile expects sorted input.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
+
+// LoadReport reads a report previously written with SaveReport.
+func LoadReport(path string) (Report, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return Report{}, fmt.Errorf("cannot read report: %w", err)
+	}
+	var r Report
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return Report{}, fmt.Errorf("cannot parse report: %w", err)
+	}
+	return r, nil
+}
+
+// SaveReport writes the report to disk to compare against it later.
+func SaveReport(path string, r Report) error {
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("cannot marshal report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("cannot write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package evaluation
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	observations := []Observation{
+		{
+			// expected threads found on ranks 2 and 4, k=3
+			Question: Question{ID: "ids", ExpectedThreadIDs: []int64{10, 20}},
+			Retrieved: []Retrieved{
+				{ThreadID: 1}, {ThreadID: 10}, {ThreadID: 2}, {ThreadID: 20},
+			},
+			RetrievalLatency: 100 * time.Millisecond,
+		},
+		{
+			Question: Question{ID: "keywords", ExpectedKeywords: []string{"Beach"}},
+			Retrieved: []Retrieved{
+				{ThreadID: 5, Text: "go to the beach in the morning"},
+			},
+			RetrievalLatency: 200 * time.Millisecond,
+		},
+		{
+			Question:         Question{ID: "nothing", ExpectedKeywords: []string{"beach"}},
+			RetrievalLatency: 300 * time.Millisecond,
+		},
+	}
+	r := Evaluate(now, 3, observations)
+
+	require.Equal(t, now, r.CreatedAt)
+	require.Len(t, r.Results, 3)
+	require.Equal(t, 0.5, r.Results[0].RecallAtK)
+	require.Equal(t, 0.5, r.Results[0].ReciprocalRank)
+	require.Equal(t, 2, r.Results[0].FirstRelevantRank)
+	require.Equal(t, []int64{1, 10, 2, 20}, r.Results[0].RetrievedThreads)
+	require.Equal(t, 1.0, r.Results[1].RecallAtK)
+	require.Equal(t, 1.0, r.Results[1].ReciprocalRank)
+	require.Equal(t, 0.0, r.Results[2].RecallAtK)
+
+	require.Equal(t, 3, r.Summary.Questions)
+	require.InDelta(t, 0.5, r.Summary.RecallAtK, 1e-9)
+	require.InDelta(t, 0.5, r.Summary.MRR, 1e-9)
+	require.InDelta(t, 1.0/3, r.Summary.NoResultRate, 1e-9)
+	require.Equal(t, 200*time.Millisecond, r.Summary.LatencyMean.Duration)
+	require.Equal(t, 200*time.Millisecond, r.Summary.LatencyP50.Duration)
+	require.Equal(t, 200*time.Millisecond, r.Summary.LatencyP95.Duration)
+}
+
+func TestEvaluate_empty(t *testing.T) {
+	r := Evaluate(time.Now(), 5, nil)
+	require.Zero(t, r.Summary.Questions)
+	require.Empty(t, r.Results)
+}
+
+func TestReport_roundtripAndDiff(t *testing.T) {
+	baseline := Evaluate(time.Now().UTC().Truncate(time.Second), 3, []Observation{
+		{Question: Question{ID: "a", ExpectedThreadIDs: []int64{1}}, Retrieved: []Retrieved{{ThreadID: 2}, {ThreadID: 1}}},
+		{Question: Question{ID: "removed", ExpectedThreadIDs: []int64{1}}},
+	})
+	path := filepath.Join(t.TempDir(), "report.yaml")
+	require.NoError(t, SaveReport(path, baseline))
+	loaded, err := LoadReport(path)
+	require.NoError(t, err)
+	require.Equal(t, baseline, loaded)
+
+	current := Evaluate(time.Now(), 3, []Observation{
+		{Question: Question{ID: "a", ExpectedThreadIDs: []int64{1}}, Retrieved: []Retrieved{{ThreadID: 1}}},
+		{Question: Question{ID: "added", ExpectedThreadIDs: []int64{1}}},
+	})
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteDiff(buf, loaded, current))
+	out := buf.String()
+	require.Contains(t, out, "a: reciprocal rank 0.500 -> 1.000")
+	require.Contains(t, out, "new questions:\n  added\n")
+	require.Contains(t, out, "removed questions:\n  removed\n")
+
+	buf.Reset()
+	require.NoError(t, WriteSummary(buf, current))
+	require.Contains(t, buf.String(), "recall@3")
+}
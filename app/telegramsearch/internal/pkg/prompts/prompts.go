@@ -0,0 +1,215 @@
+// Package prompts renders versioned text/template prompts for chat completions.
+//
+// Every version is a directory with two templates:
+//
+//	<version>/system.tmpl  - instructions sent first, usually protecting the prompt itself
+//	<version>/context.tmpl - locality specific instructions, conversations and the question
+//
+// Templates are embedded into the binary and can be replaced per deployment
+// by pointing Config.Dir to a directory with the same layout.
+package prompts
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates
+var embedded embed.FS
+
+const (
+	systemTemplate  = "system.tmpl"
+	contextTemplate = "context.tmpl"
+)
+
+type Config struct {
+	// Dir overrides embedded templates when set.
+	Dir string `yaml:"dir"`
+	// Version is rendered for everyone not participating in the experiment.
+	Version    string     `yaml:"version"`
+	Experiment Experiment `yaml:"experiment"`
+	// Locality is what users are asking about, e.g. "Cyprus" or "Bangkok".
+	Locality string `yaml:"locality"`
+	// DoNotHighlight is obvious to users and should not be mentioned in responses.
+	DoNotHighlight string `yaml:"do_not_highlight"`
+	// Language is preferred for responses.
+	Language string `yaml:"language"`
+}
+
+// Experiment renders another prompt version for a share of users.
+type Experiment struct {
+	// Version is disabled when empty.
+	Version string `yaml:"version"`
+	// Share of users from 0 to 1 getting the experiment version.
+	Share float64 `yaml:"share"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Version:        "v1",
+		Locality:       "Cyprus",
+		DoNotHighlight: "Cyprus",
+		Language:       "Russian",
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.Version == "" {
+		return errors.New("prompts version is required")
+	}
+	if c.Experiment.Share < 0 || c.Experiment.Share > 1 {
+		return fmt.Errorf("prompts experiment share must be within [0, 1], got %v", c.Experiment.Share)
+	}
+	if c.Experiment.Version == c.Version {
+		return errors.New("prompts experiment version must differ from the default version")
+	}
+	return nil
+}
+
+// Vars are available in templates by name.
+type Vars struct {
+	Locality       string
+	DoNotHighlight string
+	Language       string
+	// Date is today, formatted as time.DateOnly.
+	Date          string
+	Conversations []string
+	Question      string
+}
+
+// Rendered is a prompt ready to be sent to the model.
+type Rendered struct {
+	// Version identifies templates the prompt was rendered from.
+	Version string
+	System  string
+	Context string
+}
+
+type prompt struct {
+	version string
+	system  *template.Template
+	context *template.Template
+}
+
+// Prompts holds parsed templates for the configured versions.
+type Prompts struct {
+	cfg        Config
+	main       prompt
+	experiment *prompt
+}
+
+// New parses templates of the configured versions.
+func New(cfg Config) (*Prompts, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	var fsys fs.FS
+	if cfg.Dir != "" {
+		fsys = os.DirFS(cfg.Dir)
+	} else {
+		sub, err := fs.Sub(embedded, "templates")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	}
+	main, err := parse(fsys, cfg.Version)
+	if err != nil {
+		return nil, err
+	}
+	p := &Prompts{cfg: cfg, main: main}
+	if cfg.Experiment.Version != "" {
+		experiment, err := parse(fsys, cfg.Experiment.Version)
+		if err != nil {
+			return nil, err
+		}
+		p.experiment = &experiment
+	}
+	return p, nil
+}
+
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+func parse(fsys fs.FS, version string) (prompt, error) {
+	p := prompt{version: version}
+	for _, t := range []struct {
+		name string
+		dst  **template.Template
+	}{
+		{systemTemplate, &p.system},
+		{contextTemplate, &p.context},
+	} {
+		data, err := fs.ReadFile(fsys, version+"/"+t.name)
+		if err != nil {
+			return prompt{}, fmt.Errorf("cannot read prompt %s/%s: %w", version, t.name, err)
+		}
+		tpl, err := template.New(t.name).Funcs(funcs).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return prompt{}, fmt.Errorf("cannot parse prompt %s/%s: %w", version, t.name, err)
+		}
+		*t.dst = tpl
+	}
+	return p, nil
+}
+
+// Render renders the prompt version assigned to the user.
+// Static variables (locality, language, etc.) are taken from the config when empty in vars.
+func (p *Prompts) Render(userID int64, vars Vars) (Rendered, error) {
+	if vars.Locality == "" {
+		vars.Locality = p.cfg.Locality
+	}
+	if vars.DoNotHighlight == "" {
+		vars.DoNotHighlight = p.cfg.DoNotHighlight
+	}
+	if vars.Language == "" {
+		vars.Language = p.cfg.Language
+	}
+	pr := p.forUser(userID)
+	r := Rendered{Version: pr.version}
+	var sb strings.Builder
+	if err := pr.system.Execute(&sb, vars); err != nil {
+		return Rendered{}, fmt.Errorf("cannot render prompt %s/%s: %w", pr.version, systemTemplate, err)
+	}
+	r.System = sb.String()
+	sb.Reset()
+	if err := pr.context.Execute(&sb, vars); err != nil {
+		return Rendered{}, fmt.Errorf("cannot render prompt %s/%s: %w", pr.version, contextTemplate, err)
+	}
+	r.Context = sb.String()
+	return r, nil
+}
+
+// Version returns the prompt version assigned to the user.
+func (p *Prompts) Version(userID int64) string {
+	return p.forUser(userID).version
+}
+
+// forUser splits users between versions by hash, so the same user always gets the same version.
+func (p *Prompts) forUser(userID int64) prompt {
+	if p.experiment == nil {
+		return p.main
+	}
+	if bucket(p.experiment.version, userID) < p.cfg.Experiment.Share {
+		return *p.experiment
+	}
+	return p.main
+}
+
+// bucket maps the user to [0, 1).
+// The experiment version salts the hash, so every experiment gets its own split.
+func bucket(salt string, userID int64) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(salt))
+	_, _ = h.Write([]byte(strconv.FormatInt(userID, 10)))
+	const buckets = 10000
+	return float64(h.Sum64()%buckets) / buckets
+}
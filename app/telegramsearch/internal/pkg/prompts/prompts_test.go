@@ -0,0 +1,140 @@
+package prompts_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/prompts"
+)
+
+func TestRender_embedded(t *testing.T) {
+	p, err := prompts.New(prompts.DefaultConfig())
+	require.NoError(t, err)
+
+	r, err := p.Render(1, prompts.Vars{
+		Date:          "2024-06-01",
+		Conversations: []string{"first conversation", "second conversation"},
+		Question:      "best pediatrician?",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "v1", r.Version)
+	require.True(t, len(r.System) > 0)
+	require.NotContains(t, r.System, "{{")
+	require.NotContains(t, r.System, "community.openai.com", "template comment must not be rendered")
+	require.Contains(t, r.Context, "Strongly prefer answering in Russian language. User is definitely asking about Cyprus.")
+	require.Contains(t, r.Context, "first conversation\n\nsecond conversation\n\nQuestion: best pediatrician?\n")
+}
+
+func TestRender_varsOverrideConfig(t *testing.T) {
+	p, err := prompts.New(prompts.DefaultConfig())
+	require.NoError(t, err)
+
+	r, err := p.Render(1, prompts.Vars{Locality: "Bangkok", Language: "English"})
+	require.NoError(t, err)
+	require.Contains(t, r.Context, "answering in English language. User is definitely asking about Bangkok.")
+	require.Contains(t, r.Context, "Do not highlight that the response is about Cyprus")
+}
+
+func writeVersion(t *testing.T, dir, version, system, context string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, version), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, version, "system.tmpl"), []byte(system), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, version, "context.tmpl"), []byte(context), 0o600))
+}
+
+func TestRender_dirAndExperiment(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion(t, dir, "a", "system a", "a {{ .Locality }} {{ .Date }}: {{ .Question }}")
+	writeVersion(t, dir, "b", "system b", "b {{ .Locality }} {{ .Date }}: {{ .Question }}")
+
+	cfg := prompts.DefaultConfig()
+	cfg.Dir = dir
+	cfg.Version = "a"
+	cfg.Locality = "Bangkok"
+	cfg.Experiment = prompts.Experiment{Version: "b", Share: 0.3}
+	p, err := prompts.New(cfg)
+	require.NoError(t, err)
+
+	versions := map[string]int{}
+	const users = 10000
+	for userID := int64(0); userID < users; userID++ {
+		r, err := p.Render(userID, prompts.Vars{Date: "2024-06-01", Question: "q"})
+		require.NoError(t, err)
+		require.Equal(t, "system "+r.Version, r.System)
+		require.Equal(t, r.Version+" Bangkok 2024-06-01: q", r.Context)
+		versions[r.Version]++
+
+		again, err := p.Render(userID, prompts.Vars{})
+		require.NoError(t, err)
+		require.Equal(t, r.Version, again.Version, "user must stick to the version")
+		require.Equal(t, r.Version, p.Version(userID))
+	}
+	require.InDelta(t, 0.3, float64(versions["b"])/users, 0.03)
+	require.Equal(t, users, versions["a"]+versions["b"])
+}
+
+func TestNew_errors(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion(t, dir, "broken", "ok", "{{ .Unknown")
+	writeVersion(t, dir, "unknown_var", "ok", "{{ .Unknown }}")
+
+	tests := []struct {
+		name   string
+		modify func(cfg *prompts.Config)
+		errMsg string
+	}{
+		{
+			name:   "no version",
+			modify: func(cfg *prompts.Config) { cfg.Version = "" },
+			errMsg: "prompts version is required",
+		},
+		{
+			name:   "missing version",
+			modify: func(cfg *prompts.Config) { cfg.Version = "v0" },
+			errMsg: "cannot read prompt v0/system.tmpl",
+		},
+		{
+			name: "share out of range",
+			modify: func(cfg *prompts.Config) {
+				cfg.Experiment = prompts.Experiment{Version: "v2", Share: 1.5}
+			},
+			errMsg: "prompts experiment share must be within [0, 1]",
+		},
+		{
+			name: "experiment equals version",
+			modify: func(cfg *prompts.Config) {
+				cfg.Experiment = prompts.Experiment{Version: "v1", Share: 0.5}
+			},
+			errMsg: "prompts experiment version must differ",
+		},
+		{
+			name: "cannot parse",
+			modify: func(cfg *prompts.Config) {
+				cfg.Dir = dir
+				cfg.Version = "broken"
+			},
+			errMsg: "cannot parse prompt broken/context.tmpl",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := prompts.DefaultConfig()
+			tt.modify(&cfg)
+			_, err := prompts.New(cfg)
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+
+	t.Run("unknown variable fails rendering", func(t *testing.T) {
+		cfg := prompts.DefaultConfig()
+		cfg.Dir = dir
+		cfg.Version = "unknown_var"
+		p, err := prompts.New(cfg)
+		require.NoError(t, err)
+		_, err = p.Render(1, prompts.Vars{})
+		require.ErrorContains(t, err, "cannot render prompt unknown_var/context.tmpl")
+	})
+}
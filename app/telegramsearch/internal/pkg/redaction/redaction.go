@@ -0,0 +1,286 @@
+// Package redaction removes personal data from chat messages
+// before they are stored or sent to the LLM.
+//
+// It detects phone numbers, emails and Telegram usernames in text,
+// and redacts Telegram user IDs (from_id) of message authors.
+// Allow-listed contacts, e.g. of businesses, are kept as is.
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/yanakipre/bot/internal/secret"
+)
+
+// Mode is how the detected personal data is redacted.
+type Mode string
+
+const (
+	// ModeMask replaces personal data with a placeholder, e.g. [phone].
+	ModeMask Mode = "mask"
+	// ModeHash replaces personal data with a salted hash,
+	// so the same contact is recognizable across messages, e.g. [phone:kdmaeopb].
+	ModeHash Mode = "hash"
+	// ModeDrop removes personal data.
+	ModeDrop Mode = "drop"
+)
+
+// Kind is a kind of personal data.
+type Kind string
+
+const (
+	KindPhone    Kind = "phone"
+	KindEmail    Kind = "email"
+	KindUsername Kind = "username"
+	KindUserID   Kind = "user"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+	Mode    Mode `yaml:"mode"`
+	// HashSalt makes hashes and author keys unguessable.
+	// It is required: author keys are stored even when redaction is disabled,
+	// without the salt anyone could find the Telegram ID behind a key by hashing candidate IDs.
+	HashSalt  secret.String `yaml:"hash_salt"`
+	Phones    bool          `yaml:"phones"`
+	Emails    bool          `yaml:"emails"`
+	Usernames bool          `yaml:"usernames"`
+	// UserIDs redacts from_id of message authors.
+	UserIDs bool `yaml:"user_ids"`
+	// Allowlist of business contacts to keep: phone numbers, emails and usernames.
+	// Phone numbers are compared by digits, the rest case-insensitively.
+	Allowlist []string `yaml:"allowlist"`
+	// OptedOut are from_id (e.g. "user123456") of users whose messages are removed entirely.
+	// Users who asked to forget them are opted out as well, without being listed here.
+	OptedOut []string `yaml:"opted_out"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Enabled:   true,
+		Mode:      ModeMask,
+		Phones:    true,
+		Emails:    true,
+		Usernames: true,
+		UserIDs:   true,
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.HashSalt.Unmask() == "" {
+		return errors.New("redaction hash_salt is required, author keys are salted with it")
+	}
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Mode {
+	case ModeMask, ModeDrop, ModeHash:
+	default:
+		return fmt.Errorf("unknown redaction mode %q", c.Mode)
+	}
+	return nil
+}
+
+var (
+	emailRe = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// Telegram usernames are 5-32 characters long. The first group is the username,
+	// the prefix makes sure it is not a part of an email.
+	usernameRe = regexp.MustCompile(`(?:^|[^A-Za-z0-9._%+\-])(@[A-Za-z][A-Za-z0-9_]{4,31})\b`)
+	phoneRe    = regexp.MustCompile(`\+?\d[\d\s\-().]{5,}\d`)
+	dateRe     = regexp.MustCompile(`^\d{1,4}[.\-/]\d{1,2}[.\-/]\d{1,4}$`)
+	// placeholderRe matches what redact replaces personal data with, hashes are made of letters a-p.
+	placeholderRe = regexp.MustCompile(`\[(?:phone|email|username|user)(?::[a-p]+)?\]`)
+)
+
+// ContainsPlaceholder reports whether the text has personal data redacted in it, e.g. [phone].
+func ContainsPlaceholder(text string) bool {
+	return placeholderRe.MatchString(text)
+}
+
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+// Redactor redacts personal data according to the config.
+// Disabled redactor returns everything unchanged.
+type Redactor struct {
+	cfg       Config
+	allowlist []string
+	optedOut  []string
+}
+
+func New(cfg Config) (*Redactor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	r := &Redactor{cfg: cfg, optedOut: slices.Clone(cfg.OptedOut)}
+	for _, contact := range cfg.Allowlist {
+		r.allowlist = append(r.allowlist, normalize(contact))
+	}
+	return r, nil
+}
+
+// OptedOut reports whether the author's messages must be removed entirely.
+func (r *Redactor) OptedOut(fromID string) bool {
+	return fromID != "" && slices.Contains(r.optedOut, fromID)
+}
+
+// Text redacts personal data in text.
+func (r *Redactor) Text(text string) string {
+	if !r.cfg.Enabled {
+		return text
+	}
+	// emails go first, they contain @ like usernames.
+	if r.cfg.Emails {
+		text = r.replace(text, emailRe, KindEmail, nil)
+	}
+	if r.cfg.Usernames {
+		text = r.replace(text, usernameRe, KindUsername, nil)
+	}
+	if r.cfg.Phones {
+		text = r.replace(text, phoneRe, KindPhone, isPhone)
+	}
+	return text
+}
+
+// Entity redacts text of Telegram message entity of the given type, e.g. "phone" or "mention".
+// Entities Telegram recognized as personal data are redacted as a whole.
+func (r *Redactor) Entity(entityType, text string) string {
+	if !r.cfg.Enabled {
+		return text
+	}
+	var kind Kind
+	switch {
+	case entityType == "phone" && r.cfg.Phones:
+		kind = KindPhone
+	case entityType == "email" && r.cfg.Emails:
+		kind = KindEmail
+	case entityType == "mention" && r.cfg.Usernames:
+		kind = KindUsername
+	default:
+		return r.Text(text)
+	}
+	if r.allowed(text) {
+		return text
+	}
+	return r.redact(kind, text)
+}
+
+// UserID redacts from_id of the message author.
+func (r *Redactor) UserID(fromID string) string {
+	if !r.cfg.Enabled || !r.cfg.UserIDs || fromID == "" {
+		return fromID
+	}
+	if r.cfg.Mode == ModeHash {
+		return string(KindUserID) + ":" + r.hash(fromID)
+	}
+	return ""
+}
+
+// AuthorKey is a pseudonymous key of the message author, stored to find the author's messages,
+// e.g. to forget them. It does not depend on Enabled.
+// Changing HashSalt makes keys of already stored messages unrecognizable.
+func (r *Redactor) AuthorKey(fromID string) string {
+	if fromID == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(r.cfg.HashSalt.Unmask()))
+	_, _ = mac.Write([]byte(fromID))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// replace redacts matches of re. When re has a group, only the group is redacted.
+func (r *Redactor) replace(text string, re *regexp.Regexp, kind Kind, valid func(string) bool) string {
+	var sb strings.Builder
+	last := 0
+	for _, idx := range re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := idx[0], idx[1]
+		if len(idx) > 2 {
+			start, end = idx[2], idx[3]
+		}
+		match := text[start:end]
+		if (valid != nil && !valid(match)) || r.allowed(match) {
+			continue
+		}
+		sb.WriteString(text[last:start])
+		sb.WriteString(r.redact(kind, match))
+		last = end
+	}
+	if last == 0 {
+		return text
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+func (r *Redactor) redact(kind Kind, value string) string {
+	switch r.cfg.Mode {
+	case ModeHash:
+		return "[" + string(kind) + ":" + r.hash(value) + "]"
+	case ModeDrop:
+		return ""
+	default:
+		return "[" + string(kind) + "]"
+	}
+}
+
+// hash returns a short salted hash of the normalized value.
+// It is made of letters only, so it never looks like a phone number.
+func (r *Redactor) hash(value string) string {
+	mac := hmac.New(sha256.New, []byte(r.cfg.HashSalt.Unmask()))
+	_, _ = mac.Write([]byte(normalize(value)))
+	sum := mac.Sum(nil)
+	var sb strings.Builder
+	for _, b := range sum[:4] {
+		sb.WriteByte('a' + b>>4)
+		sb.WriteByte('a' + b&0x0f)
+	}
+	return sb.String()
+}
+
+func (r *Redactor) allowed(value string) bool {
+	return slices.Contains(r.allowlist, normalize(value))
+}
+
+// normalize makes contacts comparable: phones by digits, the rest case-insensitively.
+func normalize(value string) string {
+	value = strings.TrimSpace(value)
+	if isPhone(value) {
+		return digits(value)
+	}
+	return strings.ToLower(strings.TrimPrefix(value, "@"))
+}
+
+func isPhone(value string) bool {
+	if dateRe.MatchString(value) {
+		return false
+	}
+	n := len(digits(value))
+	if n < minPhoneDigits || n > maxPhoneDigits {
+		return false
+	}
+	for _, c := range value {
+		if !unicode.IsDigit(c) && !strings.ContainsRune("+ -().", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, value)
+}
@@ -0,0 +1,158 @@
+package redaction_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/redaction"
+	"github.com/yanakipre/bot/internal/secret"
+)
+
+func testConfig() redaction.Config {
+	cfg := redaction.DefaultConfig()
+	cfg.HashSalt = secret.NewString("salt")
+	return cfg
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *redaction.Config)
+		input  string
+		want   string
+	}{
+		{
+			name:  "phone",
+			input: "call +357 99 000-000 or 99123456",
+			want:  "call [phone] or [phone]",
+		},
+		{
+			name:  "dates and short numbers are not phones",
+			input: "on 01.06.2024 at 10:30, 2 000 euro, room 12345",
+			want:  "on 01.06.2024 at 10:30, 2 000 euro, room 12345",
+		},
+		{
+			name:  "email and username",
+			input: "write to John.Doe@example.com or @johndoe",
+			want:  "write to [email] or [username]",
+		},
+		{
+			name: "allow-listed business contacts are kept",
+			modify: func(cfg *redaction.Config) {
+				cfg.Allowlist = []string{"+35725000000", "@bestclinic", "INFO@clinic.cy"}
+			},
+			input: "clinic +357 25 000000, @BestClinic, info@clinic.cy, mine +35799000000",
+			want:  "clinic +357 25 000000, @BestClinic, info@clinic.cy, mine [phone]",
+		},
+		{
+			name:   "drop",
+			modify: func(cfg *redaction.Config) { cfg.Mode = redaction.ModeDrop },
+			input:  "call +35799000000",
+			want:   "call ",
+		},
+		{
+			name:   "hash is stable and does not look like a phone",
+			modify: func(cfg *redaction.Config) { cfg.Mode = redaction.ModeHash },
+			input:  "+357 99 000000 and +35799000000",
+			want:   "[phone:bfdbbkjk] and [phone:bfdbbkjk]",
+		},
+		{
+			name:   "disabled detector",
+			modify: func(cfg *redaction.Config) { cfg.Usernames = false },
+			input:  "@johndoe +35799000000",
+			want:   "@johndoe [phone]",
+		},
+		{
+			name:   "disabled",
+			modify: func(cfg *redaction.Config) { cfg.Enabled = false },
+			input:  "@johndoe +35799000000",
+			want:   "@johndoe +35799000000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+			r, err := redaction.New(cfg)
+			require.NoError(t, err)
+			got := r.Text(tt.input)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, got, r.Text(got), "redaction must be idempotent")
+		})
+	}
+}
+
+func TestEntity(t *testing.T) {
+	cfg := testConfig()
+	cfg.Allowlist = []string{"@bestclinic"}
+	r, err := redaction.New(cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, "[phone]", r.Entity("phone", "8 800 555"))
+	require.Equal(t, "[username]", r.Entity("mention", "@john"))
+	require.Equal(t, "@bestclinic", r.Entity("mention", "@bestclinic"))
+	require.Equal(t, "plain [email]", r.Entity("plain", "plain a@b.cy"))
+}
+
+func TestUserID(t *testing.T) {
+	cfg := testConfig()
+	cfg.OptedOut = []string{"user2"}
+	r, err := redaction.New(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "", r.UserID("user1"))
+	require.True(t, r.OptedOut("user2"))
+	require.False(t, r.OptedOut("user1"))
+	require.False(t, r.OptedOut(""))
+
+	cfg.Mode = redaction.ModeHash
+	r, err = redaction.New(cfg)
+	require.NoError(t, err)
+	require.Regexp(t, `^user:[a-p]{8}$`, r.UserID("user1"))
+	require.Equal(t, r.UserID("user1"), r.UserID("user1"))
+	require.NotEqual(t, r.UserID("user1"), r.UserID("user2"))
+}
+
+func TestAuthorKey(t *testing.T) {
+	cfg := testConfig()
+	cfg.Enabled = false
+	r, err := redaction.New(cfg)
+	require.NoError(t, err)
+	require.Len(t, r.AuthorKey("user1"), 32)
+	require.Equal(t, r.AuthorKey("user1"), r.AuthorKey("user1"))
+	require.NotEqual(t, r.AuthorKey("user1"), r.AuthorKey("user2"))
+	require.Empty(t, r.AuthorKey(""))
+
+	cfg.HashSalt = secret.NewString("pepper")
+	peppered, err := redaction.New(cfg)
+	require.NoError(t, err)
+	require.NotEqual(t, r.AuthorKey("user1"), peppered.AuthorKey("user1"))
+}
+
+func TestContainsPlaceholder(t *testing.T) {
+	for _, mode := range []redaction.Mode{redaction.ModeMask, redaction.ModeHash} {
+		cfg := testConfig()
+		cfg.Mode = mode
+		r, err := redaction.New(cfg)
+		require.NoError(t, err)
+		require.True(t, redaction.ContainsPlaceholder(r.Text("call +357 99 123456")), mode)
+		require.True(t, redaction.ContainsPlaceholder(r.Entity("mention", "@someone")), mode)
+	}
+	require.False(t, redaction.ContainsPlaceholder("+357 99 123456"))
+	require.False(t, redaction.ContainsPlaceholder("Makariou 1 [2nd floor]"))
+}
+
+func TestNew_errors(t *testing.T) {
+	// author keys are salted even when redaction is disabled.
+	cfg := redaction.DefaultConfig()
+	cfg.Enabled = false
+	_, err := redaction.New(cfg)
+	require.ErrorContains(t, err, "hash_salt is required")
+
+	cfg = testConfig()
+	cfg.Mode = "blur"
+	_, err = redaction.New(cfg)
+	require.ErrorContains(t, err, `unknown redaction mode "blur"`)
+}
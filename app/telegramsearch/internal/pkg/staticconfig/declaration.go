@@ -3,11 +3,17 @@ package staticconfig
 
 import (
 	"errors"
+	"fmt"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/emsc"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes/usgs"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/httpopenaiclient"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/bottransport"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/bottransportv2"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport"
+	"github.com/yanakipre/bot/internal/buses/busregistry"
 
 	"github.com/yanakipre/bot/internal/logger"
 )
@@ -18,15 +24,41 @@ type Config struct {
 	OpenAI            httpopenaiclient.Config `yaml:"openai"`
 	Logging           logger.Config           `yaml:"logging"`
 	TelegramTransport bottransport.Config     `yaml:"telegram_transport"`
-	TelegramV2        bottransportv2.Config   `yaml:"telegram_v2"`
+	// TenantBots run next to TelegramTransport, a bot per tenant.
+	TenantBots []bottransport.Config `yaml:"tenant_bots"`
+	TelegramV2 bottransportv2.Config `yaml:"telegram_v2"`
+	// SearchAPI is served by "telegramsearch serve".
+	SearchAPI searchtransport.Config `yaml:"search_api"`
+	// Earthquakes is the feed the bot polls for earthquake alerts.
+	Earthquakes earthquakes.Config `yaml:"earthquakes"`
+	// EMSC and USGS report earthquakes as well, often faster. Empty api_url disables the source.
+	EMSC emsc.Config `yaml:"emsc"`
+	USGS usgs.Config `yaml:"usgs"`
+	// EarthquakesMerge dedupes the same earthquake reported by several sources.
+	EarthquakesMerge earthquakes.MergeConfig `yaml:"earthquakes_merge"`
+	// Buses are looked up by the location shared with the bot.
+	Buses busregistry.Config `yaml:"buses"`
 }
 
 func DefaultConfig() Config {
+	var quakes earthquakes.Config
+	quakes.Default()
+	var emscCfg emsc.Config
+	emscCfg.Default()
+	var usgsCfg usgs.Config
+	usgsCfg.Default()
 	return Config{
-		Ctlv1:      controllerv1.DefaultConfig(),
-		OpenAI:     httpopenaiclient.DefaultConfig(),
-		Logging:    logger.DefaultConfig(),
-		TelegramV2: bottransportv2.DefaultConfig(),
+		Ctlv1:             controllerv1.DefaultConfig(),
+		OpenAI:            httpopenaiclient.DefaultConfig(),
+		Logging:           logger.DefaultConfig(),
+		TelegramV2:        bottransportv2.DefaultConfig(),
+		TelegramTransport: bottransport.DefaultConfig(),
+		SearchAPI:         searchtransport.DefaultConfig(),
+		Earthquakes:       quakes,
+		EMSC:              emscCfg,
+		USGS:              usgsCfg,
+		EarthquakesMerge:  earthquakes.DefaultMergeConfig(),
+		Buses:             busregistry.DefaultConfig(),
 	}
 }
 
@@ -34,5 +66,24 @@ func DefaultConfig() Config {
 func (c *Config) Validate() error {
 	return errors.Join(
 		c.TelegramV2.Validate(),
+		c.Ctlv1.Validate(),
+		c.ValidateTenantBots(),
+		c.Buses.Validate(),
 	)
 }
+
+// ValidateTenantBots checks every tenant bot serves a configured tenant.
+func (c *Config) ValidateTenantBots() error {
+	for _, b := range c.TenantBots {
+		if b.Tenant == "" {
+			return errors.New("tenant bot must have a tenant")
+		}
+		if !c.Ctlv1.HasTenant(b.Tenant) {
+			return fmt.Errorf("tenant bot refers to unknown tenant %q", b.Tenant)
+		}
+		if b.Token.Unmask() == "" {
+			return fmt.Errorf("tenant bot for %q has no token", b.Tenant)
+		}
+	}
+	return nil
+}
@@ -1,9 +1,13 @@
 package staticconfig
 
 import (
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/earthquakes"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/openaiclient/httpopenaiclient"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/client/storage/postgres"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/bottransport"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport"
+	"github.com/yanakipre/bot/internal/buses/busregistry"
 	"github.com/yanakipre/bot/internal/logger"
 )
 
@@ -11,5 +15,12 @@ func (c *Config) DefaultConfig() {
 	c.Ctlv1 = controllerv1.DefaultConfig()
 	c.OpenAI = httpopenaiclient.DefaultConfig()
 	c.PostgresRW = postgres.Default()
+	c.Earthquakes.Default()
+	c.EMSC.Default()
+	c.USGS.Default()
+	c.EarthquakesMerge = earthquakes.DefaultMergeConfig()
+	c.Buses = busregistry.DefaultConfig()
 	c.Logging = logger.DefaultConfig()
+	c.TelegramTransport = bottransport.DefaultConfig()
+	c.SearchAPI = searchtransport.DefaultConfig()
 }
@@ -0,0 +1,296 @@
+// Package threadfilter drops chat threads and replies that are not worth answering from:
+// ads, scams, bot messages, bare links and "+1"/"thanks" replies.
+//
+// It only applies heuristics. The optional LLM classifier is configured here,
+// and is called by the controller for threads the heuristics kept.
+package threadfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Reason is why the thread is filtered out. Empty reason keeps the thread.
+type Reason string
+
+const (
+	// ReasonSpam is for threads started with a message matching a spam pattern.
+	ReasonSpam Reason = "spam"
+	// ReasonSpammer is for threads started by an author who sent spam.
+	ReasonSpammer Reason = "spammer"
+	// ReasonBot is for threads started by a bot.
+	ReasonBot Reason = "bot"
+	// ReasonLinkOnly is for threads started with links and nothing else.
+	ReasonLinkOnly Reason = "link_only"
+	// ReasonLowContent is for threads started with too short a message,
+	// and for replies too short or with nothing but thanks.
+	ReasonLowContent Reason = "low_content"
+	// ReasonNoAnswers is for threads all the answers of which were dropped.
+	ReasonNoAnswers Reason = "no_answers"
+	// ReasonClassifier is for threads the LLM classified as spam.
+	ReasonClassifier Reason = "classifier"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+	// MinStarterLetters is the minimum number of letters and digits in the first message.
+	MinStarterLetters int `yaml:"min_starter_letters"`
+	// MinReplyLetters is the minimum number of letters and digits in a reply.
+	MinReplyLetters int `yaml:"min_reply_letters"`
+	// LowContentReplies are dropped regardless of the length, compared case-insensitively
+	// without punctuation, e.g. "thanks" or "спасибо".
+	LowContentReplies []string `yaml:"low_content_replies"`
+	// SpamPatterns are regular expressions of ads and scams.
+	SpamPatterns []string `yaml:"spam_patterns"`
+	// SpammerMessages is how many spam messages make the author a spammer,
+	// all the author's messages are dropped then. Zero disables the check.
+	SpammerMessages int `yaml:"spammer_messages"`
+	// Bots drops messages sent via bots.
+	Bots bool `yaml:"bots"`
+	// LinkOnly drops messages with links and nothing else.
+	LinkOnly   bool             `yaml:"link_only"`
+	Classifier ClassifierConfig `yaml:"classifier"`
+}
+
+// ClassifierConfig asks the LLM whether a thread the heuristics keep is spam, at ingestion.
+type ClassifierConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Prompt asks about the conversation given after it, SpamAnswer means spam.
+	Prompt     string `yaml:"prompt"`
+	SpamAnswer string `yaml:"spam_answer"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Enabled:           true,
+		MinStarterLetters: 10,
+		MinReplyLetters:   3,
+		LowContentReplies: []string{
+			"+1", "+", "thanks", "thank you", "thx", "same", "me too", "up", "bump",
+			"спасибо", "спс", "благодарю", "тоже интересует", "присоединяюсь", "апну", "ап",
+		},
+		SpamPatterns: []string{
+			`(?i)заработ\pL*\s+от\s+\d+`,
+			`(?i)пассивн\pL+\s+доход`,
+			`(?i)пиши(те)?\s+в\s+(лс|личку|личные)`,
+			`(?i)earn\s+\$?\d+\s*(\$|usd|dollars)?\s+(per|a)\s+(day|week)`,
+			`(?i)(crypto|крипт\pL*)\s+(signals|сигнал\pL*)`,
+			`(?i)\b(dm|pm)\s+me\b`,
+		},
+		SpammerMessages: 2,
+		Bots:            true,
+		LinkOnly:        true,
+		Classifier: ClassifierConfig{
+			Prompt: "You moderate a chat where people ask each other for advice." +
+				" You are given a conversation from the chat." +
+				" Answer \"spam\" if it is an advertisement, a scam or spam, and \"ok\" otherwise." +
+				" Answer with one word.",
+			SpamAnswer: "spam",
+		},
+	}
+}
+
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	for _, p := range c.SpamPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid spam pattern %q: %w", p, err)
+		}
+	}
+	if c.Classifier.Enabled && (c.Classifier.Prompt == "" || c.Classifier.SpamAnswer == "") {
+		return fmt.Errorf("classifier prompt and spam_answer are required")
+	}
+	return nil
+}
+
+// Part is a Telegram text entity of the message.
+type Part struct {
+	// Type is "plain", "link", "text_link", "mention", etc.
+	Type string
+	Text string
+}
+
+type Message struct {
+	AuthorKey string
+	Parts     []Part
+	// ViaBot is true for messages sent via a bot.
+	ViaBot bool
+}
+
+func (m Message) text() string {
+	var sb strings.Builder
+	for _, p := range m.Parts {
+		sb.WriteString(p.Text)
+	}
+	return sb.String()
+}
+
+// Authors is a set of Message.AuthorKey.
+type Authors map[string]struct{}
+
+// Filter applies heuristics. Disabled filter keeps everything.
+type Filter struct {
+	cfg        Config
+	spam       []*regexp.Regexp
+	lowContent map[string]struct{}
+}
+
+func New(cfg Config) (*Filter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	f := &Filter{cfg: cfg, lowContent: make(map[string]struct{}, len(cfg.LowContentReplies))}
+	for _, p := range cfg.SpamPatterns {
+		f.spam = append(f.spam, regexp.MustCompile(p))
+	}
+	for _, r := range cfg.LowContentReplies {
+		f.lowContent[normalize(r)] = struct{}{}
+	}
+	return f, nil
+}
+
+// Classifier returns the LLM classifier config, it is disabled when the filter is.
+func (f *Filter) Classifier() ClassifierConfig {
+	if !f.cfg.Enabled {
+		return ClassifierConfig{}
+	}
+	return f.cfg.Classifier
+}
+
+// Spammers returns authors of at least SpammerMessages spam messages.
+func (f *Filter) Spammers(msgs []Message) Authors {
+	r := Authors{}
+	if !f.cfg.Enabled || f.cfg.SpammerMessages == 0 {
+		return r
+	}
+	counts := map[string]int{}
+	for _, m := range msgs {
+		if m.AuthorKey == "" || !f.isSpam(m) {
+			continue
+		}
+		counts[m.AuthorKey]++
+		if counts[m.AuthorKey] >= f.cfg.SpammerMessages {
+			r[m.AuthorKey] = struct{}{}
+		}
+	}
+	return r
+}
+
+// Thread returns indexes of the replies to keep, and why the whole thread is filtered out.
+// The first message of the thread is the conversation starter, the rest are replies.
+func (f *Filter) Thread(msgs []Message, spammers Authors) ([]int, Reason) {
+	if !f.cfg.Enabled || len(msgs) == 0 {
+		return allReplies(len(msgs)), ""
+	}
+	if reason := f.message(msgs[0], spammers); reason != "" {
+		return nil, reason
+	}
+	if letters(msgs[0].text()) < f.cfg.MinStarterLetters {
+		return nil, ReasonLowContent
+	}
+	var keep []int
+	for i := 1; i < len(msgs); i++ {
+		if f.Reply(msgs[i], spammers) != "" {
+			continue
+		}
+		keep = append(keep, i)
+	}
+	if len(keep) == 0 {
+		return nil, ReasonNoAnswers
+	}
+	return keep, ""
+}
+
+// Reply returns why the reply is dropped from its thread, empty when it is kept.
+func (f *Filter) Reply(m Message, spammers Authors) Reason {
+	if !f.cfg.Enabled {
+		return ""
+	}
+	if reason := f.message(m, spammers); reason != "" {
+		return reason
+	}
+	if f.lowContentReply(m) {
+		return ReasonLowContent
+	}
+	return ""
+}
+
+func allReplies(n int) []int {
+	r := make([]int, 0, n)
+	for i := 1; i < n; i++ {
+		r = append(r, i)
+	}
+	return r
+}
+
+// message returns why the message is dropped.
+func (f *Filter) message(m Message, spammers Authors) Reason {
+	if _, ok := spammers[m.AuthorKey]; ok && m.AuthorKey != "" {
+		return ReasonSpammer
+	}
+	if f.cfg.Bots && m.ViaBot {
+		return ReasonBot
+	}
+	if f.isSpam(m) {
+		return ReasonSpam
+	}
+	if f.cfg.LinkOnly && linkOnly(m) {
+		return ReasonLinkOnly
+	}
+	return ""
+}
+
+func (f *Filter) isSpam(m Message) bool {
+	text := m.text()
+	for _, re := range f.spam {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) lowContentReply(m Message) bool {
+	text := m.text()
+	if letters(text) < f.cfg.MinReplyLetters {
+		return true
+	}
+	_, ok := f.lowContent[normalize(text)]
+	return ok
+}
+
+// linkOnly reports whether the message has links and nothing else but whitespace.
+func linkOnly(m Message) bool {
+	links := 0
+	for _, p := range m.Parts {
+		switch {
+		case p.Type == "link" || p.Type == "text_link":
+			links++
+		case strings.TrimSpace(p.Text) != "":
+			return false
+		}
+	}
+	return links > 0
+}
+
+func letters(text string) int {
+	n := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// normalize lower-cases the text and leaves only words, "+" is kept for "+1".
+func normalize(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+'
+	})
+	return strings.Join(words, " ")
+}
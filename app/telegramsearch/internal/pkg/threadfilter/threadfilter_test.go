@@ -0,0 +1,126 @@
+package threadfilter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/threadfilter"
+)
+
+func plain(author, text string) threadfilter.Message {
+	return threadfilter.Message{AuthorKey: author, Parts: []threadfilter.Part{{Type: "plain", Text: text}}}
+}
+
+func TestThread(t *testing.T) {
+	const question = "Can anyone recommend a pediatrician in Limassol?"
+	const answer = "Doctor X is the best pediatrician"
+	tests := []struct {
+		name       string
+		modify     func(cfg *threadfilter.Config)
+		msgs       []threadfilter.Message
+		spammers   threadfilter.Authors
+		wantKeep   []int
+		wantReason threadfilter.Reason
+	}{
+		{
+			name:     "good thread",
+			msgs:     []threadfilter.Message{plain("a", question), plain("b", answer)},
+			wantKeep: []int{1},
+		},
+		{
+			name: "thanks and +1 replies are dropped",
+			msgs: []threadfilter.Message{
+				plain("a", question), plain("b", answer), plain("c", "+1"), plain("a", "Спасибо!"), plain("d", "ok"),
+			},
+			wantKeep: []int{1},
+		},
+		{
+			name:       "nothing but thanks",
+			msgs:       []threadfilter.Message{plain("a", question), plain("c", "Thank you!")},
+			wantReason: threadfilter.ReasonNoAnswers,
+		},
+		{
+			name:       "short question",
+			msgs:       []threadfilter.Message{plain("a", "hi all"), plain("b", answer)},
+			wantReason: threadfilter.ReasonLowContent,
+		},
+		{
+			name:       "ad",
+			msgs:       []threadfilter.Message{plain("a", "Пассивный доход от 500$ в неделю, пишите в лс"), plain("b", "scam")},
+			wantReason: threadfilter.ReasonSpam,
+		},
+		{
+			name: "link only",
+			msgs: []threadfilter.Message{
+				{AuthorKey: "a", Parts: []threadfilter.Part{{Type: "link", Text: "https://example.com"}, {Type: "plain", Text: " "}}},
+				plain("b", answer),
+			},
+			wantReason: threadfilter.ReasonLinkOnly,
+		},
+		{
+			name:       "bot",
+			msgs:       []threadfilter.Message{{AuthorKey: "a", ViaBot: true, Parts: []threadfilter.Part{{Text: question}}}, plain("b", answer)},
+			wantReason: threadfilter.ReasonBot,
+		},
+		{
+			name:     "replies of spammers are dropped",
+			msgs:     []threadfilter.Message{plain("a", question), plain("s", answer), plain("b", answer)},
+			spammers: threadfilter.Authors{"s": {}},
+			wantKeep: []int{2},
+		},
+		{
+			name:       "threads of spammers are dropped",
+			msgs:       []threadfilter.Message{plain("s", question), plain("b", answer)},
+			spammers:   threadfilter.Authors{"s": {}},
+			wantReason: threadfilter.ReasonSpammer,
+		},
+		{
+			name:     "disabled",
+			modify:   func(cfg *threadfilter.Config) { cfg.Enabled = false },
+			msgs:     []threadfilter.Message{plain("a", "hi"), plain("b", "+1"), plain("c", "thanks")},
+			wantKeep: []int{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := threadfilter.DefaultConfig()
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+			f, err := threadfilter.New(cfg)
+			require.NoError(t, err)
+			keep, reason := f.Thread(tt.msgs, tt.spammers)
+			require.Equal(t, tt.wantReason, reason)
+			require.Equal(t, tt.wantKeep, keep)
+		})
+	}
+}
+
+func TestReply(t *testing.T) {
+	f, err := threadfilter.New(threadfilter.DefaultConfig())
+	require.NoError(t, err)
+	spammers := threadfilter.Authors{"s": {}}
+	require.Equal(t, threadfilter.Reason(""), f.Reply(plain("b", "Doctor X is the best pediatrician"), spammers))
+	require.Equal(t, threadfilter.ReasonLowContent, f.Reply(plain("c", "Спасибо!"), spammers))
+	require.Equal(t, threadfilter.ReasonSpammer, f.Reply(plain("s", "Doctor Y is good too"), spammers))
+}
+
+func TestSpammers(t *testing.T) {
+	f, err := threadfilter.New(threadfilter.DefaultConfig())
+	require.NoError(t, err)
+	spammers := f.Spammers([]threadfilter.Message{
+		plain("s", "Crypto signals, DM me"),
+		plain("s", "Заработок от 1000$ в день"),
+		plain("once", "Крипто сигналы бесплатно"),
+		plain("b", "Doctor X is the best pediatrician"),
+	})
+	require.Equal(t, threadfilter.Authors{"s": {}}, spammers)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := threadfilter.DefaultConfig()
+	cfg.SpamPatterns = []string{"("}
+	_, err := threadfilter.New(cfg)
+	require.ErrorContains(t, err, "invalid spam pattern")
+}
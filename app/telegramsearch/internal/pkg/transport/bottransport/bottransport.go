@@ -2,17 +2,21 @@ package bottransport
 
 import (
 	"context"
+	"fmt"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
 	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"strings"
 	"time"
 
 	"github.com/tucnak/telebot"
 	"github.com/yanakipre/bot/internal/logger"
+	"github.com/yanakipre/bot/internal/semerr"
 	"go.uber.org/zap"
 )
 
 func New(ctx context.Context, ctl *controllerv1.Ctl, cfg Config) (*telebot.Bot, error) {
-	lg := logger.FromContext(ctx)
+	cfg = cfg.withDefaults()
+	lg := logger.FromContext(ctx).With(zap.String("tenant", cfg.Tenant))
 	pref := telebot.Settings{
 		Token:  cfg.Token.Unmask(),
 		Poller: &telebot.LongPoller{Timeout: 1 * time.Second},
@@ -34,6 +38,268 @@ func New(ctx context.Context, ctl *controllerv1.Ctl, cfg Config) (*telebot.Bot,
 			return
 		}
 	})
+	b.Handle(chats, func(m *telebot.Message) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if m.Chat.Type != telebot.ChatPrivate {
+			return
+		}
+		categories, err := ctl.ChatCategories(ctx, controllerv1models.ReqChatCategories{
+			Tenant: cfg.Tenant,
+			UserID: int64(m.Sender.ID),
+		})
+		if err != nil {
+			lg.Error("ChatCategories", zap.Error(err))
+			return
+		}
+		text, markup := chatCategoriesMessage(cfg, categories)
+		_, err = b.Send(m.Sender, text, &telebot.SendOptions{
+			ReplyMarkup:         markup,
+			DisableNotification: true,
+		})
+		if err != nil {
+			lg.Error("Sender chats", zap.Error(err))
+			return
+		}
+	})
+	b.Handle(top, func(m *telebot.Message) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if m.Chat.Type != telebot.ChatPrivate {
+			return
+		}
+		text := cfg.TopUsage
+		if m.Payload != "" {
+			entities, err := ctl.TopEntities(ctx, controllerv1models.ReqTopEntities{
+				Tenant:   cfg.Tenant,
+				SenderID: int64(m.Sender.ID),
+				Query:    m.Payload,
+			})
+			if err != nil {
+				lg.Error("TopEntities", zap.Error(err))
+				return
+			}
+			text = topMessage(cfg, entities)
+		}
+		_, err := b.Send(m.Sender, text, &telebot.SendOptions{
+			ReplyTo:               m,
+			DisableWebPagePreview: true,
+			DisableNotification:   true,
+			ParseMode:             telebot.ModeDefault,
+		})
+		if err != nil {
+			lg.Error("Sender top", zap.Error(err))
+			return
+		}
+	})
+	b.Handle(quakes, func(m *telebot.Message) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if m.Chat.Type != telebot.ChatPrivate {
+			return
+		}
+		recent, err := ctl.RecentEarthquakes(ctx, controllerv1models.ReqRecentEarthquakes{})
+		if err != nil {
+			lg.Error("RecentEarthquakes", zap.Error(err))
+			return
+		}
+		_, err = b.Send(m.Sender, quakesMessage(cfg, recent), &telebot.SendOptions{
+			ReplyTo:               m,
+			DisableWebPagePreview: true,
+			DisableNotification:   true,
+		})
+		if err != nil {
+			lg.Error("Sender quakes", zap.Error(err))
+			return
+		}
+	})
+	b.Handle(quakeSubscribe, func(m *telebot.Message) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if m.Chat.Type != telebot.ChatPrivate {
+			return
+		}
+		if strings.EqualFold(strings.TrimSpace(m.Payload), quakeSubscribeOff) {
+			_, err := ctl.UnsubscribeEarthquakes(ctx, controllerv1models.ReqUnsubscribeEarthquakes{
+				Tenant:         cfg.Tenant,
+				TelegramUserID: int64(m.Sender.ID),
+			})
+			if err != nil && !semerr.IsNotFound(err) {
+				lg.Error("UnsubscribeEarthquakes", zap.Error(err))
+				return
+			}
+			if _, err := b.Send(m.Sender, cfg.QuakeUnsubscribed); err != nil {
+				lg.Error("Sender quakesubscribe", zap.Error(err))
+			}
+			return
+		}
+		text, markup := cfg.QuakeSubscribeUsage, (*telebot.ReplyMarkup)(nil)
+		if minMagnitude, radiusKm, ok := parseQuakeSubscription(m.Payload); ok {
+			subscribed, err := ctl.SubscribeEarthquakes(ctx, controllerv1models.ReqSubscribeEarthquakes{
+				Tenant:         cfg.Tenant,
+				TelegramUserID: int64(m.Sender.ID),
+				MinMagnitude:   minMagnitude,
+				RadiusKm:       radiusKm,
+			})
+			switch {
+			case semerr.IsInvalidInput(err):
+			case err != nil:
+				lg.Error("SubscribeEarthquakes", zap.Error(err))
+				return
+			default:
+				text = fmt.Sprintf(cfg.QuakeShareLocation, subscribed.MinMagnitude, subscribed.RadiusKm)
+				markup = &telebot.ReplyMarkup{
+					ReplyKeyboard:       [][]telebot.ReplyButton{{{Text: cfg.QuakeLocationButton, Location: true}}},
+					ResizeReplyKeyboard: true,
+					OneTimeKeyboard:     true,
+				}
+			}
+		}
+		if _, err := b.Send(m.Sender, text, &telebot.SendOptions{ReplyMarkup: markup}); err != nil {
+			lg.Error("Sender quakesubscribe", zap.Error(err))
+		}
+	})
+	// replyBuses answers the shared location with buses approaching it.
+	replyBuses := func(ctx context.Context, m *telebot.Message) {
+		lat, lng := float64(m.Location.Lat), float64(m.Location.Lng)
+		approaching, err := ctl.ApproachingBuses(ctx, controllerv1models.ReqApproachingBuses{
+			Latitude:  lat,
+			Longitude: lng,
+		})
+		if err != nil {
+			lg.Error("ApproachingBuses", zap.Error(err))
+			_, _ = b.Reply(m, cfg.BusesFailed)
+			return
+		}
+		text, markup := busesMessage(cfg, approaching, lat, lng)
+		if _, err := b.Reply(m, text, &telebot.SendOptions{ReplyMarkup: markup}); err != nil {
+			lg.Error("Reply buses", zap.Error(err))
+		}
+	}
+	b.Handle(telebot.OnLocation, func(m *telebot.Message) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if m.Location == nil {
+			return
+		}
+		if m.Chat.Type != telebot.ChatPrivate {
+			// in groups people share a location asking which bus to take,
+			// live locations are shared with each other. The earthquake subscription is personal.
+			// Telegram sends group locations to bots with the privacy mode off, or to admins.
+			if m.Location.LivePeriod == 0 {
+				replyBuses(ctx, m)
+			}
+			return
+		}
+		located, err := ctl.LocateEarthquakeSubscription(ctx, controllerv1models.ReqLocateEarthquakeSubscription{
+			Tenant:         cfg.Tenant,
+			TelegramUserID: int64(m.Sender.ID),
+			Latitude:       float64(m.Location.Lat),
+			Longitude:      float64(m.Location.Lng),
+		})
+		if semerr.IsNotFound(err) {
+			// no subscription waits for the location, the user looks for a bus.
+			replyBuses(ctx, m)
+			return
+		}
+		if err != nil {
+			lg.Error("LocateEarthquakeSubscription", zap.Error(err))
+			return
+		}
+		_, err = b.Send(m.Sender, fmt.Sprintf(cfg.QuakeSubscribed, located.MinMagnitude, located.RadiusKm))
+		if err != nil {
+			lg.Error("Sender location", zap.Error(err))
+			return
+		}
+	})
+	b.Handle(&telebot.InlineButton{Unique: refreshBuses}, func(c *telebot.Callback) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		lat, lng, ok := parseLocation(c.Data)
+		if !ok {
+			lg.Warn("invalid buses location", zap.String("data", c.Data))
+			_ = b.Respond(c, &telebot.CallbackResponse{Text: cfg.BusesFailed})
+			return
+		}
+		approaching, err := ctl.ApproachingBuses(ctx, controllerv1models.ReqApproachingBuses{
+			Latitude:  lat,
+			Longitude: lng,
+		})
+		if err != nil {
+			lg.Error("ApproachingBuses", zap.Error(err))
+			_ = b.Respond(c, &telebot.CallbackResponse{Text: cfg.BusesFailed})
+			return
+		}
+		text, markup := busesMessage(cfg, approaching, lat, lng)
+		// Telegram refuses to edit the message into the same text.
+		if text == c.Message.Text {
+			if err := b.Respond(c, &telebot.CallbackResponse{Text: cfg.BusesUnchanged}); err != nil {
+				lg.Error("Respond buses", zap.Error(err))
+			}
+			return
+		}
+		if _, err := b.Edit(c.Message, text, &telebot.SendOptions{ReplyMarkup: markup}); err != nil {
+			lg.Error("Edit buses", zap.Error(err))
+		}
+		if err := b.Respond(c); err != nil {
+			lg.Error("Respond buses", zap.Error(err))
+		}
+	})
+	b.Handle(&telebot.InlineButton{Unique: toggleCategory}, func(c *telebot.Callback) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		categories, err := ctl.ToggleChatCategory(ctx, controllerv1models.ReqToggleChatCategory{
+			Tenant:   cfg.Tenant,
+			UserID:   int64(c.Sender.ID),
+			Category: c.Data,
+		})
+		if err != nil {
+			lg.Error("ToggleChatCategory", zap.Error(err))
+			_ = b.Respond(c, &telebot.CallbackResponse{Text: cfg.ChatsFailed})
+			return
+		}
+		text, markup := chatCategoriesMessage(cfg, categories)
+		if _, err := b.Edit(c.Message, text, &telebot.SendOptions{ReplyMarkup: markup}); err != nil {
+			lg.Error("Edit chats", zap.Error(err))
+		}
+		if err := b.Respond(c); err != nil {
+			lg.Error("Respond chats", zap.Error(err))
+		}
+	})
+	b.Handle(forgetMe, func(m *telebot.Message) {
+		if m.Chat.Type != telebot.ChatPrivate {
+			return
+		}
+		_, err := b.Send(m.Sender, cfg.ForgetMe, &telebot.SendOptions{
+			ReplyMarkup: &telebot.ReplyMarkup{InlineKeyboard: [][]telebot.InlineButton{{{
+				Unique: forgetMeConfirm,
+				Text:   cfg.ForgetMeConfirm,
+			}}}},
+		})
+		if err != nil {
+			lg.Error("Sender forgetme", zap.Error(err))
+			return
+		}
+	})
+	b.Handle(&telebot.InlineButton{Unique: forgetMeConfirm}, func(c *telebot.Callback) {
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+		defer cancel()
+		_, err := ctl.ForgetAuthor(ctx, controllerv1models.ReqForgetAuthor{
+			TelegramUserID: int64(c.Sender.ID),
+			RequestedBy:    "bot",
+		})
+		if err != nil {
+			lg.Error("ForgetAuthor", zap.Error(err))
+			_ = b.Respond(c, &telebot.CallbackResponse{Text: cfg.ChatsFailed})
+			return
+		}
+		if _, err := b.Edit(c.Message, cfg.ForgetMeDone); err != nil {
+			lg.Error("Edit forgetme", zap.Error(err))
+		}
+		if err := b.Respond(c); err != nil {
+			lg.Error("Respond forgetme", zap.Error(err))
+		}
+	})
 	//b.Handle(telebot.OnChannelPost, func(m *telebot.Message) {
 	//	logger.Warn(ctx, "channel post", zap.String("text", m.Text))
 	//	if strings.Contains(m.Text, "@yanakipre_bot") && m.ReplyTo != nil {
@@ -69,6 +335,7 @@ func New(ctx context.Context, ctl *controllerv1.Ctl, cfg Config) (*telebot.Bot,
 				prompt := m.ReplyTo.Text
 				logger.Info(ctx, "mention", zap.String("text", prompt))
 				completion, err := ctl.TryCompletion(ctx, controllerv1models.ReqTryCompletion{
+					Tenant:   cfg.Tenant,
 					SenderID: 0, // we don't log messages for public chats.
 					Query:    prompt,
 				})
@@ -109,7 +376,7 @@ func New(ctx context.Context, ctl *controllerv1.Ctl, cfg Config) (*telebot.Bot,
 			return
 		}
 		if m.Text == help {
-			_, err = b.Send(m.Sender, ctl.Help(ctx), &telebot.SendOptions{
+			_, err = b.Send(m.Sender, ctl.Help(ctx, cfg.Tenant), &telebot.SendOptions{
 				ReplyTo:               m,
 				DisableWebPagePreview: false,
 				DisableNotification:   true,
@@ -122,7 +389,7 @@ func New(ctx context.Context, ctl *controllerv1.Ctl, cfg Config) (*telebot.Bot,
 			return
 		}
 		if m.Text == news {
-			_, err = b.Send(m.Sender, ctl.News(ctx), &telebot.SendOptions{
+			_, err = b.Send(m.Sender, ctl.News(ctx, cfg.Tenant), &telebot.SendOptions{
 				ReplyTo:               m,
 				DisableWebPagePreview: true,
 				DisableNotification:   true,
@@ -139,6 +406,7 @@ func New(ctx context.Context, ctl *controllerv1.Ctl, cfg Config) (*telebot.Bot,
 		//	return
 		//}
 		completion, err := ctl.TryCompletion(ctx, controllerv1models.ReqTryCompletion{
+			Tenant:   cfg.Tenant,
 			SenderID: m.Sender.ID,
 			Query:    m.Text,
 		})
@@ -178,7 +446,71 @@ func New(ctx context.Context, ctl *controllerv1.Ctl, cfg Config) (*telebot.Bot,
 }
 
 const (
-	help    = "/help"
-	news    = "/news"
-	explain = "/explain"
+	help     = "/help"
+	news     = "/news"
+	explain  = "/explain"
+	chats    = "/chats"
+	forgetMe = "/forgetme"
+	top      = "/top"
+	quakes   = "/quakes"
+
+	quakeSubscribe = "/quakesubscribe"
+	// quakeSubscribeOff is the /quakesubscribe payload to unsubscribe.
+	quakeSubscribeOff = "off"
+
+	// toggleCategory is the callback of /chats buttons, the data is the category.
+	toggleCategory = "toggle_category"
+	// forgetMeConfirm is the callback of the /forgetme confirmation button.
+	forgetMeConfirm = "forgetme_confirm"
 )
+
+// chatCategoriesMessage renders /chats with a button per category.
+func chatCategoriesMessage(
+	cfg Config,
+	categories controllerv1models.RespChatCategories,
+) (string, *telebot.ReplyMarkup) {
+	if len(categories.Categories) == 0 {
+		return cfg.NoChatCategories, nil
+	}
+	keyboard := make([][]telebot.InlineButton, 0, len(categories.Categories))
+	for _, category := range categories.Categories {
+		text := category.Name
+		if category.Selected {
+			text = "✅ " + text
+		}
+		keyboard = append(keyboard, []telebot.InlineButton{{
+			Unique: toggleCategory,
+			Text:   text,
+			Data:   category.Name,
+		}})
+	}
+	return cfg.ChatCategories, &telebot.ReplyMarkup{InlineKeyboard: keyboard}
+}
+
+// topMessage renders /top results, one entity per paragraph.
+func topMessage(cfg Config, resp controllerv1models.RespTopEntities) string {
+	if len(resp.Entities) == 0 {
+		return cfg.NoTopEntities
+	}
+	query := resp.Category
+	if resp.Locality != "" {
+		query += ", " + resp.Locality
+	}
+	paragraphs := make([]string, 0, len(resp.Entities)+1)
+	paragraphs = append(paragraphs, fmt.Sprintf(cfg.TopHeader, query))
+	for i, e := range resp.Entities {
+		lines := []string{
+			fmt.Sprintf("%d. %s", i+1, e.Name),
+			fmt.Sprintf("👍 %d 👎 %d, обсуждений: %d, последнее %s",
+				e.Positive, e.Negative, e.Mentions, e.LastMentionedAt.Format(time.DateOnly)),
+		}
+		if len(e.Phones) > 0 {
+			lines = append(lines, "☎️ "+strings.Join(e.Phones, ", "))
+		}
+		if len(e.Addresses) > 0 {
+			lines = append(lines, "📍 "+strings.Join(e.Addresses, "; "))
+		}
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
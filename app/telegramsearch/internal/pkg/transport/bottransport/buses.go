@@ -0,0 +1,66 @@
+package bottransport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tucnak/telebot"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+)
+
+// refreshBuses is the callback of the button under approaching buses, the data is the location.
+const refreshBuses = "refresh_buses"
+
+// busesMessage renders approaching buses with a button to look them up again.
+func busesMessage(
+	cfg Config,
+	resp controllerv1models.RespApproachingBuses,
+	lat, lng float64,
+) (string, *telebot.ReplyMarkup) {
+	markup := &telebot.ReplyMarkup{InlineKeyboard: [][]telebot.InlineButton{{{
+		Unique: refreshBuses,
+		Text:   cfg.BusesRefresh,
+		Data:   formatLocation(lat, lng),
+	}}}}
+	if len(resp.Buses) == 0 {
+		return cfg.NoBuses, markup
+	}
+	lines := make([]string, 0, len(resp.Buses)+1)
+	lines = append(lines, cfg.BusesHeader)
+	for _, b := range resp.Buses {
+		minutes := max(int(b.ETA.Round(time.Minute)/time.Minute), 1)
+		switch {
+		case b.ETA <= 0:
+			lines = append(lines, fmt.Sprintf(cfg.Bus, b.Route, b.RouteName, b.DistanceMeters))
+		case b.RoughETA:
+			lines = append(lines, fmt.Sprintf(cfg.BusRoughETA, b.Route, b.RouteName, b.DistanceMeters, minutes))
+		default:
+			lines = append(lines, fmt.Sprintf(cfg.BusStopETA, b.Route, b.RouteName, b.DistanceMeters, minutes, b.Stop))
+		}
+	}
+	return strings.Join(lines, "\n"), markup
+}
+
+// formatLocation fits the callback data, which is limited to 64 bytes.
+func formatLocation(lat, lng float64) string {
+	return strconv.FormatFloat(lat, 'f', 6, 64) + "," + strconv.FormatFloat(lng, 'f', 6, 64)
+}
+
+func parseLocation(data string) (lat, lng float64, ok bool) {
+	latText, lngText, found := strings.Cut(data, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(latText, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err = strconv.ParseFloat(lngText, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
@@ -5,4 +5,174 @@ import "github.com/yanakipre/bot/internal/secret"
 type Config struct {
 	Token    secret.String `yaml:"token"`
 	Greeting string        `yaml:"greeting"`
+	// Tenant is controllerv1.TenantConfig name the bot serves. Empty serves all chats.
+	Tenant string `yaml:"tenant"`
+	// ChatCategories is shown above /chats buttons.
+	ChatCategories string `yaml:"chat_categories"`
+	// NoChatCategories is shown by /chats when no chats are tagged.
+	NoChatCategories string `yaml:"no_chat_categories"`
+	// ChatsFailed is shown when toggling a category fails.
+	ChatsFailed string `yaml:"chats_failed"`
+	// ForgetMe asks to confirm /forgetme.
+	ForgetMe string `yaml:"forget_me"`
+	// ForgetMeConfirm is the text of the /forgetme confirmation button.
+	ForgetMeConfirm string `yaml:"forget_me_confirm"`
+	// ForgetMeDone is shown when the user's messages are deleted.
+	ForgetMeDone string `yaml:"forget_me_done"`
+	// TopUsage is shown by /top without a query.
+	TopUsage string `yaml:"top_usage"`
+	// TopHeader is shown above /top results, formatted with the category and the locality.
+	TopHeader string `yaml:"top_header"`
+	// NoTopEntities is shown by /top when nobody is recommended.
+	NoTopEntities string `yaml:"no_top_entities"`
+	// QuakesHeader is shown above /quakes results.
+	QuakesHeader string `yaml:"quakes_header"`
+	// NoQuakes is shown by /quakes when no earthquakes are known yet.
+	NoQuakes string `yaml:"no_quakes"`
+	// QuakeSubscribeUsage is shown when /quakesubscribe parameters are invalid.
+	QuakeSubscribeUsage string `yaml:"quake_subscribe_usage"`
+	// QuakeShareLocation asks for the location, formatted with the magnitude and the radius.
+	QuakeShareLocation string `yaml:"quake_share_location"`
+	// QuakeLocationButton is the text of the button sharing the location.
+	QuakeLocationButton string `yaml:"quake_location_button"`
+	// QuakeSubscribed is shown when the location is shared, formatted with the magnitude and the radius.
+	QuakeSubscribed string `yaml:"quake_subscribed"`
+	// QuakeUnsubscribed is shown by "/quakesubscribe off".
+	QuakeUnsubscribed string `yaml:"quake_unsubscribed"`
+	// QuakeAlert is sent to subscribers, formatted with the magnitude and the distance.
+	QuakeAlert string `yaml:"quake_alert"`
+	// BusesHeader is shown above buses approaching the shared location.
+	BusesHeader string `yaml:"buses_header"`
+	// Bus is a line per approaching bus, formatted with the route, its name and the distance in meters.
+	Bus string `yaml:"bus"`
+	// BusStopETA is Bus with the minutes until the bus arrives at the stop, and the stop.
+	BusStopETA string `yaml:"bus_stop_eta"`
+	// BusRoughETA is Bus with the minutes the bus needs to drive straight to the location,
+	// when it is not known to arrive at a stop nearby.
+	BusRoughETA string `yaml:"bus_rough_eta"`
+	// NoBuses is shown when no bus approaches the shared location.
+	NoBuses string `yaml:"no_buses"`
+	// BusesRefresh is the text of the button looking up the buses again.
+	BusesRefresh string `yaml:"buses_refresh"`
+	// BusesUnchanged is shown when the refreshed buses are the same.
+	BusesUnchanged string `yaml:"buses_unchanged"`
+	// BusesFailed is shown when the buses cannot be looked up.
+	BusesFailed string `yaml:"buses_failed"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		ChatCategories: "Выберите темы, в чатах которых искать ответы." +
+			" Если ничего не выбрано, поиск идет по всем чатам.",
+		NoChatCategories: "Темы чатов пока не настроены, поиск идет по всем чатам.",
+		ChatsFailed:      "Не получилось, попробуйте еще раз.",
+		ForgetMe: "Бот удалит все ваши сообщения из найденных в чатах обсуждений" +
+			" и больше не будет их сохранять. Отменить это нельзя.",
+		ForgetMeConfirm: "Удалить мои сообщения",
+		ForgetMeDone:    "Готово, ваши сообщения удалены.",
+		TopUsage:        "Напишите, кого ищете, например: /top dentist limassol",
+		TopHeader:       "Чаще всего рекомендуют в чатах (%s):",
+		NoTopEntities: "Пока никого не рекомендовали." +
+			" Попробуйте задать вопрос обычным сообщением.",
+		QuakesHeader: "Последние землетрясения:",
+		NoQuakes:     "Землетрясений пока не было.",
+		QuakeSubscribeUsage: "Напишите минимальную магнитуду и радиус в километрах, например: /quakesubscribe 3.5 100" +
+			"\nОтписаться: /quakesubscribe off",
+		QuakeShareLocation: "Отправьте геопозицию, и бот сообщит о землетрясениях магнитудой от %.1f" +
+			" в радиусе %.0f км от нее.",
+		QuakeLocationButton: "📍 Отправить геопозицию",
+		QuakeSubscribed: "Готово, бот сообщит о землетрясениях магнитудой от %.1f в радиусе %.0f км." +
+			" Отписаться: /quakesubscribe off",
+		QuakeUnsubscribed: "Вы отписались от уведомлений о землетрясениях.",
+		QuakeAlert:        "⚠️ Землетрясение магнитудой %.1f в %.0f км от вас",
+		BusesHeader:       "Автобусы, которые едут к вам:",
+		Bus:               "🚌 %s %s, %.0f м",
+		BusStopETA:        "🚌 %s %s, %.0f м, %d мин до остановки %s",
+		BusRoughETA:       "🚌 %s %s, %.0f м, ~%d мин по прямой",
+		NoBuses:           "Рядом нет автобусов, которые едут к вам.",
+		BusesRefresh:      "🔄 Обновить",
+		BusesUnchanged:    "Ничего не изменилось.",
+		BusesFailed:       "Не получилось найти автобусы, попробуйте еще раз.",
+	}
+}
+
+// withDefaults fills empty texts, e.g. of tenant bots, from DefaultConfig.
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.ChatCategories == "" {
+		c.ChatCategories = d.ChatCategories
+	}
+	if c.NoChatCategories == "" {
+		c.NoChatCategories = d.NoChatCategories
+	}
+	if c.ChatsFailed == "" {
+		c.ChatsFailed = d.ChatsFailed
+	}
+	if c.ForgetMe == "" {
+		c.ForgetMe = d.ForgetMe
+	}
+	if c.ForgetMeConfirm == "" {
+		c.ForgetMeConfirm = d.ForgetMeConfirm
+	}
+	if c.ForgetMeDone == "" {
+		c.ForgetMeDone = d.ForgetMeDone
+	}
+	if c.TopUsage == "" {
+		c.TopUsage = d.TopUsage
+	}
+	if c.TopHeader == "" {
+		c.TopHeader = d.TopHeader
+	}
+	if c.NoTopEntities == "" {
+		c.NoTopEntities = d.NoTopEntities
+	}
+	if c.QuakesHeader == "" {
+		c.QuakesHeader = d.QuakesHeader
+	}
+	if c.NoQuakes == "" {
+		c.NoQuakes = d.NoQuakes
+	}
+	if c.QuakeSubscribeUsage == "" {
+		c.QuakeSubscribeUsage = d.QuakeSubscribeUsage
+	}
+	if c.QuakeShareLocation == "" {
+		c.QuakeShareLocation = d.QuakeShareLocation
+	}
+	if c.QuakeLocationButton == "" {
+		c.QuakeLocationButton = d.QuakeLocationButton
+	}
+	if c.QuakeSubscribed == "" {
+		c.QuakeSubscribed = d.QuakeSubscribed
+	}
+	if c.QuakeUnsubscribed == "" {
+		c.QuakeUnsubscribed = d.QuakeUnsubscribed
+	}
+	if c.QuakeAlert == "" {
+		c.QuakeAlert = d.QuakeAlert
+	}
+	if c.BusesHeader == "" {
+		c.BusesHeader = d.BusesHeader
+	}
+	if c.Bus == "" {
+		c.Bus = d.Bus
+	}
+	if c.BusStopETA == "" {
+		c.BusStopETA = d.BusStopETA
+	}
+	if c.BusRoughETA == "" {
+		c.BusRoughETA = d.BusRoughETA
+	}
+	if c.NoBuses == "" {
+		c.NoBuses = d.NoBuses
+	}
+	if c.BusesRefresh == "" {
+		c.BusesRefresh = d.BusesRefresh
+	}
+	if c.BusesUnchanged == "" {
+		c.BusesUnchanged = d.BusesUnchanged
+	}
+	if c.BusesFailed == "" {
+		c.BusesFailed = d.BusesFailed
+	}
+	return c
 }
@@ -0,0 +1,118 @@
+package bottransport
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tucnak/telebot"
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+// EarthquakeAlerts polls earthquakes and sends alerts by the bot of the subscriber's tenant.
+type EarthquakeAlerts struct {
+	ctl  *controllerv1.Ctl
+	bots map[string]alertingBot
+}
+
+type alertingBot struct {
+	bot *telebot.Bot
+	cfg Config
+}
+
+func NewEarthquakeAlerts(ctl *controllerv1.Ctl) *EarthquakeAlerts {
+	return &EarthquakeAlerts{ctl: ctl, bots: map[string]alertingBot{}}
+}
+
+// Add the bot created by New with the same cfg, it sends alerts to subscribers of its tenant.
+func (a *EarthquakeAlerts) Add(b *telebot.Bot, cfg Config) {
+	a.bots[cfg.Tenant] = alertingBot{bot: b, cfg: cfg.withDefaults()}
+}
+
+// Run polls earthquakes every interval until ctx is done.
+func (a *EarthquakeAlerts) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		a.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *EarthquakeAlerts) poll(ctx context.Context) {
+	lg := logger.FromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	polled, err := a.ctl.PollEarthquakes(ctx, controllerv1models.ReqPollEarthquakes{})
+	if err != nil {
+		lg.Error("PollEarthquakes", zap.Error(err))
+		return
+	}
+	for _, alert := range polled.Alerts {
+		b, ok := a.bots[alert.Tenant]
+		if !ok {
+			lg.Warn("no bot for earthquake alert", zap.String("tenant", alert.Tenant))
+			continue
+		}
+		text := fmt.Sprintf(b.cfg.QuakeAlert, alert.Earthquake.Magnitude, alert.DistanceKm) +
+			"\n" + earthquakeLine(alert.Earthquake)
+		if _, err := b.bot.Send(&telebot.User{ID: int(alert.TelegramUserID)}, text); err != nil {
+			// the alert stays unsent and is retried by the next poll.
+			lg.Error("Sender earthquake alert", zap.Error(err), zap.Int64("telegram_user_id", alert.TelegramUserID))
+			continue
+		}
+		if _, err := a.ctl.MarkEarthquakeAlertSent(ctx, controllerv1models.ReqMarkEarthquakeAlertSent{
+			GUID:           alert.Earthquake.GUID,
+			TelegramUserID: alert.TelegramUserID,
+			Tenant:         alert.Tenant,
+		}); err != nil {
+			lg.Error("MarkEarthquakeAlertSent", zap.Error(err), zap.Int64("telegram_user_id", alert.TelegramUserID))
+		}
+	}
+}
+
+// quakesMessage renders /quakes results, one earthquake per line.
+func quakesMessage(cfg Config, resp controllerv1models.RespRecentEarthquakes) string {
+	if len(resp.Earthquakes) == 0 {
+		return cfg.NoQuakes
+	}
+	lines := make([]string, 0, len(resp.Earthquakes)+1)
+	lines = append(lines, cfg.QuakesHeader)
+	for _, e := range resp.Earthquakes {
+		lines = append(lines, earthquakeLine(e))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func earthquakeLine(e controllerv1models.Earthquake) string {
+	return fmt.Sprintf("M%.1f, %s, %s", e.Magnitude, e.HappenedAt.UTC().Format("2006-01-02 15:04 MST"), e.Location)
+}
+
+// parseQuakeSubscription reads "/quakesubscribe [magnitude] [radius km]",
+// zeros mean configured defaults.
+func parseQuakeSubscription(payload string) (minMagnitude, radiusKm float32, ok bool) {
+	// decimal commas are common, e.g. "3,5".
+	args := strings.Fields(strings.ReplaceAll(payload, ",", "."))
+	if len(args) > 2 {
+		return 0, 0, false
+	}
+	values := make([]float32, 2)
+	for i, arg := range args {
+		v, err := strconv.ParseFloat(arg, 32)
+		if err != nil || v <= 0 {
+			return 0, 0, false
+		}
+		values[i] = float32(v)
+	}
+	return values[0], values[1], true
+}
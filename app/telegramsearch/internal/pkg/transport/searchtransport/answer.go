@@ -0,0 +1,197 @@
+package searchtransport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/samber/lo"
+
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport/searchv1"
+	"github.com/yanakipre/bot/internal/logger"
+	"github.com/yanakipre/bot/internal/openapiapp"
+	"github.com/yanakipre/bot/internal/resttooling"
+)
+
+func (h *handler) Answer(ctx context.Context, req *searchv1.AnswerRequest) (*searchv1.AnswerResponse, error) {
+	resp, err := h.ctl.TryCompletion(ctx, models.ReqTryCompletion{
+		Tenant: req.Tenant.Value,
+		Query:  req.Query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return answerResponse(resp), nil
+}
+
+func answerResponse(resp models.RespTryCompletion) *searchv1.AnswerResponse {
+	return &searchv1.AnswerResponse{
+		Response: resp.Response,
+		Sources: lo.Map(resp.Sources, func(item models.AnswerSource, _ int) searchv1.AnswerSource {
+			return searchv1.AnswerSource{
+				URL:     item.URL,
+				Date:    item.MostRecentMessageAt,
+				Snippet: item.Snippet,
+			}
+		}),
+		Stale:  resp.Stale,
+		Cached: resp.Cached,
+	}
+}
+
+// answerDelta is the data of the delta event.
+type answerDelta struct {
+	Text string `json:"text"`
+}
+
+func (h *handler) AnswerStream(ctx context.Context, req *searchv1.AnswerRequest) (searchv1.AnswerStreamOK, error) {
+	stream := newEvents()
+	// errors before the first event are responded with the status code.
+	failed := make(chan error, 1)
+	go func() {
+		resp, err := h.ctl.TryCompletion(ctx, models.ReqTryCompletion{
+			Tenant: req.Tenant.Value,
+			Query:  req.Query,
+			OnDelta: func(text string) {
+				stream.write(ctx, "delta", answerDelta{Text: text})
+			},
+		})
+		if err != nil && !stream.started() {
+			failed <- err
+			return
+		}
+		if err != nil {
+			stream.write(ctx, "error", h.NewError(ctx, err).Response)
+		} else {
+			stream.write(ctx, "done", answerResponse(resp))
+		}
+		stream.close()
+	}()
+	select {
+	case err := <-failed:
+		return searchv1.AnswerStreamOK{}, err
+	case <-stream.firstEvent:
+		return searchv1.AnswerStreamOK{Data: stream}, nil
+	case <-ctx.Done():
+		return searchv1.AnswerStreamOK{}, ctx.Err()
+	}
+}
+
+// events is a stream of server-sent events, read while they are written.
+// Writes never block, so a client gone away does not hold up the answer,
+// and are dropped once the request is done, nobody reads them then.
+type events struct {
+	mu         sync.Mutex
+	buf        bytes.Buffer
+	closed     bool
+	firstEvent chan struct{}
+	// written is signaled on every write.
+	written chan struct{}
+}
+
+func newEvents() *events {
+	return &events{
+		firstEvent: make(chan struct{}),
+		written:    make(chan struct{}, 1),
+	}
+}
+
+func (e *events) write(ctx context.Context, event string, data any) {
+	if ctx.Err() != nil {
+		return
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.Error(ctx, fmt.Errorf("cannot marshal %q event: %w", event, err))
+		return
+	}
+	e.mu.Lock()
+	if !e.startedLocked() {
+		close(e.firstEvent)
+	}
+	_, _ = fmt.Fprintf(&e.buf, "event: %s\ndata: %s\n\n", event, payload)
+	e.mu.Unlock()
+	e.signal()
+}
+
+func (e *events) close() {
+	e.mu.Lock()
+	e.closed = true
+	e.mu.Unlock()
+	e.signal()
+}
+
+func (e *events) started() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.startedLocked()
+}
+
+func (e *events) startedLocked() bool {
+	select {
+	case <-e.firstEvent:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *events) signal() {
+	select {
+	case e.written <- struct{}{}:
+	default:
+	}
+}
+
+// Read blocks until the next event is written or the stream is closed.
+func (e *events) Read(p []byte) (int, error) {
+	for {
+		e.mu.Lock()
+		if e.buf.Len() > 0 {
+			n, err := e.buf.Read(p)
+			e.mu.Unlock()
+			return n, err
+		}
+		closed := e.closed
+		e.mu.Unlock()
+		if closed {
+			return 0, io.EOF
+		}
+		<-e.written
+	}
+}
+
+// flushMiddleware sends every write of streamed operations to the client immediately.
+func flushMiddleware(routeName resttooling.RouteNameFunc) openapiapp.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if routeName(r).OperationID != "AnswerStream" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&flushWriter{ResponseWriter: w, rc: http.NewResponseController(w)}, r)
+		})
+	}
+}
+
+type flushWriter struct {
+	http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.ResponseWriter.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, f.rc.Flush()
+}
+
+func (f *flushWriter) Unwrap() http.ResponseWriter {
+	return f.ResponseWriter
+}
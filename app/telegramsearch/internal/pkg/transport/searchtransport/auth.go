@@ -0,0 +1,83 @@
+package searchtransport
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/yanakipre/bot/internal/openapiapp"
+	"github.com/yanakipre/bot/internal/resttooling"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// authenticatedOperations cost money, and require an API key.
+var authenticatedOperations = map[string]bool{
+	"Answer":       true,
+	"AnswerStream": true,
+}
+
+type apiKeyNameKey struct{}
+
+var errAnonymous = errors.New("request is anonymous")
+
+// apiKeyName returns the name of the API key the request is authenticated with.
+func apiKeyName(ctx context.Context) (string, error) {
+	name, ok := ctx.Value(apiKeyNameKey{}).(*string)
+	if !ok || *name == "" {
+		return "", errAnonymous
+	}
+	return *name, nil
+}
+
+// apiKeyNamePlaceholder adds the API key name for authMiddleware to set,
+// so middlewares it runs in, e.g. the logging one, read it from their context.
+func apiKeyNamePlaceholder(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyNameKey{}, new(string))))
+	})
+}
+
+// authMiddleware requires a known API key for authenticated operations.
+// It sets the key name in apiKeyNamePlaceholder.
+func authMiddleware(
+	keys []APIKey,
+	routeName resttooling.RouteNameFunc,
+	errorHandler resttooling.ErrorHandler,
+) openapiapp.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !authenticatedOperations[routeName(r).OperationID] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tokenReq, err := resttooling.BearerTokenReqFromHTTP(r)
+			if err != nil {
+				// a missing or malformed key is unauthenticated, not an invalid request.
+				errorHandler(w, r, semerr.WrapWithAuthentication(err, "API key is required"))
+				return
+			}
+			name, ok := findAPIKey(keys, tokenReq.TokenFromReq.Unmask())
+			if !ok {
+				errorHandler(w, r, semerr.Authentication("unknown API key"))
+				return
+			}
+			ctx := r.Context()
+			if placeholder, ok := ctx.Value(apiKeyNameKey{}).(*string); ok {
+				*placeholder = name
+			} else {
+				ctx = context.WithValue(ctx, apiKeyNameKey{}, &name)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func findAPIKey(keys []APIKey, token string) (string, bool) {
+	for i := range keys {
+		if subtle.ConstantTimeCompare([]byte(keys[i].Key.Unmask()), []byte(token)) == 1 {
+			return keys[i].Name, true
+		}
+	}
+	return "", false
+}
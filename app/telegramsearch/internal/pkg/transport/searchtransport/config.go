@@ -0,0 +1,25 @@
+package searchtransport
+
+import (
+	"github.com/yanakipre/bot/internal/openapiapp"
+	"github.com/yanakipre/bot/internal/secret"
+)
+
+type Config struct {
+	App openapiapp.Config `yaml:"app"`
+	// APIKeys authorize answer generation, it costs money. Without keys answers are not served.
+	APIKeys []APIKey `yaml:"api_keys"`
+}
+
+// APIKey is sent as a bearer token.
+type APIKey struct {
+	// Name identifies the client in logs, e.g. "widget".
+	Name string        `yaml:"name"`
+	Key  secret.String `yaml:"key"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		App: openapiapp.DefaultConfig("/search/v1", "0.0.0.0:8080", "searchv1"),
+	}
+}
@@ -0,0 +1,38 @@
+package searchtransport
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport/searchv1"
+)
+
+func (h *handler) TopEntities(ctx context.Context, req *searchv1.TopEntitiesRequest) (*searchv1.TopEntitiesResponse, error) {
+	resp, err := h.ctl.TopEntities(ctx, models.ReqTopEntities{
+		Tenant:   req.Tenant.Value,
+		Category: req.Category,
+		Locality: req.Locality.Value,
+		Limit:    req.Limit.Or(10),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &searchv1.TopEntitiesResponse{
+		Entities: lo.Map(resp.Entities, func(item models.DirectoryEntity, _ int) searchv1.DirectoryEntity {
+			return searchv1.DirectoryEntity{
+				Name:            item.Name,
+				Kind:            item.Kind,
+				Category:        item.Category,
+				Phones:          item.Phones,
+				Addresses:       item.Addresses,
+				ChatIds:         item.ChatIDs,
+				Mentions:        item.Mentions,
+				Positive:        item.Positive,
+				Negative:        item.Negative,
+				LastMentionedAt: item.LastMentionedAt,
+			}
+		}),
+	}, nil
+}
@@ -0,0 +1,114 @@
+// Package searchtransport serves the search API, apispec/searchv1/search-v1.yaml.
+package searchtransport
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/samber/lo"
+
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1"
+	models "github.com/yanakipre/bot/app/telegramsearch/internal/pkg/controllers/controllerv1/controllerv1models"
+	"github.com/yanakipre/bot/app/telegramsearch/internal/pkg/transport/searchtransport/searchv1"
+	"github.com/yanakipre/bot/internal/openapiapp"
+	"github.com/yanakipre/bot/internal/resttooling"
+	"github.com/yanakipre/bot/internal/resttooling/requestid"
+)
+
+// New creates the search API application. Run it with App.StartServer.
+func New(ctl *controllerv1.Ctl, cfg Config) (*openapiapp.App, error) {
+	h := &handler{ctl: ctl}
+	errorHandler := openapiapp.ErrorHandler(h.NewError)
+	srv, err := searchv1.NewServer(
+		h,
+		searchv1.WithErrorHandler(errorHandler),
+		searchv1.WithNotFound(openapiapp.NotFound(errorHandler)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	routeName := resttooling.UrlMethodGetter(srv, "unknown")
+	writeError := func(w http.ResponseWriter, r *http.Request, appErr error) {
+		errorHandler(r.Context(), w, r, appErr)
+	}
+	return openapiapp.New(
+		cfg.App,
+		srv,
+		// first middleware in the list is executed last
+		openapiapp.Middlewares(
+			flushMiddleware(routeName),
+			authMiddleware(cfg.APIKeys, routeName, writeError),
+			resttooling.RecoveryMiddleware(writeError),
+			resttooling.SentryMiddleware(routeName),
+			resttooling.MetricsMiddleware(cfg.App.Name, routeName),
+			resttooling.LoggingMiddleware(
+				cfg.App.Name,
+				routeName,
+				apiKeyName,
+				resttooling.SubjectIdentityAsUserID,
+			),
+			apiKeyNamePlaceholder,
+		),
+		func(ctx context.Context) (any, error) {
+			return map[string]string{"status": "ok"}, nil
+		},
+	), nil
+}
+
+type handler struct {
+	ctl *controllerv1.Ctl
+}
+
+var _ searchv1.Handler = (*handler)(nil)
+
+func (h *handler) SearchThroughChats(
+	ctx context.Context,
+	req *searchv1.SearchThroughChatsRequest,
+) (*searchv1.SearchThroughChatsResponse, error) {
+	limit := req.Limit.Or(10)
+	offset := req.Offset.Or(0)
+	found, err := h.ctl.TryEmbedding(ctx, models.ReqTryEmbedding{
+		Tenant:  req.Tenant.Value,
+		Input:   req.Query,
+		ChatIDs: req.ChatIds,
+		Since:   req.Since.Value,
+		Until:   req.Until.Value,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &searchv1.SearchThroughChatsResponse{
+		ChatThreads: lo.Map(found.Result, func(item models.EmbeddingResponse, _ int) searchv1.ChatThread {
+			return searchv1.ChatThread{
+				ThreadID:            item.ThreadID,
+				ChatID:              item.ChatID,
+				TelegramChatID:      item.TelegramChatID,
+				URL:                 item.URL,
+				MostRecentMessageAt: item.MostRecentMessageAt,
+				Messages: lo.Map(item.Messages, func(m models.ThreadMessage, _ int) searchv1.ChatThreadMessage {
+					return searchv1.ChatThreadMessage{
+						MessageID: m.ID,
+						Message:   m.Text,
+						Date:      m.Date,
+						URL:       m.URL,
+					}
+				}),
+			}
+		}),
+	}
+	if found.HasMore {
+		resp.NextOffset = searchv1.NewOptInt(offset + limit)
+	}
+	return resp, nil
+}
+
+func (h *handler) NewError(ctx context.Context, err error) *searchv1.GeneralErrorStatusCode {
+	statusCode, _, message := openapiapp.PresentError(ctx, err)
+	resp := searchv1.GeneralError{Error: message}
+	if reqID, ok := requestid.FromContext(ctx); ok {
+		resp.RequestID = searchv1.NewOptString(reqID)
+	}
+	return &searchv1.GeneralErrorStatusCode{StatusCode: statusCode, Response: resp}
+}
@@ -0,0 +1,4 @@
+// Package searchv1 is generated by ogen from apispec/searchv1/search-v1.yaml.
+package searchv1
+
+//go:generate go run github.com/ogen-go/ogen/cmd/ogen --target . --package searchv1 --clean ../../../../../apispec/searchv1/search-v1.yaml
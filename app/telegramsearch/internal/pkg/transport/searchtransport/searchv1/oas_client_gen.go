@@ -0,0 +1,419 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package searchv1
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.19.0"
+	"go.opentelemetry.io/otel/trace"
+
+	ht "github.com/ogen-go/ogen/http"
+	"github.com/ogen-go/ogen/otelogen"
+	"github.com/ogen-go/ogen/uri"
+)
+
+// Invoker invokes operations described by OpenAPI v3 specification.
+type Invoker interface {
+	// Answer invokes answer operation.
+	//
+	// Generates the answer to the question from chat threads, the same the bot gives.
+	// Requires an API key: `Authorization: Bearer <key>`.
+	//
+	// POST /answer
+	Answer(ctx context.Context, request *AnswerRequest) (*AnswerResponse, error)
+	// AnswerStream invokes answerStream operation.
+	//
+	// Same as /answer, but responds with server-sent events.
+	// Requires an API key: `Authorization: Bearer <key>`.
+	// Events:
+	// * `delta`: `{"text": "..."}`, the next part of the answer.
+	// * `done`: `AnswerResponse`, the whole answer with its sources. The last event.
+	// * `error`: `GeneralError`, when the answer fails after the stream started. The last event.
+	// Errors before the stream started are responded with the status code, as usual.
+	//
+	// POST /answer/stream
+	AnswerStream(ctx context.Context, request *AnswerRequest) (AnswerStreamOK, error)
+	// SearchThroughChats invokes searchThroughChats operation.
+	//
+	// This endpoints allows to search through chats via vector search.
+	// Threads are ordered by similarity to the query and returned by pages.
+	//
+	// POST /search-through-chats
+	SearchThroughChats(ctx context.Context, request *SearchThroughChatsRequest) (*SearchThroughChatsResponse, error)
+	// TopEntities invokes topEntities operation.
+	//
+	// Entities are extracted from chat threads offline.
+	// The most recommended go first, then the most mentioned, then the most recently mentioned.
+	//
+	// POST /directory/top
+	TopEntities(ctx context.Context, request *TopEntitiesRequest) (*TopEntitiesResponse, error)
+}
+
+// Client implements OAS client.
+type Client struct {
+	serverURL *url.URL
+	baseClient
+}
+type errorHandler interface {
+	NewError(ctx context.Context, err error) *GeneralErrorStatusCode
+}
+
+var _ Handler = struct {
+	errorHandler
+	*Client
+}{}
+
+func trimTrailingSlashes(u *url.URL) {
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+}
+
+// NewClient initializes new Client defined by OAS.
+func NewClient(serverURL string, opts ...ClientOption) (*Client, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	trimTrailingSlashes(u)
+
+	c, err := newClientConfig(opts...).baseClient()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		serverURL:  u,
+		baseClient: c,
+	}, nil
+}
+
+type serverURLKey struct{}
+
+// WithServerURL sets context key to override server URL.
+func WithServerURL(ctx context.Context, u *url.URL) context.Context {
+	return context.WithValue(ctx, serverURLKey{}, u)
+}
+
+func (c *Client) requestURL(ctx context.Context) *url.URL {
+	u, ok := ctx.Value(serverURLKey{}).(*url.URL)
+	if !ok {
+		return c.serverURL
+	}
+	return u
+}
+
+// Answer invokes answer operation.
+//
+// Generates the answer to the question from chat threads, the same the bot gives.
+// Requires an API key: `Authorization: Bearer <key>`.
+//
+// POST /answer
+func (c *Client) Answer(ctx context.Context, request *AnswerRequest) (*AnswerResponse, error) {
+	res, err := c.sendAnswer(ctx, request)
+	return res, err
+}
+
+func (c *Client) sendAnswer(ctx context.Context, request *AnswerRequest) (res *AnswerResponse, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("answer"),
+		semconv.HTTPMethodKey.String("POST"),
+		semconv.HTTPRouteKey.String("/answer"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(float64(elapsedDuration)/float64(time.Millisecond)), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, "Answer",
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [1]string
+	pathParts[0] = "/answer"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "POST", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+	if err := encodeAnswerRequest(request, r); err != nil {
+		return res, errors.Wrap(err, "encode request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeAnswerResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
+// AnswerStream invokes answerStream operation.
+//
+// Same as /answer, but responds with server-sent events.
+// Requires an API key: `Authorization: Bearer <key>`.
+// Events:
+// * `delta`: `{"text": "..."}`, the next part of the answer.
+// * `done`: `AnswerResponse`, the whole answer with its sources. The last event.
+// * `error`: `GeneralError`, when the answer fails after the stream started. The last event.
+// Errors before the stream started are responded with the status code, as usual.
+//
+// POST /answer/stream
+func (c *Client) AnswerStream(ctx context.Context, request *AnswerRequest) (AnswerStreamOK, error) {
+	res, err := c.sendAnswerStream(ctx, request)
+	return res, err
+}
+
+func (c *Client) sendAnswerStream(ctx context.Context, request *AnswerRequest) (res AnswerStreamOK, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("answerStream"),
+		semconv.HTTPMethodKey.String("POST"),
+		semconv.HTTPRouteKey.String("/answer/stream"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(float64(elapsedDuration)/float64(time.Millisecond)), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, "AnswerStream",
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [1]string
+	pathParts[0] = "/answer/stream"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "POST", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+	if err := encodeAnswerStreamRequest(request, r); err != nil {
+		return res, errors.Wrap(err, "encode request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeAnswerStreamResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
+// SearchThroughChats invokes searchThroughChats operation.
+//
+// This endpoints allows to search through chats via vector search.
+// Threads are ordered by similarity to the query and returned by pages.
+//
+// POST /search-through-chats
+func (c *Client) SearchThroughChats(ctx context.Context, request *SearchThroughChatsRequest) (*SearchThroughChatsResponse, error) {
+	res, err := c.sendSearchThroughChats(ctx, request)
+	return res, err
+}
+
+func (c *Client) sendSearchThroughChats(ctx context.Context, request *SearchThroughChatsRequest) (res *SearchThroughChatsResponse, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("searchThroughChats"),
+		semconv.HTTPMethodKey.String("POST"),
+		semconv.HTTPRouteKey.String("/search-through-chats"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(float64(elapsedDuration)/float64(time.Millisecond)), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, "SearchThroughChats",
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [1]string
+	pathParts[0] = "/search-through-chats"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "POST", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+	if err := encodeSearchThroughChatsRequest(request, r); err != nil {
+		return res, errors.Wrap(err, "encode request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeSearchThroughChatsResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
+// TopEntities invokes topEntities operation.
+//
+// Entities are extracted from chat threads offline.
+// The most recommended go first, then the most mentioned, then the most recently mentioned.
+//
+// POST /directory/top
+func (c *Client) TopEntities(ctx context.Context, request *TopEntitiesRequest) (*TopEntitiesResponse, error) {
+	res, err := c.sendTopEntities(ctx, request)
+	return res, err
+}
+
+func (c *Client) sendTopEntities(ctx context.Context, request *TopEntitiesRequest) (res *TopEntitiesResponse, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("topEntities"),
+		semconv.HTTPMethodKey.String("POST"),
+		semconv.HTTPRouteKey.String("/directory/top"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(float64(elapsedDuration)/float64(time.Millisecond)), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, "TopEntities",
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [1]string
+	pathParts[0] = "/directory/top"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "POST", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+	if err := encodeTopEntitiesRequest(request, r); err != nil {
+		return res, errors.Wrap(err, "encode request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeTopEntitiesResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,23 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package searchv1
+
+// setDefaults set default value of fields.
+func (s *SearchThroughChatsRequest) setDefaults() {
+	{
+		val := int(10)
+		s.Limit.SetTo(val)
+	}
+	{
+		val := int(0)
+		s.Offset.SetTo(val)
+	}
+}
+
+// setDefaults set default value of fields.
+func (s *TopEntitiesRequest) setDefaults() {
+	{
+		val := int(10)
+		s.Limit.SetTo(val)
+	}
+}
@@ -0,0 +1,756 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package searchv1
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+func (s *GeneralErrorStatusCode) Error() string {
+	return fmt.Sprintf("code %d: %+v", s.StatusCode, s.Response)
+}
+
+// Ref: #/components/schemas/AnswerRequest
+type AnswerRequest struct {
+	Query string `json:"query"`
+	// Answers from chats of the tenant. All chats are used when omitted.
+	Tenant OptString `json:"tenant"`
+}
+
+// GetQuery returns the value of Query.
+func (s *AnswerRequest) GetQuery() string {
+	return s.Query
+}
+
+// GetTenant returns the value of Tenant.
+func (s *AnswerRequest) GetTenant() OptString {
+	return s.Tenant
+}
+
+// SetQuery sets the value of Query.
+func (s *AnswerRequest) SetQuery(val string) {
+	s.Query = val
+}
+
+// SetTenant sets the value of Tenant.
+func (s *AnswerRequest) SetTenant(val OptString) {
+	s.Tenant = val
+}
+
+// Ref: #/components/schemas/AnswerResponse
+type AnswerResponse struct {
+	Response string `json:"response"`
+	// Threads the answer is based on, the most recent first.
+	Sources []AnswerSource `json:"sources"`
+	// True when all the sources are too old to be trusted.
+	Stale bool `json:"stale"`
+	// True when the answer to a similar question is reused.
+	Cached bool `json:"cached"`
+}
+
+// GetResponse returns the value of Response.
+func (s *AnswerResponse) GetResponse() string {
+	return s.Response
+}
+
+// GetSources returns the value of Sources.
+func (s *AnswerResponse) GetSources() []AnswerSource {
+	return s.Sources
+}
+
+// GetStale returns the value of Stale.
+func (s *AnswerResponse) GetStale() bool {
+	return s.Stale
+}
+
+// GetCached returns the value of Cached.
+func (s *AnswerResponse) GetCached() bool {
+	return s.Cached
+}
+
+// SetResponse sets the value of Response.
+func (s *AnswerResponse) SetResponse(val string) {
+	s.Response = val
+}
+
+// SetSources sets the value of Sources.
+func (s *AnswerResponse) SetSources(val []AnswerSource) {
+	s.Sources = val
+}
+
+// SetStale sets the value of Stale.
+func (s *AnswerResponse) SetStale(val bool) {
+	s.Stale = val
+}
+
+// SetCached sets the value of Cached.
+func (s *AnswerResponse) SetCached(val bool) {
+	s.Cached = val
+}
+
+// Ref: #/components/schemas/AnswerSource
+type AnswerSource struct {
+	// Link to the first message of the thread in Telegram.
+	URL string `json:"url"`
+	// When the most recent message of the thread was sent.
+	Date time.Time `json:"date"`
+	// The beginning of the first message of the thread.
+	Snippet string `json:"snippet"`
+}
+
+// GetURL returns the value of URL.
+func (s *AnswerSource) GetURL() string {
+	return s.URL
+}
+
+// GetDate returns the value of Date.
+func (s *AnswerSource) GetDate() time.Time {
+	return s.Date
+}
+
+// GetSnippet returns the value of Snippet.
+func (s *AnswerSource) GetSnippet() string {
+	return s.Snippet
+}
+
+// SetURL sets the value of URL.
+func (s *AnswerSource) SetURL(val string) {
+	s.URL = val
+}
+
+// SetDate sets the value of Date.
+func (s *AnswerSource) SetDate(val time.Time) {
+	s.Date = val
+}
+
+// SetSnippet sets the value of Snippet.
+func (s *AnswerSource) SetSnippet(val string) {
+	s.Snippet = val
+}
+
+type AnswerStreamOK struct {
+	Data io.Reader
+}
+
+// Read reads data from the Data reader.
+//
+// Kept to satisfy the io.Reader interface.
+func (s AnswerStreamOK) Read(p []byte) (n int, err error) {
+	if s.Data == nil {
+		return 0, io.EOF
+	}
+	return s.Data.Read(p)
+}
+
+// Ref: #/components/schemas/ChatThread
+type ChatThread struct {
+	ThreadID       int64  `json:"thread_id"`
+	ChatID         string `json:"chat_id"`
+	TelegramChatID string `json:"telegram_chat_id"`
+	// Link to the first message of the thread in Telegram.
+	URL                 string              `json:"url"`
+	MostRecentMessageAt time.Time           `json:"most_recent_message_at"`
+	Messages            []ChatThreadMessage `json:"messages"`
+}
+
+// GetThreadID returns the value of ThreadID.
+func (s *ChatThread) GetThreadID() int64 {
+	return s.ThreadID
+}
+
+// GetChatID returns the value of ChatID.
+func (s *ChatThread) GetChatID() string {
+	return s.ChatID
+}
+
+// GetTelegramChatID returns the value of TelegramChatID.
+func (s *ChatThread) GetTelegramChatID() string {
+	return s.TelegramChatID
+}
+
+// GetURL returns the value of URL.
+func (s *ChatThread) GetURL() string {
+	return s.URL
+}
+
+// GetMostRecentMessageAt returns the value of MostRecentMessageAt.
+func (s *ChatThread) GetMostRecentMessageAt() time.Time {
+	return s.MostRecentMessageAt
+}
+
+// GetMessages returns the value of Messages.
+func (s *ChatThread) GetMessages() []ChatThreadMessage {
+	return s.Messages
+}
+
+// SetThreadID sets the value of ThreadID.
+func (s *ChatThread) SetThreadID(val int64) {
+	s.ThreadID = val
+}
+
+// SetChatID sets the value of ChatID.
+func (s *ChatThread) SetChatID(val string) {
+	s.ChatID = val
+}
+
+// SetTelegramChatID sets the value of TelegramChatID.
+func (s *ChatThread) SetTelegramChatID(val string) {
+	s.TelegramChatID = val
+}
+
+// SetURL sets the value of URL.
+func (s *ChatThread) SetURL(val string) {
+	s.URL = val
+}
+
+// SetMostRecentMessageAt sets the value of MostRecentMessageAt.
+func (s *ChatThread) SetMostRecentMessageAt(val time.Time) {
+	s.MostRecentMessageAt = val
+}
+
+// SetMessages sets the value of Messages.
+func (s *ChatThread) SetMessages(val []ChatThreadMessage) {
+	s.Messages = val
+}
+
+// Ref: #/components/schemas/ChatThreadMessage
+type ChatThreadMessage struct {
+	MessageID int64     `json:"message_id"`
+	Message   string    `json:"message"`
+	Date      time.Time `json:"date"`
+	// Link to the message in Telegram.
+	URL string `json:"url"`
+}
+
+// GetMessageID returns the value of MessageID.
+func (s *ChatThreadMessage) GetMessageID() int64 {
+	return s.MessageID
+}
+
+// GetMessage returns the value of Message.
+func (s *ChatThreadMessage) GetMessage() string {
+	return s.Message
+}
+
+// GetDate returns the value of Date.
+func (s *ChatThreadMessage) GetDate() time.Time {
+	return s.Date
+}
+
+// GetURL returns the value of URL.
+func (s *ChatThreadMessage) GetURL() string {
+	return s.URL
+}
+
+// SetMessageID sets the value of MessageID.
+func (s *ChatThreadMessage) SetMessageID(val int64) {
+	s.MessageID = val
+}
+
+// SetMessage sets the value of Message.
+func (s *ChatThreadMessage) SetMessage(val string) {
+	s.Message = val
+}
+
+// SetDate sets the value of Date.
+func (s *ChatThreadMessage) SetDate(val time.Time) {
+	s.Date = val
+}
+
+// SetURL sets the value of URL.
+func (s *ChatThreadMessage) SetURL(val string) {
+	s.URL = val
+}
+
+// Ref: #/components/schemas/DirectoryEntity
+type DirectoryEntity struct {
+	Name string `json:"name"`
+	// Business, professional or place.
+	Kind      string   `json:"kind"`
+	Category  string   `json:"category"`
+	Phones    []string `json:"phones"`
+	Addresses []string `json:"addresses"`
+	// Chats the entity is mentioned in.
+	ChatIds []string `json:"chat_ids"`
+	// Number of threads the entity is mentioned in.
+	Mentions int `json:"mentions"`
+	// Number of threads the entity is recommended in.
+	Positive int `json:"positive"`
+	// Number of threads people warn against the entity in.
+	Negative        int       `json:"negative"`
+	LastMentionedAt time.Time `json:"last_mentioned_at"`
+}
+
+// GetName returns the value of Name.
+func (s *DirectoryEntity) GetName() string {
+	return s.Name
+}
+
+// GetKind returns the value of Kind.
+func (s *DirectoryEntity) GetKind() string {
+	return s.Kind
+}
+
+// GetCategory returns the value of Category.
+func (s *DirectoryEntity) GetCategory() string {
+	return s.Category
+}
+
+// GetPhones returns the value of Phones.
+func (s *DirectoryEntity) GetPhones() []string {
+	return s.Phones
+}
+
+// GetAddresses returns the value of Addresses.
+func (s *DirectoryEntity) GetAddresses() []string {
+	return s.Addresses
+}
+
+// GetChatIds returns the value of ChatIds.
+func (s *DirectoryEntity) GetChatIds() []string {
+	return s.ChatIds
+}
+
+// GetMentions returns the value of Mentions.
+func (s *DirectoryEntity) GetMentions() int {
+	return s.Mentions
+}
+
+// GetPositive returns the value of Positive.
+func (s *DirectoryEntity) GetPositive() int {
+	return s.Positive
+}
+
+// GetNegative returns the value of Negative.
+func (s *DirectoryEntity) GetNegative() int {
+	return s.Negative
+}
+
+// GetLastMentionedAt returns the value of LastMentionedAt.
+func (s *DirectoryEntity) GetLastMentionedAt() time.Time {
+	return s.LastMentionedAt
+}
+
+// SetName sets the value of Name.
+func (s *DirectoryEntity) SetName(val string) {
+	s.Name = val
+}
+
+// SetKind sets the value of Kind.
+func (s *DirectoryEntity) SetKind(val string) {
+	s.Kind = val
+}
+
+// SetCategory sets the value of Category.
+func (s *DirectoryEntity) SetCategory(val string) {
+	s.Category = val
+}
+
+// SetPhones sets the value of Phones.
+func (s *DirectoryEntity) SetPhones(val []string) {
+	s.Phones = val
+}
+
+// SetAddresses sets the value of Addresses.
+func (s *DirectoryEntity) SetAddresses(val []string) {
+	s.Addresses = val
+}
+
+// SetChatIds sets the value of ChatIds.
+func (s *DirectoryEntity) SetChatIds(val []string) {
+	s.ChatIds = val
+}
+
+// SetMentions sets the value of Mentions.
+func (s *DirectoryEntity) SetMentions(val int) {
+	s.Mentions = val
+}
+
+// SetPositive sets the value of Positive.
+func (s *DirectoryEntity) SetPositive(val int) {
+	s.Positive = val
+}
+
+// SetNegative sets the value of Negative.
+func (s *DirectoryEntity) SetNegative(val int) {
+	s.Negative = val
+}
+
+// SetLastMentionedAt sets the value of LastMentionedAt.
+func (s *DirectoryEntity) SetLastMentionedAt(val time.Time) {
+	s.LastMentionedAt = val
+}
+
+// Ref: #/components/schemas/GeneralError
+type GeneralError struct {
+	RequestID OptString `json:"request_id"`
+	// Error description.
+	Error string `json:"error"`
+}
+
+// GetRequestID returns the value of RequestID.
+func (s *GeneralError) GetRequestID() OptString {
+	return s.RequestID
+}
+
+// GetError returns the value of Error.
+func (s *GeneralError) GetError() string {
+	return s.Error
+}
+
+// SetRequestID sets the value of RequestID.
+func (s *GeneralError) SetRequestID(val OptString) {
+	s.RequestID = val
+}
+
+// SetError sets the value of Error.
+func (s *GeneralError) SetError(val string) {
+	s.Error = val
+}
+
+// GeneralErrorStatusCode wraps GeneralError with StatusCode.
+type GeneralErrorStatusCode struct {
+	StatusCode int
+	Response   GeneralError
+}
+
+// GetStatusCode returns the value of StatusCode.
+func (s *GeneralErrorStatusCode) GetStatusCode() int {
+	return s.StatusCode
+}
+
+// GetResponse returns the value of Response.
+func (s *GeneralErrorStatusCode) GetResponse() GeneralError {
+	return s.Response
+}
+
+// SetStatusCode sets the value of StatusCode.
+func (s *GeneralErrorStatusCode) SetStatusCode(val int) {
+	s.StatusCode = val
+}
+
+// SetResponse sets the value of Response.
+func (s *GeneralErrorStatusCode) SetResponse(val GeneralError) {
+	s.Response = val
+}
+
+// NewOptDateTime returns new OptDateTime with value set to v.
+func NewOptDateTime(v time.Time) OptDateTime {
+	return OptDateTime{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptDateTime is optional time.Time.
+type OptDateTime struct {
+	Value time.Time
+	Set   bool
+}
+
+// IsSet returns true if OptDateTime was set.
+func (o OptDateTime) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptDateTime) Reset() {
+	var v time.Time
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptDateTime) SetTo(v time.Time) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptDateTime) Get() (v time.Time, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptDateTime) Or(d time.Time) time.Time {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInt returns new OptInt with value set to v.
+func NewOptInt(v int) OptInt {
+	return OptInt{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInt is optional int.
+type OptInt struct {
+	Value int
+	Set   bool
+}
+
+// IsSet returns true if OptInt was set.
+func (o OptInt) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInt) Reset() {
+	var v int
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInt) SetTo(v int) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInt) Get() (v int, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInt) Or(d int) int {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptString returns new OptString with value set to v.
+func NewOptString(v string) OptString {
+	return OptString{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptString is optional string.
+type OptString struct {
+	Value string
+	Set   bool
+}
+
+// IsSet returns true if OptString was set.
+func (o OptString) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptString) Reset() {
+	var v string
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptString) SetTo(v string) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptString) Get() (v string, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptString) Or(d string) string {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// Ref: #/components/schemas/SearchThroughChatsRequest
+type SearchThroughChatsRequest struct {
+	Query string `json:"query"`
+	// Searches only in chats of the tenant. All chats are searched when omitted.
+	Tenant OptString `json:"tenant"`
+	// Searches only in these chats. All chats of the tenant are searched when omitted.
+	ChatIds []string `json:"chat_ids"`
+	// Only threads with the most recent message at or after this time are returned.
+	Since OptDateTime `json:"since"`
+	// Only threads with the most recent message before this time are returned.
+	Until OptDateTime `json:"until"`
+	// Maximum number of threads to return.
+	Limit OptInt `json:"limit"`
+	// Number of threads to skip, next_offset of the previous page.
+	Offset OptInt `json:"offset"`
+}
+
+// GetQuery returns the value of Query.
+func (s *SearchThroughChatsRequest) GetQuery() string {
+	return s.Query
+}
+
+// GetTenant returns the value of Tenant.
+func (s *SearchThroughChatsRequest) GetTenant() OptString {
+	return s.Tenant
+}
+
+// GetChatIds returns the value of ChatIds.
+func (s *SearchThroughChatsRequest) GetChatIds() []string {
+	return s.ChatIds
+}
+
+// GetSince returns the value of Since.
+func (s *SearchThroughChatsRequest) GetSince() OptDateTime {
+	return s.Since
+}
+
+// GetUntil returns the value of Until.
+func (s *SearchThroughChatsRequest) GetUntil() OptDateTime {
+	return s.Until
+}
+
+// GetLimit returns the value of Limit.
+func (s *SearchThroughChatsRequest) GetLimit() OptInt {
+	return s.Limit
+}
+
+// GetOffset returns the value of Offset.
+func (s *SearchThroughChatsRequest) GetOffset() OptInt {
+	return s.Offset
+}
+
+// SetQuery sets the value of Query.
+func (s *SearchThroughChatsRequest) SetQuery(val string) {
+	s.Query = val
+}
+
+// SetTenant sets the value of Tenant.
+func (s *SearchThroughChatsRequest) SetTenant(val OptString) {
+	s.Tenant = val
+}
+
+// SetChatIds sets the value of ChatIds.
+func (s *SearchThroughChatsRequest) SetChatIds(val []string) {
+	s.ChatIds = val
+}
+
+// SetSince sets the value of Since.
+func (s *SearchThroughChatsRequest) SetSince(val OptDateTime) {
+	s.Since = val
+}
+
+// SetUntil sets the value of Until.
+func (s *SearchThroughChatsRequest) SetUntil(val OptDateTime) {
+	s.Until = val
+}
+
+// SetLimit sets the value of Limit.
+func (s *SearchThroughChatsRequest) SetLimit(val OptInt) {
+	s.Limit = val
+}
+
+// SetOffset sets the value of Offset.
+func (s *SearchThroughChatsRequest) SetOffset(val OptInt) {
+	s.Offset = val
+}
+
+// Ref: #/components/schemas/SearchThroughChatsResponse
+type SearchThroughChatsResponse struct {
+	ChatThreads []ChatThread `json:"chat_threads"`
+	// Offset of the next page, absent on the last page.
+	NextOffset OptInt `json:"next_offset"`
+}
+
+// GetChatThreads returns the value of ChatThreads.
+func (s *SearchThroughChatsResponse) GetChatThreads() []ChatThread {
+	return s.ChatThreads
+}
+
+// GetNextOffset returns the value of NextOffset.
+func (s *SearchThroughChatsResponse) GetNextOffset() OptInt {
+	return s.NextOffset
+}
+
+// SetChatThreads sets the value of ChatThreads.
+func (s *SearchThroughChatsResponse) SetChatThreads(val []ChatThread) {
+	s.ChatThreads = val
+}
+
+// SetNextOffset sets the value of NextOffset.
+func (s *SearchThroughChatsResponse) SetNextOffset(val OptInt) {
+	s.NextOffset = val
+}
+
+// Ref: #/components/schemas/TopEntitiesRequest
+type TopEntitiesRequest struct {
+	// What the entity is, e.g. dentist. Categories containing it match.
+	Category string `json:"category"`
+	// Locality of chats, e.g. Limassol. Any locality when omitted.
+	Locality OptString `json:"locality"`
+	// Uses only chats of the tenant. All chats are used when omitted.
+	Tenant OptString `json:"tenant"`
+	// Maximum number of entities to return.
+	Limit OptInt `json:"limit"`
+}
+
+// GetCategory returns the value of Category.
+func (s *TopEntitiesRequest) GetCategory() string {
+	return s.Category
+}
+
+// GetLocality returns the value of Locality.
+func (s *TopEntitiesRequest) GetLocality() OptString {
+	return s.Locality
+}
+
+// GetTenant returns the value of Tenant.
+func (s *TopEntitiesRequest) GetTenant() OptString {
+	return s.Tenant
+}
+
+// GetLimit returns the value of Limit.
+func (s *TopEntitiesRequest) GetLimit() OptInt {
+	return s.Limit
+}
+
+// SetCategory sets the value of Category.
+func (s *TopEntitiesRequest) SetCategory(val string) {
+	s.Category = val
+}
+
+// SetLocality sets the value of Locality.
+func (s *TopEntitiesRequest) SetLocality(val OptString) {
+	s.Locality = val
+}
+
+// SetTenant sets the value of Tenant.
+func (s *TopEntitiesRequest) SetTenant(val OptString) {
+	s.Tenant = val
+}
+
+// SetLimit sets the value of Limit.
+func (s *TopEntitiesRequest) SetLimit(val OptInt) {
+	s.Limit = val
+}
+
+// Ref: #/components/schemas/TopEntitiesResponse
+type TopEntitiesResponse struct {
+	Entities []DirectoryEntity `json:"entities"`
+}
+
+// GetEntities returns the value of Entities.
+func (s *TopEntitiesResponse) GetEntities() []DirectoryEntity {
+	return s.Entities
+}
+
+// SetEntities sets the value of Entities.
+func (s *TopEntitiesResponse) SetEntities(val []DirectoryEntity) {
+	s.Entities = val
+}
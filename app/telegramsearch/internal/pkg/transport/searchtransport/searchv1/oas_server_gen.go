@@ -0,0 +1,67 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package searchv1
+
+import (
+	"context"
+)
+
+// Handler handles operations described by OpenAPI v3 specification.
+type Handler interface {
+	// Answer implements answer operation.
+	//
+	// Generates the answer to the question from chat threads, the same the bot gives.
+	// Requires an API key: `Authorization: Bearer <key>`.
+	//
+	// POST /answer
+	Answer(ctx context.Context, req *AnswerRequest) (*AnswerResponse, error)
+	// AnswerStream implements answerStream operation.
+	//
+	// Same as /answer, but responds with server-sent events.
+	// Requires an API key: `Authorization: Bearer <key>`.
+	// Events:
+	// * `delta`: `{"text": "..."}`, the next part of the answer.
+	// * `done`: `AnswerResponse`, the whole answer with its sources. The last event.
+	// * `error`: `GeneralError`, when the answer fails after the stream started. The last event.
+	// Errors before the stream started are responded with the status code, as usual.
+	//
+	// POST /answer/stream
+	AnswerStream(ctx context.Context, req *AnswerRequest) (AnswerStreamOK, error)
+	// SearchThroughChats implements searchThroughChats operation.
+	//
+	// This endpoints allows to search through chats via vector search.
+	// Threads are ordered by similarity to the query and returned by pages.
+	//
+	// POST /search-through-chats
+	SearchThroughChats(ctx context.Context, req *SearchThroughChatsRequest) (*SearchThroughChatsResponse, error)
+	// TopEntities implements topEntities operation.
+	//
+	// Entities are extracted from chat threads offline.
+	// The most recommended go first, then the most mentioned, then the most recently mentioned.
+	//
+	// POST /directory/top
+	TopEntities(ctx context.Context, req *TopEntitiesRequest) (*TopEntitiesResponse, error)
+	// NewError creates *GeneralErrorStatusCode from error returned by handler.
+	//
+	// Used for common default response.
+	NewError(ctx context.Context, err error) *GeneralErrorStatusCode
+}
+
+// Server implements http server based on OpenAPI v3 specification and
+// calls Handler to handle requests.
+type Server struct {
+	h Handler
+	baseServer
+}
+
+// NewServer creates new Server.
+func NewServer(h Handler, opts ...ServerOption) (*Server, error) {
+	s, err := newServerConfig(opts...).baseServer()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		h:          h,
+		baseServer: s,
+	}, nil
+}
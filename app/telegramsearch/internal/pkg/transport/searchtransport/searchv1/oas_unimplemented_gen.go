@@ -0,0 +1,67 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package searchv1
+
+import (
+	"context"
+
+	ht "github.com/ogen-go/ogen/http"
+)
+
+// UnimplementedHandler is no-op Handler which returns http.ErrNotImplemented.
+type UnimplementedHandler struct{}
+
+var _ Handler = UnimplementedHandler{}
+
+// Answer implements answer operation.
+//
+// Generates the answer to the question from chat threads, the same the bot gives.
+// Requires an API key: `Authorization: Bearer <key>`.
+//
+// POST /answer
+func (UnimplementedHandler) Answer(ctx context.Context, req *AnswerRequest) (r *AnswerResponse, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// AnswerStream implements answerStream operation.
+//
+// Same as /answer, but responds with server-sent events.
+// Requires an API key: `Authorization: Bearer <key>`.
+// Events:
+// * `delta`: `{"text": "..."}`, the next part of the answer.
+// * `done`: `AnswerResponse`, the whole answer with its sources. The last event.
+// * `error`: `GeneralError`, when the answer fails after the stream started. The last event.
+// Errors before the stream started are responded with the status code, as usual.
+//
+// POST /answer/stream
+func (UnimplementedHandler) AnswerStream(ctx context.Context, req *AnswerRequest) (r AnswerStreamOK, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// SearchThroughChats implements searchThroughChats operation.
+//
+// This endpoints allows to search through chats via vector search.
+// Threads are ordered by similarity to the query and returned by pages.
+//
+// POST /search-through-chats
+func (UnimplementedHandler) SearchThroughChats(ctx context.Context, req *SearchThroughChatsRequest) (r *SearchThroughChatsResponse, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// TopEntities implements topEntities operation.
+//
+// Entities are extracted from chat threads offline.
+// The most recommended go first, then the most mentioned, then the most recently mentioned.
+//
+// POST /directory/top
+func (UnimplementedHandler) TopEntities(ctx context.Context, req *TopEntitiesRequest) (r *TopEntitiesResponse, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// NewError creates *GeneralErrorStatusCode from error returned by handler.
+//
+// Used for common default response.
+func (UnimplementedHandler) NewError(ctx context.Context, err error) (r *GeneralErrorStatusCode) {
+	r = new(GeneralErrorStatusCode)
+	return r
+}
@@ -1,15 +1,54 @@
 CREATE EXTENSION IF NOT EXISTS vector WITH SCHEMA public;
 
+CREATE TABLE public.cached_answers (
+    cached_answer_id bigint NOT NULL,
+    tenant text NOT NULL,
+    chat_ids text[] NOT NULL,
+    prompt_version text NOT NULL,
+    embedding public.vector(2000) NOT NULL,
+    response text NOT NULL,
+    used_conversations jsonb NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
+CREATE SEQUENCE public.cached_answers_cached_answer_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.cached_answers_cached_answer_id_seq OWNED BY public.cached_answers.cached_answer_id;
+
+CREATE TABLE public.cached_embeddings (
+    model text NOT NULL,
+    text_hash text NOT NULL,
+    embedding public.vector NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE public.chats (
     chat_id text NOT NULL,
-    telegram_chat_id text DEFAULT 'empty'::text NOT NULL
+    telegram_chat_id text DEFAULT 'empty'::text NOT NULL,
+    categories text[] DEFAULT '{}'::text[] NOT NULL,
+    locality text DEFAULT ''::text NOT NULL
+);
+
+CREATE TABLE public.chatthread_authors (
+    thread_id bigint NOT NULL,
+    author_key text NOT NULL
 );
 
 CREATE TABLE public.chatthreads (
     thread_id bigint NOT NULL,
     chat_id text NOT NULL,
     body jsonb NOT NULL,
-    most_recent_message_at timestamp with time zone DEFAULT '2011-05-19 09:45:17+00'::timestamp with time zone
+    most_recent_message_at timestamp with time zone DEFAULT '2011-05-19 09:45:17+00'::timestamp with time zone,
+    filtered_reason text,
+    entities_extracted_at timestamp with time zone,
+    dropped_replies jsonb
 );
 
 CREATE SEQUENCE public.chatthreads_thread_id_seq
@@ -21,12 +60,45 @@ CREATE SEQUENCE public.chatthreads_thread_id_seq
 
 ALTER SEQUENCE public.chatthreads_thread_id_seq OWNED BY public.chatthreads.thread_id;
 
+CREATE TABLE public.earthquake_alerts (
+    guid text NOT NULL,
+    telegram_user_id bigint NOT NULL,
+    tenant text NOT NULL,
+    distance_km double precision NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    sent_at timestamp with time zone
+);
+
+CREATE TABLE public.earthquake_subscriptions (
+    telegram_user_id bigint NOT NULL,
+    tenant text NOT NULL,
+    min_magnitude real NOT NULL,
+    radius_km real NOT NULL,
+    latitude double precision,
+    longitude double precision,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE public.earthquakes (
+    guid text NOT NULL,
+    magnitude real NOT NULL,
+    happened_at timestamp with time zone NOT NULL,
+    location text NOT NULL,
+    latitude double precision NOT NULL,
+    longitude double precision NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    alerts_planned_at timestamp with time zone,
+    source_rank smallint NOT NULL
+);
+
 CREATE TABLE public.embeddings (
     thread_id bigint NOT NULL,
     chat_id text NOT NULL,
     message text NOT NULL,
     embedding public.vector(2000),
-    embedding_id bigint NOT NULL
+    embedding_id bigint NOT NULL,
+    summary text
 );
 
 CREATE SEQUENCE public.embeddings_embedding_id_seq
@@ -47,27 +119,129 @@ CREATE SEQUENCE public.embeddings_thread_id_seq
 
 ALTER SEQUENCE public.embeddings_thread_id_seq OWNED BY public.embeddings.thread_id;
 
+CREATE TABLE public.entities (
+    entity_id bigint NOT NULL,
+    chat_id text NOT NULL,
+    kind text NOT NULL,
+    category text NOT NULL,
+    name text NOT NULL,
+    normalized_name text NOT NULL
+);
+
+CREATE SEQUENCE public.entities_entity_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.entities_entity_id_seq OWNED BY public.entities.entity_id;
+
+CREATE TABLE public.entity_mentions (
+    entity_id bigint NOT NULL,
+    thread_id bigint NOT NULL,
+    sentiment smallint NOT NULL,
+    mentioned_at timestamp with time zone NOT NULL,
+    phones text[] DEFAULT '{}'::text[] NOT NULL,
+    addresses text[] DEFAULT '{}'::text[] NOT NULL
+);
+
+CREATE TABLE public.forget_requests (
+    forget_request_id bigint NOT NULL,
+    author_key text NOT NULL,
+    requested_by text NOT NULL,
+    threads_deleted integer NOT NULL,
+    threads_updated integer NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE SEQUENCE public.forget_requests_forget_request_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.forget_requests_forget_request_id_seq OWNED BY public.forget_requests.forget_request_id;
+
 CREATE TABLE public.schema_version (
     version integer NOT NULL
 );
 
+CREATE TABLE public.user_chat_categories (
+    user_id bigint NOT NULL,
+    category text NOT NULL,
+    tenant text NOT NULL
+);
+
+ALTER TABLE ONLY public.cached_answers ALTER COLUMN cached_answer_id SET DEFAULT nextval('public.cached_answers_cached_answer_id_seq'::regclass);
+
 ALTER TABLE ONLY public.chatthreads ALTER COLUMN thread_id SET DEFAULT nextval('public.chatthreads_thread_id_seq'::regclass);
 
 ALTER TABLE ONLY public.embeddings ALTER COLUMN thread_id SET DEFAULT nextval('public.embeddings_thread_id_seq'::regclass);
 
 ALTER TABLE ONLY public.embeddings ALTER COLUMN embedding_id SET DEFAULT nextval('public.embeddings_embedding_id_seq'::regclass);
 
+ALTER TABLE ONLY public.entities ALTER COLUMN entity_id SET DEFAULT nextval('public.entities_entity_id_seq'::regclass);
+
+ALTER TABLE ONLY public.forget_requests ALTER COLUMN forget_request_id SET DEFAULT nextval('public.forget_requests_forget_request_id_seq'::regclass);
+
+ALTER TABLE ONLY public.cached_answers
+    ADD CONSTRAINT cached_answers_pkey PRIMARY KEY (cached_answer_id);
+
+ALTER TABLE ONLY public.cached_embeddings
+    ADD CONSTRAINT cached_embeddings_pkey PRIMARY KEY (model, text_hash);
+
+ALTER TABLE ONLY public.chatthread_authors
+    ADD CONSTRAINT chatthread_authors_pkey PRIMARY KEY (author_key, thread_id);
+
 ALTER TABLE ONLY public.chats
     ADD CONSTRAINT chats_pkey PRIMARY KEY (chat_id);
 
 ALTER TABLE ONLY public.chatthreads
     ADD CONSTRAINT chatthreads_pkey PRIMARY KEY (thread_id);
 
+ALTER TABLE ONLY public.earthquake_alerts
+    ADD CONSTRAINT earthquake_alerts_pkey PRIMARY KEY (guid, telegram_user_id, tenant);
+
+ALTER TABLE ONLY public.earthquake_subscriptions
+    ADD CONSTRAINT earthquake_subscriptions_pkey PRIMARY KEY (telegram_user_id, tenant);
+
+ALTER TABLE ONLY public.earthquakes
+    ADD CONSTRAINT earthquakes_pkey PRIMARY KEY (guid);
+
 ALTER TABLE ONLY public.embeddings
     ADD CONSTRAINT embeddings_pkey PRIMARY KEY (embedding_id);
 
+ALTER TABLE ONLY public.entities
+    ADD CONSTRAINT entities_chat_id_category_normalized_name_key UNIQUE (chat_id, category, normalized_name);
+
+ALTER TABLE ONLY public.entities
+    ADD CONSTRAINT entities_pkey PRIMARY KEY (entity_id);
+
+ALTER TABLE ONLY public.entity_mentions
+    ADD CONSTRAINT entity_mentions_pkey PRIMARY KEY (entity_id, thread_id);
+
+ALTER TABLE ONLY public.forget_requests
+    ADD CONSTRAINT forget_requests_pkey PRIMARY KEY (forget_request_id);
+
+ALTER TABLE ONLY public.user_chat_categories
+    ADD CONSTRAINT user_chat_categories_pkey PRIMARY KEY (user_id, tenant, category);
+
+CREATE INDEX cached_answers_expires_at_idx ON public.cached_answers USING btree (expires_at);
+
+CREATE INDEX cached_embeddings_expires_at_idx ON public.cached_embeddings USING btree (expires_at);
+
 CREATE INDEX chatthreads_chat_id_idx ON public.chatthreads USING hash (chat_id);
 
+CREATE INDEX chatthreads_filtered_idx ON public.chatthreads USING btree (chat_id) WHERE (filtered_reason IS NOT NULL);
+
+CREATE INDEX chatthreads_not_extracted_idx ON public.chatthreads USING btree (thread_id) WHERE (entities_extracted_at IS NULL);
+
+CREATE INDEX earthquake_alerts_unsent_idx ON public.earthquake_alerts USING btree (guid) WHERE (sent_at IS NULL);
+
+CREATE INDEX earthquakes_happened_at_idx ON public.earthquakes USING btree (happened_at);
+
 CREATE INDEX embeddings_2000_idx ON public.embeddings USING hnsw (embedding public.vector_l2_ops);
 
 CREATE INDEX embeddings_chat_id_idx ON public.embeddings USING hash (chat_id);
@@ -76,11 +250,32 @@ CREATE UNIQUE INDEX embeddings_chatthread_id_idx ON public.embeddings USING btre
 
 CREATE INDEX embeddings_most_recent_message_at_idx ON public.chatthreads USING btree (most_recent_message_at);
 
+CREATE INDEX embeddings_not_summarized_idx ON public.embeddings USING btree (thread_id) WHERE (summary IS NULL);
+
+CREATE INDEX entity_mentions_thread_id_idx ON public.entity_mentions USING btree (thread_id);
+
+CREATE INDEX forget_requests_author_key_idx ON public.forget_requests USING hash (author_key);
+
+ALTER TABLE ONLY public.chatthread_authors
+    ADD CONSTRAINT chatthread_authors_thread_id_fk FOREIGN KEY (thread_id) REFERENCES public.chatthreads(thread_id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY public.chatthreads
-    ADD CONSTRAINT chatthreads_chat_id_fk FOREIGN KEY (chat_id) REFERENCES public.chats(chat_id) ON DELETE CASCADE;
+    ADD CONSTRAINT chatthreads_chat_id_fk FOREIGN KEY (chat_id) REFERENCES public.chats(chat_id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.earthquake_alerts
+    ADD CONSTRAINT earthquake_alerts_guid_fk FOREIGN KEY (guid) REFERENCES public.earthquakes(guid) ON DELETE CASCADE;
 
 ALTER TABLE ONLY public.embeddings
-    ADD CONSTRAINT embeddings_chat_id_fk FOREIGN KEY (chat_id) REFERENCES public.chats(chat_id) ON DELETE CASCADE;
+    ADD CONSTRAINT embeddings_chat_id_fk FOREIGN KEY (chat_id) REFERENCES public.chats(chat_id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 ALTER TABLE ONLY public.embeddings
-    ADD CONSTRAINT embeddings_chatthread_id_fk FOREIGN KEY (thread_id) REFERENCES public.chatthreads(thread_id) ON DELETE CASCADE;
\ No newline at end of file
+    ADD CONSTRAINT embeddings_chatthread_id_fk FOREIGN KEY (thread_id) REFERENCES public.chatthreads(thread_id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.entities
+    ADD CONSTRAINT entities_chat_id_fk FOREIGN KEY (chat_id) REFERENCES public.chats(chat_id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.entity_mentions
+    ADD CONSTRAINT entity_mentions_entity_id_fk FOREIGN KEY (entity_id) REFERENCES public.entities(entity_id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.entity_mentions
+    ADD CONSTRAINT entity_mentions_thread_id_fk FOREIGN KEY (thread_id) REFERENCES public.chatthreads(thread_id) ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1,43 @@
+package telegramsearchdb
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLastMigration checks last_migration.json follows the migrations,
+// its hash names the reusable test databases, see testtooling.FixtureReusableDBWithSchemaSeeded.
+func TestLastMigration(t *testing.T) {
+	files, err := filepath.Glob("migrations/*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	// Glob sorts the files, the migrations are numbered with leading zeros.
+	hash := sha256.New()
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		require.NoError(t, err)
+		hash.Write(b)
+	}
+	number, _, _ := strings.Cut(filepath.Base(files[len(files)-1]), "_")
+	version, err := strconv.Atoi(number)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile("last_migration.json")
+	require.NoError(t, err)
+	var last struct {
+		Version int    `json:"version"`
+		Hash    string `json:"hash"`
+	}
+	require.NoError(t, json.Unmarshal(b, &last))
+	require.Equal(t, version, last.Version)
+	require.Equal(t, fmt.Sprintf("%X", hash.Sum(nil)), last.Hash, "the hash of the migrations in order")
+}
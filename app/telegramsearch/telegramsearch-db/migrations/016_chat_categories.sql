@@ -0,0 +1,13 @@
+ALTER TABLE chats ADD COLUMN categories text[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE user_chat_categories (
+    user_id  BIGINT NOT NULL,
+    category TEXT   NOT NULL,
+    PRIMARY KEY (user_id, category)
+);
+
+---- create above / drop below ----
+
+DROP TABLE user_chat_categories;
+
+ALTER TABLE chats DROP COLUMN categories;
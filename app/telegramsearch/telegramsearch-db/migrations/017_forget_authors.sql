@@ -0,0 +1,25 @@
+CREATE TABLE chatthread_authors (
+    thread_id  BIGINT NOT NULL,
+    author_key TEXT   NOT NULL,
+    PRIMARY KEY (author_key, thread_id)
+);
+
+ALTER TABLE chatthread_authors
+    ADD CONSTRAINT chatthread_authors_thread_id_fk FOREIGN KEY (thread_id) REFERENCES chatthreads (thread_id) ON DELETE CASCADE;
+
+CREATE TABLE forget_requests (
+    forget_request_id BIGSERIAL PRIMARY KEY,
+    author_key        TEXT                     NOT NULL,
+    requested_by      TEXT                     NOT NULL,
+    threads_deleted   INTEGER                  NOT NULL,
+    threads_updated   INTEGER                  NOT NULL,
+    created_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX forget_requests_author_key_idx ON forget_requests USING hash (author_key);
+
+---- create above / drop below ----
+
+DROP TABLE forget_requests;
+
+DROP TABLE chatthread_authors;
@@ -0,0 +1,18 @@
+CREATE TABLE cached_answers (
+    cached_answer_id   BIGSERIAL    PRIMARY KEY,
+    tenant             TEXT         NOT NULL,
+    -- empty when all chats were searched.
+    chat_ids           TEXT[]       NOT NULL,
+    prompt_version     TEXT         NOT NULL,
+    embedding          VECTOR(2000) NOT NULL,
+    response           TEXT         NOT NULL,
+    used_conversations JSONB        NOT NULL,
+    created_at         TIMESTAMPTZ  NOT NULL,
+    expires_at         TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX cached_answers_expires_at_idx ON cached_answers USING btree (expires_at);
+
+---- create above / drop below ----
+
+DROP TABLE cached_answers;
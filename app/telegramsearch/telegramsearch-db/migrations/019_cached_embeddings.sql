@@ -0,0 +1,12 @@
+-- dimensions depend on the model.
+CREATE TABLE cached_embeddings (
+    model      TEXT        NOT NULL,
+    text_hash  TEXT        NOT NULL,
+    embedding  VECTOR      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (model, text_hash)
+);
+
+---- create above / drop below ----
+
+DROP TABLE cached_embeddings;
@@ -0,0 +1,23 @@
+-- locality is what the chat is about, e.g. "Limassol", it goes into threads shown to the model.
+ALTER TABLE chats ADD COLUMN locality text NOT NULL DEFAULT '';
+
+-- renaming a chat renames it everywhere.
+ALTER TABLE chatthreads
+    DROP CONSTRAINT chatthreads_chat_id_fk,
+    ADD CONSTRAINT chatthreads_chat_id_fk FOREIGN KEY (chat_id) REFERENCES chats (chat_id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE embeddings
+    DROP CONSTRAINT embeddings_chat_id_fk,
+    ADD CONSTRAINT embeddings_chat_id_fk FOREIGN KEY (chat_id) REFERENCES chats (chat_id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+---- create above / drop below ----
+
+ALTER TABLE embeddings
+    DROP CONSTRAINT embeddings_chat_id_fk,
+    ADD CONSTRAINT embeddings_chat_id_fk FOREIGN KEY (chat_id) REFERENCES chats (chat_id) ON DELETE CASCADE;
+
+ALTER TABLE chatthreads
+    DROP CONSTRAINT chatthreads_chat_id_fk,
+    ADD CONSTRAINT chatthreads_chat_id_fk FOREIGN KEY (chat_id) REFERENCES chats (chat_id) ON DELETE CASCADE;
+
+ALTER TABLE chats DROP COLUMN locality;
@@ -0,0 +1,10 @@
+-- filtered threads are kept for audit, but never embedded.
+ALTER TABLE chatthreads ADD COLUMN filtered_reason text;
+
+CREATE INDEX chatthreads_filtered_idx ON chatthreads (chat_id) WHERE filtered_reason IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX chatthreads_filtered_idx;
+
+ALTER TABLE chatthreads DROP COLUMN filtered_reason;
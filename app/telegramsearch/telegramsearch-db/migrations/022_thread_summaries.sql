@@ -0,0 +1,11 @@
+-- summary is a compact factual summary of the thread, it is embedded and given to the model instead of the message.
+-- empty until the thread is summarized.
+ALTER TABLE embeddings ADD COLUMN summary text;
+
+CREATE INDEX embeddings_not_summarized_idx ON embeddings (thread_id) WHERE summary IS NULL;
+
+---- create above / drop below ----
+
+DROP INDEX embeddings_not_summarized_idx;
+
+ALTER TABLE embeddings DROP COLUMN summary;
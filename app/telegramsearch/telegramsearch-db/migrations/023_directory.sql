@@ -0,0 +1,48 @@
+-- entities are businesses, professionals and places recommended in threads.
+CREATE TABLE entities (
+    entity_id       BIGSERIAL PRIMARY KEY,
+    chat_id         TEXT      NOT NULL,
+    -- business, professional or place.
+    kind            TEXT      NOT NULL,
+    -- what the entity is, e.g. "dentist" or "beach", lower-cased.
+    category        TEXT      NOT NULL,
+    name            TEXT      NOT NULL,
+    -- name without case and punctuation, mentions of the same entity are merged by it.
+    normalized_name TEXT      NOT NULL,
+    phones          TEXT[]    NOT NULL DEFAULT '{}',
+    addresses       TEXT[]    NOT NULL DEFAULT '{}',
+    UNIQUE (chat_id, category, normalized_name)
+);
+
+ALTER TABLE entities
+    ADD CONSTRAINT entities_chat_id_fk FOREIGN KEY (chat_id) REFERENCES chats (chat_id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE TABLE entity_mentions (
+    entity_id    BIGINT      NOT NULL,
+    thread_id    BIGINT      NOT NULL,
+    -- -1 negative, 0 neutral, 1 positive.
+    sentiment    SMALLINT    NOT NULL,
+    mentioned_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (entity_id, thread_id)
+);
+
+ALTER TABLE entity_mentions
+    ADD CONSTRAINT entity_mentions_entity_id_fk FOREIGN KEY (entity_id) REFERENCES entities (entity_id) ON DELETE CASCADE,
+    ADD CONSTRAINT entity_mentions_thread_id_fk FOREIGN KEY (thread_id) REFERENCES chatthreads (thread_id) ON DELETE CASCADE;
+
+CREATE INDEX entity_mentions_thread_id_idx ON entity_mentions USING btree (thread_id);
+
+-- empty until entities are extracted from the thread.
+ALTER TABLE chatthreads ADD COLUMN entities_extracted_at TIMESTAMPTZ;
+
+CREATE INDEX chatthreads_not_extracted_idx ON chatthreads (thread_id) WHERE entities_extracted_at IS NULL;
+
+---- create above / drop below ----
+
+DROP INDEX chatthreads_not_extracted_idx;
+
+ALTER TABLE chatthreads DROP COLUMN entities_extracted_at;
+
+DROP TABLE entity_mentions;
+
+DROP TABLE entities;
@@ -0,0 +1,33 @@
+-- earthquakes seen in the feed, subscribers are alerted about every event once.
+CREATE TABLE earthquakes (
+    -- identifies the event in the feed.
+    guid        TEXT             PRIMARY KEY,
+    magnitude   REAL             NOT NULL,
+    happened_at TIMESTAMPTZ      NOT NULL,
+    location    TEXT             NOT NULL,
+    latitude    DOUBLE PRECISION NOT NULL,
+    longitude   DOUBLE PRECISION NOT NULL,
+    created_at  TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX earthquakes_happened_at_idx ON earthquakes USING btree (happened_at);
+
+CREATE TABLE earthquake_subscriptions (
+    telegram_user_id BIGINT           NOT NULL,
+    -- tenant of the bot the user subscribed with, alerts are sent by it.
+    tenant           TEXT             NOT NULL,
+    min_magnitude    REAL             NOT NULL,
+    radius_km        REAL             NOT NULL,
+    -- empty until the user shares the location.
+    latitude         DOUBLE PRECISION,
+    longitude        DOUBLE PRECISION,
+    created_at       TIMESTAMPTZ      NOT NULL,
+    updated_at       TIMESTAMPTZ      NOT NULL,
+    PRIMARY KEY (telegram_user_id, tenant)
+);
+
+---- create above / drop below ----
+
+DROP TABLE earthquake_subscriptions;
+
+DROP TABLE earthquakes;
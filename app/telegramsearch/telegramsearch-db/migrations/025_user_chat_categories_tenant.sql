@@ -0,0 +1,19 @@
+-- categories are chosen in the bot of a tenant, other tenants have other chats.
+-- selections made before are kept for the default tenant.
+ALTER TABLE user_chat_categories ADD COLUMN tenant text NOT NULL DEFAULT '';
+
+ALTER TABLE user_chat_categories ALTER COLUMN tenant DROP DEFAULT;
+
+ALTER TABLE user_chat_categories
+    DROP CONSTRAINT user_chat_categories_pkey,
+    ADD CONSTRAINT user_chat_categories_pkey PRIMARY KEY (user_id, tenant, category);
+
+---- create above / drop below ----
+
+DELETE FROM user_chat_categories WHERE tenant <> '';
+
+ALTER TABLE user_chat_categories
+    DROP CONSTRAINT user_chat_categories_pkey,
+    ADD CONSTRAINT user_chat_categories_pkey PRIMARY KEY (user_id, category);
+
+ALTER TABLE user_chat_categories DROP COLUMN tenant;
@@ -0,0 +1,15 @@
+-- cached embeddings expire, the table would grow forever otherwise,
+-- and embeddings of a model no longer used are never fetched again.
+ALTER TABLE cached_embeddings ADD COLUMN expires_at TIMESTAMPTZ;
+
+UPDATE cached_embeddings SET expires_at = created_at + INTERVAL '30 days';
+
+ALTER TABLE cached_embeddings ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX cached_embeddings_expires_at_idx ON cached_embeddings USING btree (expires_at);
+
+---- create above / drop below ----
+
+DROP INDEX cached_embeddings_expires_at_idx;
+
+ALTER TABLE cached_embeddings DROP COLUMN expires_at;
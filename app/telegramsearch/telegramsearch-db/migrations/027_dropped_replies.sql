@@ -0,0 +1,7 @@
+-- replies the filter dropped from kept threads are counted per reason for audit,
+-- e.g. {"low_content": 3, "spammer": 1}. NULL when none were dropped.
+ALTER TABLE chatthreads ADD COLUMN dropped_replies jsonb;
+
+---- create above / drop below ----
+
+ALTER TABLE chatthreads DROP COLUMN dropped_replies;
@@ -0,0 +1,30 @@
+-- contacts are kept per mention, so they go away with mentions of forgotten and deleted threads.
+ALTER TABLE entity_mentions
+    ADD COLUMN phones    TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN addresses TEXT[] NOT NULL DEFAULT '{}';
+
+-- contacts merged into entities before can't be attributed to threads, they are extracted again.
+UPDATE chatthreads SET entities_extracted_at = NULL
+WHERE thread_id IN (
+    SELECT m.thread_id
+    FROM entity_mentions m JOIN entities e ON (e.entity_id = m.entity_id)
+    WHERE cardinality(e.phones) > 0 OR cardinality(e.addresses) > 0
+);
+
+ALTER TABLE entities
+    DROP COLUMN phones,
+    DROP COLUMN addresses;
+
+---- create above / drop below ----
+
+ALTER TABLE entities
+    ADD COLUMN phones    TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN addresses TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE entities e SET
+    phones = ARRAY(SELECT DISTINCT p FROM entity_mentions m, unnest(m.phones) p WHERE m.entity_id = e.entity_id),
+    addresses = ARRAY(SELECT DISTINCT a FROM entity_mentions m, unnest(m.addresses) a WHERE m.entity_id = e.entity_id);
+
+ALTER TABLE entity_mentions
+    DROP COLUMN phones,
+    DROP COLUMN addresses;
@@ -0,0 +1,28 @@
+-- alerts about an earthquake are planned once, when its subscribers are found,
+-- and every alert is marked sent only when the bot sent it, failed ones are sent again.
+ALTER TABLE earthquakes ADD COLUMN alerts_planned_at TIMESTAMPTZ;
+
+-- subscribers were alerted about earthquakes seen before.
+UPDATE earthquakes SET alerts_planned_at = created_at;
+
+CREATE TABLE earthquake_alerts (
+    guid             TEXT             NOT NULL,
+    telegram_user_id BIGINT           NOT NULL,
+    tenant           TEXT             NOT NULL,
+    distance_km      DOUBLE PRECISION NOT NULL,
+    created_at       TIMESTAMPTZ      NOT NULL,
+    -- empty until the alert is sent.
+    sent_at          TIMESTAMPTZ,
+    PRIMARY KEY (guid, telegram_user_id, tenant)
+);
+
+ALTER TABLE earthquake_alerts
+    ADD CONSTRAINT earthquake_alerts_guid_fk FOREIGN KEY (guid) REFERENCES earthquakes (guid) ON DELETE CASCADE;
+
+CREATE INDEX earthquake_alerts_unsent_idx ON earthquake_alerts USING btree (guid) WHERE sent_at IS NULL;
+
+---- create above / drop below ----
+
+DROP TABLE earthquake_alerts;
+
+ALTER TABLE earthquakes DROP COLUMN alerts_planned_at;
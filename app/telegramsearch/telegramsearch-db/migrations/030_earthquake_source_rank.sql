@@ -0,0 +1,8 @@
+-- an earthquake reported by a more authoritative source replaces the stored magnitude and position.
+-- 0 is the most authoritative, earthquakes seen before are not replaced.
+ALTER TABLE earthquakes ADD COLUMN source_rank SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE earthquakes ALTER COLUMN source_rank DROP DEFAULT;
+
+---- create above / drop below ----
+
+ALTER TABLE earthquakes DROP COLUMN source_rank;
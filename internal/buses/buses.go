@@ -1,7 +1,24 @@
 package buses
 
-import "context"
+import (
+	"context"
+
+	"github.com/yanakipre/bot/internal/geo"
+)
 
 type Client interface {
-	GetNearest(ctx context.Context, dot Dot) ([]Bus, error)
+	GetNearest(ctx context.Context, dot geo.Point) ([]Bus, error)
+	// Arrivals at the stop, the soonest first.
+	// route is the short name or the ID of the route, arrivals of all routes are returned when it is empty.
+	Arrivals(ctx context.Context, stopID string, route string) ([]Arrival, error)
+	// NearestStops to the dot, the nearest first.
+	NearestStops(ctx context.Context, dot geo.Point, limit int) ([]Stop, error)
+	// Routes of the timetable, ordered by the short name.
+	Routes(ctx context.Context) ([]Route, error)
+	Route(ctx context.Context, routeID string) (Route, error)
+	Stop(ctx context.Context, stopID string) (Stop, error)
+	// Vehicle is the latest position of the bus.
+	Vehicle(ctx context.Context, busID string) (Bus, error)
+	// StaticVersion is the version of the timetable, empty until it is loaded.
+	StaticVersion() string
 }
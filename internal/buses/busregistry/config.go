@@ -0,0 +1,80 @@
+package busregistry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yanakipre/bot/internal/buses/cyprusbus"
+	"github.com/yanakipre/bot/internal/geo"
+)
+
+type Config struct {
+	// Feeds go from the most relevant, lookups by ID ask them in this order.
+	Feeds []FeedConfig `yaml:"feeds"`
+}
+
+// FeedConfig is a GTFS-realtime feed with its timetable, served by cyprusbus.
+type FeedConfig struct {
+	// Area is the polygon of the feed, lookups around a position ask the feeds containing it.
+	// The feed serves everywhere when it is empty.
+	Area             geo.Polygon `yaml:"area"`
+	cyprusbus.Config `yaml:",inline"`
+}
+
+// UnmarshalYAML starts a feed from cyprusbus.DefaultConfig,
+// feeds of other cities set name, base_url, static_url and disable embedded_static.
+func (c *FeedConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain FeedConfig
+	p := plain{Config: cyprusbus.DefaultConfig()}
+	if err := unmarshal(&p); err != nil {
+		return err
+	}
+	*c = FeedConfig(p)
+	return nil
+}
+
+// cyprusArea covers the island.
+var cyprusArea = geo.Polygon{
+	{Lat: 34.4, Lon: 32.2},
+	{Lat: 35.8, Lon: 32.2},
+	{Lat: 35.8, Lon: 34.7},
+	{Lat: 34.4, Lon: 34.7},
+}
+
+func DefaultConfig() Config {
+	return Config{Feeds: []FeedConfig{{
+		Area:   cyprusArea,
+		Config: cyprusbus.DefaultConfig(),
+	}}}
+}
+
+func (c *Config) Validate() error {
+	if len(c.Feeds) == 0 {
+		return errors.New("at least one bus feed is required")
+	}
+	seen := make(map[string]struct{}, len(c.Feeds))
+	for _, f := range c.Feeds {
+		if f.Name == "" {
+			return errors.New("bus feed name is required")
+		}
+		if strings.Contains(f.Name, idSeparator) {
+			return fmt.Errorf("bus feed name %q must not contain %q", f.Name, idSeparator)
+		}
+		if _, ok := seen[f.Name]; ok {
+			return fmt.Errorf("duplicate bus feed %q", f.Name)
+		}
+		seen[f.Name] = struct{}{}
+		if len(f.Area) > 0 && len(f.Area) < 3 {
+			return fmt.Errorf("area of bus feed %q must have at least 3 points", f.Name)
+		}
+		if f.BaseURL == "" {
+			return fmt.Errorf("bus feed %q has no base_url", f.Name)
+		}
+		// the embedded timetable has routes only, stop lookups fail on it until static_url is set.
+		if f.StaticURL == "" && !f.EmbeddedStatic {
+			return fmt.Errorf("bus feed %q has neither static_url nor embedded_static", f.Name)
+		}
+	}
+	return nil
+}
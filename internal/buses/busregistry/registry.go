@@ -0,0 +1,311 @@
+// Package busregistry serves several bus feeds, e.g. operators of different cities, as one buses.Client.
+package busregistry
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sourcegraph/conc/pool"
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/buses/cyprusbus"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/logger"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// idSeparator qualifies IDs with the feed, e.g. "cyprus:10300011".
+const idSeparator = ":"
+
+// Feed is a buses.Client of Registry.
+type Feed struct {
+	Name string
+	// Area is the polygon the feed serves, everywhere when it is empty.
+	Area geo.Polygon
+	buses.Client
+}
+
+var _ buses.Client = (*Registry)(nil)
+
+// Registry asks the feeds relevant for the request in parallel and merges their results.
+// Routes and stops are attributed with the feed name as the operator.
+// With several feeds the IDs it returns are qualified with the feed, "feed:id", as the feeds may reuse IDs.
+// The feeds are asked in order for unqualified IDs.
+type Registry struct {
+	feeds []Feed
+	// qualified is set when there are several feeds.
+	qualified bool
+}
+
+func NewRegistry(feeds ...Feed) *Registry {
+	return &Registry{feeds: feeds, qualified: len(feeds) > 1}
+}
+
+// New creates a cyprusbus client per feed.
+func New(cfg Config) *Registry {
+	feeds := make([]Feed, 0, len(cfg.Feeds))
+	for _, f := range cfg.Feeds {
+		feeds = append(feeds, Feed{Name: f.Name, Area: f.Area, Client: cyprusbus.NewClient(f.Config)})
+	}
+	return NewRegistry(feeds...)
+}
+
+// Ready fails when all feeds fail, failures of some of them are logged.
+func (r *Registry) Ready(ctx context.Context) error {
+	_, err := fanOut(ctx, r.feeds, func(f Feed) ([]struct{}, error) {
+		ready, ok := f.Client.(interface{ Ready(context.Context) error })
+		if !ok {
+			return nil, nil
+		}
+		return nil, ready.Ready(ctx)
+	})
+	return err
+}
+
+// Run runs the feeds polling in the background until ctx is done, see cyprusbus.Client.Run.
+func (r *Registry) Run(ctx context.Context) {
+	p := pool.New()
+	for _, f := range r.feeds {
+		if runner, ok := f.Client.(interface{ Run(context.Context) }); ok {
+			p.Go(func() { runner.Run(ctx) })
+		}
+	}
+	p.Wait()
+}
+
+// GetNearest returns buses of the feeds serving the dot, the nearest first.
+func (r *Registry) GetNearest(ctx context.Context, dot geo.Point) ([]buses.Bus, error) {
+	found, err := fanOut(ctx, r.covering(dot), func(f Feed) ([]buses.Bus, error) {
+		found, err := f.GetNearest(ctx, dot)
+		found = attribute(f, found, r.attributeBus)
+		return found, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return geo.Distance(dot, found[i].Position) < geo.Distance(dot, found[j].Position)
+	})
+	return found, nil
+}
+
+func (r *Registry) NearestStops(ctx context.Context, dot geo.Point, limit int) ([]buses.Stop, error) {
+	if limit <= 0 {
+		return nil, semerr.InvalidInput("limit must be positive", zap.Int("limit", limit))
+	}
+	stops, err := fanOut(ctx, r.covering(dot), func(f Feed) ([]buses.Stop, error) {
+		found, err := f.NearestStops(ctx, dot, limit)
+		found = attribute(f, found, r.attributeStop)
+		return found, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		return geo.Distance(dot, stops[i].Position) < geo.Distance(dot, stops[j].Position)
+	})
+	return stops[:min(limit, len(stops))], nil
+}
+
+// Arrivals at the stop from the feeds knowing it, it is not found when none of them does.
+func (r *Registry) Arrivals(ctx context.Context, stopID string, route string) ([]buses.Arrival, error) {
+	feeds, id := r.byID(stopID)
+	var known atomic.Int32
+	arrivals, err := fanOut(ctx, feeds, func(f Feed) ([]buses.Arrival, error) {
+		found, err := f.Arrivals(ctx, id, route)
+		if semerr.IsNotFound(err) {
+			return nil, nil
+		}
+		if err == nil {
+			known.Add(1)
+		}
+		found = attribute(f, found, r.attributeArrival)
+		return found, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if known.Load() == 0 {
+		return nil, semerr.NotFound("unknown stop", zap.String("stop_id", stopID))
+	}
+	sort.SliceStable(arrivals, func(i, j int) bool {
+		return arrivals[i].At.Before(arrivals[j].At)
+	})
+	return arrivals, nil
+}
+
+// Routes of all feeds, in the order of the feeds.
+func (r *Registry) Routes(ctx context.Context) ([]buses.Route, error) {
+	return fanOut(ctx, r.feeds, func(f Feed) ([]buses.Route, error) {
+		found, err := f.Routes(ctx)
+		found = attribute(f, found, r.attributeRoute)
+		return found, err
+	})
+}
+
+func (r *Registry) Route(ctx context.Context, routeID string) (buses.Route, error) {
+	feeds, id := r.byID(routeID)
+	return first(feeds, func(f Feed) (buses.Route, error) {
+		route, err := f.Route(ctx, id)
+		return r.attributeRoute(f, route), err
+	})
+}
+
+func (r *Registry) Stop(ctx context.Context, stopID string) (buses.Stop, error) {
+	feeds, id := r.byID(stopID)
+	return first(feeds, func(f Feed) (buses.Stop, error) {
+		stop, err := f.Stop(ctx, id)
+		return r.attributeStop(f, stop), err
+	})
+}
+
+func (r *Registry) Vehicle(ctx context.Context, busID string) (buses.Bus, error) {
+	feeds, id := r.byID(busID)
+	return first(feeds, func(f Feed) (buses.Bus, error) {
+		bus, err := f.Vehicle(ctx, id)
+		return r.attributeBus(f, bus), err
+	})
+}
+
+// StaticVersion lists versions of the feeds, e.g. "cyprus=2025-02-01".
+func (r *Registry) StaticVersion() string {
+	versions := make([]string, 0, len(r.feeds))
+	for _, f := range r.feeds {
+		versions = append(versions, f.Name+"="+f.StaticVersion())
+	}
+	return strings.Join(versions, ", ")
+}
+
+// covering are the feeds serving the dot.
+func (r *Registry) covering(dot geo.Point) []Feed {
+	var feeds []Feed
+	for _, f := range r.feeds {
+		if len(f.Area) == 0 || f.Area.Contains(dot) {
+			feeds = append(feeds, f)
+		}
+	}
+	return feeds
+}
+
+// byID returns the feed of the qualified ID and the ID without the qualifier,
+// or all feeds and the ID as is.
+func (r *Registry) byID(id string) ([]Feed, string) {
+	name, rest, ok := strings.Cut(id, idSeparator)
+	if ok {
+		if i := slices.IndexFunc(r.feeds, func(f Feed) bool { return f.Name == name }); i != -1 {
+			return r.feeds[i : i+1], rest
+		}
+	}
+	return r.feeds, id
+}
+
+// qualify the ID of the feed, see Registry.
+func (r *Registry) qualify(f Feed, id string) string {
+	if !r.qualified || id == "" {
+		return id
+	}
+	return f.Name + idSeparator + id
+}
+
+func (r *Registry) attributeRoute(f Feed, route buses.Route) buses.Route {
+	if route.ID != "" && route.Operator == "" {
+		route.Operator = f.Name
+	}
+	route.ID = r.qualify(f, route.ID)
+	return route
+}
+
+func (r *Registry) attributeStop(f Feed, stop buses.Stop) buses.Stop {
+	if stop.ID != "" && stop.Operator == "" {
+		stop.Operator = f.Name
+	}
+	stop.ID = r.qualify(f, stop.ID)
+	return stop
+}
+
+func (r *Registry) attributeBus(f Feed, bus buses.Bus) buses.Bus {
+	bus.ID = r.qualify(f, bus.ID)
+	bus.TripID = r.qualify(f, bus.TripID)
+	bus.Route = r.attributeRoute(f, bus.Route)
+	return bus
+}
+
+func (r *Registry) attributeArrival(f Feed, a buses.Arrival) buses.Arrival {
+	a.Stop = r.attributeStop(f, a.Stop)
+	a.Route = r.attributeRoute(f, a.Route)
+	a.TripID = r.qualify(f, a.TripID)
+	a.BusID = r.qualify(f, a.BusID)
+	return a
+}
+
+// attribute copies the found items, a feed may return the slice it keeps.
+func attribute[T any](f Feed, found []T, fn func(Feed, T) T) []T {
+	if found == nil {
+		return nil
+	}
+	r := make([]T, len(found))
+	for i := range found {
+		r[i] = fn(f, found[i])
+	}
+	return r
+}
+
+// fanOut calls fn for the feeds in parallel and concatenates the results in the order of the feeds.
+// It fails when all feeds fail, failures of some of them are logged.
+func fanOut[T any](ctx context.Context, feeds []Feed, fn func(f Feed) ([]T, error)) ([]T, error) {
+	results := make([][]T, len(feeds))
+	errs := make([]error, len(feeds))
+	p := pool.New()
+	for i, f := range feeds {
+		p.Go(func() {
+			results[i], errs[i] = fn(f)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("feed %s: %w", f.Name, errs[i])
+			}
+		})
+	}
+	p.Wait()
+	if err := errors.Join(errs...); err != nil {
+		if !slices.Contains(errs, nil) {
+			return nil, err
+		}
+		logger.Warn(ctx, "bus feeds failed", zap.Error(err))
+	}
+	var merged []T
+	for i := range results {
+		if errs[i] == nil {
+			merged = append(merged, results[i]...)
+		}
+	}
+	return merged, nil
+}
+
+// first asks the feeds in order and returns the first found.
+// It is not found when no feed fails otherwise.
+func first[T any](feeds []Feed, fn func(f Feed) (T, error)) (T, error) {
+	var zero T
+	var errs []error
+	var notFound error
+	for _, f := range feeds {
+		found, err := fn(f)
+		switch {
+		case err == nil:
+			return found, nil
+		case semerr.IsNotFound(err):
+			notFound = cmp.Or(notFound, err)
+		default:
+			errs = append(errs, fmt.Errorf("feed %s: %w", f.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return zero, errors.Join(errs...)
+	}
+	return zero, notFound
+}
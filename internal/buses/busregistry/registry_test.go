@@ -0,0 +1,242 @@
+package busregistry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+	"github.com/yanakipre/bot/internal/testtooling"
+)
+
+// feedFake knows the routes and stops, nearest are the buses and stops it returns for any dot.
+type feedFake struct {
+	buses.Client
+	err    error
+	routes map[string]buses.Route
+	stops  map[string]buses.Stop
+	buses  []buses.Bus
+}
+
+func (f *feedFake) GetNearest(context.Context, geo.Point) ([]buses.Bus, error) {
+	return f.buses, f.err
+}
+
+func (f *feedFake) NearestStops(_ context.Context, _ geo.Point, limit int) ([]buses.Stop, error) {
+	var r []buses.Stop
+	for _, s := range f.stops {
+		r = append(r, s)
+	}
+	return r[:min(limit, len(r))], f.err
+}
+
+func (f *feedFake) Route(_ context.Context, routeID string) (buses.Route, error) {
+	if f.err != nil {
+		return buses.Route{}, f.err
+	}
+	route, ok := f.routes[routeID]
+	if !ok {
+		return buses.Route{}, semerr.NotFound("unknown route")
+	}
+	return route, nil
+}
+
+func (f *feedFake) Arrivals(_ context.Context, stopID string, _ string) ([]buses.Arrival, error) {
+	stop, ok := f.stops[stopID]
+	if !ok {
+		return nil, semerr.NotFound("unknown stop")
+	}
+	var r []buses.Arrival
+	for _, route := range f.routes {
+		r = append(r, buses.Arrival{Stop: stop, Route: route, At: time.Unix(int64(len(route.ShortName)), 0)})
+	}
+	return r, nil
+}
+
+var (
+	limassol = geo.Point{Lat: 34.684422, Lon: 33.037147}
+	athens   = geo.Point{Lat: 37.9838, Lon: 23.7275}
+	// attica roughly.
+	atticaArea = geo.Polygon{{Lat: 37.6, Lon: 23.3}, {Lat: 38.3, Lon: 23.3}, {Lat: 38.3, Lon: 24.2}, {Lat: 37.6, Lon: 24.2}}
+)
+
+func newTestRegistry() (*Registry, *feedFake, *feedFake) {
+	cyprus := &feedFake{
+		routes: map[string]buses.Route{"10300011": {ID: "10300011", ShortName: "30"}},
+		stops:  map[string]buses.Stop{"A": {ID: "A", Position: geo.Point{Lat: limassol.Lat + 0.002, Lon: limassol.Lon}}},
+		buses:  []buses.Bus{{ID: "1", Route: buses.Route{ID: "10300011", ShortName: "30"}}},
+	}
+	attica := &feedFake{
+		// the same route ID as in Cyprus.
+		routes: map[string]buses.Route{"10300011": {ID: "10300011", ShortName: "A1"}, "X95": {ID: "X95", ShortName: "X95"}},
+		stops:  map[string]buses.Stop{"A": {ID: "A", Position: geo.Point{Lat: limassol.Lat + 0.001, Lon: limassol.Lon}}},
+		buses:  []buses.Bus{{ID: "1", Route: buses.Route{ID: "X95"}}},
+	}
+	return NewRegistry(
+		Feed{Name: "cyprus", Area: cyprusArea, Client: cyprus},
+		Feed{Name: "attica", Area: atticaArea, Client: attica},
+	), cyprus, attica
+}
+
+func TestCyprusArea(t *testing.T) {
+	require.True(t, cyprusArea.Contains(limassol))
+	require.False(t, cyprusArea.Contains(athens))
+	require.True(t, atticaArea.Contains(athens))
+}
+
+func TestRegistry_GetNearest(t *testing.T) {
+	testtooling.SetNewGlobalLoggerQuietly()
+	ctx := context.Background()
+	r, cyprus, attica := newTestRegistry()
+
+	found, err := r.GetNearest(ctx, athens)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, "attica", found[0].Route.Operator)
+
+	found, err = r.GetNearest(ctx, geo.Point{Lat: 0, Lon: 0})
+	require.NoError(t, err)
+	require.Empty(t, found)
+
+	// the feeds overlap, a failing one is skipped.
+	r = NewRegistry(
+		Feed{Name: "cyprus", Client: cyprus},
+		Feed{Name: "attica", Client: attica},
+	)
+	attica.err = errors.New("connection reset")
+	found, err = r.GetNearest(ctx, limassol)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, "cyprus", found[0].Route.Operator)
+
+	cyprus.err = errors.New("connection reset")
+	_, err = r.GetNearest(ctx, limassol)
+	require.ErrorContains(t, err, "feed cyprus")
+	require.ErrorContains(t, err, "feed attica")
+}
+
+func TestRegistry_GetNearestOrder(t *testing.T) {
+	ctx := context.Background()
+	cyprus := &feedFake{buses: []buses.Bus{
+		{ID: "1", Route: buses.Route{ID: "30"}, Position: geo.Point{Lat: limassol.Lat + 0.003, Lon: limassol.Lon}},
+	}}
+	attica := &feedFake{buses: []buses.Bus{
+		// the same vehicle ID as in Cyprus.
+		{ID: "1", Route: buses.Route{ID: "X95"}, Position: geo.Point{Lat: limassol.Lat + 0.001, Lon: limassol.Lon}},
+	}}
+	r := NewRegistry(Feed{Name: "cyprus", Client: cyprus}, Feed{Name: "attica", Client: attica})
+
+	found, err := r.GetNearest(ctx, limassol)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, "attica:1", found[0].ID, "the nearest first across the feeds")
+	require.Equal(t, "attica:X95", found[0].Route.ID)
+	require.Equal(t, "cyprus:1", found[1].ID)
+
+	// a single feed keeps its IDs.
+	found, err = NewRegistry(Feed{Name: "cyprus", Client: cyprus}).GetNearest(ctx, limassol)
+	require.NoError(t, err)
+	require.Equal(t, "1", found[0].ID)
+	require.Equal(t, "30", found[0].Route.ID)
+}
+
+func TestRegistry_NearestStops(t *testing.T) {
+	r, cyprus, attica := newTestRegistry()
+	r = NewRegistry(
+		Feed{Name: "cyprus", Client: cyprus},
+		Feed{Name: "attica", Client: attica},
+	)
+	stops, err := r.NearestStops(context.Background(), limassol, 1)
+	require.NoError(t, err)
+	require.Len(t, stops, 1)
+	require.Equal(t, "attica", stops[0].Operator)
+	require.Equal(t, "attica:A", stops[0].ID)
+
+	_, err = r.NearestStops(context.Background(), limassol, 0)
+	require.True(t, semerr.IsInvalidInput(err))
+}
+
+func TestRegistry_ByID(t *testing.T) {
+	ctx := context.Background()
+	r, _, _ := newTestRegistry()
+
+	route, err := r.Route(ctx, "10300011")
+	require.NoError(t, err)
+	require.Equal(t, buses.Route{ID: "cyprus:10300011", ShortName: "30", Operator: "cyprus"}, route)
+
+	// the returned ID is looked up again.
+	route, err = r.Route(ctx, route.ID)
+	require.NoError(t, err)
+	require.Equal(t, "30", route.ShortName)
+
+	route, err = r.Route(ctx, "attica:10300011")
+	require.NoError(t, err)
+	require.Equal(t, "A1", route.ShortName)
+
+	route, err = r.Route(ctx, "X95")
+	require.NoError(t, err)
+	require.Equal(t, "attica", route.Operator)
+
+	_, err = r.Route(ctx, "cyprus:X95")
+	require.True(t, semerr.IsNotFound(err))
+
+	arrivals, err := r.Arrivals(ctx, "A", "")
+	require.NoError(t, err)
+	require.Len(t, arrivals, 3)
+
+	arrivals, err = r.Arrivals(ctx, "cyprus:A", "")
+	require.NoError(t, err)
+	require.Len(t, arrivals, 1)
+	require.Equal(t, "cyprus", arrivals[0].Stop.Operator)
+	require.Equal(t, "cyprus:A", arrivals[0].Stop.ID)
+	require.Equal(t, "cyprus:10300011", arrivals[0].Route.ID)
+
+	_, err = r.Arrivals(ctx, "B", "")
+	require.True(t, semerr.IsNotFound(err))
+}
+
+func TestConfig(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+feeds:
+  - name: cyprus
+    static_url: https://example.com/cyprus.zip
+  - name: attica
+    base_url: https://example.com/gtfs-rt
+    static_url: https://example.com/gtfs.zip
+    embedded_static: false
+    timezone: Europe/Athens
+    area:
+      - {lat: 37.6, lon: 23.3}
+      - {lat: 38.3, lon: 23.3}
+      - {lat: 38.3, lon: 24.2}
+`), &cfg))
+	require.NoError(t, cfg.Validate())
+	require.Len(t, cfg.Feeds, 2)
+
+	// feeds start from the defaults.
+	require.True(t, cfg.Feeds[0].EmbeddedStatic)
+	require.Equal(t, 20*time.Second, cfg.Feeds[1].Timer.Duration)
+	require.False(t, cfg.Feeds[1].EmbeddedStatic)
+	require.Equal(t, "Europe/Athens", cfg.Feeds[1].Timezone)
+	require.Equal(t, geo.Point{Lat: 38.3, Lon: 24.2}, cfg.Feeds[1].Area[2])
+
+	cfg.Feeds[1].Name = "cyprus"
+	require.ErrorContains(t, cfg.Validate(), "duplicate")
+	cfg.Feeds[1].Name = "gr:attica"
+	require.ErrorContains(t, cfg.Validate(), "must not contain")
+	cfg.Feeds[1].Name = "attica"
+	cfg.Feeds[1].StaticURL = ""
+	require.ErrorContains(t, cfg.Validate(), "neither static_url nor embedded_static")
+}
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,260 @@
+package cyprusbus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// realtimeFetcher reads vehicles and trip updates of the timetable, see protobufFetcher.
+type realtimeFetcher interface {
+	Ready(ctx context.Context) error
+	FetchRealtime(ctx context.Context) (realtimeFeed, error)
+	// Schedule is nil until the fetcher is ready.
+	Schedule() *schedule
+}
+
+const (
+	// passedToleranceMeters keeps the bus arriving while it is at the stop, positions are noisy.
+	passedToleranceMeters = 30
+	// offPathMeters is the distance to the trip path, the vehicle is on another trip when it is farther.
+	offPathMeters = 500
+	// arrivalGrace keeps the bus arriving while it boards passengers.
+	arrivalGrace = time.Minute
+)
+
+// Arrivals are predicted by trip updates of the operator when there are some,
+// by the progress of the bus along its trip otherwise.
+// Trips without the bus come from the timetable within the arrivals horizon.
+func (c *Client) Arrivals(ctx context.Context, stopID string, route string) ([]buses.Arrival, error) {
+	if err := c.realtime.Ready(ctx); err != nil {
+		return nil, err
+	}
+	s := c.realtime.Schedule()
+	if err := s.requireStops(); err != nil {
+		return nil, err
+	}
+	stop, ok := s.stops[stopID]
+	if !ok {
+		return nil, semerr.NotFound("unknown stop", zap.String("stop_id", stopID))
+	}
+	feed, err := c.realtime.FetchRealtime(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("realtime fetch failed: %w", err)
+	}
+	now := c.now()
+
+	vehicles := map[string]buses.Bus{}
+	for _, bus := range feed.Buses {
+		if bus.TripID != "" {
+			vehicles[bus.TripID] = bus
+		}
+	}
+	// tracked trips do not arrive by the timetable, even when they have passed the stop.
+	tracked := map[string]bool{}
+	var r []buses.Arrival
+	for _, tu := range feed.TripUpdates {
+		t := s.trips[tu.TripID]
+		tripRoute := buses.Route{ID: tu.RouteID}
+		if t != nil {
+			tripRoute = s.tripRoute(t)
+		} else if rt, ok := s.routes[tu.RouteID]; ok {
+			tripRoute = rt
+		}
+		if !matchRoute(tripRoute, route) {
+			continue
+		}
+		tracked[tu.TripID] = true
+		at, ok := s.tripUpdateArrival(tu, t, stopID, now)
+		if !ok {
+			continue
+		}
+		arrival := buses.Arrival{
+			Stop:     stop,
+			Route:    tripRoute,
+			TripID:   tu.TripID,
+			BusID:    tu.VehicleID,
+			At:       at,
+			Estimate: buses.EstimateRealtime,
+		}
+		if t != nil {
+			arrival.Headsign = t.Headsign
+		}
+		if arrival.BusID == "" {
+			arrival.BusID = vehicles[tu.TripID].ID
+		}
+		r = append(r, arrival)
+	}
+
+	for tripID, bus := range vehicles {
+		t, ok := s.trips[tripID]
+		if tracked[tripID] || !ok || !matchRoute(s.tripRoute(t), route) {
+			continue
+		}
+		tracked[tripID] = true
+		at, ok := t.progressArrival(bus.Position, stopID, now)
+		if !ok {
+			continue
+		}
+		r = append(r, buses.Arrival{
+			Stop:     stop,
+			Route:    s.tripRoute(t),
+			TripID:   tripID,
+			Headsign: t.Headsign,
+			BusID:    bus.ID,
+			At:       at,
+			Estimate: buses.EstimateProgress,
+		})
+	}
+
+	// trips after midnight belong to the day before.
+	days := []time.Time{s.serviceDay(now.AddDate(0, 0, -1)), s.serviceDay(now)}
+	for _, t := range s.stopTrips[stopID] {
+		if tracked[t.ID] || !matchRoute(s.tripRoute(t), route) {
+			continue
+		}
+		for _, day := range days {
+			if !s.active(t.ServiceID, day) {
+				continue
+			}
+			for _, st := range t.StopTimes {
+				at := day.Add(st.Arrival)
+				if st.StopID != stopID || st.Arrival < 0 || at.Before(now) || !at.Before(now.Add(c.horizon)) {
+					continue
+				}
+				r = append(r, buses.Arrival{
+					Stop:     stop,
+					Route:    s.tripRoute(t),
+					TripID:   t.ID,
+					Headsign: t.Headsign,
+					At:       at,
+					Estimate: buses.EstimateScheduled,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].At.Before(r[j].At)
+	})
+	return r, nil
+}
+
+func (c *Client) NearestStops(ctx context.Context, dot geo.Point, limit int) ([]buses.Stop, error) {
+	if limit <= 0 {
+		return nil, semerr.InvalidInput("limit must be positive", zap.Int("limit", limit))
+	}
+	if err := c.realtime.Ready(ctx); err != nil {
+		return nil, err
+	}
+	s := c.realtime.Schedule()
+	if err := s.requireStops(); err != nil {
+		return nil, err
+	}
+	return s.stopIndex.Nearest(dot, limit), nil
+}
+
+// matchRoute by the short name or the ID, any route matches the empty one.
+func matchRoute(r buses.Route, route string) bool {
+	return route == "" || strings.EqualFold(r.ShortName, route) || r.ID == route
+}
+
+// tripUpdateArrival is the predicted arrival at the stop, or the timetable with the delay of the latest update before it.
+// Producers drop updates of passed stops, so a stop before all updates is passed.
+func (s *schedule) tripUpdateArrival(tu tripUpdate, t *trip, stopID string, now time.Time) (time.Time, bool) {
+	if t == nil {
+		// not in the timetable, only the predictions of the stop are known.
+		for _, u := range tu.StopTimeUpdates {
+			if u.StopID == stopID && !u.Skipped && !u.Arrival.IsZero() && !u.Arrival.Before(now.Add(-arrivalGrace)) {
+				return u.Arrival, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	day := s.tripDay(t, tu.StartDate, now)
+	var delay time.Duration
+	hasDelay := false
+	for _, st := range t.StopTimes {
+		update, ok := findStopTimeUpdate(tu.StopTimeUpdates, st)
+		if ok {
+			switch {
+			case update.HasDelay:
+				delay, hasDelay = update.Delay, true
+			case !update.Arrival.IsZero() && st.Arrival >= 0:
+				delay, hasDelay = update.Arrival.Sub(day.Add(st.Arrival)), true
+			}
+		}
+		if st.StopID != stopID || (ok && update.Skipped) {
+			continue
+		}
+		var at time.Time
+		switch {
+		case ok && !update.Arrival.IsZero():
+			at = update.Arrival
+		case hasDelay && st.Arrival >= 0:
+			at = day.Add(st.Arrival + delay)
+		default:
+			continue
+		}
+		if at.Before(now.Add(-arrivalGrace)) {
+			continue
+		}
+		return at, true
+	}
+	return time.Time{}, false
+}
+
+func findStopTimeUpdate(updates []stopTimeUpdate, st stopTime) (stopTimeUpdate, bool) {
+	for _, u := range updates {
+		if (u.Sequence >= 0 && u.Sequence == st.Sequence) || (u.Sequence < 0 && u.StopID == st.StopID) {
+			return u, true
+		}
+	}
+	return stopTimeUpdate{}, false
+}
+
+// tripDay is the service day of the trip: the start date when it is known,
+// otherwise the day the trip starts the nearest to now, today or yesterday.
+func (s *schedule) tripDay(t *trip, startDate string, now time.Time) time.Time {
+	if d, err := time.ParseInLocation(dateLayout, startDate, s.location); err == nil {
+		return s.serviceDay(d)
+	}
+	today, yesterday := s.serviceDay(now), s.serviceDay(now.AddDate(0, 0, -1))
+	if len(t.StopTimes) == 0 {
+		return today
+	}
+	start := t.StopTimes[0].Departure
+	if now.Sub(yesterday.Add(start)).Abs() < now.Sub(today.Add(start)).Abs() {
+		return yesterday
+	}
+	return today
+}
+
+// progressArrival applies the timetable to the progress of the bus along the trip path:
+// the bus is as late as it is behind the timetable.
+func (t *trip) progressArrival(position geo.Point, stopID string, now time.Time) (time.Time, bool) {
+	dist, _, offset := projectOnPath(t.Path, 0, position)
+	if offset > offPathMeters {
+		return time.Time{}, false
+	}
+	scheduled, ok := t.scheduledAt(dist)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, st := range t.StopTimes {
+		if st.StopID != stopID || st.Arrival < 0 || st.Dist < dist-passedToleranceMeters {
+			continue
+		}
+		return now.Add(max(st.Arrival-scheduled, 0)), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,273 @@
+package cyprusbus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+	"github.com/yanakipre/bot/internal/testtooling"
+)
+
+type mockRealtime struct {
+	schedule *schedule
+	feed     realtimeFeed
+}
+
+func (m *mockRealtime) Ready(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockRealtime) FetchRealtime(ctx context.Context) (realtimeFeed, error) {
+	return m.feed, nil
+}
+
+func (m *mockRealtime) Schedule() *schedule {
+	return m.schedule
+}
+
+func newArrivalsClient(t *testing.T, feed realtimeFeed) (*Client, time.Time) {
+	t.Helper()
+	s, err := loadSchedule(os.DirFS("testdata/gtfs"), "UTC")
+	if err != nil {
+		t.Fatalf("Failed to load the schedule: %v", err)
+	}
+	// Wednesday, T1 has left A, T2 and T3 have not.
+	now := time.Date(2025, 2, 5, 8, 4, 0, 0, s.location)
+	client := NewClient(DefaultConfig())
+	client.realtime = &mockRealtime{schedule: s, feed: feed}
+	client.now = func() time.Time { return now }
+	return client, now
+}
+
+type wantArrival struct {
+	TripID   string
+	BusID    string
+	At       string
+	Estimate buses.Estimate
+}
+
+func checkArrivals(t *testing.T, got []buses.Arrival, want []wantArrival) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d arrivals, got %d: %+v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.TripID != w.TripID || g.BusID != w.BusID || g.At.Format("15:04") != w.At || g.Estimate != w.Estimate {
+			t.Errorf("Arrival %d: got %s %s %s %s, want %+v", i, g.TripID, g.BusID, g.At.Format("15:04"), g.Estimate, w)
+		}
+	}
+}
+
+func TestArrivals(t *testing.T) {
+	delay := realtimeFeed{
+		Buses: []buses.Bus{
+			// at B, 5 minutes before the timetable.
+			{ID: "v2", TripID: "T2", Position: geo.Point{Lat: 34.70, Lon: 33.01}},
+		},
+		TripUpdates: map[string]tripUpdate{
+			"T1": {
+				TripID:    "T1",
+				VehicleID: "v1",
+				StopTimeUpdates: []stopTimeUpdate{
+					{StopID: "A", Sequence: 1, Delay: 2 * time.Minute, HasDelay: true},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		feed  realtimeFeed
+		stop  string
+		route string
+		want  []wantArrival
+	}{
+		{
+			name: "delay is propagated and buses progress along trips",
+			feed: delay,
+			stop: "C",
+			want: []wantArrival{
+				{TripID: "T2", BusID: "v2", At: "08:09", Estimate: buses.EstimateProgress},
+				{TripID: "T1", BusID: "v1", At: "08:12", Estimate: buses.EstimateRealtime},
+				{TripID: "T3", At: "08:30", Estimate: buses.EstimateScheduled},
+			},
+		},
+		{
+			name:  "route by short name",
+			feed:  delay,
+			stop:  "C",
+			route: "30",
+			want: []wantArrival{
+				{TripID: "T3", At: "08:30", Estimate: buses.EstimateScheduled},
+			},
+		},
+		{
+			name: "passed stop",
+			feed: delay,
+			stop: "A",
+			want: []wantArrival{
+				{TripID: "T3", At: "08:20", Estimate: buses.EstimateScheduled},
+			},
+		},
+		{
+			name: "predicted arrivals",
+			feed: realtimeFeed{
+				TripUpdates: map[string]tripUpdate{
+					// updates of passed stops are dropped.
+					"T1": {
+						TripID: "T1",
+						StopTimeUpdates: []stopTimeUpdate{
+							{StopID: "C", Sequence: -1, Arrival: time.Date(2025, 2, 5, 6, 15, 0, 0, time.UTC)},
+						},
+					},
+					"X9": {
+						TripID:  "X9",
+						RouteID: "10030011",
+						StopTimeUpdates: []stopTimeUpdate{
+							{StopID: "B", Sequence: 7, Arrival: time.Date(2025, 2, 5, 6, 7, 0, 0, time.UTC)},
+						},
+					},
+				},
+			},
+			stop:  "B",
+			route: "3",
+			want: []wantArrival{
+				{TripID: "X9", At: "08:07", Estimate: buses.EstimateRealtime},
+				{TripID: "T2", At: "09:05", Estimate: buses.EstimateScheduled},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, now := newArrivalsClient(t, tt.feed)
+			if tt.name == "predicted arrivals" {
+				client.horizon = 2 * time.Hour
+			}
+			got, err := client.Arrivals(context.Background(), tt.stop, tt.route)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for i := range got {
+				got[i].At = got[i].At.In(now.Location())
+			}
+			checkArrivals(t, got, tt.want)
+		})
+	}
+
+	t.Run("unknown stop", func(t *testing.T) {
+		client, _ := newArrivalsClient(t, delay)
+		_, err := client.Arrivals(context.Background(), "Z", "")
+		if !semerr.IsNotFound(err) {
+			t.Errorf("Expected not found, got %v", err)
+		}
+	})
+}
+
+func TestNearestStops(t *testing.T) {
+	client, _ := newArrivalsClient(t, realtimeFeed{})
+	stops, err := client.NearestStops(context.Background(), geo.Point{Lat: 34.701, Lon: 33.012}, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stops) != 2 || stops[0].ID != "B" || stops[1].ID != "C" {
+		t.Errorf("Expected B and C, got %+v", stops)
+	}
+}
+
+func TestFetchRealtime(t *testing.T) {
+	testtooling.SetNewGlobalLoggerQuietly()
+	feed := &gtfs.FeedMessage{
+		Header: &gtfs.FeedHeader{GtfsRealtimeVersion: proto.String("2.0")},
+		Entity: []*gtfs.FeedEntity{
+			{
+				Id: proto.String("1"),
+				Vehicle: &gtfs.VehiclePosition{
+					Trip:     &gtfs.TripDescriptor{TripId: proto.String("T1"), RouteId: proto.String("10030011")},
+					Vehicle:  &gtfs.VehicleDescriptor{Id: proto.String("v1")},
+					Position: &gtfs.Position{Latitude: proto.Float32(34.7), Longitude: proto.Float32(33.01)},
+				},
+			},
+			{
+				Id: proto.String("2"),
+				TripUpdate: &gtfs.TripUpdate{
+					Trip:    &gtfs.TripDescriptor{TripId: proto.String("T1"), StartDate: proto.String("20250205")},
+					Vehicle: &gtfs.VehicleDescriptor{Id: proto.String("v1")},
+					StopTimeUpdate: []*gtfs.TripUpdate_StopTimeUpdate{
+						{
+							StopSequence: proto.Uint32(2),
+							StopId:       proto.String("B"),
+							Arrival:      &gtfs.TripUpdate_StopTimeEvent{Delay: proto.Int32(60)},
+						},
+						{
+							StopId:    proto.String("C"),
+							Departure: &gtfs.TripUpdate_StopTimeEvent{Time: proto.Int64(1738736000)},
+						},
+					},
+				},
+			},
+			{
+				Id: proto.String("3"),
+				TripUpdate: &gtfs.TripUpdate{
+					Trip: &gtfs.TripDescriptor{
+						TripId:               proto.String("T2"),
+						ScheduleRelationship: gtfs.TripDescriptor_CANCELED.Enum(),
+					},
+				},
+			},
+		},
+	}
+	data, err := proto.Marshal(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/gtfs-realtime" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = srv.URL
+	fetcher := newProtobufFetcher(cfg)
+	if err := fetcher.Ready(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := fetcher.FetchRealtime(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(got.Buses) != 1 || got.Buses[0].TripID != "T1" || got.Buses[0].Route.ShortName != "3" {
+		t.Errorf("Unexpected buses: %+v", got.Buses)
+	}
+	if _, ok := got.TripUpdates["T2"]; ok {
+		t.Error("Expected canceled trips to be skipped")
+	}
+	tu, ok := got.TripUpdates["T1"]
+	if !ok {
+		t.Fatalf("Expected the trip update of T1, got %+v", got.TripUpdates)
+	}
+	if tu.VehicleID != "v1" || tu.StartDate != "20250205" || len(tu.StopTimeUpdates) != 2 {
+		t.Fatalf("Unexpected trip update: %+v", tu)
+	}
+	if u := tu.StopTimeUpdates[0]; u.Sequence != 2 || !u.HasDelay || u.Delay != time.Minute || !u.Arrival.IsZero() {
+		t.Errorf("Unexpected delay update: %+v", u)
+	}
+	if u := tu.StopTimeUpdates[1]; u.Sequence != -1 || u.HasDelay || u.Arrival.Unix() != 1738736000 {
+		t.Errorf("Unexpected departure update: %+v", u)
+	}
+}
@@ -8,14 +8,34 @@ import (
 )
 
 type Config struct {
-	// Timer is the time interval between 2 requests to the API.
-	// It is used to understand the directions of the buses.
+	// Name of the feed in logs and metrics, see busregistry.
+	Name string `yaml:"name"`
+	// Timer is the time interval between 2 polls of the API, see Client.Run.
+	// Positions of recent polls give the directions of the buses.
 	Timer encodingtooling.Duration `yaml:"timer"`
+	// HistorySize is the number of recent positions kept per bus to compute its heading and speed.
+	HistorySize int `yaml:"history_size"`
+	// MinMoveMeters is the distance a bus moves before its heading changes, smaller moves are GPS noise.
+	MinMoveMeters float64 `yaml:"min_move_meters"`
+	// HeadingToleranceDegrees is the largest angle between the heading of a bus
+	// and the direction to the user, for the bus to approach the user.
+	HeadingToleranceDegrees float64 `yaml:"heading_tolerance_degrees"`
 	// BoxSizeMeters limits the size of the bounding box around the user's location.
 	// Buses outside of this box are not considered.
 	BoxSizeMeters float64            `yaml:"box_size_meters"`
 	BaseURL       string             `yaml:"base_url"`
 	HTTPTransport resttooling.Config `yaml:"http_transport"`
+	// Timezone of the timetable when the feed has no agency time zone.
+	Timezone string `yaml:"timezone"`
+	// ArrivalsHorizon limits arrivals by the timetable, tracked buses arrive at any time.
+	ArrivalsHorizon encodingtooling.Duration `yaml:"arrivals_horizon"`
+	// StaticURL is the GTFS static zip, downloaded on start and every StaticRefresh.
+	// Stops and trips of arrivals come from it, the copy embedded at build time has routes only
+	// and is used when it is empty or until the download succeeds.
+	StaticURL     string                   `yaml:"static_url"`
+	StaticRefresh encodingtooling.Duration `yaml:"static_refresh"`
+	// EmbeddedStatic falls back to the timetable of Cyprus embedded at build time, feeds of other cities disable it.
+	EmbeddedStatic bool `yaml:"embedded_static"`
 }
 
 // this URL is retrieved from data.gov.cy.
@@ -25,9 +45,17 @@ func DefaultConfig() Config {
 	tr := resttooling.DefaultTransportConfig()
 	tr.ClientName = "cyprusbus"
 	return Config{
-		Timer:         encodingtooling.NewDuration(20 * time.Second),
-		BoxSizeMeters: 1000,
-		BaseURL:       baseUrl,
-		HTTPTransport: tr,
+		Name:                    "cyprus",
+		Timer:                   encodingtooling.NewDuration(20 * time.Second),
+		HistorySize:             4,
+		MinMoveMeters:           10,
+		HeadingToleranceDegrees: 60,
+		BoxSizeMeters:           1000,
+		BaseURL:                 baseUrl,
+		HTTPTransport:           tr,
+		Timezone:                "Asia/Nicosia",
+		ArrivalsHorizon:         encodingtooling.NewDuration(time.Hour),
+		StaticRefresh:           encodingtooling.NewDuration(24 * time.Hour),
+		EmbeddedStatic:          true,
 	}
 }
@@ -5,9 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yanakipre/bot/internal/geo"
 	"github.com/yanakipre/bot/internal/testtooling"
-
-	"github.com/yanakipre/bot/internal/buses"
 )
 
 func TestFullWorkflow(t *testing.T) {
@@ -23,9 +22,9 @@ func TestFullWorkflow(t *testing.T) {
 		t.Fatalf("Failed to initialize the routes cache: %v", err)
 	}
 
-	testDot := buses.Dot{
-		Lat:  34.700474470158184,
-		Long: 33.100647034953774,
+	testDot := geo.Point{
+		Lat: 34.700474470158184,
+		Lon: 33.100647034953774,
 	}
 
 	nearestBuses, err := client.GetNearest(context.Background(), testDot)
@@ -38,7 +37,7 @@ func TestFullWorkflow(t *testing.T) {
 		t.Logf("Found %d buses approaching the area", len(nearestBuses))
 		for _, bus := range nearestBuses {
 			t.Logf("Route %s %s (ID: %s, Route: %s) - Distance: %1.fm\n",
-				bus.Route.ShortName, bus.Route.LongName, bus.ID, bus.Route.ID, CalculateDistance(testDot, bus.Position))
+				bus.Route.ShortName, bus.Route.LongName, bus.ID, bus.Route.ID, geo.Distance(testDot, bus.Position))
 		}
 	} else {
 		t.Log("No approaching buses found (might be expected behavior)")
@@ -0,0 +1,106 @@
+package cyprusbus
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// Ready loads the timetable, the client answers without it only about positions of buses.
+func (c *Client) Ready(ctx context.Context) error {
+	return c.realtime.Ready(ctx)
+}
+
+func (c *Client) StaticVersion() string {
+	if s := c.realtime.Schedule(); s != nil {
+		return s.version
+	}
+	return ""
+}
+
+func (c *Client) Routes(ctx context.Context) ([]buses.Route, error) {
+	if err := c.realtime.Ready(ctx); err != nil {
+		return nil, err
+	}
+	s := c.realtime.Schedule()
+	r := make([]buses.Route, 0, len(s.routes))
+	for _, route := range s.routes {
+		r = append(r, route)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		return routeLess(r[i], r[j])
+	})
+	return r, nil
+}
+
+func (c *Client) Route(ctx context.Context, routeID string) (buses.Route, error) {
+	if err := c.realtime.Ready(ctx); err != nil {
+		return buses.Route{}, err
+	}
+	route, ok := c.realtime.Schedule().routes[routeID]
+	if !ok {
+		return buses.Route{}, semerr.NotFound("unknown route", zap.String("route_id", routeID))
+	}
+	return route, nil
+}
+
+func (c *Client) Stop(ctx context.Context, stopID string) (buses.Stop, error) {
+	if err := c.realtime.Ready(ctx); err != nil {
+		return buses.Stop{}, err
+	}
+	s := c.realtime.Schedule()
+	if err := s.requireStops(); err != nil {
+		return buses.Stop{}, err
+	}
+	stop, ok := s.stops[stopID]
+	if !ok {
+		return buses.Stop{}, semerr.NotFound("unknown stop", zap.String("stop_id", stopID))
+	}
+	return stop, nil
+}
+
+func (c *Client) Vehicle(ctx context.Context, busID string) (buses.Bus, error) {
+	latest, err := c.snapshot(ctx)
+	if err != nil {
+		return buses.Bus{}, err
+	}
+	for _, bus := range latest {
+		if bus.ID == busID {
+			return bus, nil
+		}
+	}
+	return buses.Bus{}, semerr.NotFound("bus is not on the road", zap.String("bus_id", busID))
+}
+
+// routeLess orders short names by their numbers: 3, 3A, 30.
+func routeLess(a, b buses.Route) bool {
+	na, sa := splitNumber(a.ShortName)
+	nb, sb := splitNumber(b.ShortName)
+	if na != nb {
+		return na < nb
+	}
+	if sa != sb {
+		return sa < sb
+	}
+	return a.ID < b.ID
+}
+
+// splitNumber splits the leading number of s, names without one go after numbered.
+func splitNumber(s string) (int, string) {
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	if i == -1 {
+		i = len(s)
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return int(^uint(0) >> 1), s
+	}
+	return n, s[i:]
+}
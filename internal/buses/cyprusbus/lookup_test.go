@@ -0,0 +1,73 @@
+package cyprusbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+func TestRouteLess(t *testing.T) {
+	routes := []buses.Route{
+		{ID: "5", ShortName: "EXPRESS"},
+		{ID: "4", ShortName: "30"},
+		{ID: "3", ShortName: "3A"},
+		{ID: "2", ShortName: "4"},
+		{ID: "1", ShortName: "3"},
+	}
+	want := []string{"3", "3A", "4", "30", "EXPRESS"}
+	client, _ := newArrivalsClient(t, realtimeFeed{})
+	client.realtime.Schedule().routes = map[string]buses.Route{}
+	for _, r := range routes {
+		client.realtime.Schedule().routes[r.ID] = r
+	}
+	got, err := client.Routes(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, r := range got {
+		if r.ShortName != want[i] {
+			t.Errorf("Route %d is %s, want %s", i, r.ShortName, want[i])
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	client, _ := newArrivalsClient(t, realtimeFeed{})
+	ctx := context.Background()
+
+	if stop, err := client.Stop(ctx, "B"); err != nil || stop.Name != "Mesa Geitonia" {
+		t.Errorf("Unexpected stop %+v: %v", stop, err)
+	}
+	if _, err := client.Stop(ctx, "Z"); !semerr.IsNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+	if route, err := client.Route(ctx, "10300011"); err != nil || route.ShortName != "30" {
+		t.Errorf("Unexpected route %+v: %v", route, err)
+	}
+	if _, err := client.Route(ctx, "0"); !semerr.IsNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+
+	positions := [][]buses.Bus{
+		{{ID: "bus1", Position: geo.Point{Lat: 34.700, Lon: 33.000}}},
+		{{ID: "bus1", Position: geo.Point{Lat: 34.701, Lon: 33.000}}},
+	}
+	client.fetcher = NewMockFetcher(positions, make([]error, len(positions)))
+	now := time.Date(2025, 2, 5, 8, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	if bus, err := client.Vehicle(ctx, "bus1"); err != nil || bus.Moving {
+		t.Errorf("Expected the bus without the heading after the first poll, got %+v: %v", bus, err)
+	}
+	now = now.Add(client.timer)
+	bus, err := client.Vehicle(ctx, "bus1")
+	if err != nil || !bus.Moving || angleDiff(bus.Heading, 0) > 1 {
+		t.Errorf("Unexpected bus %+v: %v", bus, err)
+	}
+	if _, err := client.Vehicle(ctx, "bus2"); !semerr.IsNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package cyprusbus
+
+import (
+	"sync"
+
+	"github.com/yanakipre/bot/internal/promtooling"
+)
+
+var registerMetricsOnce sync.Once
+
+// registerMetrics is called by NewClient, tests create many clients.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		promtooling.MustRegister(StaticInfo, StaticRefreshes)
+	})
+}
+
+var StaticInfo = promtooling.NewGaugeVec(
+	"gtfs_static_info",
+	"The loaded GTFS static feed is 1, per feed, version and source: url or embedded",
+	[]string{"feed", "version", "source"},
+)
+
+var StaticRefreshes = promtooling.NewCounterVec(
+	"gtfs_static_refreshes",
+	"Number of GTFS static feed downloads, per feed and result: ok or failed",
+	[]string{"feed", "result"},
+)
@@ -0,0 +1,546 @@
+package cyprusbus
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	// the timetable is in the agency time zone, systems without zoneinfo still need it.
+	_ "time/tzdata"
+
+	"go.uber.org/zap"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+// schedule is the GTFS static feed, see https://gtfs.org/documentation/schedule/reference/.
+// Only routes.txt is required: without stops, trips and stop times nothing arrives at stops,
+// e.g. the embedded feed has routes only, see requireStops.
+type schedule struct {
+	routes map[string]buses.Route
+	stops  map[string]buses.Stop
+	// stopIndex finds the stops nearest to a point.
+	stopIndex *geo.Index[buses.Stop]
+	trips     map[string]*trip
+	// stopTrips are the trips visiting the stop.
+	stopTrips map[string][]*trip
+	services  map[string]*service
+	location  *time.Location
+	// version is feed_version of feed_info.txt, or the checksum of the downloaded zip.
+	version string
+	// source is "url" or "embedded".
+	source string
+}
+
+type trip struct {
+	ID        string
+	RouteID   string
+	ServiceID string
+	ShapeID   string
+	Headsign  string
+	// StopTimes ordered by the stop sequence.
+	StopTimes []stopTime
+	// Path is the shape of the trip, or lines between its stops when it has no shape.
+	Path []pathPoint
+}
+
+type stopTime struct {
+	StopID   string
+	Sequence int
+	// Arrival and Departure are since noon minus 12h of the service day,
+	// they exceed 24h for trips after midnight.
+	Arrival   time.Duration
+	Departure time.Duration
+	// Dist is the distance along the trip path in meters.
+	Dist float64
+}
+
+type pathPoint struct {
+	Position geo.Point
+	// Dist is the distance from the start of the path in meters.
+	Dist float64
+}
+
+// service is the calendar of trips.
+type service struct {
+	// Weekdays are indexed by time.Weekday.
+	Weekdays [7]bool
+	// Start and End are inclusive dates.
+	Start, End string
+	// Exceptions are added (true) and removed (false) dates.
+	Exceptions map[string]bool
+}
+
+// dateLayout of GTFS dates, they compare as strings.
+const dateLayout = "20060102"
+
+func (s *schedule) active(serviceID string, day time.Time) bool {
+	svc, ok := s.services[serviceID]
+	if !ok {
+		// feeds without calendars run every day.
+		return len(s.services) == 0
+	}
+	date := day.Format(dateLayout)
+	if added, ok := svc.Exceptions[date]; ok {
+		return added
+	}
+	return svc.Weekdays[day.Weekday()] && svc.Start <= date && date <= svc.End
+}
+
+// serviceDay is the midnight of the day, the timetable counts from noon minus 12h.
+func (s *schedule) serviceDay(t time.Time) time.Time {
+	t = t.In(s.location)
+	noon := time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, s.location)
+	return noon.Add(-12 * time.Hour)
+}
+
+// requireStops fails lookups of stops in a feed of routes only, they would find nothing.
+func (s *schedule) requireStops() error {
+	if len(s.stops) == 0 {
+		return semerr.FailedPrecondition(
+			"the timetable has no stops, set static_url of the feed",
+			zap.String("version", s.version),
+			zap.String("source", s.source),
+		)
+	}
+	return nil
+}
+
+func (s *schedule) tripRoute(t *trip) buses.Route {
+	if route, ok := s.routes[t.RouteID]; ok {
+		return route
+	}
+	return buses.Route{ID: t.RouteID}
+}
+
+// loadSchedule reads the feed files from the root of fsys.
+// The agency time zone is used when the feed has it, defaultLocation otherwise.
+func loadSchedule(fsys fs.FS, defaultLocation string) (*schedule, error) {
+	s := &schedule{
+		stops:     map[string]buses.Stop{},
+		trips:     map[string]*trip{},
+		stopTrips: map[string][]*trip{},
+		services:  map[string]*service{},
+	}
+	var err error
+	if s.routes, err = readRoutes(fsys); err != nil {
+		return nil, err
+	}
+
+	location := defaultLocation
+	err = readTable(fsys, "agency.txt", []string{"agency_timezone"}, func(row tableRow) error {
+		location = row.get("agency_timezone")
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if s.location, err = time.LoadLocation(location); err != nil {
+		return nil, fmt.Errorf("failed to load the agency time zone: %w", err)
+	}
+	err = readTable(fsys, "feed_info.txt", nil, func(row tableRow) error {
+		s.version = row.get("feed_version")
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, load := range []func(fs.FS) error{s.readStops, s.readTrips, s.readStopTimes, s.readCalendar, s.readCalendarDates} {
+		if err := load(fsys); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	shapes, err := readShapes(fsys)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, t := range s.trips {
+		sort.Slice(t.StopTimes, func(i, j int) bool {
+			return t.StopTimes[i].Sequence < t.StopTimes[j].Sequence
+		})
+		s.buildPath(t, shapes[t.ShapeID])
+		for _, st := range t.StopTimes {
+			s.stopTrips[st.StopID] = append(s.stopTrips[st.StopID], t)
+		}
+	}
+	stops := make([]buses.Stop, 0, len(s.stops))
+	for _, stop := range s.stops {
+		stops = append(stops, stop)
+	}
+	s.stopIndex = geo.NewIndex(stops, func(stop buses.Stop) geo.Point {
+		return stop.Position
+	})
+	return s, nil
+}
+
+func readRoutes(fsys fs.FS) (map[string]buses.Route, error) {
+	routes := map[string]buses.Route{}
+	err := readTable(fsys, "routes.txt", []string{"route_id", "route_short_name", "route_long_name"}, func(row tableRow) error {
+		route := buses.Route{
+			ID:        row.get("route_id"),
+			ShortName: row.get("route_short_name"),
+			LongName:  row.get("route_long_name"),
+		}
+		routes[route.ID] = route
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (s *schedule) readStops(fsys fs.FS) error {
+	return readTable(fsys, "stops.txt", []string{"stop_id", "stop_lat", "stop_lon"}, func(row tableRow) error {
+		lat, err := row.float("stop_lat")
+		if err != nil {
+			return err
+		}
+		lon, err := row.float("stop_lon")
+		if err != nil {
+			return err
+		}
+		stop := buses.Stop{
+			ID:       row.get("stop_id"),
+			Name:     row.get("stop_name"),
+			Position: geo.Point{Lat: lat, Lon: lon},
+		}
+		s.stops[stop.ID] = stop
+		return nil
+	})
+}
+
+func (s *schedule) readTrips(fsys fs.FS) error {
+	return readTable(fsys, "trips.txt", []string{"route_id", "service_id", "trip_id"}, func(row tableRow) error {
+		t := &trip{
+			ID:        row.get("trip_id"),
+			RouteID:   row.get("route_id"),
+			ServiceID: row.get("service_id"),
+			ShapeID:   row.get("shape_id"),
+			Headsign:  row.get("trip_headsign"),
+		}
+		s.trips[t.ID] = t
+		return nil
+	})
+}
+
+func (s *schedule) readStopTimes(fsys fs.FS) error {
+	required := []string{"trip_id", "stop_id", "stop_sequence", "arrival_time", "departure_time"}
+	// times are given for timepoints only, the rest are interpolated along the path.
+	return readTable(fsys, "stop_times.txt", required, func(row tableRow) error {
+		t, ok := s.trips[row.get("trip_id")]
+		if !ok {
+			return fmt.Errorf("unknown trip %q", row.get("trip_id"))
+		}
+		if _, ok := s.stops[row.get("stop_id")]; !ok {
+			return fmt.Errorf("unknown stop %q", row.get("stop_id"))
+		}
+		seq, err := strconv.Atoi(row.get("stop_sequence"))
+		if err != nil {
+			return fmt.Errorf("stop_sequence: %w", err)
+		}
+		st := stopTime{StopID: row.get("stop_id"), Sequence: seq, Arrival: -1, Departure: -1}
+		if v := row.get("arrival_time"); v != "" {
+			if st.Arrival, err = parseGTFSTime(v); err != nil {
+				return fmt.Errorf("arrival_time: %w", err)
+			}
+		}
+		if v := row.get("departure_time"); v != "" {
+			if st.Departure, err = parseGTFSTime(v); err != nil {
+				return fmt.Errorf("departure_time: %w", err)
+			}
+		}
+		t.StopTimes = append(t.StopTimes, st)
+		return nil
+	})
+}
+
+func (s *schedule) readCalendar(fsys fs.FS) error {
+	days := []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	required := append([]string{"service_id", "start_date", "end_date"}, days...)
+	return readTable(fsys, "calendar.txt", required, func(row tableRow) error {
+		svc := s.service(row.get("service_id"))
+		for i, day := range days {
+			svc.Weekdays[i] = row.get(day) == "1"
+		}
+		svc.Start = row.get("start_date")
+		svc.End = row.get("end_date")
+		return nil
+	})
+}
+
+func (s *schedule) readCalendarDates(fsys fs.FS) error {
+	return readTable(fsys, "calendar_dates.txt", []string{"service_id", "date", "exception_type"}, func(row tableRow) error {
+		svc := s.service(row.get("service_id"))
+		switch row.get("exception_type") {
+		case "1":
+			svc.Exceptions[row.get("date")] = true
+		case "2":
+			svc.Exceptions[row.get("date")] = false
+		default:
+			return fmt.Errorf("unknown exception_type %q", row.get("exception_type"))
+		}
+		return nil
+	})
+}
+
+func (s *schedule) service(id string) *service {
+	svc, ok := s.services[id]
+	if !ok {
+		// services of calendar_dates.txt only run on the added dates.
+		svc = &service{Exceptions: map[string]bool{}}
+		s.services[id] = svc
+	}
+	return svc
+}
+
+type shapePoint struct {
+	Position geo.Point
+	Sequence int
+}
+
+func readShapes(fsys fs.FS) (map[string][]shapePoint, error) {
+	shapes := map[string][]shapePoint{}
+	err := readTable(fsys, "shapes.txt", []string{"shape_id", "shape_pt_lat", "shape_pt_lon", "shape_pt_sequence"}, func(row tableRow) error {
+		lat, err := row.float("shape_pt_lat")
+		if err != nil {
+			return err
+		}
+		lon, err := row.float("shape_pt_lon")
+		if err != nil {
+			return err
+		}
+		seq, err := strconv.Atoi(row.get("shape_pt_sequence"))
+		if err != nil {
+			return fmt.Errorf("shape_pt_sequence: %w", err)
+		}
+		id := row.get("shape_id")
+		shapes[id] = append(shapes[id], shapePoint{Position: geo.Point{Lat: lat, Lon: lon}, Sequence: seq})
+		return nil
+	})
+	for _, points := range shapes {
+		sort.Slice(points, func(i, j int) bool { return points[i].Sequence < points[j].Sequence })
+	}
+	return shapes, err
+}
+
+// buildPath measures the trip path and places stops on it,
+// then fills stop times missing between timepoints in proportion to the distance.
+func (s *schedule) buildPath(t *trip, shape []shapePoint) {
+	positions := make([]geo.Point, 0, max(len(shape), len(t.StopTimes)))
+	if len(shape) > 1 {
+		for _, p := range shape {
+			positions = append(positions, p.Position)
+		}
+	} else {
+		for _, st := range t.StopTimes {
+			positions = append(positions, s.stops[st.StopID].Position)
+		}
+	}
+	t.Path = make([]pathPoint, len(positions))
+	for i, p := range positions {
+		t.Path[i].Position = p
+		if i > 0 {
+			t.Path[i].Dist = t.Path[i-1].Dist + geo.Distance(positions[i-1], p)
+		}
+	}
+
+	// stops are in the order of the path, a loop does not bring a stop back to the start.
+	from := 0
+	for i := range t.StopTimes {
+		var dist float64
+		dist, from, _ = projectOnPath(t.Path, from, s.stops[t.StopTimes[i].StopID].Position)
+		t.StopTimes[i].Dist = dist
+	}
+
+	for i := range t.StopTimes {
+		st := &t.StopTimes[i]
+		if st.Arrival < 0 {
+			st.Arrival = st.Departure
+		}
+		if st.Departure < 0 {
+			st.Departure = st.Arrival
+		}
+	}
+	prev := -1
+	for i, st := range t.StopTimes {
+		if st.Arrival < 0 {
+			continue
+		}
+		if prev >= 0 && i-prev > 1 {
+			a, b := t.StopTimes[prev], t.StopTimes[i]
+			for j := prev + 1; j < i; j++ {
+				at := interpolate(a.Dist, b.Dist, a.Departure, b.Arrival, t.StopTimes[j].Dist)
+				t.StopTimes[j].Arrival, t.StopTimes[j].Departure = at, at
+			}
+		}
+		prev = i
+	}
+}
+
+// scheduledAt is the time of the timetable when the vehicle is dist along the path.
+func (t *trip) scheduledAt(dist float64) (time.Duration, bool) {
+	var a *stopTime
+	for i := range t.StopTimes {
+		b := &t.StopTimes[i]
+		if b.Arrival < 0 {
+			continue
+		}
+		if a == nil && dist <= b.Dist {
+			return b.Departure, true
+		}
+		if a != nil && dist <= b.Dist {
+			return interpolate(a.Dist, b.Dist, a.Departure, b.Arrival, dist), true
+		}
+		a = b
+	}
+	if a == nil {
+		return 0, false
+	}
+	return a.Arrival, true
+}
+
+func interpolate(fromDist, toDist float64, from, to time.Duration, dist float64) time.Duration {
+	if toDist <= fromDist {
+		return from
+	}
+	ratio := math.Min(math.Max((dist-fromDist)/(toDist-fromDist), 0), 1)
+	return from + time.Duration(ratio*float64(to-from))
+}
+
+// projectOnPath finds the point of the path nearest to p, starting at the segment from.
+// It returns the distance of the point along the path, its segment and the distance from p to it.
+func projectOnPath(path []pathPoint, from int, p geo.Point) (dist float64, segment int, offset float64) {
+	if len(path) == 0 {
+		return 0, 0, math.Inf(1)
+	}
+	if len(path) == 1 {
+		return 0, 0, geo.Distance(path[0].Position, p)
+	}
+	offset = math.Inf(1)
+	for i := from; i < len(path)-1; i++ {
+		a, b := path[i], path[i+1]
+		ratio := segmentRatio(a.Position, b.Position, p)
+		nearest := geo.Point{
+			Lat: a.Position.Lat + ratio*(b.Position.Lat-a.Position.Lat),
+			Lon: a.Position.Lon + ratio*(b.Position.Lon-a.Position.Lon),
+		}
+		if d := geo.Distance(nearest, p); d < offset {
+			offset = d
+			segment = i
+			dist = a.Dist + ratio*(b.Dist-a.Dist)
+		}
+	}
+	return dist, segment, offset
+}
+
+// segmentRatio is the position of the projection of p on the segment a-b, from 0 at a to 1 at b.
+// Segments are short, so degrees are scaled to a plane around a.
+func segmentRatio(a, b, p geo.Point) float64 {
+	scale := math.Cos(a.Lat * math.Pi / 180)
+	bx, by := (b.Lon-a.Lon)*scale, b.Lat-a.Lat
+	px, py := (p.Lon-a.Lon)*scale, p.Lat-a.Lat
+	length := bx*bx + by*by
+	if length == 0 {
+		return 0
+	}
+	return math.Min(math.Max((px*bx+py*by)/length, 0), 1)
+}
+
+// parseGTFSTime reads "H:MM:SS", hours exceed 23 after midnight.
+func parseGTFSTime(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("unknown time format %q", s)
+	}
+	var values [3]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 || (i > 0 && v > 59) {
+			return 0, fmt.Errorf("unknown time format %q", s)
+		}
+		values[i] = v
+	}
+	return time.Duration(values[0])*time.Hour + time.Duration(values[1])*time.Minute + time.Duration(values[2])*time.Second, nil
+}
+
+type tableRow struct {
+	fieldIndex map[string]int
+	record     []string
+}
+
+func (r tableRow) get(field string) string {
+	i, ok := r.fieldIndex[field]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r tableRow) float(field string) (float64, error) {
+	v, err := strconv.ParseFloat(r.get(field), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", field, err)
+	}
+	return v, nil
+}
+
+// readTable calls fn for every record of the CSV file name.
+// fs.ErrNotExist is returned when there is no such file.
+func readTable(fsys fs.FS, name string, requiredFields []string, fn func(row tableRow) error) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	// Read the file content first to handle BOM (<feff>)
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	// Remove BOM if present
+	data = bytes.TrimLeft(data, "\xef\xbb\xbf")
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	// optional trailing fields are omitted sometimes.
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read %s header: %w", name, err)
+	}
+	row := tableRow{fieldIndex: make(map[string]int, len(header))}
+	for i, field := range header {
+		row.fieldIndex[strings.TrimSpace(field)] = i
+	}
+	for _, field := range requiredFields {
+		if _, ok := row.fieldIndex[field]; !ok {
+			return fmt.Errorf("required field %s is not present in %s", field, name)
+		}
+	}
+
+	for line := 2; ; line++ {
+		row.record, err = reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s record %w", name, err)
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("%s line %d: %w", name, line, err)
+		}
+	}
+}
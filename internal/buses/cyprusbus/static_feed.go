@@ -0,0 +1,236 @@
+package cyprusbus
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/yanakipre/bot/internal/logger"
+)
+
+const (
+	// embeddedVersion is the version of the embedded feed without feed_info.txt.
+	embeddedVersion = "embedded"
+	// maxStaticBytes limits the downloaded zip, the feed of Cyprus is a few megabytes.
+	maxStaticBytes = 200 << 20
+	// staticTimeout limits the download and parsing of the feed.
+	staticTimeout = 5 * time.Minute
+)
+
+// staticFeed is the GTFS static feed downloaded from Config.StaticURL,
+// the copy embedded at build time is used until the download succeeds, see Config.EmbeddedStatic.
+// Refreshed schedules replace the previous one at once, readers keep the one they got.
+type staticFeed struct {
+	name       string
+	url        string
+	httpClient *http.Client
+	timezone   string
+	embedded   bool
+
+	// loads share the first load between callers of Ready.
+	loads singleflight.Group
+
+	mu       sync.Mutex
+	schedule *schedule
+}
+
+func newStaticFeed(cfg Config, httpClient *http.Client) *staticFeed {
+	return &staticFeed{
+		name:       cfg.Name,
+		url:        cfg.StaticURL,
+		httpClient: httpClient,
+		timezone:   cfg.Timezone,
+		embedded:   cfg.EmbeddedStatic,
+	}
+}
+
+// Ready loads the schedule once: downloads it, or falls back to the embedded one.
+// Concurrent callers share the load, the lock is held only to swap the schedule.
+func (f *staticFeed) Ready(ctx context.Context) error {
+	if f.Schedule() != nil {
+		return nil
+	}
+	_, err, _ := f.loads.Do("ready", func() (any, error) {
+		if f.Schedule() != nil {
+			return nil, nil
+		}
+		s, err := f.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		// Refresh may have swapped a newer one meanwhile.
+		if f.schedule == nil {
+			f.swap(s)
+		}
+		return nil, nil
+	})
+	return err
+}
+
+func (f *staticFeed) load(ctx context.Context) (*schedule, error) {
+	if f.url == "" && !f.embedded {
+		return nil, fmt.Errorf("feed %s has neither static_url nor embedded_static", f.name)
+	}
+	if f.url != "" {
+		s, err := f.download(ctx)
+		if err == nil {
+			return s, nil
+		}
+		if !f.embedded {
+			return nil, fmt.Errorf("GTFS static download of feed %s failed: %w", f.name, err)
+		}
+		logger.Warn(ctx, "GTFS static download failed, using the embedded feed", zap.Error(err), zap.String("feed", f.name))
+	}
+	return loadEmbeddedSchedule(f.timezone)
+}
+
+// Refresh downloads the feed and replaces the schedule.
+// The schedule is kept when the download fails or the feed is invalid.
+func (f *staticFeed) Refresh(ctx context.Context) error {
+	if f.url == "" {
+		return nil
+	}
+	s, err := f.download(ctx)
+	if err != nil {
+		StaticRefreshes.WithLabelValues(f.name, "failed").Inc()
+		return err
+	}
+	StaticRefreshes.WithLabelValues(f.name, "ok").Inc()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.schedule != nil && f.schedule.version == s.version {
+		return nil
+	}
+	f.swap(s)
+	logger.Info(ctx, "GTFS static feed refreshed", zap.String("feed", f.name), zap.String("version", s.version))
+	return nil
+}
+
+// Run refreshes the feed every interval until ctx is done.
+func (f *staticFeed) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := f.Refresh(ctx); err != nil {
+			logger.Warn(ctx, "GTFS static refresh failed", zap.Error(err), zap.String("feed", f.name))
+		}
+	}
+}
+
+// Schedule is nil until the feed is ready.
+func (f *staticFeed) Schedule() *schedule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.schedule
+}
+
+// Version of the loaded feed, empty until the feed is ready.
+func (f *staticFeed) Version() string {
+	if s := f.Schedule(); s != nil {
+		return s.version
+	}
+	return ""
+}
+
+// swap is called with mu held.
+func (f *staticFeed) swap(s *schedule) {
+	f.schedule = s
+	StaticInfo.DeletePartialMatch(prometheus.Labels{"feed": f.name})
+	StaticInfo.WithLabelValues(f.name, s.version, s.source).Set(1)
+}
+
+func (f *staticFeed) download(ctx context.Context) (*schedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, staticTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxStaticBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxStaticBytes {
+		return nil, fmt.Errorf("GTFS static feed exceeds %d bytes", maxStaticBytes)
+	}
+	return loadZipSchedule(data, f.timezone)
+}
+
+// loadZipSchedule reads the feed zip, files are at the root of the archive.
+func loadZipSchedule(data []byte, defaultLocation string) (*schedule, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GTFS static zip: %w", err)
+	}
+	s, err := loadValidSchedule(archive, defaultLocation)
+	if err != nil {
+		return nil, err
+	}
+	if s.version == "" {
+		sum := sha256.Sum256(data)
+		s.version = hex.EncodeToString(sum[:6])
+	}
+	s.source = "url"
+	return s, nil
+}
+
+func loadEmbeddedSchedule(defaultLocation string) (*schedule, error) {
+	data, err := fs.Sub(routeFS, "data")
+	if err != nil {
+		return nil, err
+	}
+	s, err := loadValidSchedule(data, defaultLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the embedded schedule: %w", err)
+	}
+	if s.version == "" {
+		s.version = embeddedVersion
+	}
+	s.source = "embedded"
+	return s, nil
+}
+
+// loadValidSchedule rejects feeds which would break the lookups, e.g. a truncated export.
+func loadValidSchedule(fsys fs.FS, defaultLocation string) (*schedule, error) {
+	s, err := loadSchedule(fsys, defaultLocation)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.routes) == 0 {
+		return nil, errors.New("GTFS static feed has no routes")
+	}
+	for _, t := range s.trips {
+		if _, ok := s.routes[t.RouteID]; !ok {
+			return nil, fmt.Errorf("trip %q refers to unknown route %q", t.ID, t.RouteID)
+		}
+	}
+	return s, nil
+}
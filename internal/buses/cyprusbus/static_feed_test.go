@@ -0,0 +1,226 @@
+package cyprusbus
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yanakipre/bot/internal/testtooling"
+)
+
+// gtfsZip archives testdata/gtfs, files override or add the ones of the directory.
+func gtfsZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	entries, err := os.ReadDir("testdata/gtfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string]string{}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata/gtfs", e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[e.Name()] = string(data)
+	}
+	for name, data := range files {
+		contents[name] = data
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range contents {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// staticServer serves the zip set by the test, the status when it is not 200.
+type staticServer struct {
+	// requests counts requests, including ones waiting for mu.
+	requests atomic.Int32
+
+	mu     sync.Mutex
+	data   []byte
+	status int
+}
+
+func (s *staticServer) set(status int, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status, s.data = status, data
+}
+
+func (s *staticServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != http.StatusOK {
+		w.WriteHeader(s.status)
+		return
+	}
+	_, _ = w.Write(s.data)
+}
+
+func newStaticFeedForTest(t *testing.T, upstream *staticServer) *staticFeed {
+	t.Helper()
+	testtooling.SetNewGlobalLoggerQuietly()
+	srv := httptest.NewServer(upstream)
+	t.Cleanup(srv.Close)
+	cfg := DefaultConfig()
+	cfg.StaticURL = srv.URL + "/gtfs.zip"
+	return newStaticFeed(cfg, srv.Client())
+}
+
+func TestStaticFeed_Refresh(t *testing.T) {
+	ctx := context.Background()
+	upstream := &staticServer{}
+	upstream.set(http.StatusOK, gtfsZip(t, map[string]string{
+		"feed_info.txt": "feed_publisher_name,feed_publisher_url,feed_lang,feed_version\nCyprus,https://example.com,en,2025-02-01\n",
+	}))
+	f := newStaticFeedForTest(t, upstream)
+
+	if err := f.Ready(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if f.Version() != "2025-02-01" || f.Schedule().source != "url" {
+		t.Fatalf("Expected the downloaded feed, got version %q from %q", f.Version(), f.Schedule().source)
+	}
+	if _, ok := f.Schedule().stops["C"]; !ok {
+		t.Error("Expected stops of the downloaded feed")
+	}
+	before := f.Schedule()
+
+	// a route is added, the feed has no version this time.
+	routes, err := os.ReadFile("testdata/gtfs/routes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream.set(http.StatusOK, gtfsZip(t, map[string]string{
+		"routes.txt": string(routes) + "10500011,6,5,Limassol Marina - Germasogeia,,3,0000FF,FFFFFF\n",
+	}))
+	if err := f.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v := f.Version(); v == "2025-02-01" || len(v) != 12 {
+		t.Errorf("Expected the checksum version, got %q", v)
+	}
+	if _, ok := f.Schedule().routes["10500011"]; !ok {
+		t.Error("Expected the added route")
+	}
+	if _, ok := before.routes["10500011"]; ok {
+		t.Error("Expected the previous schedule unchanged")
+	}
+
+	refreshed := f.Schedule()
+	for name, set := range map[string]func(){
+		"server error": func() { upstream.set(http.StatusInternalServerError, nil) },
+		"not a zip":    func() { upstream.set(http.StatusOK, []byte("<html>maintenance</html>")) },
+		"no routes": func() {
+			upstream.set(http.StatusOK, gtfsZip(t, map[string]string{"routes.txt": "route_id,route_short_name,route_long_name,route_type\n"}))
+		},
+		"unknown route": func() {
+			upstream.set(http.StatusOK, gtfsZip(t, map[string]string{"trips.txt": "route_id,service_id,trip_id\n999,WD,T9\n"}))
+		},
+	} {
+		set()
+		if err := f.Refresh(ctx); err == nil {
+			t.Errorf("%s: expected the refresh to fail", name)
+		}
+		if f.Schedule() != refreshed {
+			t.Errorf("%s: expected the schedule kept", name)
+		}
+	}
+}
+
+func TestStaticFeed_EmbeddedFallback(t *testing.T) {
+	ctx := context.Background()
+	upstream := &staticServer{}
+	upstream.set(http.StatusServiceUnavailable, nil)
+	f := newStaticFeedForTest(t, upstream)
+
+	if err := f.Ready(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if f.Version() != embeddedVersion || f.Schedule().source != "embedded" {
+		t.Fatalf("Expected the embedded feed, got version %q", f.Version())
+	}
+	if len(f.Schedule().routes) == 0 {
+		t.Error("Expected routes of the embedded feed")
+	}
+
+	upstream.set(http.StatusOK, gtfsZip(t, nil))
+	if err := f.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if f.Schedule().source != "url" {
+		t.Error("Expected the downloaded feed to replace the embedded one")
+	}
+}
+
+func TestStaticFeed_NoEmbedded(t *testing.T) {
+	ctx := context.Background()
+	upstream := &staticServer{}
+	upstream.set(http.StatusServiceUnavailable, nil)
+	f := newStaticFeedForTest(t, upstream)
+	// feeds of other cities have no embedded timetable.
+	f.embedded = false
+
+	if err := f.Ready(ctx); err == nil {
+		t.Fatal("Expected the feed not ready without the download")
+	}
+	upstream.set(http.StatusOK, gtfsZip(t, nil))
+	if err := f.Ready(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if f.Schedule().source != "url" {
+		t.Error("Expected the downloaded feed")
+	}
+}
+
+func TestStaticFeed_ReadyUnlocked(t *testing.T) {
+	upstream := &staticServer{}
+	upstream.set(http.StatusOK, gtfsZip(t, nil))
+	f := newStaticFeedForTest(t, upstream)
+
+	// the download waits until the server is unlocked.
+	upstream.mu.Lock()
+	ready := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { ready <- f.Ready(context.Background()) }()
+	}
+	for upstream.requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if s := f.Schedule(); s != nil {
+		t.Fatalf("Expected no schedule during the download, got %q", s.version)
+	}
+	upstream.mu.Unlock()
+	for i := 0; i < 2; i++ {
+		if err := <-ready; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if f.Schedule().source != "url" {
+		t.Error("Expected the downloaded feed")
+	}
+	if n := upstream.requests.Load(); n != 1 {
+		t.Errorf("Expected callers to share the download, got %d requests", n)
+	}
+}
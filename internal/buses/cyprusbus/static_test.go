@@ -0,0 +1,134 @@
+package cyprusbus
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/semerr"
+)
+
+func TestLoadSchedule(t *testing.T) {
+	s, err := loadSchedule(os.DirFS("testdata/gtfs"), "UTC")
+	if err != nil {
+		t.Fatalf("Failed to load the schedule: %v", err)
+	}
+
+	if s.location.String() != "Asia/Nicosia" {
+		t.Errorf("Expected the agency time zone, got %s", s.location)
+	}
+	if route := s.routes["10300011"]; route.ShortName != "30" {
+		t.Errorf("Unexpected route: %+v", route)
+	}
+	if len(s.stops) != 3 || len(s.trips) != 4 {
+		t.Fatalf("Expected 3 stops and 4 trips, got %d and %d", len(s.stops), len(s.trips))
+	}
+	if len(s.stopTrips["B"]) != 4 {
+		t.Errorf("Expected 4 trips visiting B, got %d", len(s.stopTrips["B"]))
+	}
+
+	t.Run("shape path", func(t *testing.T) {
+		trip := s.trips["T1"]
+		if len(trip.Path) != 4 {
+			t.Fatalf("Expected the shape of 4 points, got %d", len(trip.Path))
+		}
+		if trip.Path[1].Position.Lat != 34.701 {
+			t.Errorf("Shape points are not ordered by sequence: %+v", trip.Path)
+		}
+		if trip.StopTimes[0].Dist != 0 {
+			t.Errorf("The first stop is at %.1fm, expected the start of the path", trip.StopTimes[0].Dist)
+		}
+		last := trip.Path[len(trip.Path)-1].Dist
+		if math.Abs(trip.StopTimes[2].Dist-last) > 1 {
+			t.Errorf("The last stop is at %.1fm, expected the end of the path at %.1fm", trip.StopTimes[2].Dist, last)
+		}
+	})
+
+	t.Run("path between stops without a shape", func(t *testing.T) {
+		trip := s.trips["T2"]
+		if len(trip.Path) != 3 {
+			t.Fatalf("Expected the path through 3 stops, got %d", len(trip.Path))
+		}
+		if math.Abs(trip.StopTimes[1].Dist-geo.Distance(s.stops["A"].Position, s.stops["B"].Position)) > 1 {
+			t.Errorf("Unexpected distance of B: %.1fm", trip.StopTimes[1].Dist)
+		}
+	})
+
+	t.Run("interpolated stop times", func(t *testing.T) {
+		st := s.trips["T1"].StopTimes[1]
+		// B is in the middle of the path, a bit farther because of the detour.
+		if st.Arrival < 8*time.Hour+4*time.Minute || st.Arrival > 8*time.Hour+6*time.Minute {
+			t.Errorf("Expected B at about 08:05, got %s", st.Arrival)
+		}
+	})
+
+	t.Run("calendar", func(t *testing.T) {
+		wednesday := s.serviceDay(time.Date(2025, 2, 5, 10, 0, 0, 0, s.location))
+		thursday := wednesday.AddDate(0, 0, 1)
+		saturday := wednesday.AddDate(0, 0, 3)
+		tests := []struct {
+			service string
+			day     time.Time
+			want    bool
+		}{
+			{"WEEKDAYS", wednesday, true},
+			{"WEEKDAYS", saturday, false},
+			{"WEEKDAYS", thursday, false},
+			{"HOLIDAY", thursday, true},
+			{"HOLIDAY", wednesday, false},
+			{"unknown", wednesday, false},
+		}
+		for _, tt := range tests {
+			if got := s.active(tt.service, tt.day); got != tt.want {
+				t.Errorf("active(%s, %s) = %v, want %v", tt.service, tt.day.Format(dateLayout), got, tt.want)
+			}
+		}
+	})
+}
+
+func TestParseGTFSTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "08:05:30", want: 8*time.Hour + 5*time.Minute + 30*time.Second},
+		{in: "7:00:00", want: 7 * time.Hour},
+		{in: "25:10:00", want: 25*time.Hour + 10*time.Minute},
+		{in: "08:60:00", wantErr: true},
+		{in: "08:05", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseGTFSTime(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseGTFSTime(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseGTFSTime(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEmbeddedSchedule(t *testing.T) {
+	fetcher := newProtobufFetcher(DefaultConfig())
+	if err := fetcher.Ready(context.Background()); err != nil {
+		t.Fatalf("Failed to load the embedded schedule: %v", err)
+	}
+	if len(fetcher.Schedule().routes) == 0 {
+		t.Error("Expected routes in the embedded schedule")
+	}
+
+	// stops are downloaded, lookups of them fail instead of finding nothing.
+	client := NewClient(DefaultConfig())
+	client.realtime = &mockRealtime{schedule: fetcher.Schedule()}
+	if _, err := client.NearestStops(context.Background(), geo.Point{Lat: 34.7, Lon: 33}, 1); !semerr.IsFailedPrecondition(err) {
+		t.Errorf("Expected failed precondition, got %v", err)
+	}
+	if _, err := client.Arrivals(context.Background(), "A", ""); !semerr.IsFailedPrecondition(err) {
+		t.Errorf("Expected failed precondition, got %v", err)
+	}
+}
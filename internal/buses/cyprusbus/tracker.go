@@ -0,0 +1,104 @@
+package cyprusbus
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+)
+
+type sample struct {
+	Position geo.Point
+	At       time.Time
+}
+
+// tracker keeps recent positions of buses, they give the heading and the speed of every bus.
+type tracker struct {
+	size    int
+	minMove float64
+	// maxAge drops older positions, e.g. when positions are polled on demand rarely.
+	maxAge time.Duration
+
+	mu sync.RWMutex
+	// history of the vehicle, the oldest first.
+	history map[string][]sample
+	// latest are the buses of the latest poll.
+	latest []buses.Bus
+	polled time.Time
+	polls  int
+}
+
+func newTracker(size int, minMove float64, maxAge time.Duration) *tracker {
+	return &tracker{
+		size:    max(size, 2),
+		minMove: minMove,
+		maxAge:  maxAge,
+		history: map[string][]sample{},
+	}
+}
+
+// add the buses of the poll, buses missing from it and positions older than maxAge are forgotten.
+func (t *tracker) add(at time.Time, fetched []buses.Bus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	history := make(map[string][]sample, len(fetched))
+	latest := make([]buses.Bus, 0, len(fetched))
+	for _, bus := range fetched {
+		h := t.history[bus.ID]
+		for len(h) > 0 && at.Sub(h[0].At) > t.maxAge {
+			h = h[1:]
+		}
+		h = append(h, sample{Position: bus.Position, At: at})
+		if len(h) > t.size {
+			h = h[len(h)-t.size:]
+		}
+		history[bus.ID] = h
+		bus.Moving, bus.Heading, bus.Speed = t.motion(h)
+		latest = append(latest, bus)
+	}
+	t.history = history
+	t.latest = latest
+	t.polled = at
+	t.polls++
+}
+
+// motion is the heading from the latest position that is farther than minMove,
+// positions of a bus standing at a stop jitter.
+// Speed is the average over the history.
+func (t *tracker) motion(h []sample) (moving bool, heading, speed float64) {
+	last := h[len(h)-1]
+	for i := len(h) - 2; i >= 0; i-- {
+		if geo.Distance(h[i].Position, last.Position) >= t.minMove {
+			moving = true
+			heading = geo.Bearing(h[i].Position, last.Position)
+			break
+		}
+	}
+	if !moving {
+		return false, 0, 0
+	}
+	var dist float64
+	for i := 1; i < len(h); i++ {
+		dist += geo.Distance(h[i-1].Position, h[i].Position)
+	}
+	if elapsed := last.At.Sub(h[0].At).Seconds(); elapsed > 0 {
+		speed = dist / elapsed
+	}
+	return moving, heading, speed
+}
+
+// snapshot is the buses of the latest poll, the time of the poll and the number of polls.
+func (t *tracker) snapshot() ([]buses.Bus, time.Time, int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.latest, t.polled, t.polls
+}
+
+// angleDiff is the smallest angle between two bearings, from 0 to 180 degrees.
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
@@ -0,0 +1,120 @@
+package cyprusbus
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+)
+
+func TestTrackerMotion(t *testing.T) {
+	start := time.Date(2025, 2, 5, 8, 0, 0, 0, time.UTC)
+	tr := newTracker(4, 10, time.Hour)
+	positions := []geo.Point{
+		{Lat: 34.700, Lon: 33.000},
+		{Lat: 34.701, Lon: 33.000},     // ~111m north
+		{Lat: 34.702, Lon: 33.000},     // ~111m north
+		{Lat: 34.70201, Lon: 33.00001}, // jitter at a stop
+	}
+	for i, p := range positions {
+		tr.add(start.Add(time.Duration(i)*20*time.Second), []buses.Bus{{ID: "bus1", Position: p}})
+	}
+
+	latest, _, polls := tr.snapshot()
+	if polls != 4 || len(latest) != 1 {
+		t.Fatalf("Expected 1 bus after 4 polls, got %d after %d", len(latest), polls)
+	}
+	bus := latest[0]
+	if !bus.Moving || angleDiff(bus.Heading, 0) > 1 {
+		t.Errorf("Expected the bus heading north through the jitter, got moving=%v heading=%.1f", bus.Moving, bus.Heading)
+	}
+	// ~224m in a minute.
+	if math.Abs(bus.Speed-3.7) > 0.2 {
+		t.Errorf("Expected the speed of about 3.7m/s, got %.2f", bus.Speed)
+	}
+
+	tr.add(start.Add(80*time.Second), nil)
+	if latest, _, _ := tr.snapshot(); len(latest) != 0 {
+		t.Errorf("Expected buses missing from the poll to be forgotten, got %+v", latest)
+	}
+	tr.add(start.Add(100*time.Second), []buses.Bus{{ID: "bus1", Position: positions[3]}})
+	if latest, _, _ := tr.snapshot(); latest[0].Moving {
+		t.Errorf("Expected the heading of a reappeared bus to be unknown, got %+v", latest[0])
+	}
+}
+
+func TestGetNearest_Heading(t *testing.T) {
+	user := geo.Point{Lat: 34.707, Lon: 33.022}
+	// 500m south of the user, driving east: it comes closer but passes by.
+	passing := [][]buses.Bus{
+		{{ID: "bus1", Position: geo.Point{Lat: 34.7025, Lon: 33.020}}},
+		{{ID: "bus1", Position: geo.Point{Lat: 34.7025, Lon: 33.021}}},
+	}
+	client, advance := newPollingClient(NewMockFetcher(passing, make([]error, len(passing))))
+	if _, err := client.GetNearest(context.Background(), user); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	advance()
+	got, err := client.GetNearest(context.Background(), user)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Expected the passing bus to be skipped, got %+v", got)
+	}
+}
+
+func TestGetNearest_SharedPolls(t *testing.T) {
+	user := geo.Point{Lat: 34.707, Lon: 33.022}
+	approaching := [][]buses.Bus{
+		{{ID: "bus1", Position: geo.Point{Lat: user.Lat - 0.001, Lon: user.Lon - 0.001}}},
+		{{ID: "bus1", Position: geo.Point{Lat: user.Lat - 0.0005, Lon: user.Lon - 0.0005}}},
+	}
+	fetcher := NewMockFetcher(approaching, make([]error, len(approaching)))
+	client, advance := newPollingClient(fetcher)
+
+	getNearest := func(want int) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := client.GetNearest(context.Background(), user)
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+					return
+				}
+				if len(got) != want {
+					t.Errorf("Expected %d buses, got %d", want, len(got))
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	// the heading is unknown after the first poll.
+	getNearest(0)
+	getNearest(0)
+	if fetcher.callCount != 1 {
+		t.Errorf("Expected users to share the poll, got %d", fetcher.callCount)
+	}
+	advance()
+	getNearest(1)
+	if fetcher.callCount != 2 {
+		t.Errorf("Expected users to share 2 polls, got %d", fetcher.callCount)
+	}
+}
+
+func TestTrackerMaxAge(t *testing.T) {
+	start := time.Date(2025, 2, 5, 8, 0, 0, 0, time.UTC)
+	tr := newTracker(4, 10, time.Minute)
+	tr.add(start, []buses.Bus{{ID: "bus1", Position: geo.Point{Lat: 34.700, Lon: 33.000}}})
+	tr.add(start.Add(time.Hour), []buses.Bus{{ID: "bus1", Position: geo.Point{Lat: 34.701, Lon: 33.000}}})
+	if latest, _, _ := tr.snapshot(); latest[0].Moving {
+		t.Errorf("Expected the heading from an old position to be unknown, got %+v", latest[0])
+	}
+}
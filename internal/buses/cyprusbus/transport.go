@@ -3,114 +3,149 @@ package cyprusbus
 import (
 	"context"
 	"fmt"
-	"math"
 	"sort"
 	"time"
 
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
 	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
+	"github.com/yanakipre/bot/internal/logger"
 )
 
+var _ buses.Client = (*Client)(nil)
+
+// historyPolls is how many Timer periods positions are kept for,
+// older ones would give headings of a different part of the route.
+const historyPolls = 5
+
 type Client struct {
-	fetcher   buses.BusFetcher
-	sleepFunc func(time.Duration)
-	timer     time.Duration
-	boxSize   float64
+	fetcher  buses.BusFetcher
+	realtime realtimeFetcher
+	// static is nil when the timetable is not refreshed, e.g. in tests.
+	static        *staticFeed
+	staticRefresh time.Duration
+	now           func() time.Time
+	timer         time.Duration
+	boxSize       float64
+	horizon       time.Duration
+	tolerance     float64
+	tracker       *tracker
+	// polls share one upstream request between the users waiting for fresh positions.
+	polls singleflight.Group
 }
 
 func NewClient(cfg Config) *Client {
+	registerMetrics()
+	fetcher := newProtobufFetcher(cfg)
 	return &Client{
-		fetcher:   newProtobufFetcher(cfg),
-		sleepFunc: time.Sleep,
-		timer:     cfg.Timer.Duration,
-		boxSize:   cfg.BoxSizeMeters,
+		fetcher:       fetcher,
+		realtime:      fetcher,
+		static:        fetcher.static,
+		staticRefresh: cfg.StaticRefresh.Duration,
+		now:           time.Now,
+		timer:         cfg.Timer.Duration,
+		boxSize:       cfg.BoxSizeMeters,
+		horizon:       cfg.ArrivalsHorizon.Duration,
+		tolerance:     cfg.HeadingToleranceDegrees,
+		tracker:       newTracker(cfg.HistorySize, cfg.MinMoveMeters, historyPolls*cfg.Timer.Duration),
 	}
 }
 
-func (c *Client) GetNearest(ctx context.Context, dot buses.Dot) ([]buses.Bus, error) {
-	// Fetch Buses
-	fetchedBuses, err := c.fetcher.FetchBuses(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("initial fetch failed: %w", err)
+// Run polls positions of the buses every Timer until ctx is done, so GetNearest answers at once.
+// The timetable is refreshed every StaticRefresh meanwhile.
+func (c *Client) Run(ctx context.Context) {
+	if c.static != nil && c.staticRefresh > 0 {
+		go c.static.Run(ctx, c.staticRefresh)
 	}
-
-	// Calculate 1km bounding box
-	latDelta := c.boxSize / 111000.0
-	longDelta := c.boxSize / (111000.0 * math.Cos(dot.Lat*math.Pi/180))
-
-	latMin := dot.Lat - latDelta
-	latMax := dot.Lat + latDelta
-	longMin := dot.Long - longDelta
-	longMax := dot.Long + longDelta
-
-	// Filter fetched list if in bounds
-	var initialBuses []buses.Bus
-	for _, bus := range fetchedBuses {
-		if bus.Position.Lat >= latMin && bus.Position.Lat <= latMax &&
-			bus.Position.Long >= longMin && bus.Position.Long <= longMax {
-			initialBuses = append(initialBuses, bus)
+	ticker := time.NewTicker(c.timer)
+	defer ticker.Stop()
+	for {
+		if err := c.poll(ctx); err != nil {
+			logger.Warn(ctx, "bus positions poll failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
 	}
+}
 
-	// Wait c.time seconds
-	c.sleepFunc(c.timer)
-
-	// Second fetch
-	secondFetchedBuses, err := c.fetcher.FetchBuses(ctx)
+func (c *Client) poll(ctx context.Context) error {
+	fetched, err := c.fetcher.FetchBuses(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("second fetch failed: %w", err)
+		return err
 	}
+	c.tracker.add(c.now(), fetched)
+	return nil
+}
 
-	// Filter fetched list if in bounds
-	var currentBuses []buses.Bus
-	for _, bus := range secondFetchedBuses {
-		if bus.Position.Lat >= latMin && bus.Position.Lat <= latMax &&
-			bus.Position.Long >= longMin && bus.Position.Long <= longMax {
-			currentBuses = append(currentBuses, bus)
+// snapshot is the latest positions of the buses with their headings.
+// Without Run positions are polled on demand: buses have no headings until the second poll,
+// which happens on a request after Timer, and are not moving meanwhile.
+func (c *Client) snapshot(ctx context.Context) ([]buses.Bus, error) {
+	if latest, polled, polls := c.tracker.snapshot(); !c.stale(polled, polls) {
+		return latest, nil
+	}
+	_, err, _ := c.polls.Do("poll", func() (any, error) {
+		if _, polled, polls := c.tracker.snapshot(); !c.stale(polled, polls) {
+			return nil, nil
 		}
+		if err := c.poll(ctx); err != nil {
+			return nil, fmt.Errorf("fetch failed: %w", err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	latest, _, _ := c.tracker.snapshot()
+	return latest, nil
+}
 
-	// Create current bus map
-	initialMap := make(map[string]buses.Bus)
-	for _, bus := range initialBuses {
-		initialMap[bus.ID] = bus
+// stale tells whether positions are polled on demand: Run polls every Timer,
+// the second poll is made after Timer to know the headings.
+func (c *Client) stale(polled time.Time, polls int) bool {
+	switch polls {
+	case 0:
+		return true
+	case 1:
+		return c.now().Sub(polled) >= c.timer
+	default:
+		return c.now().Sub(polled) >= 2*c.timer
 	}
+}
 
-	// Filter approaching buses
+// GetNearest returns buses in the box around the dot heading to it, the nearest first.
+func (c *Client) GetNearest(ctx context.Context, dot geo.Point) ([]buses.Bus, error) {
+	latest, err := c.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	box := geo.BoxAround(dot, c.boxSize)
+
+	// Filter approaching buses in bounds
 	var filteredBuses []buses.Bus
-	for _, currentBus := range currentBuses {
-		initialBus, exists := initialMap[currentBus.ID]
-		if !exists {
-			//filteredBuses = append(filteredBuses, currentBus) // This line may cause adding filteredBuses with a departing Bus, if GPS data is flowed in a margin larger than a tolerance treshold
+	for _, bus := range latest {
+		if !box.Contains(bus.Position) {
 			continue
 		}
-
-		// Compare distances
-		initialDist := CalculateDistance(dot, initialBus.Position)
-		currentDist := CalculateDistance(dot, currentBus.Position)
-		if currentDist < initialDist {
-			filteredBuses = append(filteredBuses, currentBus)
+		// standing buses may go anywhere.
+		if !bus.Moving {
+			continue
+		}
+		if angleDiff(bus.Heading, geo.Bearing(bus.Position, dot)) <= c.tolerance {
+			filteredBuses = append(filteredBuses, bus)
 		}
 	}
 
 	// Sort by current distance
 	sort.Slice(filteredBuses, func(i, j int) bool {
-		return CalculateDistance(dot, filteredBuses[i].Position) < CalculateDistance(dot, filteredBuses[j].Position)
+		return geo.Distance(dot, filteredBuses[i].Position) < geo.Distance(dot, filteredBuses[j].Position)
 	})
 
 	return filteredBuses, nil
 }
-
-func CalculateDistance(a, b buses.Dot) float64 {
-	const earthRadius = 6378137
-	phi1 := a.Lat * math.Pi / 180
-	phi2 := b.Lat * math.Pi / 180
-	deltaPhi := (b.Lat - a.Lat) * math.Pi / 180
-	deltaLambda := (b.Long - a.Long) * math.Pi / 180
-
-	sinTerm := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
-		math.Cos(phi1)*math.Cos(phi2)*
-			math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
-	c := 2 * math.Atan2(math.Sqrt(sinTerm), math.Sqrt(1-sinTerm))
-
-	return earthRadius * c
-}
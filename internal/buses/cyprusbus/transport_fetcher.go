@@ -1,34 +1,38 @@
 package cyprusbus
 
 import (
-	"bytes"
 	"context"
 	"embed"
-	"encoding/csv"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
 	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
 	"github.com/yanakipre/bot/internal/resttooling"
 	"google.golang.org/protobuf/proto"
 )
 
-//go:embed data/routes.txt
+// routeFS holds routes.txt of the GTFS static feed of Cyprus, stops and trips are downloaded, see Config.StaticURL.
+//
+//go:embed data/*.txt
 var routeFS embed.FS
 
 type protobufFetcher struct {
 	baseURL    string
 	httpClient *http.Client
-	routeCache *routeCache
+	static     *staticFeed
 }
 
 func newProtobufFetcher(cfg Config) *protobufFetcher {
+	httpClient := resttooling.NewHTTPClientFromConfig(cfg.HTTPTransport)
 	return &protobufFetcher{
 		baseURL:    cfg.BaseURL,
-		httpClient: resttooling.NewHTTPClientFromConfig(cfg.HTTPTransport),
+		httpClient: httpClient,
+		static:     newStaticFeed(cfg, httpClient),
 	}
 }
 
@@ -41,130 +45,162 @@ func (rc *routeCache) getRoutes(routeID string) (buses.Route, bool) {
 	return route, ok
 }
 
-// Ready checks if cache is properly initialized and initializes it if not
+// Ready loads the static feed unless it is loaded already.
 func (pf *protobufFetcher) Ready(ctx context.Context) error {
-	if pf.routeCache == nil {
-		cache, err := newRouteCache()
-		if err != nil {
-			return fmt.Errorf("failed to initialize route cache %w", err)
-		}
-		pf.routeCache = cache
-	}
-	return nil
+	return pf.static.Ready(ctx)
 }
 
-func newRouteCache() (*routeCache, error) {
-	rc := &routeCache{
-		routes: make(map[string]buses.Route),
-	}
-
-	routesFile, err := routeFS.Open("data/routes.txt")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open routes.txt: %w", err)
-	}
-	defer routesFile.Close()
+// Schedule is nil until the fetcher is ready.
+func (pf *protobufFetcher) Schedule() *schedule {
+	return pf.static.Schedule()
+}
 
-	// Read the file content first to handle BOM (<feff>)
-	data, err := io.ReadAll(routesFile)
+func newRouteCache() (*routeCache, error) {
+	data, err := fs.Sub(routeFS, "data")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read routes.txt: %w", err)
+		return nil, err
 	}
-	// Remove BOM if present
-	data = bytes.TrimLeft(data, "\xef\xbb\xbf")
-
-	reader := csv.NewReader(bytes.NewReader(data))
-
-	// Read Header
-	header, err := reader.Read()
+	routes, err := readRoutes(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read routes.txt header: %w", err)
-	}
-
-	// Create index map for required fields
-	fieldIndex := make(map[string]int)
-	requiredFields := []string{"route_id", "route_short_name", "route_long_name"}
-
-	for i, field := range header {
-		fieldIndex[strings.TrimSpace(field)] = i
-	}
-
-	for _, field := range requiredFields {
-		if _, ok := fieldIndex[field]; !ok {
-			return nil, fmt.Errorf("required field is not present in routes.txt")
-		}
+		return nil, err
 	}
+	return &routeCache{routes: routes}, nil
+}
 
-	// Read and parse routes
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to read route record %w", err)
-		}
+// realtimeFeed is the GTFS-realtime feed.
+type realtimeFeed struct {
+	Buses []buses.Bus
+	// TripUpdates by trip ID.
+	TripUpdates map[string]tripUpdate
+}
 
-		route := buses.Route{
-			ID:        record[fieldIndex["route_id"]],
-			ShortName: record[fieldIndex["route_short_name"]],
-			LongName:  record[fieldIndex["route_long_name"]],
-		}
-		rc.routes[route.ID] = route
-	}
+type tripUpdate struct {
+	TripID    string
+	RouteID   string
+	VehicleID string
+	// StartDate of the trip, it is empty when not reported.
+	StartDate string
+	// StopTimeUpdates are in the order of the trip.
+	StopTimeUpdates []stopTimeUpdate
+}
 
-	return rc, nil
+type stopTimeUpdate struct {
+	StopID string
+	// Sequence is -1 when not reported.
+	Sequence int
+	Skipped  bool
+	// Arrival is the predicted arrival, or departure when the arrival is not reported.
+	// It is zero when only the delay is known.
+	Arrival  time.Time
+	Delay    time.Duration
+	HasDelay bool
 }
 
 func (f *protobufFetcher) FetchBuses(ctx context.Context) ([]buses.Bus, error) {
+	feed, err := f.FetchRealtime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return feed.Buses, nil
+}
 
+func (f *protobufFetcher) FetchRealtime(ctx context.Context) (realtimeFeed, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", f.baseURL+"/api/gtfs-realtime", nil)
 	if err != nil {
-		return nil, err
+		return realtimeFeed{}, err
 	}
 
 	resp, err := f.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return realtimeFeed{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return realtimeFeed{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return realtimeFeed{}, err
 	}
 
 	feed := &gtfs.FeedMessage{}
 	if err := proto.Unmarshal(data, feed); err != nil {
-		return nil, err
+		return realtimeFeed{}, err
 	}
+	return f.parseFeed(feed), nil
+}
 
-	var fetchedBuses []buses.Bus
+func (f *protobufFetcher) parseFeed(feed *gtfs.FeedMessage) realtimeFeed {
+	r := realtimeFeed{TripUpdates: map[string]tripUpdate{}}
 	for _, entity := range feed.Entity {
 		if bp := entity.GetVehicle(); bp != nil {
 			pos := bp.GetPosition()
 			trip := bp.GetTrip()
 			if pos != nil && trip != nil {
 				routeID := trip.GetRouteId()
-				route, ok := f.routeCache.getRoutes(routeID)
+				route, ok := f.route(routeID)
 				if !ok {
 					// If route is not found, route is routeID
 					route = buses.Route{ID: routeID}
 				}
 
-				fetchedBuses = append(fetchedBuses, buses.Bus{
+				r.Buses = append(r.Buses, buses.Bus{
 					ID:    bp.GetVehicle().GetId(),
 					Route: route,
-					Position: buses.Dot{
-						Lat:  float64(pos.GetLatitude()),
-						Long: float64(pos.GetLongitude()),
+					Position: geo.Point{
+						Lat: float64(pos.GetLatitude()),
+						Lon: float64(pos.GetLongitude()),
 					},
+					TripID: trip.GetTripId(),
 				})
 			}
 		}
+		if tu := entity.GetTripUpdate(); tu != nil && tu.GetTrip().GetTripId() != "" {
+			if tu.GetTrip().GetScheduleRelationship() == gtfs.TripDescriptor_CANCELED {
+				continue
+			}
+			update := tripUpdate{
+				TripID:    tu.GetTrip().GetTripId(),
+				RouteID:   tu.GetTrip().GetRouteId(),
+				VehicleID: tu.GetVehicle().GetId(),
+				StartDate: tu.GetTrip().GetStartDate(),
+			}
+			for _, stu := range tu.GetStopTimeUpdate() {
+				u := stopTimeUpdate{
+					StopID:   stu.GetStopId(),
+					Sequence: -1,
+					Skipped:  stu.GetScheduleRelationship() == gtfs.TripUpdate_StopTimeUpdate_SKIPPED,
+				}
+				if stu.StopSequence != nil {
+					u.Sequence = int(stu.GetStopSequence())
+				}
+				for _, event := range []*gtfs.TripUpdate_StopTimeEvent{stu.GetArrival(), stu.GetDeparture()} {
+					if event == nil {
+						continue
+					}
+					if event.Time != nil && u.Arrival.IsZero() {
+						u.Arrival = time.Unix(event.GetTime(), 0)
+					}
+					if event.Delay != nil && !u.HasDelay {
+						u.Delay = time.Duration(event.GetDelay()) * time.Second
+						u.HasDelay = true
+					}
+				}
+				update.StopTimeUpdates = append(update.StopTimeUpdates, u)
+			}
+			r.TripUpdates[update.TripID] = update
+		}
 	}
-	return fetchedBuses, nil
+	return r
+}
+
+func (f *protobufFetcher) route(routeID string) (buses.Route, bool) {
+	s := f.Schedule()
+	if s == nil {
+		return buses.Route{}, false
+	}
+	route, ok := s.routes[routeID]
+	return route, ok
 }
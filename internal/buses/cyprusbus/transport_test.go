@@ -3,11 +3,11 @@ package cyprusbus
 import (
 	"context"
 	"fmt"
-	"math"
 	"testing"
 	"time"
 
 	"github.com/yanakipre/bot/internal/buses"
+	"github.com/yanakipre/bot/internal/geo"
 )
 
 type MockFetcher struct {
@@ -41,8 +41,18 @@ func (pf *MockFetcher) Ready(ctx context.Context) error {
 	return nil
 }
 
+// newPollingClient polls the fetcher on demand, advance moves its clock by Timer so the next request polls again.
+func newPollingClient(fetcher buses.BusFetcher) (client *Client, advance func()) {
+	cfg := DefaultConfig()
+	now := time.Date(2025, 2, 5, 8, 0, 0, 0, time.UTC)
+	client = NewClient(cfg)
+	client.fetcher = fetcher
+	client.now = func() time.Time { return now }
+	return client, func() { now = now.Add(cfg.Timer.Duration) }
+}
+
 func TestGetNearest_MovementScenarios(t *testing.T) {
-	testDot := buses.Dot{Lat: 34.707, Long: 33.022}
+	testDot := geo.Point{Lat: 34.707, Lon: 33.022}
 
 	tests := []struct {
 		name          string
@@ -56,9 +66,9 @@ func TestGetNearest_MovementScenarios(t *testing.T) {
 					{
 						ID:    "bus1",
 						Route: buses.Route{ID: "100"},
-						Position: buses.Dot{
-							Lat:  testDot.Lat - 0.001,
-							Long: testDot.Long - 0.001,
+						Position: geo.Point{
+							Lat: testDot.Lat - 0.001,
+							Lon: testDot.Lon - 0.001,
 						},
 					},
 				},
@@ -66,9 +76,9 @@ func TestGetNearest_MovementScenarios(t *testing.T) {
 					{
 						ID:    "bus1",
 						Route: buses.Route{ID: "100"},
-						Position: buses.Dot{
-							Lat:  testDot.Lat - 0.0005, // Moving closer
-							Long: testDot.Long - 0.0005,
+						Position: geo.Point{
+							Lat: testDot.Lat - 0.0005, // Moving closer
+							Lon: testDot.Lon - 0.0005,
 						},
 					},
 				},
@@ -82,9 +92,9 @@ func TestGetNearest_MovementScenarios(t *testing.T) {
 					{
 						ID:    "bus2",
 						Route: buses.Route{ID: "200"},
-						Position: buses.Dot{
-							Lat:  testDot.Lat - 0.0005,
-							Long: testDot.Long - 0.0005,
+						Position: geo.Point{
+							Lat: testDot.Lat - 0.0005,
+							Lon: testDot.Lon - 0.0005,
 						},
 					},
 				},
@@ -92,9 +102,9 @@ func TestGetNearest_MovementScenarios(t *testing.T) {
 					{
 						ID:    "bus2",
 						Route: buses.Route{ID: "200"},
-						Position: buses.Dot{
-							Lat:  testDot.Lat - 0.001, // Moving away
-							Long: testDot.Long - 0.001,
+						Position: geo.Point{
+							Lat: testDot.Lat - 0.001, // Moving away
+							Lon: testDot.Lon - 0.001,
 						},
 					},
 				},
@@ -108,9 +118,9 @@ func TestGetNearest_MovementScenarios(t *testing.T) {
 					{
 						ID:    "bus3",
 						Route: buses.Route{ID: "300"},
-						Position: buses.Dot{
-							Lat:  testDot.Lat - 0.001,
-							Long: testDot.Long - 0.001,
+						Position: geo.Point{
+							Lat: testDot.Lat - 0.001,
+							Lon: testDot.Lon - 0.001,
 						},
 					},
 				},
@@ -118,9 +128,9 @@ func TestGetNearest_MovementScenarios(t *testing.T) {
 					{
 						ID:    "bus3",
 						Route: buses.Route{ID: "300"},
-						Position: buses.Dot{
-							Lat:  testDot.Lat - 0.001, // Stationary
-							Long: testDot.Long - 0.001,
+						Position: geo.Point{
+							Lat: testDot.Lat - 0.001, // Stationary
+							Lon: testDot.Lon - 0.001,
 						},
 					},
 				},
@@ -136,70 +146,24 @@ func TestGetNearest_MovementScenarios(t *testing.T) {
 				make([]error, len(tt.mockResponses)),
 			)
 
-			cfg := DefaultConfig()
-
-			client := NewClient(cfg)
-			client.fetcher = mockFetcher
-			client.sleepFunc = func(time.Duration) {}
+			client, advance := newPollingClient(mockFetcher)
 
 			result, err := client.GetNearest(context.Background(), testDot)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
-
-			if len(result) != tt.wantCount {
-				t.Errorf("Expected %d buses, got %d", tt.wantCount, len(result))
+			if len(result) != 0 {
+				t.Errorf("Expected no buses before their headings are known, got %d", len(result))
 			}
-		})
-	}
-}
 
-func TestCalculateDistance(t *testing.T) {
-	tests := []struct {
-		name      string
-		a         buses.Dot
-		b         buses.Dot
-		expected  float64
-		tolerance float64
-	}{
-		{
-			name:      "Same point",
-			a:         buses.Dot{Lat: 34.707, Long: 33.022},
-			b:         buses.Dot{Lat: 34.707, Long: 33.022},
-			expected:  0,
-			tolerance: 0.1,
-		},
-		{
-			name:      "Short distance (143 meters)",
-			a:         buses.Dot{Lat: 34.707, Long: 33.022},
-			b:         buses.Dot{Lat: 34.708, Long: 33.023},
-			expected:  144,
-			tolerance: 1,
-		},
-		{
-			name:      "Medium distance (1 km)",
-			a:         buses.Dot{Lat: 34.707, Long: 33.022},
-			b:         buses.Dot{Lat: 34.707 + 0.00899322, Long: 33.022},
-			expected:  1001,
-			tolerance: 1,
-		},
-		{
-			name:      "Long distance (10 km)",
-			a:         buses.Dot{Lat: 34.707, Long: 33.022},
-			b:         buses.Dot{Lat: 34.707 + 0.0899322, Long: 33.022},
-			expected:  10011,
-			tolerance: 10,
-		},
-	}
+			advance()
+			result, err = client.GetNearest(context.Background(), testDot)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := CalculateDistance(tt.a, tt.b)
-			if math.Abs(got-tt.expected) > tt.tolerance {
-				t.Errorf(
-					"calculateDistance(%v, %v) = %.2f meters, expected %.2f meters ±%.2f meters",
-					tt.a, tt.b, got, tt.expected, tt.tolerance,
-				)
+			if len(result) != tt.wantCount {
+				t.Errorf("Expected %d buses, got %d", tt.wantCount, len(result))
 			}
 		})
 	}
@@ -1,22 +1,30 @@
 package buses
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/yanakipre/bot/internal/geo"
+)
 
 type BusFetcher interface {
 	FetchBuses(ctx context.Context) ([]Bus, error)
 	Ready(ctx context.Context) error
 }
 
-type Dot struct {
-	Lat  float64
-	Long float64
-}
-
 type Bus struct {
 	// ID - vehicle identification #
 	ID       string
 	Route    Route
-	Position Dot
+	Position geo.Point
+	// TripID - the trip the vehicle serves, empty when it is not reported.
+	TripID string
+	// Moving is set when Heading and Speed are known from recent positions.
+	Moving bool
+	// Heading - degrees clockwise from north.
+	Heading float64
+	// Speed - meters per second.
+	Speed float64
 }
 
 type Route struct {
@@ -24,4 +32,38 @@ type Route struct {
 	ID        string
 	ShortName string
 	LongName  string
+	// Operator is the feed of the route, empty when the client serves one feed.
+	Operator string
+}
+
+type Stop struct {
+	ID       string
+	Name     string
+	Position geo.Point
+	// Operator is the feed of the stop, empty when the client serves one feed.
+	Operator string
+}
+
+// Estimate tells how the arrival time is known.
+type Estimate string
+
+const (
+	// EstimateRealtime is predicted by the operator.
+	EstimateRealtime Estimate = "realtime"
+	// EstimateProgress is the timetable applied to the progress of the vehicle along the trip.
+	EstimateProgress Estimate = "progress"
+	// EstimateScheduled is the timetable, the vehicle is not tracked.
+	EstimateScheduled Estimate = "scheduled"
+)
+
+type Arrival struct {
+	Stop  Stop
+	Route Route
+	// TripID - the trip of the timetable.
+	TripID   string
+	Headsign string
+	// BusID - vehicle identification #, empty when the vehicle is not known.
+	BusID    string
+	At       time.Time
+	Estimate Estimate
 }
@@ -0,0 +1,52 @@
+package geo
+
+import "math"
+
+// Box is the area between the corners, it does not cross the antimeridian.
+type Box struct {
+	Min, Max Point
+}
+
+// BoxAround is the box extending meters from p to every side.
+func BoxAround(p Point, meters float64) Box {
+	latDelta := degrees(meters / EarthRadius)
+	lonDelta := latDelta / math.Cos(radians(p.Lat))
+	return Box{
+		Min: Point{Lat: p.Lat - latDelta, Lon: p.Lon - lonDelta},
+		Max: Point{Lat: p.Lat + latDelta, Lon: p.Lon + lonDelta},
+	}
+}
+
+func (b Box) Contains(p Point) bool {
+	return p.Lat >= b.Min.Lat && p.Lat <= b.Max.Lat && p.Lon >= b.Min.Lon && p.Lon <= b.Max.Lon
+}
+
+// Polygon is an area bounded by the points, the last one connects to the first.
+type Polygon []Point
+
+// Contains tells whether p is inside the polygon, by counting crossings of its edges by a ray to the east.
+// Coordinates are treated as planar, which is fine for areas of a city or a country.
+func (pg Polygon) Contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(pg)-1; i < len(pg); j, i = i, i+1 {
+		a, b := pg[i], pg[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < a.Lon+(p.Lat-a.Lat)*(b.Lon-a.Lon)/(b.Lat-a.Lat) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Bounds is the smallest box containing the polygon.
+func (pg Polygon) Bounds() Box {
+	if len(pg) == 0 {
+		return Box{}
+	}
+	b := Box{Min: pg[0], Max: pg[0]}
+	for _, p := range pg[1:] {
+		b.Min.Lat, b.Max.Lat = min(b.Min.Lat, p.Lat), max(b.Max.Lat, p.Lat)
+		b.Min.Lon, b.Max.Lon = min(b.Min.Lon, p.Lon), max(b.Max.Lon, p.Lon)
+	}
+	return b
+}
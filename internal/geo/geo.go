@@ -0,0 +1,53 @@
+// Package geo is points on the Earth surface: distances, bearings, areas,
+// the nearest points by a spatial index and GeoJSON encoding.
+//
+// The Earth is a sphere here, good to a fraction of a percent for city and country scale lookups.
+package geo
+
+import "math"
+
+// EarthRadius is the equatorial radius of WGS 84 in meters.
+const EarthRadius = 6378137
+
+// Point is a position in degrees of WGS 84.
+type Point struct {
+	Lat float64 `yaml:"lat"`
+	Lon float64 `yaml:"lon"`
+}
+
+// Valid tells whether the point is within the ranges of latitude and longitude.
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// Distance between a and b in meters, by the haversine formula.
+func Distance(a, b Point) float64 {
+	phi1 := radians(a.Lat)
+	phi2 := radians(b.Lat)
+	deltaPhi := radians(b.Lat - a.Lat)
+	deltaLambda := radians(b.Lon - a.Lon)
+
+	sinTerm := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*
+			math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	return EarthRadius * 2 * math.Atan2(math.Sqrt(sinTerm), math.Sqrt(1-sinTerm))
+}
+
+// Bearing is the initial direction from a to b in degrees clockwise from north.
+func Bearing(a, b Point) float64 {
+	phi1 := radians(a.Lat)
+	phi2 := radians(b.Lat)
+	deltaLambda := radians(b.Lon - a.Lon)
+
+	y := math.Sin(deltaLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(deltaLambda)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
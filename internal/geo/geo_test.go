@@ -0,0 +1,123 @@
+package geo
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name      string
+		a         Point
+		b         Point
+		expected  float64
+		tolerance float64
+	}{
+		{
+			name:      "Same point",
+			a:         Point{Lat: 34.707, Lon: 33.022},
+			b:         Point{Lat: 34.707, Lon: 33.022},
+			expected:  0,
+			tolerance: 0.1,
+		},
+		{
+			name:      "Short distance (143 meters)",
+			a:         Point{Lat: 34.707, Lon: 33.022},
+			b:         Point{Lat: 34.708, Lon: 33.023},
+			expected:  144,
+			tolerance: 1,
+		},
+		{
+			name:      "Medium distance (1 km)",
+			a:         Point{Lat: 34.707, Lon: 33.022},
+			b:         Point{Lat: 34.707 + 0.00899322, Lon: 33.022},
+			expected:  1001,
+			tolerance: 1,
+		},
+		{
+			name:      "Long distance (10 km)",
+			a:         Point{Lat: 34.707, Lon: 33.022},
+			b:         Point{Lat: 34.707 + 0.0899322, Lon: 33.022},
+			expected:  10011,
+			tolerance: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.a, tt.b)
+			if math.Abs(got-tt.expected) > tt.tolerance {
+				t.Errorf(
+					"Distance(%v, %v) = %.2f meters, expected %.2f meters ±%.2f meters",
+					tt.a, tt.b, got, tt.expected, tt.tolerance,
+				)
+			}
+		})
+	}
+}
+
+func TestBearing(t *testing.T) {
+	a := Point{Lat: 34.707, Lon: 33.022}
+	tests := []struct {
+		name string
+		b    Point
+		want float64
+	}{
+		{name: "north", b: Point{Lat: 34.717, Lon: 33.022}, want: 0},
+		{name: "east", b: Point{Lat: 34.707, Lon: 33.032}, want: 90},
+		{name: "south", b: Point{Lat: 34.697, Lon: 33.022}, want: 180},
+		{name: "west", b: Point{Lat: 34.707, Lon: 33.012}, want: 270},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Bearing(a, tt.b); math.Abs(math.Remainder(got-tt.want, 360)) > 0.1 {
+				t.Errorf("Bearing = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoxAround(t *testing.T) {
+	center := Point{Lat: 34.707, Lon: 33.022}
+	box := BoxAround(center, 1000)
+	require.True(t, box.Contains(center))
+	// the box reaches 1km to the sides, the corners are farther.
+	require.InDelta(t, 1000, Distance(center, Point{Lat: box.Max.Lat, Lon: center.Lon}), 1)
+	require.InDelta(t, 1000, Distance(center, Point{Lat: center.Lat, Lon: box.Min.Lon}), 1)
+	require.True(t, box.Contains(Point{Lat: 34.711, Lon: 33.027}))
+	require.False(t, box.Contains(Point{Lat: 34.717, Lon: 33.022}))
+}
+
+func TestPolygon_Contains(t *testing.T) {
+	cyprus := Polygon{{Lat: 34.4, Lon: 32.2}, {Lat: 35.8, Lon: 32.2}, {Lat: 35.8, Lon: 34.7}, {Lat: 34.4, Lon: 34.7}}
+	require.True(t, cyprus.Contains(Point{Lat: 34.684422, Lon: 33.037147}))
+	require.False(t, cyprus.Contains(Point{Lat: 37.9838, Lon: 23.7275}))
+	require.Equal(t, Box{Min: Point{Lat: 34.4, Lon: 32.2}, Max: Point{Lat: 35.8, Lon: 34.7}}, cyprus.Bounds())
+	// a concave polygon, the notch is outside.
+	notched := Polygon{{Lat: 0, Lon: 0}, {Lat: 10, Lon: 0}, {Lat: 10, Lon: 10}, {Lat: 5, Lon: 5}, {Lat: 0, Lon: 10}}
+	require.True(t, notched.Contains(Point{Lat: 5, Lon: 2}))
+	require.False(t, notched.Contains(Point{Lat: 5, Lon: 8}))
+	require.False(t, Polygon{}.Contains(Point{}))
+}
+
+func TestGeoJSON(t *testing.T) {
+	stop := NewFeature("A", Point{Lat: 34.707, Lon: 33.022}.Geometry(), map[string]any{"name": "Limassol"})
+	// clockwise, the ring is reversed and closed.
+	area := NewFeature("", Polygon{{Lat: 0, Lon: 0}, {Lat: 1, Lon: 0}, {Lat: 1, Lon: 1}}.Geometry(), nil)
+	data, err := json.Marshal(NewFeatureCollection(stop, area))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "id": "A", "geometry": {"type": "Point", "coordinates": [33.022, 34.707]}, "properties": {"name": "Limassol"}},
+			{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[1, 1], [0, 1], [0, 0], [1, 1]]]}, "properties": null}
+		]
+	}`, string(data))
+
+	data, err = json.Marshal(NewFeatureCollection())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, string(data))
+}
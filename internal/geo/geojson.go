@@ -0,0 +1,67 @@
+package geo
+
+// GeoJSON types, see RFC 7946. They are encoded with encoding/json.
+
+// Geometry is a GeoJSON geometry, positions in Coordinates are longitude first.
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Feature is a geometry with properties, e.g. a stop with its name.
+type Feature struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id,omitempty"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func NewFeature(id string, geometry Geometry, properties map[string]any) Feature {
+	return Feature{Type: "Feature", ID: id, Geometry: geometry, Properties: properties}
+}
+
+func NewFeatureCollection(features ...Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+func (p Point) Geometry() Geometry {
+	return Geometry{Type: "Point", Coordinates: p.position()}
+}
+
+// Geometry of the polygon is a closed counterclockwise ring, as RFC 7946 requires of exterior rings.
+func (pg Polygon) Geometry() Geometry {
+	ring := make([][]float64, 0, len(pg)+1)
+	for _, p := range pg {
+		ring = append(ring, p.position())
+	}
+	if pg.clockwise() {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	if len(pg) > 0 && pg[0] != pg[len(pg)-1] {
+		ring = append(ring, ring[0])
+	}
+	return Geometry{Type: "Polygon", Coordinates: [][][]float64{ring}}
+}
+
+func (p Point) position() []float64 {
+	return []float64{p.Lon, p.Lat}
+}
+
+// clockwise by the sign of the shoelace area, longitude is x.
+func (pg Polygon) clockwise() bool {
+	var area float64
+	for i, j := 0, len(pg)-1; i < len(pg); j, i = i, i+1 {
+		area += (pg[i].Lon - pg[j].Lon) * (pg[i].Lat + pg[j].Lat)
+	}
+	return area > 0
+}
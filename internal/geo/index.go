@@ -0,0 +1,128 @@
+package geo
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Index finds the items nearest to a point, it is immutable and safe for concurrent use.
+//
+// It is a k-d tree of the points as vectors from the center of a unit sphere:
+// the straight distance between them grows with the distance on the surface,
+// so the nearest are exact anywhere on the Earth, including the poles and the antimeridian.
+type Index[T any] struct {
+	// nodes are in the tree order: the median of a range by its axis is in the middle of the range.
+	nodes []indexNode[T]
+}
+
+type indexNode[T any] struct {
+	v    vector
+	item T
+}
+
+type vector [3]float64
+
+func NewIndex[T any](items []T, position func(T) Point) *Index[T] {
+	nodes := make([]indexNode[T], 0, len(items))
+	for _, item := range items {
+		nodes = append(nodes, indexNode[T]{v: toVector(position(item)), item: item})
+	}
+	buildTree(nodes, 0)
+	return &Index[T]{nodes: nodes}
+}
+
+func (ix *Index[T]) Len() int {
+	return len(ix.nodes)
+}
+
+// Nearest returns up to k items nearest to p, the nearest first.
+func (ix *Index[T]) Nearest(p Point, k int) []T {
+	if k <= 0 || len(ix.nodes) == 0 {
+		return nil
+	}
+	s := nearestSearch[T]{target: toVector(p), k: k, found: make([]neighbour[T], 0, min(k, len(ix.nodes)))}
+	s.visit(ix.nodes, 0)
+	r := make([]T, 0, len(s.found))
+	for _, n := range s.found {
+		r = append(r, n.item)
+	}
+	return r
+}
+
+func buildTree[T any](nodes []indexNode[T], depth int) {
+	if len(nodes) <= 1 {
+		return
+	}
+	axis := depth % len(vector{})
+	slices.SortFunc(nodes, func(a, b indexNode[T]) int {
+		return cmp.Compare(a.v[axis], b.v[axis])
+	})
+	m := len(nodes) / 2
+	buildTree(nodes[:m], depth+1)
+	buildTree(nodes[m+1:], depth+1)
+}
+
+type neighbour[T any] struct {
+	// d is the squared straight distance to the target.
+	d    float64
+	item T
+}
+
+type nearestSearch[T any] struct {
+	target vector
+	k      int
+	// found are ordered by the distance.
+	found []neighbour[T]
+}
+
+func (s *nearestSearch[T]) visit(nodes []indexNode[T], depth int) {
+	if len(nodes) == 0 {
+		return
+	}
+	m := len(nodes) / 2
+	node := nodes[m]
+	s.offer(node.v.distance2(s.target), node.item)
+
+	axis := depth % len(vector{})
+	diff := s.target[axis] - node.v[axis]
+	near, far := nodes[:m], nodes[m+1:]
+	if diff > 0 {
+		near, far = far, near
+	}
+	s.visit(near, depth+1)
+	// the far side is closer than the splitting plane.
+	if len(s.found) < s.k || diff*diff < s.found[len(s.found)-1].d {
+		s.visit(far, depth+1)
+	}
+}
+
+func (s *nearestSearch[T]) offer(d float64, item T) {
+	if len(s.found) == s.k && d >= s.found[len(s.found)-1].d {
+		return
+	}
+	i := sort.Search(len(s.found), func(i int) bool { return s.found[i].d > d })
+	if len(s.found) < s.k {
+		s.found = append(s.found, neighbour[T]{})
+	}
+	copy(s.found[i+1:], s.found[i:])
+	s.found[i] = neighbour[T]{d: d, item: item}
+}
+
+func toVector(p Point) vector {
+	phi, lambda := radians(p.Lat), radians(p.Lon)
+	return vector{
+		math.Cos(phi) * math.Cos(lambda),
+		math.Cos(phi) * math.Sin(lambda),
+		math.Sin(phi),
+	}
+}
+
+func (v vector) distance2(w vector) float64 {
+	var d float64
+	for i := range v {
+		d += (v[i] - w[i]) * (v[i] - w[i])
+	}
+	return d
+}
@@ -0,0 +1,37 @@
+package geo
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex_Nearest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	points := make([]Point, 1000)
+	for i := range points {
+		points[i] = Point{Lat: rnd.Float64()*180 - 90, Lon: rnd.Float64()*360 - 180}
+	}
+	ix := NewIndex(points, func(p Point) Point { return p })
+	require.Equal(t, len(points), ix.Len())
+
+	targets := []Point{
+		{Lat: 34.707, Lon: 33.022},
+		// across the antimeridian and at the pole.
+		{Lat: 10, Lon: 179.9},
+		{Lat: 90, Lon: 0},
+	}
+	for _, target := range targets {
+		want := append([]Point(nil), points...)
+		sort.Slice(want, func(i, j int) bool {
+			return Distance(target, want[i]) < Distance(target, want[j])
+		})
+		require.Equal(t, want[:10], ix.Nearest(target, 10), "around %v", target)
+	}
+
+	require.Len(t, ix.Nearest(Point{}, len(points)+1), len(points))
+	require.Empty(t, ix.Nearest(Point{}, 0))
+	require.Empty(t, NewIndex(nil, func(p Point) Point { return p }).Nearest(Point{}, 1))
+}
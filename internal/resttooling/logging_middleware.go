@@ -153,6 +153,11 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController flush streamed responses.
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
+
 var httpPathKeyToIdKey = map[string]string{
 	"projects":  "project_id",
 	"branches":  "branch_id",